@@ -15,14 +15,43 @@ func ServiceDefaults() Configuration {
 		"service.name": "assets",
 
 		// WebServer
-		"webserver.port": "8080",
+		"webserver.port":            "8080",
+		"webserver.trusted_proxies": []string{},
 
 		// Database
 		"cassandra.cluster.ip":       "cassandra",
 		"cassandra.cluster.keyspace": "assets_service",
 
 		// Auth
-		"auth.secret": "Ao8Qg52wYPhIzND",
+		"auth.secret":                  "Ao8Qg52wYPhIzND",
+		"auth.admins":                  []string{},
+		"auth.session.lifetime":        "24h",
+		"auth.access_token.lifetime":   "15m",
+		"auth.refresh_token.lifetime":  "720h",
+		"auth.password_reset.lifetime": "1h",
+		"auth.verification.lifetime":   "48h",
+		"auth.verification.url":        "http://localhost:8080/api/users/verify",
+		"auth.verification.required":   false,
+		"auth.lockout.email_threshold": 5,
+		"auth.lockout.ip_threshold":    20,
+		"auth.lockout.window":          "15m",
+		"auth.lockout.duration":        "15m",
+		"auth.totp.challenge_lifetime": "5m",
+		"auth.oidc.issuer":             "",
+		"auth.oidc.client_id":          "",
+		"auth.oidc.client_secret":      "",
+		"auth.oidc.redirect_url":       "http://localhost:8080/api/users/oidc/callback",
+		"auth.oidc.scopes":             []string{"openid", "email", "profile"},
+		"auth.oidc.state_lifetime":     "10m",
+		"auth.oidc.auto_provision":     true,
+
+		// Assets
+		"assets.trash.retention":      "720h",
+		"assets.trash.purge_interval": "1h",
+		"assets.render.cache_size":    256,
+
+		// Mailer
+		"mailer.file": "",
 	}
 }
 
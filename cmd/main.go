@@ -3,26 +3,52 @@ package main
 import (
 	"assets/cfg"
 	assets_itc "assets/internal/core/interactors/assets"
+	audit_itc "assets/internal/core/interactors/audit"
+	collections_itc "assets/internal/core/interactors/collections"
 	favourites_itc "assets/internal/core/interactors/favourites"
+	keys_itc "assets/internal/core/interactors/keys"
+	tokens_itc "assets/internal/core/interactors/tokens"
 	users_itc "assets/internal/core/interactors/users"
+	"assets/internal/core/policies"
+	"assets/internal/core/ports"
 	assets_hl "assets/internal/handlers/assets"
+	audit_hl "assets/internal/handlers/audit"
+	auth_hl "assets/internal/handlers/auth"
+	collections_hl "assets/internal/handlers/collections"
 	favourites_hl "assets/internal/handlers/favourites"
+	keys_hl "assets/internal/handlers/keys"
 	users_hl "assets/internal/handlers/users"
+	"assets/internal/mailers"
+	"assets/internal/providers"
+	"assets/internal/render"
 	assets_db "assets/internal/repositories/assets"
+	attempts_db "assets/internal/repositories/attempts"
 	audiences_db "assets/internal/repositories/audiences"
+	audit_db "assets/internal/repositories/audit"
 	charts_db "assets/internal/repositories/charts"
+	collections_db "assets/internal/repositories/collections"
 	favourites_db "assets/internal/repositories/favourites"
+	grants_db "assets/internal/repositories/grants"
 	insights_db "assets/internal/repositories/insights"
+	keys_db "assets/internal/repositories/keys"
+	migrations_db "assets/internal/repositories/migrations"
+	onetimetokens_db "assets/internal/repositories/onetimetokens"
 	sessions_db "assets/internal/repositories/sessions"
+	tokens_db "assets/internal/repositories/tokens"
 	users_db "assets/internal/repositories/users"
+	versions_db "assets/internal/repositories/versions"
+	"assets/internal/search"
+	"assets/pkg/jobs"
 	"assets/pkg/logging"
 	"assets/pkg/validation"
+	"context"
 	"fmt"
 	"github.com/gocql/gocql"
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 	"log"
+	"net"
 	"net/http"
 	"time"
 )
@@ -65,6 +91,12 @@ func loadComponents() (webServer *echo.Echo, err error) {
 	validator := validation.NewDefaultValidator()
 	webServer = echo.New()
 
+	// client address counts towards login lockout, so it is taken from forwarding headers only when they were added by
+	// trusted proxies, otherwise clients could pick any address they like
+	if webServer.IPExtractor, err = prepareIPExtractor(viper.GetStringSlice("webserver.trusted_proxies")); err != nil {
+		return nil, errors.Wrap(err, "failed to parse trusted proxies")
+	}
+
 	webServer.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			if c.Request().Method == http.MethodPost && c.Request().Header.Get("Content-Type") != "application/json" {
@@ -111,17 +143,128 @@ func loadComponents() (webServer *echo.Echo, err error) {
 	audiencesRepo := audiences_db.NewCassandraRepo(logger, session)
 	favouritesRepo := favourites_db.NewCassandraRepo(logger, session)
 	assetsRepo := assets_db.NewCassandraRepo(logger, session, chartsRepo, insightsRepo, audiencesRepo)
-	sessionsRepo := sessions_db.NewCassandraRepo(logger, session)
+	tokensRepo := tokens_db.NewCassandraRepo(logger, session)
+	oneTimeTokensRepo := onetimetokens_db.NewCassandraRepo(logger, session)
+	attemptsRepo := attempts_db.NewCassandraRepo(logger, session)
+	keysRepo := keys_db.NewCassandraRepo(logger, session)
+	auditRepo := audit_db.NewCassandraRepo(logger, session)
+	versionsRepo := versions_db.NewCassandraRepo(logger, session)
+	grantsRepo := grants_db.NewCassandraRepo(logger, session)
+	collectionsRepo := collections_db.NewCassandraRepo(logger, session)
+	sessionsRepo := sessions_db.NewCassandraRepo(logger, session, viper.GetDuration("auth.session.lifetime"))
+	migrationsRepo := migrations_db.NewCassandraRepo(logger, session)
+
+	/// migrations bring data stored by previous versions in line with current tables, each of them runs once
+	if err = migrationsRepo.Apply(context.Background(), "assets_tags_backfill", assetsRepo.BackfillTags); err != nil {
+		return nil, errors.Wrap(err, "failed to migrate assets")
+	}
+
+	if err = migrationsRepo.Apply(context.Background(), "assets_order_backfill", assetsRepo.BackfillOrder); err != nil {
+		return nil, errors.Wrap(err, "failed to migrate assets")
+	}
+
+	if err = migrationsRepo.Apply(context.Background(), "assets_owner_tags_backfill", assetsRepo.BackfillOwnerTags); err != nil {
+		return nil, errors.Wrap(err, "failed to migrate assets")
+	}
+
+	if err = migrationsRepo.Apply(context.Background(), "grants_grantees_backfill", grantsRepo.BackfillGrantees); err != nil {
+		return nil, errors.Wrap(err, "failed to migrate grants")
+	}
+
+	if err = migrationsRepo.Apply(context.Background(), "users_backfill", usersRepo.Backfill); err != nil {
+		return nil, errors.Wrap(err, "failed to migrate users")
+	}
+
+	/// search
+	searchIndex := search.NewInvertedIndex()
+
+	/// renderers
+	renderer := render.NewCache(render.NewChartRenderer(), viper.GetInt("assets.render.cache_size"))
+
+	/// mailers
+	mailer := mailers.NewLogMailer(logger, viper.GetString("mailer.file"))
+
+	/// identity providers
+	var identityProvider ports.IdentityProvider
+	if viper.GetString("auth.oidc.issuer") != "" {
+		identityProvider = providers.NewOidcProvider(logger, nil, providers.OidcConfig{
+			Issuer:       viper.GetString("auth.oidc.issuer"),
+			ClientId:     viper.GetString("auth.oidc.client_id"),
+			ClientSecret: viper.GetString("auth.oidc.client_secret"),
+			RedirectUrl:  viper.GetString("auth.oidc.redirect_url"),
+			Scopes:       viper.GetStringSlice("auth.oidc.scopes"),
+		})
+	}
+
+	/// policies
+	policy := policies.NewRolePolicy()
 
 	/// interactors
-	usersItc := users_itc.NewInteractor(logger, validator, usersRepo)
-	favouritesItc := favourites_itc.NewInteractor(logger, validator, favouritesRepo, usersRepo, assetsRepo)
-	assetsItc := assets_itc.NewInteractor(logger, validator, assetsRepo, chartsRepo, insightsRepo, audiencesRepo, favouritesRepo)
+	usersItc := users_itc.NewInteractor(logger, validator, usersRepo, oneTimeTokensRepo, attemptsRepo, favouritesRepo, keysRepo, mailer, identityProvider, policy, users_itc.Settings{
+		Admins:               viper.GetStringSlice("auth.admins"),
+		ResetLifetime:        viper.GetDuration("auth.password_reset.lifetime"),
+		VerificationLifetime: viper.GetDuration("auth.verification.lifetime"),
+		VerificationUrl:      viper.GetString("auth.verification.url"),
+		RequireVerified:      viper.GetBool("auth.verification.required"),
+
+		LockoutEmailThreshold: viper.GetInt("auth.lockout.email_threshold"),
+		LockoutIpThreshold:    viper.GetInt("auth.lockout.ip_threshold"),
+		LockoutWindow:         viper.GetDuration("auth.lockout.window"),
+		LockoutDuration:       viper.GetDuration("auth.lockout.duration"),
+
+		TotpIssuer:        viper.GetString("service.name"),
+		ChallengeLifetime: viper.GetDuration("auth.totp.challenge_lifetime"),
+
+		OidcStateLifetime: viper.GetDuration("auth.oidc.state_lifetime"),
+		OidcAutoProvision: viper.GetBool("auth.oidc.auto_provision"),
+	})
+	auditItc := audit_itc.NewInteractor(logger, validator, auditRepo, policy)
+	favouritesItc := favourites_itc.NewInteractor(logger, validator, favouritesRepo, usersRepo, assetsRepo, auditItc)
+	assetsItc := assets_itc.NewInteractor(logger, validator, assetsRepo, chartsRepo, insightsRepo, audiencesRepo, favouritesRepo, versionsRepo, grantsRepo, collectionsRepo, usersRepo, searchIndex, renderer, auditItc, policy)
+	collectionsItc := collections_itc.NewInteractor(logger, validator, collectionsRepo, assetsItc, auditItc)
+	keysItc := keys_itc.NewInteractor(logger, validator, keysRepo, usersRepo, viper.GetBool("auth.verification.required"))
+	tokensItc := tokens_itc.NewInteractor(logger, validator, tokensRepo, usersRepo, viper.GetDuration("auth.refresh_token.lifetime"), viper.GetBool("auth.verification.required"))
 
 	/// handlers
-	users_hl.Init(webServer, logger, usersItc, sessionsRepo, viper.GetString("auth.secret"))
-	favourites_hl.Init(webServer, logger, favouritesItc)
-	assets_hl.Init(webServer, logger, assetsItc)
+	authenticator := auth_hl.NewAuthenticator(logger, sessionsRepo, keysItc, usersRepo, viper.GetString("auth.secret"), viper.GetDuration("auth.access_token.lifetime"))
+
+	users_hl.Init(webServer, logger, usersItc, tokensItc, authenticator, viper.GetBool("auth.verification.required"))
+	keys_hl.Init(webServer, logger, keysItc, authenticator.Authenticate)
+	favourites_hl.Init(webServer, logger, favouritesItc, authenticator.Authenticate)
+	assets_hl.Init(webServer, logger, assetsItc, authenticator.Authenticate)
+	audit_hl.Init(webServer, logger, auditItc, authenticator.Authenticate)
+	collections_hl.Init(webServer, logger, collectionsItc, authenticator.Authenticate)
+
+	/// search index is kept in memory, so it is rebuilt on every start
+	if _, err = assetsItc.Reindex(context.Background()); err != nil {
+		return nil, errors.Wrap(err, "failed to build search index")
+	}
+
+	/// jobs
+	jobs.Every(context.Background(), logger, "assets_purge", viper.GetDuration("assets.trash.purge_interval"), func(ctx context.Context) error {
+		_, err := assetsItc.Purge(ctx, ports.PurgeAssetsItcParams{
+			DeletedBefore: time.Now().Add(-viper.GetDuration("assets.trash.retention")),
+		})
+		return err
+	})
 
 	return webServer, nil
 }
+
+func prepareIPExtractor(proxies []string) (echo.IPExtractor, error) {
+	if len(proxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	// only listed ranges are trusted, private networks aren't trusted by default
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, proxy := range proxies {
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
@@ -18,6 +18,25 @@ func Types() []Type {
 	return []Type{TypeChart, TypeInsight, TypeAudience}
 }
 
+/*
+ * Sort
+ */
+
+type (
+	Sort = string
+)
+
+const (
+	SortCreateTimeAsc  Sort = "create_time"
+	SortCreateTimeDesc Sort = "-create_time"
+	SortNameAsc        Sort = "name"
+	SortNameDesc       Sort = "-name"
+)
+
+func Sorts() []Sort {
+	return []Sort{SortCreateTimeAsc, SortCreateTimeDesc, SortNameAsc, SortNameDesc}
+}
+
 /*
  * Gender
  */
@@ -53,3 +72,39 @@ const (
 func AgeGroups() []AgeGroup {
 	return []AgeGroup{AgeGroup18TO24, AgeGroup24TO35, AgeGroup35To45, AgeGroup46AndMore}
 }
+
+/*
+ * ChartKind
+ */
+
+type (
+	ChartKind = string
+)
+
+const (
+	ChartKindLine    ChartKind = "LINE"
+	ChartKindBar     ChartKind = "BAR"
+	ChartKindPie     ChartKind = "PIE"
+	ChartKindScatter ChartKind = "SCATTER"
+)
+
+func ChartKinds() []ChartKind {
+	return []ChartKind{ChartKindLine, ChartKindBar, ChartKindPie, ChartKindScatter}
+}
+
+/*
+ * RenderFormat
+ */
+
+type (
+	RenderFormat = string
+)
+
+const (
+	RenderFormatSvg RenderFormat = "svg"
+	RenderFormatPng RenderFormat = "png"
+)
+
+func RenderFormats() []RenderFormat {
+	return []RenderFormat{RenderFormatSvg, RenderFormatPng}
+}
@@ -1,7 +1,11 @@
 package assets_dm
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/google/uuid"
+	"math"
 	"time"
 )
 
@@ -15,13 +19,19 @@ type Asset struct {
 	Name        string            `validate:"required,max=32" json:"name"`
 	Description string            `validate:"required,max=8192" json:"description"`
 	AssetData   AssetDataEntities `validate:"required" json:"asset_data"`
+	Tags        []string          `validate:"max=32,dive,min=1,max=32" json:"tags"`
 }
 
+// AssetEntity is moved to the trash by setting DeleteTime, trashed assets are purged after the retention period.
+// Assets without OwnerId were created before ownership was recorded, they are accessible to everyone.
 type AssetEntity struct {
 	Asset
-	Id         string    `validate:"required,uuid" json:"id"`
-	CreateTime time.Time `validate:"required" json:"create_time"`
-	UpdateTime time.Time `validate:"required" json:"update_time"`
+	Id         string     `validate:"required,uuid" json:"id"`
+	CreatorId  string     `validate:"omitempty,uuid" json:"creator_id"`
+	OwnerId    string     `validate:"omitempty,uuid" json:"owner_id"`
+	CreateTime time.Time  `validate:"required" json:"create_time"`
+	UpdateTime time.Time  `validate:"required" json:"update_time"`
+	DeleteTime *time.Time `json:"delete_time,omitempty"`
 }
 
 type AssetData struct {
@@ -46,15 +56,33 @@ func NewAssetEntity() AssetEntity {
 	}
 }
 
+// TagCount tells how many live assets are labelled with the tag.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// SearchResult is the asset found by text search together with its relevance, higher score is more relevant.
+type SearchResult struct {
+	Asset AssetEntity `json:"asset"`
+	Score float64     `json:"score"`
+}
+
+// Image is the asset drawn in given format.
+type Image struct {
+	Format RenderFormat `json:"format"`
+	Data   []byte       `json:"data"`
+}
+
 /*
  * ChartEntity
  */
 
 type Chart struct {
-	ChartTitle string `validate:"required,max=32" json:"title"`
-	XAxisTitle string `validate:"required,max=32" json:"x_axis_title"`
-	YAxisTitle string `validate:"required,max=32" json:"y_axis_title"`
-	Data       any    `validate:"required" json:"data"`
+	ChartTitle string    `validate:"required,max=32" json:"title"`
+	XAxisTitle string    `validate:"required,max=32" json:"x_axis_title"`
+	YAxisTitle string    `validate:"required,max=32" json:"y_axis_title"`
+	Data       ChartData `validate:"required" json:"data"`
 }
 
 type ChartEntity struct {
@@ -74,6 +102,102 @@ func NewChartEntity() ChartEntity {
 	}
 }
 
+// ChartData holds named series of points drawn by the chart. Points of all series share type of x values, which are
+// either numbers or moments in time. Every series of the pie chart is a single slice sized by sum of its y values.
+type ChartData struct {
+	Kind   ChartKind `validate:"required,oneof=LINE BAR PIE SCATTER" json:"kind"`
+	XUnit  string    `validate:"max=32" json:"x_unit"`
+	YUnit  string    `validate:"max=32" json:"y_unit"`
+	Series []Series  `validate:"required,min=1,max=32,unique=Name,dive" json:"series"`
+}
+
+type Series struct {
+	Name   string  `validate:"required,max=64" json:"name"`
+	Points []Point `validate:"required,min=1,max=10000,dive" json:"points"`
+}
+
+type Point struct {
+	X XValue  `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Check implements validation.Checker with rules spanning points of all series.
+func (d ChartData) Check() error {
+	temporal := len(d.Series) != 0 && len(d.Series[0].Points) != 0 && d.Series[0].Points[0].X.Time != nil
+
+	for _, series := range d.Series {
+		for _, point := range series.Points {
+			switch {
+			case (point.X.Number == nil) == (point.X.Time == nil):
+				return fmt.Errorf("point of series %q has to have either numeric or temporal x", series.Name)
+			case (point.X.Time != nil) != temporal:
+				return errors.New("x values of all points have to be of the same type")
+			case (point.X.Number != nil && !finite(*point.X.Number)) || !finite(point.Y):
+				return fmt.Errorf("point of series %q has to have finite values", series.Name)
+			case d.Kind == ChartKindPie && point.Y < 0:
+				return fmt.Errorf("slice %q of pie chart cannot have negative values", series.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// XValue is x value of the point, either a number or a moment in time. It is written to JSON as a number or as RFC 3339
+// string respectively.
+type XValue struct {
+	Number *float64
+	Time   *time.Time
+}
+
+func NumberX(value float64) XValue {
+	return XValue{Number: &value}
+}
+
+func TimeX(value time.Time) XValue {
+	return XValue{Time: &value}
+}
+
+func (x XValue) MarshalJSON() ([]byte, error) {
+	switch {
+	case x.Time != nil:
+		return json.Marshal(x.Time.Format(time.RFC3339Nano))
+	case x.Number != nil:
+		return json.Marshal(*x.Number)
+	}
+
+	return []byte("null"), nil
+}
+
+func (x *XValue) UnmarshalJSON(data []byte) (err error) {
+	*x = XValue{}
+
+	var value any
+	if err = json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch typed := value.(type) {
+	case nil:
+	case float64:
+		x.Number = &typed
+	case string:
+		var moment time.Time
+		if moment, err = time.Parse(time.RFC3339Nano, typed); err != nil {
+			return fmt.Errorf("x value has to be a number or RFC 3339 time: %w", err)
+		}
+		x.Time = &moment
+	default:
+		return errors.New("x value has to be a number or RFC 3339 time")
+	}
+
+	return nil
+}
+
+func finite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
+
 /*
  * InsightEntity
  */
@@ -0,0 +1,38 @@
+package attempts_dm
+
+import (
+	"time"
+)
+
+/*
+ * LoginAttempt
+ */
+
+type LoginAttempt struct {
+	Failures       int       `validate:"gte=0" json:"failures"`
+	LockExpireTime time.Time `json:"lock_expire_time"`
+	ExpireTime     time.Time `validate:"required" json:"expire_time"`
+}
+
+// LoginAttemptEntity tracks failed logins of single email or client address, Id is the tracked key.
+type LoginAttemptEntity struct {
+	LoginAttempt
+	Id         string    `validate:"required" json:"id"`
+	CreateTime time.Time `validate:"required" json:"create_time"`
+	UpdateTime time.Time `validate:"required" json:"update_time"`
+}
+
+func NewLoginAttemptEntity(id string) LoginAttemptEntity {
+	now := time.Now()
+
+	return LoginAttemptEntity{
+		Id:         id,
+		CreateTime: now,
+		UpdateTime: now,
+	}
+}
+
+// Locked reports whether login is blocked at given time.
+func (e LoginAttemptEntity) Locked(now time.Time) bool {
+	return e.LockExpireTime.After(now)
+}
@@ -0,0 +1,37 @@
+package audit_dm
+
+/*
+ * Action
+ */
+
+type (
+	Action = string
+)
+
+const (
+	ActionInsert Action = "INSERT"
+	ActionUpdate Action = "UPDATE"
+	ActionDelete Action = "DELETE"
+)
+
+func Actions() []Action {
+	return []Action{ActionInsert, ActionUpdate, ActionDelete}
+}
+
+/*
+ * EntityType
+ */
+
+type (
+	EntityType = string
+)
+
+const (
+	EntityTypeAsset      EntityType = "ASSET"
+	EntityTypeFavourite  EntityType = "FAVOURITE"
+	EntityTypeCollection EntityType = "COLLECTION"
+)
+
+func EntityTypes() []EntityType {
+	return []EntityType{EntityTypeAsset, EntityTypeFavourite, EntityTypeCollection}
+}
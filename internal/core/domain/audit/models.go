@@ -0,0 +1,38 @@
+package audit_dm
+
+import (
+	"assets/pkg/diff"
+	"github.com/google/uuid"
+	"time"
+)
+
+/*
+ * Entry
+ */
+
+// Entry records single modification of an entity, diff lists every changed field. Actor is empty when the change
+// wasn't made on behalf of any user.
+type Entry struct {
+	ActorId    string        `validate:"omitempty,uuid" json:"actor_id"`
+	Action     Action        `validate:"required,oneof=INSERT UPDATE DELETE" json:"action"`
+	EntityType EntityType    `validate:"required,oneof=ASSET FAVOURITE COLLECTION" json:"entity_type"`
+	EntityId   string        `validate:"required" json:"entity_id"`
+	Diff       []diff.Change `json:"diff"`
+}
+
+type EntryEntity struct {
+	Entry
+	Id         string    `validate:"required,uuid" json:"id"`
+	CreateTime time.Time `validate:"required" json:"create_time"`
+	UpdateTime time.Time `validate:"required" json:"update_time"`
+}
+
+func NewEntryEntity() EntryEntity {
+	now := time.Now()
+
+	return EntryEntity{
+		Id:         uuid.NewString(),
+		CreateTime: now,
+		UpdateTime: now,
+	}
+}
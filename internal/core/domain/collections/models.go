@@ -0,0 +1,59 @@
+package collections_dm
+
+import (
+	assets_dm "assets/internal/core/domain/assets"
+	"github.com/google/uuid"
+	"time"
+)
+
+/*
+ * Item
+ */
+
+// Item places the asset on the board of the collection. Position and size are measured in cells of the board grid.
+type Item struct {
+	AssetId string `validate:"required,uuid" json:"asset_id"`
+	X       int    `validate:"gte=0,lte=1000" json:"x"`
+	Y       int    `validate:"gte=0,lte=1000" json:"y"`
+	Width   int    `validate:"gte=1,lte=24" json:"width"`
+	Height  int    `validate:"gte=1,lte=24" json:"height"`
+}
+
+/*
+ * Collection
+ */
+
+// Collection is an ordered list of assets building a board, every asset can be placed on it only once.
+type Collection struct {
+	OwnerId     string `validate:"required,uuid" json:"owner_id"`
+	Name        string `validate:"required,min=1,max=128" json:"name"`
+	Description string `validate:"max=8192" json:"description"`
+	Items       []Item `validate:"max=100,unique=AssetId,dive" json:"items"`
+}
+
+type CollectionEntity struct {
+	Collection
+	Id         string    `validate:"required,uuid" json:"id"`
+	CreateTime time.Time `validate:"required" json:"create_time"`
+	UpdateTime time.Time `validate:"required" json:"update_time"`
+}
+
+func NewCollectionEntity() CollectionEntity {
+	now := time.Now()
+
+	return CollectionEntity{
+		Id:         uuid.NewString(),
+		CreateTime: now,
+		UpdateTime: now,
+	}
+}
+
+/*
+ * PopulatedCollection
+ */
+
+// PopulatedCollection comes with assets of its items, in order of the items.
+type PopulatedCollection struct {
+	CollectionEntity
+	Assets []assets_dm.AssetEntity `json:"assets"`
+}
@@ -0,0 +1,35 @@
+package grants_dm
+
+/*
+ * GranteeType
+ */
+
+type (
+	GranteeType = string
+)
+
+const (
+	GranteeTypeUser  GranteeType = "USER"
+	GranteeTypeGroup GranteeType = "GROUP"
+)
+
+func GranteeTypes() []GranteeType {
+	return []GranteeType{GranteeTypeUser, GranteeTypeGroup}
+}
+
+/*
+ * Permission
+ */
+
+type (
+	Permission = string
+)
+
+const (
+	PermissionRead Permission = "READ"
+	PermissionEdit Permission = "EDIT"
+)
+
+func Permissions() []Permission {
+	return []Permission{PermissionRead, PermissionEdit}
+}
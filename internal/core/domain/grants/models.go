@@ -0,0 +1,36 @@
+package grants_dm
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+/*
+ * Grant
+ */
+
+// Grant shares the asset with a user or with a group. Groups are user roles, so the grant applies to every user
+// holding the role. Edit permission includes read permission.
+type Grant struct {
+	AssetId     string      `validate:"required,uuid" json:"asset_id"`
+	GranteeType GranteeType `validate:"required,oneof=USER GROUP" json:"grantee_type"`
+	GranteeId   string      `validate:"required,max=64" json:"grantee_id"`
+	Permission  Permission  `validate:"required,oneof=READ EDIT" json:"permission"`
+}
+
+type GrantEntity struct {
+	Grant
+	Id         string    `validate:"required,uuid" json:"id"`
+	CreateTime time.Time `validate:"required" json:"create_time"`
+	UpdateTime time.Time `validate:"required" json:"update_time"`
+}
+
+func NewGrantEntity() GrantEntity {
+	now := time.Now()
+
+	return GrantEntity{
+		Id:         uuid.NewString(),
+		CreateTime: now,
+		UpdateTime: now,
+	}
+}
@@ -0,0 +1,22 @@
+package keys_dm
+
+/*
+ * Scope
+ */
+
+type (
+	Scope = string
+)
+
+const (
+	ScopeAssetsRead       Scope = "assets:read"
+	ScopeAssetsWrite      Scope = "assets:write"
+	ScopeFavouritesRead   Scope = "favourites:read"
+	ScopeFavouritesWrite  Scope = "favourites:write"
+	ScopeCollectionsRead  Scope = "collections:read"
+	ScopeCollectionsWrite Scope = "collections:write"
+)
+
+func Scopes() []Scope {
+	return []Scope{ScopeAssetsRead, ScopeAssetsWrite, ScopeFavouritesRead, ScopeFavouritesWrite, ScopeCollectionsRead, ScopeCollectionsWrite}
+}
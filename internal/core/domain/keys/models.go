@@ -0,0 +1,39 @@
+package keys_dm
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Prefix distinguishes api keys from other bearer tokens.
+const Prefix = "ak_"
+
+/*
+ * ApiKey
+ */
+
+type ApiKey struct {
+	UserId       string    `validate:"required,uuid" json:"user_id"`
+	Name         string    `validate:"required,max=64" json:"name"`
+	Scopes       []Scope   `validate:"required,min=1,dive,oneof=assets:read assets:write favourites:read favourites:write collections:read collections:write" json:"scopes"`
+	Hash         string    `validate:"required" json:"-"`
+	LastUsedTime time.Time `json:"last_used_time"`
+}
+
+type ApiKeyEntity struct {
+	ApiKey
+	Key        string    `json:"key,omitempty"`
+	Id         string    `validate:"required,uuid" json:"id"`
+	CreateTime time.Time `validate:"required" json:"create_time"`
+	UpdateTime time.Time `validate:"required" json:"update_time"`
+}
+
+func NewApiKeyEntity() ApiKeyEntity {
+	now := time.Now()
+
+	return ApiKeyEntity{
+		Id:         uuid.NewString(),
+		CreateTime: now,
+		UpdateTime: now,
+	}
+}
@@ -0,0 +1,20 @@
+package tokens_dm
+
+/*
+ * Purpose
+ */
+
+type (
+	Purpose = string
+)
+
+const (
+	PurposePasswordReset     Purpose = "PASSWORD_RESET"
+	PurposeEmailVerification Purpose = "EMAIL_VERIFICATION"
+	PurposeLoginChallenge    Purpose = "LOGIN_CHALLENGE"
+	PurposeOidcLogin         Purpose = "OIDC_LOGIN"
+)
+
+func Purposes() []Purpose {
+	return []Purpose{PurposePasswordReset, PurposeEmailVerification, PurposeLoginChallenge, PurposeOidcLogin}
+}
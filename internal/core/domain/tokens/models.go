@@ -0,0 +1,70 @@
+package tokens_dm
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+/*
+ * RefreshToken
+ */
+
+// RefreshToken keeps id of the session it was issued along with, so that refreshing continues the same session.
+type RefreshToken struct {
+	UserId     string    `validate:"required,uuid" json:"user_id"`
+	FamilyId   string    `validate:"required,uuid" json:"family_id"`
+	SessionId  string    `json:"session_id"`
+	Hash       string    `validate:"required" json:"-"`
+	Used       bool      `json:"used"`
+	ExpireTime time.Time `validate:"required" json:"expire_time"`
+}
+
+type RefreshTokenEntity struct {
+	RefreshToken
+	Token      string    `json:"token,omitempty"`
+	Id         string    `validate:"required,uuid" json:"id"`
+	CreateTime time.Time `validate:"required" json:"create_time"`
+	UpdateTime time.Time `validate:"required" json:"update_time"`
+}
+
+func NewRefreshTokenEntity() RefreshTokenEntity {
+	now := time.Now()
+
+	return RefreshTokenEntity{
+		Id:         uuid.NewString(),
+		CreateTime: now,
+		UpdateTime: now,
+	}
+}
+
+/*
+ * OneTimeToken
+ */
+
+// OneTimeToken is issued either to the user or, like OIDC login state, before the user is known. Payload keeps data
+// bound to the token which must not leave the service.
+type OneTimeToken struct {
+	UserId     string            `validate:"omitempty,uuid" json:"user_id"`
+	Purpose    Purpose           `validate:"required,oneof=PASSWORD_RESET EMAIL_VERIFICATION LOGIN_CHALLENGE OIDC_LOGIN" json:"purpose"`
+	Hash       string            `validate:"required" json:"-"`
+	Payload    map[string]string `json:"-"`
+	ExpireTime time.Time         `validate:"required" json:"expire_time"`
+}
+
+type OneTimeTokenEntity struct {
+	OneTimeToken
+	Token      string    `json:"token,omitempty"`
+	Id         string    `validate:"required,uuid" json:"id"`
+	CreateTime time.Time `validate:"required" json:"create_time"`
+	UpdateTime time.Time `validate:"required" json:"update_time"`
+}
+
+func NewOneTimeTokenEntity() OneTimeTokenEntity {
+	now := time.Now()
+
+	return OneTimeTokenEntity{
+		Id:         uuid.NewString(),
+		CreateTime: now,
+		UpdateTime: now,
+	}
+}
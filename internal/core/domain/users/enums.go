@@ -0,0 +1,19 @@
+package users_dm
+
+/*
+ * Role
+ */
+
+type (
+	Role = string
+)
+
+const (
+	RoleViewer Role = "VIEWER"
+	RoleEditor Role = "EDITOR"
+	RoleAdmin  Role = "ADMIN"
+)
+
+func Roles() []Role {
+	return []Role{RoleViewer, RoleEditor, RoleAdmin}
+}
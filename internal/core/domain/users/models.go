@@ -9,18 +9,60 @@ import (
  * UserEntity
  */
 
+// User holds credentials, password hash and two-factor secrets are never serialized.
 type User struct {
 	Email    string `validate:"required,email" json:"email"`
-	Password string `validate:"required,max=32" json:"password"`
+	Password string `validate:"required,max=32" json:"-"`
+	Verified bool   `json:"verified"`
+	Disabled bool   `json:"disabled"`
+
+	TotpEnabled   bool     `json:"totp_enabled"`
+	TotpSecret    string   `json:"-"`
+	TotpLastStep  int64    `json:"-"`
+	RecoveryCodes []string `json:"-"`
 }
 
 type UserEntity struct {
 	User
+	Roles      []Role    `validate:"dive,oneof=VIEWER EDITOR ADMIN" json:"roles"`
 	Id         string    `validate:"required,uuid" json:"id"`
 	CreateTime time.Time `validate:"required" json:"create_time"`
 	UpdateTime time.Time `validate:"required" json:"update_time"`
 }
 
+/*
+ * TotpEnrollment
+ */
+
+// TotpEnrollment holds secret of pending two-factor enrollment, which has to be confirmed with a valid code.
+type TotpEnrollment struct {
+	Secret string `json:"secret"`
+	Uri    string `json:"uri"`
+}
+
+/*
+ * ExternalIdentity
+ */
+
+// ExternalIdentity describes the user as asserted by an external identity provider.
+type ExternalIdentity struct {
+	Subject       string `json:"subject"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+/*
+ * OidcAuthorization
+ */
+
+// OidcAuthorization holds address of the identity provider where the user signs in, state has to come back together
+// with the authorization code.
+type OidcAuthorization struct {
+	Url        string    `json:"url"`
+	State      string    `json:"state"`
+	ExpireTime time.Time `json:"expire_time"`
+}
+
 func NewUserEntity() UserEntity {
 	now := time.Now()
 
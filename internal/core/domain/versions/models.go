@@ -0,0 +1,36 @@
+package versions_dm
+
+import (
+	assets_dm "assets/internal/core/domain/assets"
+	"github.com/google/uuid"
+	"time"
+)
+
+/*
+ * Version
+ */
+
+// Version is immutable snapshot of the asset taken after every change, numbers grow by one per asset starting from 1.
+type Version struct {
+	AssetId  string                `validate:"required,uuid" json:"asset_id"`
+	Number   int                   `validate:"required,gt=0" json:"number"`
+	AuthorId string                `validate:"omitempty,uuid" json:"author_id"`
+	Snapshot assets_dm.AssetEntity `validate:"required" json:"snapshot"`
+}
+
+type VersionEntity struct {
+	Version
+	Id         string    `validate:"required,uuid" json:"id"`
+	CreateTime time.Time `validate:"required" json:"create_time"`
+	UpdateTime time.Time `validate:"required" json:"update_time"`
+}
+
+func NewVersionEntity() VersionEntity {
+	now := time.Now()
+
+	return VersionEntity{
+		Id:         uuid.NewString(),
+		CreateTime: now,
+		UpdateTime: now,
+	}
+}
@@ -0,0 +1,142 @@
+package assets_itc
+
+import (
+	assets_dm "assets/internal/core/domain/assets"
+	grants_dm "assets/internal/core/domain/grants"
+	users_dm "assets/internal/core/domain/users"
+	"assets/internal/core/ports"
+	errs "assets/pkg/errors"
+	"assets/pkg/identity"
+	"assets/pkg/slices"
+	"context"
+	"errors"
+)
+
+// access is level of access to the asset, every level includes the lower ones.
+type access int
+
+const (
+	accessRead access = iota
+	accessEdit
+	accessOwn
+)
+
+// accessible filters models the caller can access on given level, order of models is preserved.
+func (i *Interactor) accessible(ctx context.Context, models []assets_dm.AssetEntity, level access) (results []assets_dm.AssetEntity, err error) {
+	caller, _ := identity.FromContext(ctx)
+
+	// grants are looked up only for assets the caller neither owns nor can access otherwise
+	shared := slices.Filter(models, func(model assets_dm.AssetEntity) bool {
+		return !canAccess(caller, model, nil, level)
+	})
+
+	var grants []grants_dm.GrantEntity
+	if len(shared) != 0 && level != accessOwn {
+		if grants, _, err = i.grantsRepo.Select(ctx, ports.SelectGrantsRepoParams{
+			AssetIds: slices.Map(shared, func(model assets_dm.AssetEntity) string { return model.Id }),
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, model := range models {
+		if canAccess(caller, model, grants, level) {
+			results = append(results, model)
+		}
+	}
+
+	return results, nil
+}
+
+// selectAccessible reads page of assets the caller can access on given level. Assets the caller cannot access are
+// dropped, so following pages are read until the page is full. Every page is read only up to the number of missing
+// assets, so the cursor points right after the last returned asset.
+func (i *Interactor) selectAccessible(ctx context.Context, params ports.SelectAssetsRepoParams, level access) (results []assets_dm.AssetEntity, cursor string, err error) {
+
+	limit := params.Limit
+
+	for {
+		var models []assets_dm.AssetEntity
+		if models, cursor, err = i.assetsRepo.Select(ctx, params); err != nil {
+			return nil, cursor, err
+		}
+
+		if models, err = i.accessible(ctx, models, level); err != nil {
+			return nil, cursor, err
+		}
+
+		results = append(results, models...)
+
+		if limit == 0 || cursor == "" || len(results) >= limit {
+			return results, cursor, nil
+		}
+
+		params.Cursor = cursor
+		params.Limit = limit - len(results)
+	}
+}
+
+// authorizeAccess fails unless the caller can access all models on given level.
+func (i *Interactor) authorizeAccess(ctx context.Context, models []assets_dm.AssetEntity, level access) (err error) {
+	var results []assets_dm.AssetEntity
+	if results, err = i.accessible(ctx, models, level); err != nil {
+		return errors.Join(errs.ProcessingError, err)
+	}
+
+	if len(results) != len(models) {
+		return errors.Join(errs.PermissionError, errors.New("asset is not shared with the caller"))
+	}
+
+	return nil
+}
+
+// selectAsset loads single live asset which the caller can access on given level.
+func (i *Interactor) selectAsset(ctx context.Context, id string, level access) (result assets_dm.AssetEntity, err error) {
+	var models []assets_dm.AssetEntity
+	if models, _, err = i.assetsRepo.Select(ctx, ports.SelectAssetsRepoParams{
+		Ids: []string{id},
+	}); err != nil {
+		return result, errors.Join(errs.ProcessingError, err)
+	}
+
+	if len(models) == 0 {
+		return result, errors.Join(errs.CannotBeFoundError, errors.New("asset cannot be found"))
+	}
+
+	if err = i.authorizeAccess(ctx, models, level); err != nil {
+		return result, err
+	}
+
+	return models[0], nil
+}
+
+// canAccess tells whether the caller can access the asset. Admins and owners have full access, assets without owner
+// are accessible to everyone and grants give read or edit access to users and groups they name.
+func canAccess(caller identity.Identity, model assets_dm.AssetEntity, grants []grants_dm.GrantEntity, level access) bool {
+	if model.OwnerId == "" || slices.Contains(caller.Roles, users_dm.RoleAdmin) || (caller.UserId != "" && model.OwnerId == caller.UserId) {
+		return true
+	}
+
+	if level == accessOwn {
+		return false
+	}
+
+	for _, grant := range grants {
+		if grant.AssetId != model.Id || (level == accessEdit && grant.Permission != grants_dm.PermissionEdit) {
+			continue
+		}
+
+		switch grant.GranteeType {
+		case grants_dm.GranteeTypeUser:
+			if caller.UserId != "" && grant.GranteeId == caller.UserId {
+				return true
+			}
+		case grants_dm.GranteeTypeGroup:
+			if slices.Contains(caller.Roles, grant.GranteeId) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -4,8 +4,13 @@ import "assets/internal/core/ports"
 
 func convertSelectParams(params ports.SelectAssetsItcParams) (result ports.SelectAssetsRepoParams) {
 	return ports.SelectAssetsRepoParams{
-		Ids:    params.Ids,
-		Cursor: params.Cursor,
-		Limit:  params.Limit,
+		Ids:           params.Ids,
+		Tags:          normalizeTags(params.Tags),
+		Type:          params.Type,
+		CreatedAfter:  params.CreatedAfter,
+		CreatedBefore: params.CreatedBefore,
+		Sort:          params.Sort,
+		Cursor:        params.Cursor,
+		Limit:         params.Limit,
 	}
 }
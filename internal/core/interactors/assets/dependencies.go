@@ -4,6 +4,7 @@ import (
 	assets_dm "assets/internal/core/domain/assets"
 	"assets/internal/core/ports"
 	"context"
+	"errors"
 	"sync"
 )
 
@@ -81,7 +82,14 @@ func (i *Interactor) createDependencies(ctx context.Context, params ...ports.Ins
 	return charts, insights, audiences, mapper, nil
 }
 
-func (i *Interactor) deleteDependencies(ctx context.Context, models ...assets_dm.AssetEntity) (charts []assets_dm.ChartEntity, insights []assets_dm.InsightEntity, audiences []assets_dm.AudienceEntity, err error) {
+// deleteDependencies removes content of the assets, content which is already gone is skipped.
+func (i *Interactor) deleteDependencies(ctx context.Context, models ...assets_dm.AssetEntity) (err error) {
+
+	var (
+		charts    []assets_dm.ChartEntity
+		insights  []assets_dm.InsightEntity
+		audiences []assets_dm.AudienceEntity
+	)
 
 	for _, model := range models {
 		switch model.Type {
@@ -110,24 +118,113 @@ func (i *Interactor) deleteDependencies(ctx context.Context, models ...assets_dm
 
 	go func() {
 		defer wg.Done()
-		_, err = i.chartsRepo.Delete(ctx, charts...)
-		if err != nil {
+		if _, err := i.chartsRepo.Delete(ctx, charts...); err != nil {
 			errChan <- err
 		}
 	}()
 
 	go func() {
 		defer wg.Done()
-		_, err = i.insightsRepo.Delete(ctx, insights...)
-		if err != nil {
+		if _, err := i.insightsRepo.Delete(ctx, insights...); err != nil {
 			errChan <- err
 		}
 	}()
 
 	go func() {
 		defer wg.Done()
-		_, err = i.audiencesRepo.Delete(ctx, audiences...)
+		if _, err := i.audiencesRepo.Delete(ctx, audiences...); err != nil {
+			errChan <- err
+		}
+	}()
+
+	wg.Wait()
+
+	close(errChan)
+
+	for err = range errChan {
 		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dependencies groups content of assets by its type.
+type dependencies struct {
+	charts    []assets_dm.ChartEntity
+	insights  []assets_dm.InsightEntity
+	audiences []assets_dm.AudienceEntity
+}
+
+// selectDependencies loads current content of assets which are going to receive new asset data.
+func (i *Interactor) selectDependencies(ctx context.Context, params []ports.UpdateAssetItcParams, models []assets_dm.AssetEntity) (results dependencies, err error) {
+
+	var chartIds, insightIds, audienceIds []string
+	for idx, param := range params {
+		if param.AssetData == nil {
+			continue
+		}
+
+		switch models[idx].Type {
+		case assets_dm.TypeChart:
+			chartIds = append(chartIds, models[idx].ContentId)
+		case assets_dm.TypeInsight:
+			insightIds = append(insightIds, models[idx].ContentId)
+		case assets_dm.TypeAudience:
+			audienceIds = append(audienceIds, models[idx].ContentId)
+		}
+	}
+
+	if len(chartIds) != 0 {
+		if results.charts, _, err = i.chartsRepo.Select(ctx, ports.SelectChartsRepoParams{Ids: chartIds}); err != nil {
+			return results, err
+		}
+	}
+
+	if len(insightIds) != 0 {
+		if results.insights, _, err = i.insightsRepo.Select(ctx, ports.SelectInsightsRepoParams{Ids: insightIds}); err != nil {
+			return results, err
+		}
+	}
+
+	if len(audienceIds) != 0 {
+		if results.audiences, _, err = i.audiencesRepo.Select(ctx, ports.SelectAudiencesRepoParams{Ids: audienceIds}); err != nil {
+			return results, err
+		}
+	}
+
+	if len(results.charts) != len(chartIds) || len(results.insights) != len(insightIds) || len(results.audiences) != len(audienceIds) {
+		return results, errors.New("asset content cannot be found")
+	}
+
+	return results, nil
+}
+
+func (i *Interactor) updateDependencies(ctx context.Context, models dependencies) (err error) {
+
+	errChan := make(chan error, 3)
+	var wg sync.WaitGroup
+
+	wg.Add(3)
+
+	go func() {
+		defer wg.Done()
+		if _, err := i.chartsRepo.Update(ctx, models.charts...); err != nil {
+			errChan <- err
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		if _, err := i.insightsRepo.Update(ctx, models.insights...); err != nil {
+			errChan <- err
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		if _, err := i.audiencesRepo.Update(ctx, models.audiences...); err != nil {
 			errChan <- err
 		}
 	}()
@@ -138,9 +235,9 @@ func (i *Interactor) deleteDependencies(ctx context.Context, models ...assets_dm
 
 	for err = range errChan {
 		if err != nil {
-			return nil, nil, nil, err
+			return err
 		}
 	}
 
-	return charts, insights, audiences, nil
+	return nil
 }
@@ -0,0 +1,204 @@
+package assets_itc
+
+import (
+	assets_dm "assets/internal/core/domain/assets"
+	audit_dm "assets/internal/core/domain/audit"
+	grants_dm "assets/internal/core/domain/grants"
+	users_dm "assets/internal/core/domain/users"
+	"assets/internal/core/ports"
+	errs "assets/pkg/errors"
+	"assets/pkg/slices"
+	"context"
+	"errors"
+	"time"
+)
+
+func (i *Interactor) TransferOwnership(ctx context.Context, params ports.TransferAssetOwnershipItcParams) (result assets_dm.AssetEntity, err error) {
+
+	i.logger.Info("assets_itc.TransferOwnership() performed",
+		"params", params,
+		"result", result,
+	)
+
+	if err = i.policy.Authorize(ctx, ports.ActionUpdateAssets); err != nil {
+		return result, err
+	}
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, errors.Join(errs.ValidationError, err)
+	}
+
+	var model assets_dm.AssetEntity
+	if model, err = i.selectAsset(ctx, params.Id, accessOwn); err != nil {
+		return result, err
+	}
+
+	if err = i.selectUser(ctx, params.OwnerId); err != nil {
+		return result, err
+	}
+
+	updated := model
+	updated.OwnerId = params.OwnerId
+	updated.UpdateTime = time.Now()
+
+	var results []assets_dm.AssetEntity
+	if results, err = i.assetsRepo.Update(ctx, updated); err != nil {
+		return result, errors.Join(errs.ProcessingError, err)
+	}
+
+	if err = i.recordVersions(ctx, results...); err != nil {
+		if _, err := i.assetsRepo.Update(ctx, model); err != nil {
+			i.logger.Info("failed to recreate data")
+		}
+		return result, errors.Join(errs.ProcessingError, err)
+	}
+
+	i.record(ctx, prepareAssetsAuditParams(audit_dm.ActionUpdate, []assets_dm.AssetEntity{model}, results)...)
+
+	return results[0], nil
+}
+
+func (i *Interactor) SelectGrants(ctx context.Context, params ports.SelectAssetGrantsItcParams) (results []grants_dm.GrantEntity, err error) {
+
+	i.logger.Info("assets_itc.SelectGrants() performed",
+		"params", params,
+		"results", results,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return nil, errors.Join(errs.ValidationError, err)
+	}
+
+	if _, err = i.selectAsset(ctx, params.AssetId, accessOwn); err != nil {
+		return nil, err
+	}
+
+	if results, _, err = i.grantsRepo.Select(ctx, ports.SelectGrantsRepoParams{
+		AssetIds: []string{params.AssetId},
+	}); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	return results, nil
+}
+
+func (i *Interactor) InsertGrant(ctx context.Context, params ports.InsertAssetGrantItcParams) (result grants_dm.GrantEntity, err error) {
+
+	i.logger.Info("assets_itc.InsertGrant() performed",
+		"params", params,
+		"result", result,
+	)
+
+	if err = i.policy.Authorize(ctx, ports.ActionUpdateAssets); err != nil {
+		return result, err
+	}
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, errors.Join(errs.ValidationError, err)
+	}
+
+	switch params.GranteeType {
+	case grants_dm.GranteeTypeGroup:
+		if !slices.Contains(users_dm.Roles(), params.GranteeId) {
+			return result, errors.Join(errs.ValidationError, errors.New("group has to be one of user roles"))
+		}
+	case grants_dm.GranteeTypeUser:
+		if err = i.validator.Validate(struct {
+			GranteeId string `validate:"uuid"`
+		}{params.GranteeId}); err != nil {
+			return result, errors.Join(errs.ValidationError, err)
+		}
+		if err = i.selectUser(ctx, params.GranteeId); err != nil {
+			return result, err
+		}
+	}
+
+	if _, err = i.selectAsset(ctx, params.AssetId, accessOwn); err != nil {
+		return result, err
+	}
+
+	var current []grants_dm.GrantEntity
+	if current, _, err = i.grantsRepo.Select(ctx, ports.SelectGrantsRepoParams{
+		AssetIds: []string{params.AssetId},
+	}); err != nil {
+		return result, errors.Join(errs.ProcessingError, err)
+	}
+
+	var results []grants_dm.GrantEntity
+	if results, err = i.grantsRepo.Insert(ctx, prepareGrant(params, current)); err != nil {
+		return result, errors.Join(errs.ProcessingError, err)
+	}
+
+	return results[0], nil
+}
+
+func (i *Interactor) DeleteGrant(ctx context.Context, params ports.DeleteAssetGrantItcParams) (result grants_dm.GrantEntity, err error) {
+
+	i.logger.Info("assets_itc.DeleteGrant() performed",
+		"params", params,
+		"result", result,
+	)
+
+	if err = i.policy.Authorize(ctx, ports.ActionUpdateAssets); err != nil {
+		return result, err
+	}
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, errors.Join(errs.ValidationError, err)
+	}
+
+	if _, err = i.selectAsset(ctx, params.AssetId, accessOwn); err != nil {
+		return result, err
+	}
+
+	var current []grants_dm.GrantEntity
+	if current, _, err = i.grantsRepo.Select(ctx, ports.SelectGrantsRepoParams{
+		AssetIds: []string{params.AssetId},
+	}); err != nil {
+		return result, errors.Join(errs.ProcessingError, err)
+	}
+
+	grants := slices.Filter(current, func(grant grants_dm.GrantEntity) bool {
+		return grant.Id == params.GrantId
+	})
+
+	if len(grants) == 0 {
+		return result, errors.Join(errs.CannotBeFoundError, errors.New("grant cannot be found"))
+	}
+
+	var results []grants_dm.GrantEntity
+	if results, err = i.grantsRepo.Delete(ctx, grants...); err != nil {
+		return result, errors.Join(errs.ProcessingError, err)
+	}
+
+	return results[0], nil
+}
+
+// selectUser makes sure the user exists, so that assets aren't handed over to nobody.
+func (i *Interactor) selectUser(ctx context.Context, id string) (err error) {
+	var users []users_dm.UserEntity
+	if users, _, err = i.usersRepo.Select(ctx, ports.SelectUsersRepoParams{Ids: []string{id}}); err != nil {
+		return errors.Join(errs.ProcessingError, err)
+	}
+
+	if len(users) == 0 {
+		return errors.Join(errs.ValidationError, errors.New("user cannot be found"))
+	}
+
+	return nil
+}
+
+// deleteGrants removes grants of purged assets.
+func (i *Interactor) deleteGrants(ctx context.Context, ids []string) (err error) {
+
+	var grants []grants_dm.GrantEntity
+	if grants, _, err = i.grantsRepo.Select(ctx, ports.SelectGrantsRepoParams{AssetIds: ids}); err != nil {
+		return err
+	}
+
+	if _, err = i.grantsRepo.Delete(ctx, grants...); err != nil {
+		return err
+	}
+
+	return nil
+}
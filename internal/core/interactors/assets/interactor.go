@@ -2,7 +2,7 @@ package assets_itc
 
 import (
 	assets_dm "assets/internal/core/domain/assets"
-	favourites_dm "assets/internal/core/domain/favourites"
+	audit_dm "assets/internal/core/domain/audit"
 	"assets/internal/core/ports"
 	errs "assets/pkg/errors"
 	"assets/pkg/logging"
@@ -13,24 +13,40 @@ import (
 )
 
 type Interactor struct {
-	logger         logging.Logger
-	validator      validation.Validator
-	assetsRepo     ports.AssetsRepository
-	chartsRepo     ports.ChartsRepository
-	insightsRepo   ports.InsightsRepository
-	audiencesRepo  ports.AudiencesRepository
-	favouritesRepo ports.FavouritesRepository
+	logger          logging.Logger
+	validator       validation.Validator
+	assetsRepo      ports.AssetsRepository
+	chartsRepo      ports.ChartsRepository
+	insightsRepo    ports.InsightsRepository
+	audiencesRepo   ports.AudiencesRepository
+	favouritesRepo  ports.FavouritesRepository
+	versionsRepo    ports.VersionsRepository
+	grantsRepo      ports.GrantsRepository
+	collectionsRepo ports.CollectionsRepository
+	usersRepo       ports.UsersRepository
+	searchIndex     ports.SearchIndex
+	renderer        ports.Renderer
+	auditItc        ports.AuditInteractor
+	policy          ports.Policy
 }
 
-func NewInteractor(logger logging.Logger, validator validation.Validator, assetsRepo ports.AssetsRepository, chartsRepo ports.ChartsRepository, insightsRepo ports.InsightsRepository, audiencesRepo ports.AudiencesRepository, favouritesRepo ports.FavouritesRepository) *Interactor {
+func NewInteractor(logger logging.Logger, validator validation.Validator, assetsRepo ports.AssetsRepository, chartsRepo ports.ChartsRepository, insightsRepo ports.InsightsRepository, audiencesRepo ports.AudiencesRepository, favouritesRepo ports.FavouritesRepository, versionsRepo ports.VersionsRepository, grantsRepo ports.GrantsRepository, collectionsRepo ports.CollectionsRepository, usersRepo ports.UsersRepository, searchIndex ports.SearchIndex, renderer ports.Renderer, auditItc ports.AuditInteractor, policy ports.Policy) *Interactor {
 	return &Interactor{
-		logger:         logger,
-		validator:      validator,
-		assetsRepo:     assetsRepo,
-		chartsRepo:     chartsRepo,
-		insightsRepo:   insightsRepo,
-		audiencesRepo:  audiencesRepo,
-		favouritesRepo: favouritesRepo,
+		logger:          logger,
+		validator:       validator,
+		assetsRepo:      assetsRepo,
+		chartsRepo:      chartsRepo,
+		insightsRepo:    insightsRepo,
+		audiencesRepo:   audiencesRepo,
+		favouritesRepo:  favouritesRepo,
+		versionsRepo:    versionsRepo,
+		grantsRepo:      grantsRepo,
+		collectionsRepo: collectionsRepo,
+		usersRepo:       usersRepo,
+		searchIndex:     searchIndex,
+		renderer:        renderer,
+		auditItc:        auditItc,
+		policy:          policy,
 	}
 }
 
@@ -45,7 +61,11 @@ func (i *Interactor) Select(ctx context.Context, params ports.SelectAssetsItcPar
 		return nil, cursor, errors.Join(errs.ValidationError, err)
 	}
 
-	if results, cursor, err = i.assetsRepo.Select(ctx, convertSelectParams(params)); err != nil {
+	if !params.CreatedAfter.IsZero() && !params.CreatedBefore.IsZero() && !params.CreatedAfter.Before(params.CreatedBefore) {
+		return nil, cursor, errors.Join(errs.ValidationError, errors.New("created after has to precede created before"))
+	}
+
+	if results, cursor, err = i.selectAccessible(ctx, convertSelectParams(params), accessRead); err != nil {
 		return nil, cursor, errors.Join(errs.ProcessingError, err)
 	}
 
@@ -59,6 +79,10 @@ func (i *Interactor) Insert(ctx context.Context, params ...ports.InsertAssetItcP
 		"results", results,
 	)
 
+	if err = i.policy.Authorize(ctx, ports.ActionInsertAssets); err != nil {
+		return nil, err
+	}
+
 	if err = i.validator.Validate(params); err != nil {
 		return nil, errors.Join(errs.ValidationError, err)
 	}
@@ -79,18 +103,18 @@ func (i *Interactor) Insert(ctx context.Context, params ...ports.InsertAssetItcP
 			return
 		}
 
-		if _, err = i.chartsRepo.Delete(ctx, charts...); err != nil {
+		if _, err := i.chartsRepo.Delete(ctx, charts...); err != nil {
 			i.logger.Info("failed to recreate data")
 		}
-		if _, err = i.insightsRepo.Delete(ctx, insights...); err != nil {
+		if _, err := i.insightsRepo.Delete(ctx, insights...); err != nil {
 			i.logger.Info("failed to recreate data")
 		}
-		if _, err = i.audiencesRepo.Delete(ctx, audiences...); err != nil {
+		if _, err := i.audiencesRepo.Delete(ctx, audiences...); err != nil {
 			i.logger.Info("failed to recreate data")
 		}
 	}()
 
-	if results, err = prepareCreatableModels(params, mapper); err != nil {
+	if results, err = prepareCreatableModels(ctx, params, mapper, dependencies{charts: charts, insights: insights, audiences: audiences}); err != nil {
 		return nil, errors.Join(errs.ProcessingError, err)
 	}
 
@@ -98,6 +122,16 @@ func (i *Interactor) Insert(ctx context.Context, params ...ports.InsertAssetItcP
 		return nil, errors.Join(errs.ProcessingError, err)
 	}
 
+	if err = i.recordVersions(ctx, results...); err != nil {
+		if _, err := i.assetsRepo.Delete(ctx, results...); err != nil {
+			i.logger.Info("failed to recreate data")
+		}
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	i.index(ctx, results...)
+	i.record(ctx, prepareAssetsAuditParams(audit_dm.ActionInsert, nil, results)...)
+
 	return results, err
 }
 
@@ -108,6 +142,10 @@ func (i *Interactor) Update(ctx context.Context, params ...ports.UpdateAssetItcP
 		"results", results,
 	)
 
+	if err = i.policy.Authorize(ctx, ports.ActionUpdateAssets); err != nil {
+		return nil, err
+	}
+
 	if err = i.validator.Validate(params); err != nil {
 		return nil, errors.Join(errs.ValidationError, err)
 	}
@@ -129,10 +167,50 @@ func (i *Interactor) Update(ctx context.Context, params ...ports.UpdateAssetItcP
 		return nil, errors.Join(errs.ProcessingError, err)
 	}
 
-	if results, err = i.assetsRepo.Update(ctx, prepareUpdatableModels(params, models)...); err != nil {
+	if err = i.authorizeAccess(ctx, models, accessEdit); err != nil {
+		return nil, err
+	}
+
+	for idx, param := range params {
+		if param.AssetData != nil && !matchesType(*param.AssetData, models[idx].Type) {
+			return nil, errors.Join(errs.ValidationError, errors.New("asset data has to match asset type"))
+		}
+	}
+
+	var current dependencies
+	if current, err = i.selectDependencies(ctx, params, models); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	updated := prepareUpdatableDependencies(params, models, current)
+	if err = i.updateDependencies(ctx, updated); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	defer func() {
+		if err == nil {
+			return
+		}
+
+		if err := i.updateDependencies(ctx, current); err != nil {
+			i.logger.Info("failed to recreate data")
+		}
+	}()
+
+	if results, err = i.assetsRepo.Update(ctx, prepareUpdatableModels(params, models, updated)...); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	if err = i.recordVersions(ctx, results...); err != nil {
+		if _, err := i.assetsRepo.Update(ctx, models...); err != nil {
+			i.logger.Info("failed to recreate data")
+		}
 		return nil, errors.Join(errs.ProcessingError, err)
 	}
 
+	i.index(ctx, results...)
+	i.record(ctx, prepareAssetsAuditParams(audit_dm.ActionUpdate, models, results)...)
+
 	return results, err
 }
 
@@ -143,6 +221,10 @@ func (i *Interactor) Delete(ctx context.Context, params ...ports.DeleteAssetItcP
 		"results", results,
 	)
 
+	if err = i.policy.Authorize(ctx, ports.ActionDeleteAssets); err != nil {
+		return nil, err
+	}
+
 	if err = i.validator.Validate(params); err != nil {
 		return nil, errors.Join(errs.ValidationError, err)
 	}
@@ -151,56 +233,30 @@ func (i *Interactor) Delete(ctx context.Context, params ...ports.DeleteAssetItcP
 		return param.Id
 	})
 
-	var favourites []favourites_dm.FavouriteEntity
-	if favourites, _, err = i.favouritesRepo.Select(ctx, ports.SelectFavouritesRepoParams{AssetIds: ids}); err != nil {
-		return nil, err
-	}
-
-	if _, err = i.favouritesRepo.Delete(ctx, favourites...); err != nil {
-		return nil, err
-	}
-
-	defer func() {
-		if err == nil {
-			return
-		}
-
-		if _, err = i.favouritesRepo.Insert(ctx, favourites...); err != nil {
-			i.logger.Info("failed to recreate data")
-		}
-	}()
-
 	var models []assets_dm.AssetEntity
-	if models, _, err = i.Select(ctx, ports.SelectAssetsItcParams{
+	if models, _, err = i.assetsRepo.Select(ctx, ports.SelectAssetsRepoParams{
 		Ids: ids,
 	}); err != nil {
 		return nil, errors.Join(errs.ProcessingError, err)
 	}
 
-	charts, insights, audiences, err := i.deleteDependencies(ctx, models...)
-	if err != nil {
-		return nil, errors.Join(errs.ProcessingError, err)
+	if err = i.authorizeAccess(ctx, models, accessOwn); err != nil {
+		return nil, err
 	}
 
-	defer func() {
-		if err == nil {
-			return
-		}
-
-		if _, err = i.chartsRepo.Insert(ctx, charts...); err != nil {
-			i.logger.Info("failed to recreate data")
-		}
-		if _, err = i.insightsRepo.Insert(ctx, insights...); err != nil {
-			i.logger.Info("failed to recreate data")
-		}
-		if _, err = i.audiencesRepo.Insert(ctx, audiences...); err != nil {
-			i.logger.Info("failed to recreate data")
-		}
-	}()
-
-	if results, err = i.assetsRepo.Delete(ctx, models...); err != nil {
+	if results, err = i.assetsRepo.Update(ctx, prepareTrashableModels(models, true)...); err != nil {
 		return nil, errors.Join(errs.ProcessingError, err)
 	}
 
+	i.unindex(ctx, results...)
+	i.record(ctx, prepareAssetsAuditParams(audit_dm.ActionDelete, models, results)...)
+
 	return results, err
 }
+
+// record stores audit entries of completed modification, failure is logged since the modification cannot be undone.
+func (i *Interactor) record(ctx context.Context, params ...ports.RecordAuditItcParams) {
+	if _, err := i.auditItc.Record(ctx, params...); err != nil {
+		i.logger.Info("failed to record audit entries", "err", err)
+	}
+}
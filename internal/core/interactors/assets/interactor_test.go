@@ -2,20 +2,39 @@ package assets_itc
 
 import (
 	assets_dm "assets/internal/core/domain/assets"
+	favourites_dm "assets/internal/core/domain/favourites"
+	grants_dm "assets/internal/core/domain/grants"
+	users_dm "assets/internal/core/domain/users"
+	audit_itc "assets/internal/core/interactors/audit"
+	"assets/internal/core/policies"
 	"assets/internal/core/ports"
+	"assets/internal/render"
 	assets_db "assets/internal/repositories/assets"
 	audiences_db "assets/internal/repositories/audiences"
+	audit_db "assets/internal/repositories/audit"
 	charts_db "assets/internal/repositories/charts"
+	collections_db "assets/internal/repositories/collections"
 	favourites_db "assets/internal/repositories/favourites"
+	grants_db "assets/internal/repositories/grants"
 	insights_db "assets/internal/repositories/insights"
+	users_db "assets/internal/repositories/users"
+	versions_db "assets/internal/repositories/versions"
+	"assets/internal/search"
+	"assets/pkg/diff"
+	"assets/pkg/identity"
 	"assets/pkg/logging"
 	"assets/pkg/slices"
 	"assets/pkg/validation"
+	"bytes"
 	"context"
 	r "crypto/rand"
+	"encoding/json"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/suite"
+	"image"
+	"image/png"
+	"math"
 	"math/rand"
 	"testing"
 	"time"
@@ -23,7 +42,13 @@ import (
 
 type InteractorSuite struct {
 	suite.Suite
-	interactor ports.AssetsInteractor
+	interactor     ports.AssetsInteractor
+	favouritesRepo ports.FavouritesRepository
+	searchIndex    ports.SearchIndex
+	usersRepo      ports.UsersRepository
+	renderer       *countingRenderer
+
+	ctx context.Context
 }
 
 func TestInteractorSuite(t *testing.T) {
@@ -47,10 +72,23 @@ func (suite *InteractorSuite) TestListShouldReturnErrorWhenInputDataAreIncorrect
 		{
 			Limit: -1,
 		},
+		// incorrect Type
+		{
+			Type: "fooBar",
+		},
+		// incorrect Sort
+		{
+			Sort: "fooBar",
+		},
+		// inverted create time range
+		{
+			CreatedAfter:  time.Now(),
+			CreatedBefore: time.Now().Add(-time.Hour),
+		},
 	}
 
 	for _, param := range params {
-		testsModels, cursor, err := suite.interactor.Select(context.Background(), param)
+		testsModels, cursor, err := suite.interactor.Select(suite.ctx, param)
 
 		suite.Empty(testsModels, "should return empty objects list when params are empty")
 		suite.Equal("", cursor, "should empty cursor when nothing found")
@@ -80,7 +118,7 @@ func (suite *InteractorSuite) TestListShouldReturnModelListByFilters() {
 	}
 
 	for _, c := range testCases {
-		testsModels, cursor, err := suite.interactor.Select(context.Background(), c.Param)
+		testsModels, cursor, err := suite.interactor.Select(suite.ctx, c.Param)
 
 		suite.Nil(err, fmt.Sprintf("should return empty error when %s was specified", c.Name))
 		suite.Equal("", cursor, "should return empty cursor when all data was returned")
@@ -182,7 +220,7 @@ func (suite *InteractorSuite) TestCreateShouldReturnErrorWhenInputDataAreIncorre
 				Chart: &assets_dm.Chart{
 					XAxisTitle: "important axis",
 					YAxisTitle: "second axis",
-					Data:       "something",
+					Data:       sampleChartData(),
 				},
 			},
 		},
@@ -195,7 +233,7 @@ func (suite *InteractorSuite) TestCreateShouldReturnErrorWhenInputDataAreIncorre
 				Chart: &assets_dm.Chart{
 					ChartTitle: "nice title",
 					YAxisTitle: "second axis",
-					Data:       "something",
+					Data:       sampleChartData(),
 				},
 			},
 		},
@@ -208,7 +246,7 @@ func (suite *InteractorSuite) TestCreateShouldReturnErrorWhenInputDataAreIncorre
 				Chart: &assets_dm.Chart{
 					ChartTitle: "nice title",
 					XAxisTitle: "important axis",
-					Data:       "something",
+					Data:       sampleChartData(),
 				},
 			},
 		},
@@ -235,7 +273,7 @@ func (suite *InteractorSuite) TestCreateShouldReturnErrorWhenInputDataAreIncorre
 					ChartTitle: randomString(200),
 					XAxisTitle: "important axis",
 					YAxisTitle: "second axis",
-					Data:       "something",
+					Data:       sampleChartData(),
 				},
 			},
 		},
@@ -249,7 +287,7 @@ func (suite *InteractorSuite) TestCreateShouldReturnErrorWhenInputDataAreIncorre
 					ChartTitle: "nice title",
 					XAxisTitle: randomString(200),
 					YAxisTitle: "second axis",
-					Data:       "something",
+					Data:       sampleChartData(),
 				},
 			},
 		},
@@ -263,7 +301,7 @@ func (suite *InteractorSuite) TestCreateShouldReturnErrorWhenInputDataAreIncorre
 					ChartTitle: "nice title",
 					XAxisTitle: "important axis",
 					YAxisTitle: randomString(200),
-					Data:       "something",
+					Data:       sampleChartData(),
 				},
 			},
 		},
@@ -387,7 +425,7 @@ func (suite *InteractorSuite) TestCreateShouldReturnErrorWhenInputDataAreIncorre
 	}
 
 	for _, param := range params {
-		createdModels, err := suite.interactor.Insert(context.Background(), param)
+		createdModels, err := suite.interactor.Insert(suite.ctx, param)
 
 		suite.Empty(createdModels, "should return empty objects list when input data are incorrect")
 		suite.ErrorContains(err, "validation error")
@@ -416,7 +454,7 @@ func (suite *InteractorSuite) TestCreateShouldCreateModelsWithDefaultValues() {
 					ChartTitle: "interesting title",
 					XAxisTitle: "important parameter",
 					YAxisTitle: "also important thing",
-					Data:       "test",
+					Data:       sampleChartData(),
 				},
 			},
 		},
@@ -436,7 +474,7 @@ func (suite *InteractorSuite) TestCreateShouldCreateModelsWithDefaultValues() {
 		},
 	}
 
-	createdModels, err := suite.interactor.Insert(context.Background(), params...)
+	createdModels, err := suite.interactor.Insert(suite.ctx, params...)
 	suite.Nil(err, "should return empty error when provided params are correct")
 
 	for _, model := range createdModels {
@@ -445,18 +483,153 @@ func (suite *InteractorSuite) TestCreateShouldCreateModelsWithDefaultValues() {
 		suite.NotZero(model.UpdateTime)
 	}
 
-	testsModels, _, err := suite.interactor.Select(context.Background(), ports.SelectAssetsItcParams{
+	testsModels, _, err := suite.interactor.Select(suite.ctx, ports.SelectAssetsItcParams{
 		Ids: slices.Map(createdModels, func(model assets_dm.AssetEntity) string { return model.Id }),
 	})
 	suite.ElementsMatch(testsModels, createdModels, "listed and created objects should be the same")
 }
 
+func (suite *InteractorSuite) TestCreateShouldReturnErrorWhenChartDataAreIncorrect() {
+
+	moment := time.Date(2023, 6, 27, 12, 0, 0, 0, time.UTC)
+
+	data := []assets_dm.ChartData{
+		// incorrect Kind
+		{
+			Kind:   "AREA",
+			Series: sampleChartData().Series,
+		},
+		// missing Series
+		{
+			Kind: assets_dm.ChartKindLine,
+		},
+		// missing Points
+		{
+			Kind:   assets_dm.ChartKindLine,
+			Series: []assets_dm.Series{{Name: "empty"}},
+		},
+		// duplicated Series Name
+		{
+			Kind: assets_dm.ChartKindLine,
+			Series: []assets_dm.Series{
+				{Name: "same", Points: []assets_dm.Point{{X: assets_dm.NumberX(1), Y: 1}}},
+				{Name: "same", Points: []assets_dm.Point{{X: assets_dm.NumberX(1), Y: 2}}},
+			},
+		},
+		// missing X
+		{
+			Kind:   assets_dm.ChartKindScatter,
+			Series: []assets_dm.Series{{Name: "missing", Points: []assets_dm.Point{{Y: 1}}}},
+		},
+		// mixed X types
+		{
+			Kind: assets_dm.ChartKindLine,
+			Series: []assets_dm.Series{
+				{Name: "numeric", Points: []assets_dm.Point{{X: assets_dm.NumberX(1), Y: 1}}},
+				{Name: "temporal", Points: []assets_dm.Point{{X: assets_dm.TimeX(moment), Y: 1}}},
+			},
+		},
+		// not finite Y
+		{
+			Kind:   assets_dm.ChartKindBar,
+			Series: []assets_dm.Series{{Name: "infinite", Points: []assets_dm.Point{{X: assets_dm.NumberX(1), Y: math.Inf(1)}}}},
+		},
+		// negative slice of the pie
+		{
+			Kind:   assets_dm.ChartKindPie,
+			Series: []assets_dm.Series{{Name: "negative", Points: []assets_dm.Point{{X: assets_dm.NumberX(1), Y: -1}}}},
+		},
+	}
+
+	for _, d := range data {
+		createdModels, err := suite.interactor.Insert(suite.ctx, ports.InsertAssetItcParams{
+			Type:        assets_dm.TypeChart,
+			Name:        "Nice Name",
+			Description: "Nice Description",
+			AssetData: assets_dm.AssetData{
+				Chart: &assets_dm.Chart{
+					ChartTitle: "nice title",
+					XAxisTitle: "important axis",
+					YAxisTitle: "second axis",
+					Data:       d,
+				},
+			},
+		})
+
+		suite.Empty(createdModels)
+		suite.ErrorContains(err, "validation error")
+	}
+}
+
+func (suite *InteractorSuite) TestCreateShouldKeepChartDataIntact() {
+
+	data := assets_dm.ChartData{
+		Kind:  assets_dm.ChartKindLine,
+		YUnit: "mm",
+		Series: []assets_dm.Series{
+			{
+				Name: "rainfall",
+				Points: []assets_dm.Point{
+					{X: assets_dm.TimeX(time.Date(2023, 6, 27, 0, 0, 0, 0, time.UTC)), Y: 0.1},
+					{X: assets_dm.TimeX(time.Date(2023, 6, 28, 0, 0, 0, 0, time.UTC)), Y: 12.75},
+				},
+			},
+		},
+	}
+
+	createdModels, err := suite.interactor.Insert(suite.ctx, ports.InsertAssetItcParams{
+		Type:        assets_dm.TypeChart,
+		Name:        "Rainfall",
+		Description: "Nice Description",
+		AssetData: assets_dm.AssetData{
+			Chart: &assets_dm.Chart{ChartTitle: "rainfall", XAxisTitle: "day", YAxisTitle: "rain", Data: data},
+		},
+	})
+	suite.Nil(err, "should return empty error when provided params are correct")
+
+	models, _, err := suite.interactor.Select(suite.ctx, ports.SelectAssetsItcParams{Ids: []string{createdModels[0].Id}})
+	suite.Nil(err)
+	suite.Equal(data, models[0].AssetData.Chart.Data, "chart data should come back as they were stored")
+
+	encoded, err := json.Marshal(models[0].AssetData.Chart.Data)
+	suite.Nil(err)
+	suite.JSONEq(`{"kind":"LINE","x_unit":"","y_unit":"mm","series":[{"name":"rainfall","points":[{"x":"2023-06-27T00:00:00Z","y":0.1},{"x":"2023-06-28T00:00:00Z","y":12.75}]}]}`, string(encoded))
+
+	var decoded assets_dm.ChartData
+	suite.Nil(json.Unmarshal(encoded, &decoded))
+	suite.Equal(data, decoded, "chart data should survive JSON round trip")
+}
+
+func (suite *InteractorSuite) TestCreateShouldReturnErrorWhenCallerIsNotEditor() {
+
+	params := ports.InsertAssetItcParams{
+		Type:        assets_dm.TypeInsight,
+		Name:        "test name",
+		Description: "Nice Description",
+		AssetData: assets_dm.AssetData{
+			Insight: &assets_dm.Insight{
+				Text: "Nice Insight",
+			},
+		},
+	}
+
+	createdModels, err := suite.interactor.Insert(context.Background(), params)
+	suite.Empty(createdModels, "should return empty objects list when caller is unknown")
+	suite.ErrorContains(err, "failed to authenticate user")
+
+	createdModels, err = suite.interactor.Insert(viewerContext(), params)
+	suite.Empty(createdModels, "should return empty objects list when caller is viewer")
+	suite.ErrorContains(err, "permission denied")
+}
+
 /// Update
 
 func (suite *InteractorSuite) TestUpdateShouldReturnErrorWhenInputDataAreIncorrect() {
 
 	preparedModels := suite.setupSampleAssets()
 	incorrectDescription := randomString(9000)
+	incorrectName := randomString(200)
+	emptyName := ""
 	params := []ports.UpdateAssetItcParams{
 		// incorrect Id
 		{
@@ -467,10 +640,39 @@ func (suite *InteractorSuite) TestUpdateShouldReturnErrorWhenInputDataAreIncorre
 			Id:          preparedModels[0].Id,
 			Description: &incorrectDescription,
 		},
+		// incorrect Name
+		{
+			Id:   preparedModels[0].Id,
+			Name: &incorrectName,
+		},
+		// empty Name
+		{
+			Id:   preparedModels[0].Id,
+			Name: &emptyName,
+		},
+		// incomplete AssetData
+		{
+			Id: preparedModels[0].Id,
+			AssetData: &assets_dm.AssetData{
+				Insight: &assets_dm.Insight{},
+			},
+		},
+		// AssetData of different type
+		{
+			Id: preparedModels[0].Id,
+			AssetData: &assets_dm.AssetData{
+				Chart: &assets_dm.Chart{
+					ChartTitle: "interesting title",
+					XAxisTitle: "important parameter",
+					YAxisTitle: "also important thing",
+					Data:       sampleChartData(),
+				},
+			},
+		},
 	}
 
 	for _, param := range params {
-		updatedModels, err := suite.interactor.Update(context.Background(), param)
+		updatedModels, err := suite.interactor.Update(suite.ctx, param)
 
 		suite.Empty(updatedModels, "should return empty objects list when input data are incorrect")
 		suite.ErrorContains(err, "validation error")
@@ -499,7 +701,7 @@ func (suite *InteractorSuite) TestUpdateShouldUpdateSpecifiedModel() {
 		},
 	}
 
-	updatedModels, err := suite.interactor.Update(context.Background(), params...)
+	updatedModels, err := suite.interactor.Update(suite.ctx, params...)
 	expectedModels := make([]assets_dm.AssetEntity, len(updatedModels))
 	copy(expectedModels, updatedModels)
 
@@ -512,7 +714,7 @@ func (suite *InteractorSuite) TestUpdateShouldUpdateSpecifiedModel() {
 	suite.Nil(err, "should return empty error when provided params are correct")
 	suite.Equal(expectedModels, updatedModels, "expected and returned objects should be the same")
 
-	testsModels, cursor, err := suite.interactor.Select(context.Background(), ports.SelectAssetsItcParams{
+	testsModels, cursor, err := suite.interactor.Select(suite.ctx, ports.SelectAssetsItcParams{
 		Ids: slices.Map(expectedModels, func(model assets_dm.AssetEntity) string { return model.Id }),
 	})
 	suite.Empty(err, "it shouldn't be an error while listing models")
@@ -520,11 +722,66 @@ func (suite *InteractorSuite) TestUpdateShouldUpdateSpecifiedModel() {
 	suite.ElementsMatch(testsModels, updatedModels, "listed and updated objects should be the same")
 }
 
+func (suite *InteractorSuite) TestUpdateShouldUpdateNameAndAssetData() {
+
+	createdModels := suite.setupSampleAssets()
+	newName := "New Name"
+
+	params := []ports.UpdateAssetItcParams{
+		{
+			Id:   createdModels[0].Id,
+			Name: &newName,
+			AssetData: &assets_dm.AssetData{
+				Insight: &assets_dm.Insight{Text: "Better Insight"},
+			},
+		},
+		{
+			Id: createdModels[1].Id,
+			AssetData: &assets_dm.AssetData{
+				Chart: &assets_dm.Chart{
+					ChartTitle: "better title",
+					XAxisTitle: "time",
+					YAxisTitle: "value",
+					Data:       sampleChartData(),
+				},
+			},
+		},
+	}
+
+	updatedModels, err := suite.interactor.Update(suite.ctx, params...)
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal(2, len(updatedModels))
+
+	suite.Equal(newName, updatedModels[0].Name, "name should be updated")
+	suite.Equal(createdModels[0].Description, updatedModels[0].Description, "description should be left untouched")
+	suite.Equal("Better Insight", updatedModels[0].AssetData.Insight.Text, "insight should be updated")
+	suite.Equal(createdModels[0].ContentId, updatedModels[0].AssetData.Insight.Id, "content should keep its identity")
+	suite.True(updatedModels[0].UpdateTime.After(createdModels[0].UpdateTime), "update time should be refreshed")
+	suite.True(updatedModels[0].AssetData.Insight.UpdateTime.After(updatedModels[0].AssetData.Insight.CreateTime), "content update time should be refreshed")
+
+	suite.Equal(createdModels[1].Name, updatedModels[1].Name, "name should be left untouched")
+	suite.Equal("better title", updatedModels[1].AssetData.Chart.ChartTitle, "chart should be updated")
+	suite.Equal(createdModels[1].ContentId, updatedModels[1].AssetData.Chart.Id, "content should keep its identity")
+}
+
+func (suite *InteractorSuite) TestUpdateShouldReturnErrorWhenCallerIsNotEditor() {
+
+	createdModels := suite.setupSampleAssets()
+	newDescription := "New Description"
+
+	updatedModels, err := suite.interactor.Update(viewerContext(), ports.UpdateAssetItcParams{
+		Id:          createdModels[0].Id,
+		Description: &newDescription,
+	})
+	suite.Empty(updatedModels, "should return empty objects list when caller is viewer")
+	suite.ErrorContains(err, "permission denied")
+}
+
 /// Delete
 
 func (suite *InteractorSuite) TestDeleteShouldReturnErrorWhenInputDataAreIncorrect() {
 
-	deletedModels, err := suite.interactor.Delete(context.Background(), ports.DeleteAssetItcParams{
+	deletedModels, err := suite.interactor.Delete(suite.ctx, ports.DeleteAssetItcParams{
 		Id: "fooBar",
 	})
 
@@ -538,14 +795,18 @@ func (suite *InteractorSuite) TestDeleteShouldDeleteSpecifiedModel() {
 	consideredModels := make([]assets_dm.AssetEntity, 2)
 	copy(consideredModels, createdModels[:2])
 
-	deletedModels, err := suite.interactor.Delete(context.Background(), []ports.DeleteAssetItcParams{
+	deletedModels, err := suite.interactor.Delete(suite.ctx, []ports.DeleteAssetItcParams{
 		{Id: consideredModels[0].Id},
 		{Id: consideredModels[1].Id},
 	}...)
 	suite.Nil(err, "should return empty error when provided params are correct")
-	suite.EqualValues(consideredModels, deletedModels, "created and deleted objects should be the same")
+	suite.Equal(2, len(deletedModels), "should return deleted objects")
+	for idx, model := range deletedModels {
+		suite.Equal(consideredModels[idx].Id, model.Id, "created and deleted objects should be the same")
+		suite.NotNil(model.DeleteTime, "deleted object should be moved to the trash")
+	}
 
-	models, cursor, err := suite.interactor.Select(context.Background(), ports.SelectAssetsItcParams{
+	models, cursor, err := suite.interactor.Select(suite.ctx, ports.SelectAssetsItcParams{
 		Ids: slices.Map(consideredModels, func(model assets_dm.AssetEntity) string { return model.Id }),
 	})
 	suite.Nil(err, "error should be nil")
@@ -553,85 +814,966 @@ func (suite *InteractorSuite) TestDeleteShouldDeleteSpecifiedModel() {
 	suite.Equal(0, len(models), "count number after deletion should be equal zero")
 }
 
-/*
-* SUITE SETUP
- */
+func (suite *InteractorSuite) TestDeleteShouldReturnErrorWhenCallerIsNotEditor() {
 
-func (suite *InteractorSuite) SetupInteractor() {
-	logger := logging.NewDefaultLogger()
-	validator := validation.NewDefaultValidator()
-	assetsRepo := assets_db.NewMemoryRepo()
-	chartsRepo := charts_db.NewMemoryRepo()
-	insightsRepo := insights_db.NewMemoryRepo()
-	audiencesRepo := audiences_db.NewMemoryRepo()
-	favouritesRepo := favourites_db.NewMemoryRepo()
+	createdModels := suite.setupSampleAssets()
 
-	suite.interactor = NewInteractor(logger, validator, assetsRepo, chartsRepo, insightsRepo, audiencesRepo, favouritesRepo)
-}
+	deletedModels, err := suite.interactor.Delete(viewerContext(), ports.DeleteAssetItcParams{
+		Id: createdModels[0].Id,
+	})
+	suite.Empty(deletedModels, "should return empty objects list when caller is viewer")
+	suite.ErrorContains(err, "permission denied")
 
-func (suite *InteractorSuite) SetupSuite() {
-	println("SetupSuite")
+	models, _, err := suite.interactor.Select(suite.ctx, ports.SelectAssetsItcParams{
+		Ids: []string{createdModels[0].Id},
+	})
+	suite.Nil(err, "error should be nil")
+	suite.Equal(1, len(models), "asset should not be deleted by viewer")
 }
 
-func (suite *InteractorSuite) SetupTest() {
-	println("SetupTest")
-	suite.SetupInteractor()
+func (suite *InteractorSuite) TestListShouldFilterModelsByTypeAndCreateTime() {
+
+	createdModels := suite.setupSampleAssets()
+
+	models, _, err := suite.interactor.Select(suite.ctx, ports.SelectAssetsItcParams{Type: assets_dm.TypeChart})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal(1, len(models), "should return models of given type")
+	suite.Equal(createdModels[1].Id, models[0].Id)
+
+	models, _, err = suite.interactor.Select(suite.ctx, ports.SelectAssetsItcParams{CreatedAfter: createdModels[0].CreateTime})
+	suite.Nil(err)
+	suite.Equal(2, len(models), "should return models created after given moment")
+	suite.Equal(createdModels[2].Id, models[0].Id, "models should be sorted by create time, newest first")
+	suite.Equal(createdModels[1].Id, models[1].Id, "models should be sorted by create time, newest first")
+
+	models, _, err = suite.interactor.Select(suite.ctx, ports.SelectAssetsItcParams{
+		CreatedBefore: createdModels[2].CreateTime,
+		Sort:          assets_dm.SortCreateTimeAsc,
+	})
+	suite.Nil(err)
+	suite.Equal(2, len(models), "should return models created before given moment")
+	suite.Equal(createdModels[0].Id, models[0].Id, "models should be sorted by create time, oldest first")
 }
 
-func (suite *InteractorSuite) setupSampleAssets() (models []assets_dm.AssetEntity) {
+func (suite *InteractorSuite) TestListShouldSortModelsByName() {
 
-	var err error
-	if models, err = suite.interactor.Insert(context.Background(),
-		[]ports.InsertAssetItcParams{
-			{
-				Type:        assets_dm.TypeInsight,
-				Name:        "test name",
-				Description: "Nice Description",
-				AssetData: assets_dm.AssetData{
-					Insight: &assets_dm.Insight{
-						Text: "Nice Insight",
-					},
-				},
-			},
-			{
-				Type:        assets_dm.TypeChart,
-				Name:        "test name",
-				Description: "Nice Description",
-				AssetData: assets_dm.AssetData{
-					Chart: &assets_dm.Chart{
-						ChartTitle: "interesting title",
-						XAxisTitle: "important parameter",
-						YAxisTitle: "also important thing",
-						Data:       "test",
-					},
-				},
-			},
-			{
-				Type:        assets_dm.TypeAudience,
-				Name:        "test name",
-				Description: "Nice Description",
-				AssetData: assets_dm.AssetData{
-					Audience: &assets_dm.Audience{
-						Gender:             assets_dm.GenderFemale,
-						BirthCountry:       "Greece",
-						AgeGroup:           "18-23",
-						SocialMediaHours:   3,
-						PurchasesLastMonth: 6,
-					},
-				},
-			},
-		}...,
-	); err != nil {
-		panic(err)
+	createdModels := suite.setupSampleAssets()
+
+	for idx, name := range []string{"beta", "gamma", "alpha"} {
+		name := name
+		_, err := suite.interactor.Update(suite.ctx, ports.UpdateAssetItcParams{Id: createdModels[idx].Id, Name: &name})
+		suite.Nil(err)
 	}
 
-	return models
+	models, _, err := suite.interactor.Select(suite.ctx, ports.SelectAssetsItcParams{Sort: assets_dm.SortNameAsc})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal([]string{"alpha", "beta", "gamma"}, slices.Map(models, func(model assets_dm.AssetEntity) string { return model.Name }))
+
+	models, _, err = suite.interactor.Select(suite.ctx, ports.SelectAssetsItcParams{Sort: assets_dm.SortNameDesc})
+	suite.Nil(err)
+	suite.Equal([]string{"gamma", "beta", "alpha"}, slices.Map(models, func(model assets_dm.AssetEntity) string { return model.Name }))
 }
 
-func (suite *InteractorSuite) AssertValidUuid(id string) {
-	parsed, err := uuid.Parse(id)
-	suite.NotEmpty(parsed)
+/// Tags
+
+func (suite *InteractorSuite) TestUpdateTagsShouldAddAndRemoveNormalizedTags() {
+
+	createdModels := suite.setupSampleAssets()
+
+	taggedModels, err := suite.interactor.UpdateTags(suite.ctx, ports.UpdateAssetTagsItcParams{
+		Id:  createdModels[0].Id,
+		Add: []string{" Weather ", "climate", "weather"},
+	})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal([]string{"climate", "weather"}, taggedModels[0].Tags, "tags should be normalized and deduplicated")
+
+	taggedModels, err = suite.interactor.UpdateTags(suite.ctx, ports.UpdateAssetTagsItcParams{
+		Id:     createdModels[0].Id,
+		Add:    []string{"europe"},
+		Remove: []string{"CLIMATE"},
+	})
+	suite.Nil(err)
+	suite.Equal([]string{"europe", "weather"}, taggedModels[0].Tags, "removed tags should be dropped")
+
+	versions, _, err := suite.interactor.SelectVersions(suite.ctx, ports.SelectAssetVersionsItcParams{AssetId: createdModels[0].Id})
+	suite.Nil(err)
+	suite.Equal(3, len(versions), "tag changes should be recorded as versions")
+
+	_, err = suite.interactor.UpdateTags(viewerContext(), ports.UpdateAssetTagsItcParams{
+		Id:  createdModels[0].Id,
+		Add: []string{"foo"},
+	})
+	suite.ErrorContains(err, "permission denied")
+
+	_, err = suite.interactor.UpdateTags(suite.ctx, ports.UpdateAssetTagsItcParams{
+		Id:  uuid.NewString(),
+		Add: []string{"foo"},
+	})
+	suite.ErrorContains(err, "cannot be found")
+}
+
+func (suite *InteractorSuite) TestSelectShouldFilterModelsByAllTags() {
+
+	createdModels := suite.setupSampleAssets()
+
+	_, err := suite.interactor.UpdateTags(suite.ctx, []ports.UpdateAssetTagsItcParams{
+		{Id: createdModels[0].Id, Add: []string{"weather", "europe"}},
+		{Id: createdModels[1].Id, Add: []string{"weather"}},
+	}...)
+	suite.Nil(err)
+
+	models, _, err := suite.interactor.Select(suite.ctx, ports.SelectAssetsItcParams{Tags: []string{"Weather"}})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal(2, len(models), "should return models labelled with the tag")
+
+	models, _, err = suite.interactor.Select(suite.ctx, ports.SelectAssetsItcParams{Tags: []string{"weather", "europe"}})
+	suite.Nil(err)
+	suite.Equal(1, len(models), "should return models labelled with all tags")
+	suite.Equal(createdModels[0].Id, models[0].Id)
+}
+
+func (suite *InteractorSuite) TestSelectShouldFillLimitWithAccessibleModels() {
+
+	suite.setupSampleAssets()
+	editor := suite.setupUser()
+	editorCtx := editorContext(editor.Id)
+
+	ownModels, err := suite.interactor.Insert(editorCtx, []ports.InsertAssetItcParams{
+		{
+			Type:        assets_dm.TypeInsight,
+			Name:        "own name",
+			Description: "Nice Description",
+			AssetData:   assets_dm.AssetData{Insight: &assets_dm.Insight{Text: "Nice Insight"}},
+		},
+		{
+			Type:        assets_dm.TypeInsight,
+			Name:        "own name",
+			Description: "Nice Description",
+			AssetData:   assets_dm.AssetData{Insight: &assets_dm.Insight{Text: "Nice Insight"}},
+		},
+	}...)
+	suite.Nil(err)
+
+	results, cursor, err := suite.interactor.Select(editorCtx, ports.SelectAssetsItcParams{Sort: assets_dm.SortCreateTimeAsc, Limit: 2})
+	suite.Nil(err, "should return empty error")
+	suite.ElementsMatch(
+		[]string{ownModels[0].Id, ownModels[1].Id},
+		slices.Map(results, func(model assets_dm.AssetEntity) string { return model.Id }),
+		"page should be filled with models the caller can read",
+	)
+	suite.Empty(cursor, "should not return cursor past the last model")
+}
+
+func (suite *InteractorSuite) TestSelectTagsShouldCountLiveModels() {
+
+	createdModels := suite.setupSampleAssets()
+
+	_, err := suite.interactor.UpdateTags(suite.ctx, []ports.UpdateAssetTagsItcParams{
+		{Id: createdModels[0].Id, Add: []string{"weather", "europe"}},
+		{Id: createdModels[1].Id, Add: []string{"weather"}},
+		{Id: createdModels[2].Id, Add: []string{"weather"}},
+	}...)
+	suite.Nil(err)
+
+	_, err = suite.interactor.Delete(suite.ctx, ports.DeleteAssetItcParams{Id: createdModels[2].Id})
+	suite.Nil(err)
+
+	tags, err := suite.interactor.SelectTags(suite.ctx)
+	suite.Nil(err, "should return empty error")
+	suite.Equal([]assets_dm.TagCount{{Tag: "europe", Count: 1}, {Tag: "weather", Count: 2}}, tags, "trashed models should not be counted")
+}
+
+func (suite *InteractorSuite) TestSelectTagsShouldCountOnlyAccessibleModels() {
+
+	createdModels := suite.setupSampleAssets()
+	editor := suite.setupUser()
+	editorCtx := editorContext(editor.Id)
+
+	_, err := suite.interactor.UpdateTags(suite.ctx, []ports.UpdateAssetTagsItcParams{
+		{Id: createdModels[0].Id, Add: []string{"weather", "europe"}},
+		{Id: createdModels[1].Id, Add: []string{"weather"}},
+	}...)
+	suite.Nil(err)
+
+	_, err = suite.interactor.InsertGrant(suite.ctx, ports.InsertAssetGrantItcParams{
+		AssetId:     createdModels[1].Id,
+		GranteeType: grants_dm.GranteeTypeUser,
+		GranteeId:   editor.Id,
+		Permission:  grants_dm.PermissionRead,
+	})
+	suite.Nil(err)
+
+	tags, err := suite.interactor.SelectTags(editorCtx)
+	suite.Nil(err, "should return empty error")
+	suite.Equal([]assets_dm.TagCount{{Tag: "weather", Count: 1}}, tags, "tags of hidden models should not be counted")
+
+	tags, err = suite.interactor.SelectTags(adminContext())
 	suite.Nil(err)
+	suite.Equal([]assets_dm.TagCount{{Tag: "europe", Count: 1}, {Tag: "weather", Count: 2}}, tags, "admin should see all tags")
+}
+
+func (suite *InteractorSuite) TestSelectTagsShouldCountModelsGrantedTwiceOnce() {
+
+	createdModels := suite.setupSampleAssets()
+	editor := suite.setupUser()
+
+	_, err := suite.interactor.UpdateTags(suite.ctx, ports.UpdateAssetTagsItcParams{
+		Id: createdModels[0].Id, Add: []string{"weather", "europe"},
+	})
+	suite.Nil(err)
+
+	_, err = suite.interactor.InsertGrant(suite.ctx, ports.InsertAssetGrantItcParams{
+		AssetId:     createdModels[0].Id,
+		GranteeType: grants_dm.GranteeTypeUser,
+		GranteeId:   editor.Id,
+		Permission:  grants_dm.PermissionRead,
+	})
+	suite.Nil(err)
+
+	_, err = suite.interactor.InsertGrant(suite.ctx, ports.InsertAssetGrantItcParams{
+		AssetId:     createdModels[0].Id,
+		GranteeType: grants_dm.GranteeTypeGroup,
+		GranteeId:   users_dm.RoleEditor,
+		Permission:  grants_dm.PermissionRead,
+	})
+	suite.Nil(err)
+
+	tags, err := suite.interactor.SelectTags(editorContext(editor.Id))
+	suite.Nil(err, "should return empty error")
+	suite.Equal([]assets_dm.TagCount{{Tag: "europe", Count: 1}, {Tag: "weather", Count: 1}}, tags, "model granted to the user and the group should be counted once")
+}
+
+/// Search
+
+func (suite *InteractorSuite) TestSearchShouldReturnErrorWhenInputDataAreIncorrect() {
+
+	results, err := suite.interactor.Search(suite.ctx, ports.SearchAssetsItcParams{})
+
+	suite.Empty(results, "should return empty results when query is empty")
+	suite.ErrorContains(err, "validation error")
+}
+
+func (suite *InteractorSuite) TestSearchShouldMatchPrefixesAcrossFields() {
+
+	createdModels := suite.setupSampleAssets()
+
+	results, err := suite.interactor.Search(suite.ctx, ports.SearchAssetsItcParams{Query: "Interest"})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal(1, len(results), "should match chart title by prefix")
+	suite.Equal(createdModels[1].Id, results[0].Asset.Id)
+
+	results, err = suite.interactor.Search(suite.ctx, ports.SearchAssetsItcParams{Query: "nice insight"})
+	suite.Nil(err)
+	suite.Equal(1, len(results), "every word of the query has to match")
+	suite.Equal(createdModels[0].Id, results[0].Asset.Id)
+
+	results, err = suite.interactor.Search(suite.ctx, ports.SearchAssetsItcParams{Query: "Greece"})
+	suite.Nil(err)
+	suite.Empty(results, "audience fields should not be searchable")
+}
+
+func (suite *InteractorSuite) TestSearchShouldRankNameMatchesFirst() {
+
+	createdModels := suite.setupSampleAssets()
+
+	name, description := "weather report", "report about the weather"
+	_, err := suite.interactor.Update(suite.ctx, []ports.UpdateAssetItcParams{
+		{Id: createdModels[0].Id, Description: &description},
+		{Id: createdModels[2].Id, Name: &name},
+	}...)
+	suite.Nil(err)
+
+	results, err := suite.interactor.Search(suite.ctx, ports.SearchAssetsItcParams{Query: "weather"})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal(2, len(results), "updated assets should be searchable")
+	suite.Equal(createdModels[2].Id, results[0].Asset.Id, "match in the name should rank higher than match in description")
+	suite.True(results[0].Score > results[1].Score)
+
+	results, err = suite.interactor.Search(suite.ctx, ports.SearchAssetsItcParams{Query: "test"})
+	suite.Nil(err)
+	suite.Equal(2, len(results), "replaced words should not be found anymore")
+}
+
+func (suite *InteractorSuite) TestSearchShouldFollowTrashAndRestore() {
+
+	createdModels := suite.setupSampleAssets()
+
+	_, err := suite.interactor.Delete(suite.ctx, ports.DeleteAssetItcParams{Id: createdModels[1].Id})
+	suite.Nil(err)
+
+	results, err := suite.interactor.Search(suite.ctx, ports.SearchAssetsItcParams{Query: "interesting"})
+	suite.Nil(err)
+	suite.Empty(results, "trashed assets should not be found")
+
+	_, err = suite.interactor.Restore(suite.ctx, ports.RestoreAssetItcParams{Id: createdModels[1].Id})
+	suite.Nil(err)
+
+	results, err = suite.interactor.Search(suite.ctx, ports.SearchAssetsItcParams{Query: "interesting"})
+	suite.Nil(err)
+	suite.Equal(1, len(results), "restored assets should be found again")
+}
+
+func (suite *InteractorSuite) TestSearchShouldFillLimitWithAccessibleModels() {
+
+	for idx := 0; idx < 3; idx++ {
+		suite.setupSampleAssets()
+	}
+
+	editor := suite.setupUser()
+	editorCtx := editorContext(editor.Id)
+	for idx := 0; idx < 2; idx++ {
+		_, err := suite.interactor.Insert(editorCtx, ports.InsertAssetItcParams{
+			Type:        assets_dm.TypeInsight,
+			Name:        "note",
+			Description: "nice",
+			AssetData:   assets_dm.AssetData{Insight: &assets_dm.Insight{Text: "note"}},
+		})
+		suite.Nil(err)
+	}
+
+	results, err := suite.interactor.Search(editorCtx, ports.SearchAssetsItcParams{Query: "nice", Limit: 2})
+	suite.Nil(err, "should return empty error")
+	suite.Equal(2, len(results), "hidden hits should not take place of accessible ones")
+	for _, result := range results {
+		suite.Equal(editor.Id, result.Asset.OwnerId, "only accessible models should be returned")
+	}
+
+	results, err = suite.interactor.Search(adminContext(), ports.SearchAssetsItcParams{Query: "nice", Limit: 4})
+	suite.Nil(err)
+	suite.Equal(4, len(results), "should not exceed the limit")
+}
+
+func (suite *InteractorSuite) TestReindexShouldRebuildIndexFromRepository() {
+
+	createdModels := suite.setupSampleAssets()
+
+	err := suite.searchIndex.Remove(suite.ctx, slices.Map(createdModels, func(model assets_dm.AssetEntity) string { return model.Id })...)
+	suite.Nil(err)
+
+	results, err := suite.interactor.Search(suite.ctx, ports.SearchAssetsItcParams{Query: "nice"})
+	suite.Nil(err)
+	suite.Empty(results, "emptied index should not find anything")
+
+	count, err := suite.interactor.Reindex(suite.ctx)
+	suite.Nil(err, "should return empty error")
+	suite.Equal(len(createdModels), count, "should index all assets")
+
+	results, err = suite.interactor.Search(suite.ctx, ports.SearchAssetsItcParams{Query: "nice"})
+	suite.Nil(err)
+	suite.Equal(len(createdModels), len(results), "rebuilt index should find all assets")
+}
+
+/// Render
+
+func (suite *InteractorSuite) TestRenderShouldReturnErrorWhenInputDataAreIncorrect() {
+
+	createdModels := suite.setupSampleAssets()
+
+	_, err := suite.interactor.Render(suite.ctx, ports.RenderAssetItcParams{Id: createdModels[1].Id, Format: "gif"})
+	suite.ErrorContains(err, "validation error", "should reject unsupported format")
+
+	_, err = suite.interactor.Render(suite.ctx, ports.RenderAssetItcParams{Id: createdModels[1].Id, Width: 10000})
+	suite.ErrorContains(err, "validation error", "should reject too large image")
+
+	_, err = suite.interactor.Render(suite.ctx, ports.RenderAssetItcParams{Id: createdModels[0].Id})
+	suite.ErrorContains(err, "validation error", "should reject assets other than charts")
+}
+
+func (suite *InteractorSuite) TestRenderShouldDrawChartAsSvg() {
+
+	createdModels := suite.setupSampleAssets()
+
+	result, err := suite.interactor.Render(suite.ctx, ports.RenderAssetItcParams{Id: createdModels[1].Id})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal(assets_dm.RenderFormatSvg, result.Format, "should default to svg")
+
+	document := string(result.Data)
+	suite.Contains(document, `width="800" height="450"`, "should default to 800x450")
+	suite.Contains(document, ">interesting title<", "should draw the title")
+	suite.Contains(document, ">important parameter (°C)<", "should draw x axis title with its unit")
+	suite.Contains(document, ">also important thing (%)<", "should draw y axis title with its unit")
+	suite.Contains(document, "<polyline", "should draw the series")
+}
+
+func (suite *InteractorSuite) TestRenderShouldDrawChartAsPng() {
+
+	createdModels := suite.setupSampleAssets()
+
+	for _, kind := range assets_dm.ChartKinds() {
+		data := sampleChartData()
+		data.Kind = kind
+		_, err := suite.interactor.Update(suite.ctx, ports.UpdateAssetItcParams{
+			Id: createdModels[1].Id,
+			AssetData: &assets_dm.AssetData{Chart: &assets_dm.Chart{
+				ChartTitle: "interesting title",
+				XAxisTitle: "important parameter",
+				YAxisTitle: "also important thing",
+				Data:       data,
+			}},
+		})
+		suite.Nil(err)
+
+		result, err := suite.interactor.Render(suite.ctx, ports.RenderAssetItcParams{Id: createdModels[1].Id, Format: assets_dm.RenderFormatPng, Width: 320, Height: 240})
+		suite.Nil(err, "should return empty error when provided params are correct")
+		suite.Equal(assets_dm.RenderFormatPng, result.Format)
+
+		decoded, err := png.Decode(bytes.NewReader(result.Data))
+		suite.Nil(err, "should encode valid png image of %s chart", kind)
+		suite.Equal(image.Rect(0, 0, 320, 240), decoded.Bounds(), "should keep requested size")
+	}
+}
+
+func (suite *InteractorSuite) TestRenderShouldCacheUntilModelIsUpdated() {
+
+	createdModels := suite.setupSampleAssets()
+	params := ports.RenderAssetItcParams{Id: createdModels[1].Id}
+
+	first, err := suite.interactor.Render(suite.ctx, params)
+	suite.Nil(err)
+	second, err := suite.interactor.Render(suite.ctx, params)
+	suite.Nil(err)
+	suite.Equal(first.Data, second.Data)
+	suite.Equal(1, suite.renderer.count, "should draw the chart once")
+
+	_, err = suite.interactor.Render(suite.ctx, ports.RenderAssetItcParams{Id: createdModels[1].Id, Width: 400})
+	suite.Nil(err)
+	suite.Equal(2, suite.renderer.count, "should draw the chart again in other size")
+
+	data := sampleChartData()
+	data.Series[0].Name = "pressure"
+	_, err = suite.interactor.Update(suite.ctx, ports.UpdateAssetItcParams{
+		Id: createdModels[1].Id,
+		AssetData: &assets_dm.AssetData{Chart: &assets_dm.Chart{
+			ChartTitle: "interesting title",
+			XAxisTitle: "important parameter",
+			YAxisTitle: "also important thing",
+			Data:       data,
+		}},
+	})
+	suite.Nil(err)
+
+	third, err := suite.interactor.Render(suite.ctx, params)
+	suite.Nil(err)
+	suite.Equal(3, suite.renderer.count, "should draw updated chart again")
+	suite.Contains(string(third.Data), ">pressure<", "should draw updated series")
+}
+
+/// Trash
+
+func (suite *InteractorSuite) TestDeleteShouldKeepFavouritesOfTrashedModel() {
+
+	createdModels := suite.setupSampleAssets()
+
+	favourite := favourites_dm.NewFavouriteEntity()
+	favourite.UserId = uuid.NewString()
+	favourite.AssetId = createdModels[0].Id
+	_, err := suite.favouritesRepo.Insert(suite.ctx, favourite)
+	suite.Nil(err)
+
+	_, err = suite.interactor.Delete(suite.ctx, ports.DeleteAssetItcParams{Id: createdModels[0].Id})
+	suite.Nil(err, "should return empty error when provided params are correct")
+
+	favourites, _, err := suite.favouritesRepo.Select(suite.ctx, ports.SelectFavouritesRepoParams{AssetIds: []string{createdModels[0].Id}})
+	suite.Nil(err)
+	suite.Equal(1, len(favourites), "favourites of trashed asset should be kept")
+}
+
+func (suite *InteractorSuite) TestSelectTrashShouldReturnDeletedModels() {
+
+	createdModels := suite.setupSampleAssets()
+
+	_, err := suite.interactor.Delete(suite.ctx, ports.DeleteAssetItcParams{Id: createdModels[0].Id})
+	suite.Nil(err)
+	_, err = suite.interactor.Delete(suite.ctx, ports.DeleteAssetItcParams{Id: createdModels[1].Id})
+	suite.Nil(err)
+
+	trashed, _, err := suite.interactor.SelectTrash(suite.ctx, ports.SelectTrashItcParams{})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal(2, len(trashed), "should list only deleted objects")
+	suite.Equal(createdModels[1].Id, trashed[0].Id, "trash should be ordered by delete time, newest first")
+
+	_, _, err = suite.interactor.SelectTrash(viewerContext(), ports.SelectTrashItcParams{})
+	suite.ErrorContains(err, "permission denied")
+}
+
+func (suite *InteractorSuite) TestRestoreShouldBringBackDeletedModel() {
+
+	createdModels := suite.setupSampleAssets()
+
+	_, err := suite.interactor.Delete(suite.ctx, ports.DeleteAssetItcParams{Id: createdModels[0].Id})
+	suite.Nil(err)
+
+	restoredModels, err := suite.interactor.Restore(suite.ctx, ports.RestoreAssetItcParams{Id: createdModels[0].Id})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal(1, len(restoredModels))
+	suite.Nil(restoredModels[0].DeleteTime, "restored object should be taken out of the trash")
+
+	models, _, err := suite.interactor.Select(suite.ctx, ports.SelectAssetsItcParams{Ids: []string{createdModels[0].Id}})
+	suite.Nil(err)
+	suite.Equal(1, len(models), "restored object should be visible again")
+
+	_, err = suite.interactor.Restore(suite.ctx, ports.RestoreAssetItcParams{Id: createdModels[1].Id})
+	suite.ErrorContains(err, "cannot be found", "live object cannot be restored")
+}
+
+func (suite *InteractorSuite) TestPurgeShouldRemoveModelsTrashedBeforeRetention() {
+
+	createdModels := suite.setupSampleAssets()
+
+	favourite := favourites_dm.NewFavouriteEntity()
+	favourite.UserId = uuid.NewString()
+	favourite.AssetId = createdModels[0].Id
+	_, err := suite.favouritesRepo.Insert(suite.ctx, favourite)
+	suite.Nil(err)
+
+	_, err = suite.interactor.Delete(suite.ctx, ports.DeleteAssetItcParams{Id: createdModels[0].Id})
+	suite.Nil(err)
+
+	purgedModels, err := suite.interactor.Purge(suite.ctx, ports.PurgeAssetsItcParams{DeletedBefore: time.Now().Add(-time.Hour)})
+	suite.Nil(err)
+	suite.Empty(purgedModels, "objects trashed within retention period should be kept")
+
+	purgedModels, err = suite.interactor.Purge(suite.ctx, ports.PurgeAssetsItcParams{DeletedBefore: time.Now().Add(time.Minute)})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal(1, len(purgedModels), "should purge objects trashed before given moment")
+
+	trashed, _, err := suite.interactor.SelectTrash(suite.ctx, ports.SelectTrashItcParams{})
+	suite.Nil(err)
+	suite.Empty(trashed, "trash should be empty after purge")
+
+	favourites, _, err := suite.favouritesRepo.Select(suite.ctx, ports.SelectFavouritesRepoParams{AssetIds: []string{createdModels[0].Id}})
+	suite.Nil(err)
+	suite.Empty(favourites, "favourites of purged object should be removed")
+}
+
+func (suite *InteractorSuite) TestPurgeShouldRemoveModelsPageByPage() {
+
+	params := make([]ports.InsertAssetItcParams, 2*purgePageSize+1)
+	for idx := range params {
+		params[idx] = ports.InsertAssetItcParams{
+			Type:        assets_dm.TypeInsight,
+			Name:        fmt.Sprintf("test name %d", idx),
+			Description: "Nice Description",
+			AssetData: assets_dm.AssetData{
+				Insight: &assets_dm.Insight{
+					Text: "Nice Insight",
+				},
+			},
+		}
+	}
+
+	createdModels, err := suite.interactor.Insert(suite.ctx, params...)
+	suite.Nil(err)
+
+	_, err = suite.interactor.Delete(suite.ctx, slices.Map(createdModels, func(model assets_dm.AssetEntity) ports.DeleteAssetItcParams {
+		return ports.DeleteAssetItcParams{Id: model.Id}
+	})...)
+	suite.Nil(err)
+
+	purgedModels, err := suite.interactor.Purge(suite.ctx, ports.PurgeAssetsItcParams{DeletedBefore: time.Now().Add(time.Minute)})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal(len(createdModels), len(purgedModels), "should purge every page of the trash")
+
+	trashed, _, err := suite.interactor.SelectTrash(suite.ctx, ports.SelectTrashItcParams{})
+	suite.Nil(err)
+	suite.Empty(trashed, "trash should be empty after purge")
+}
+
+/// Sharing
+
+func (suite *InteractorSuite) TestInsertShouldRecordCreatorAsOwner() {
+
+	createdModels := suite.setupSampleAssets()
+	caller, _ := identity.FromContext(suite.ctx)
+
+	for _, model := range createdModels {
+		suite.Equal(caller.UserId, model.CreatorId, "caller should be recorded as creator")
+		suite.Equal(caller.UserId, model.OwnerId, "creator should own the object")
+	}
+}
+
+func (suite *InteractorSuite) TestGrantsShouldShareModelWithUser() {
+
+	createdModels := suite.setupSampleAssets()
+	editor := suite.setupUser()
+	editorCtx := editorContext(editor.Id)
+	name := "shared name"
+
+	models, _, err := suite.interactor.Select(editorCtx, ports.SelectAssetsItcParams{Ids: []string{createdModels[0].Id}})
+	suite.Nil(err)
+	suite.Empty(models, "object should be hidden from users it isn't shared with")
+
+	_, err = suite.interactor.Update(editorCtx, ports.UpdateAssetItcParams{Id: createdModels[0].Id, Name: &name})
+	suite.ErrorContains(err, "permission denied")
+
+	_, err = suite.interactor.InsertGrant(suite.ctx, ports.InsertAssetGrantItcParams{
+		AssetId:     createdModels[0].Id,
+		GranteeType: grants_dm.GranteeTypeUser,
+		GranteeId:   editor.Id,
+		Permission:  grants_dm.PermissionRead,
+	})
+	suite.Nil(err, "owner should be able to share the object")
+
+	models, _, err = suite.interactor.Select(editorCtx, ports.SelectAssetsItcParams{Ids: []string{createdModels[0].Id}})
+	suite.Nil(err)
+	suite.Equal(1, len(models), "read grant should reveal the object")
+
+	_, err = suite.interactor.Update(editorCtx, ports.UpdateAssetItcParams{Id: createdModels[0].Id, Name: &name})
+	suite.ErrorContains(err, "permission denied", "read grant shouldn't allow changes")
+
+	grant, err := suite.interactor.InsertGrant(suite.ctx, ports.InsertAssetGrantItcParams{
+		AssetId:     createdModels[0].Id,
+		GranteeType: grants_dm.GranteeTypeUser,
+		GranteeId:   editor.Id,
+		Permission:  grants_dm.PermissionEdit,
+	})
+	suite.Nil(err)
+
+	grants, err := suite.interactor.SelectGrants(suite.ctx, ports.SelectAssetGrantsItcParams{AssetId: createdModels[0].Id})
+	suite.Nil(err)
+	suite.Equal(1, len(grants), "another grant for the same user should replace previous one")
+
+	updatedModels, err := suite.interactor.Update(editorCtx, ports.UpdateAssetItcParams{Id: createdModels[0].Id, Name: &name})
+	suite.Nil(err, "edit grant should allow changes")
+	suite.Equal(name, updatedModels[0].Name)
+
+	_, err = suite.interactor.Delete(editorCtx, ports.DeleteAssetItcParams{Id: createdModels[0].Id})
+	suite.ErrorContains(err, "permission denied", "only owner should be able to delete the object")
+
+	_, err = suite.interactor.SelectGrants(editorCtx, ports.SelectAssetGrantsItcParams{AssetId: createdModels[0].Id})
+	suite.ErrorContains(err, "permission denied", "only owner should manage grants")
+
+	_, err = suite.interactor.DeleteGrant(suite.ctx, ports.DeleteAssetGrantItcParams{AssetId: createdModels[0].Id, GrantId: grant.Id})
+	suite.Nil(err)
+
+	models, _, err = suite.interactor.Select(editorCtx, ports.SelectAssetsItcParams{Ids: []string{createdModels[0].Id}})
+	suite.Nil(err)
+	suite.Empty(models, "removed grant should hide the object again")
+}
+
+func (suite *InteractorSuite) TestGrantsShouldShareModelWithGroup() {
+
+	createdModels := suite.setupSampleAssets()
+
+	_, err := suite.interactor.InsertGrant(suite.ctx, ports.InsertAssetGrantItcParams{
+		AssetId:     createdModels[0].Id,
+		GranteeType: grants_dm.GranteeTypeGroup,
+		GranteeId:   "fooBar",
+		Permission:  grants_dm.PermissionRead,
+	})
+	suite.ErrorContains(err, "validation error", "group should be one of user roles")
+
+	_, err = suite.interactor.InsertGrant(suite.ctx, ports.InsertAssetGrantItcParams{
+		AssetId:     createdModels[0].Id,
+		GranteeType: grants_dm.GranteeTypeGroup,
+		GranteeId:   users_dm.RoleViewer,
+		Permission:  grants_dm.PermissionRead,
+	})
+	suite.Nil(err)
+
+	models, _, err := suite.interactor.Select(viewerContext(), ports.SelectAssetsItcParams{Sort: assets_dm.SortNameAsc})
+	suite.Nil(err)
+	suite.Equal(1, len(models), "group grant should reveal the object to every member")
+	suite.Equal(createdModels[0].Id, models[0].Id)
+}
+
+func (suite *InteractorSuite) TestTransferOwnershipShouldHandOverModel() {
+
+	createdModels := suite.setupSampleAssets()
+	editor := suite.setupUser()
+
+	_, err := suite.interactor.TransferOwnership(suite.ctx, ports.TransferAssetOwnershipItcParams{
+		Id:      createdModels[0].Id,
+		OwnerId: uuid.NewString(),
+	})
+	suite.ErrorContains(err, "validation error", "object cannot be handed over to unknown user")
+
+	transferredModel, err := suite.interactor.TransferOwnership(suite.ctx, ports.TransferAssetOwnershipItcParams{
+		Id:      createdModels[0].Id,
+		OwnerId: editor.Id,
+	})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal(editor.Id, transferredModel.OwnerId)
+	suite.Equal(createdModels[0].CreatorId, transferredModel.CreatorId, "creator shouldn't change")
+
+	models, _, err := suite.interactor.Select(suite.ctx, ports.SelectAssetsItcParams{Ids: []string{createdModels[0].Id}})
+	suite.Nil(err)
+	suite.Empty(models, "previous owner should lose access")
+
+	models, _, err = suite.interactor.Select(editorContext(editor.Id), ports.SelectAssetsItcParams{Ids: []string{createdModels[0].Id}})
+	suite.Nil(err)
+	suite.Equal(1, len(models), "new owner should gain access")
+
+	models, _, err = suite.interactor.Select(adminContext(), ports.SelectAssetsItcParams{Sort: assets_dm.SortNameAsc})
+	suite.Nil(err)
+	suite.Equal(len(createdModels), len(models), "admin should see every object")
+}
+
+/// Versions
+
+func (suite *InteractorSuite) TestSelectVersionsShouldReturnErrorWhenAssetCannotBeFound() {
+
+	versions, _, err := suite.interactor.SelectVersions(suite.ctx, ports.SelectAssetVersionsItcParams{
+		AssetId: uuid.NewString(),
+	})
+
+	suite.Empty(versions, "should return empty versions list when asset doesn't exist")
+	suite.ErrorContains(err, "cannot be found")
+}
+
+func (suite *InteractorSuite) TestUpdateShouldRecordNextVersion() {
+
+	createdModels := suite.setupSampleAssets()
+	newName := "New Name"
+
+	_, err := suite.interactor.Update(suite.ctx, ports.UpdateAssetItcParams{
+		Id:   createdModels[1].Id,
+		Name: &newName,
+	})
+	suite.Nil(err)
+
+	versions, cursor, err := suite.interactor.SelectVersions(suite.ctx, ports.SelectAssetVersionsItcParams{
+		AssetId: createdModels[1].Id,
+	})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Empty(cursor)
+	suite.Equal(2, len(versions), "should keep version of creation and update")
+
+	suite.Equal(2, versions[0].Number, "newest version should come first")
+	suite.Equal(newName, versions[0].Snapshot.Name)
+	suite.Equal(1, versions[1].Number)
+	suite.Equal(createdModels[1].Name, versions[1].Snapshot.Name)
+	suite.Equal("interesting title", versions[1].Snapshot.AssetData.Chart.ChartTitle, "snapshot should contain asset data")
+
+	caller, _ := identity.FromContext(suite.ctx)
+	suite.Equal(caller.UserId, versions[0].AuthorId)
+}
+
+func (suite *InteractorSuite) TestDiffVersionsShouldReturnChangedFields() {
+
+	createdModels := suite.setupSampleAssets()
+
+	_, err := suite.interactor.Update(suite.ctx, ports.UpdateAssetItcParams{
+		Id: createdModels[0].Id,
+		AssetData: &assets_dm.AssetData{
+			Insight: &assets_dm.Insight{Text: "Better Insight"},
+		},
+	})
+	suite.Nil(err)
+
+	changes, err := suite.interactor.DiffVersions(suite.ctx, ports.DiffAssetVersionsItcParams{
+		AssetId: createdModels[0].Id,
+		From:    1,
+		To:      2,
+	})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Contains(changes, diff.Change{Path: "asset_data.insight.text", Before: "Nice Insight", After: "Better Insight"})
+
+	_, err = suite.interactor.DiffVersions(suite.ctx, ports.DiffAssetVersionsItcParams{
+		AssetId: createdModels[0].Id,
+		From:    1,
+		To:      3,
+	})
+	suite.ErrorContains(err, "version 3 cannot be found")
+}
+
+func (suite *InteractorSuite) TestRestoreVersionShouldCreateNewVersion() {
+
+	createdModels := suite.setupSampleAssets()
+	newName := "New Name"
+
+	_, err := suite.interactor.Update(suite.ctx, ports.UpdateAssetItcParams{
+		Id:   createdModels[2].Id,
+		Name: &newName,
+		AssetData: &assets_dm.AssetData{
+			Audience: &assets_dm.Audience{
+				Gender:       assets_dm.GenderMale,
+				BirthCountry: "Spain",
+				AgeGroup:     "24-35",
+			},
+		},
+	})
+	suite.Nil(err)
+
+	restored, err := suite.interactor.RestoreVersion(suite.ctx, ports.RestoreAssetVersionItcParams{
+		AssetId: createdModels[2].Id,
+		Number:  1,
+	})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal(createdModels[2].Name, restored.Name, "name should be restored")
+	suite.Equal("Greece", restored.AssetData.Audience.BirthCountry, "asset data should be restored")
+
+	versions, _, err := suite.interactor.SelectVersions(suite.ctx, ports.SelectAssetVersionsItcParams{
+		AssetId: createdModels[2].Id,
+	})
+	suite.Nil(err)
+	suite.Equal(3, len(versions), "restoration should be recorded as new version")
+	suite.Equal(3, versions[0].Number)
+
+	_, err = suite.interactor.RestoreVersion(viewerContext(), ports.RestoreAssetVersionItcParams{
+		AssetId: createdModels[2].Id,
+		Number:  2,
+	})
+	suite.ErrorContains(err, "permission denied", "viewer shouldn't restore versions")
+}
+
+/*
+* SUITE SETUP
+ */
+
+func (suite *InteractorSuite) SetupInteractor() {
+	logger := logging.NewDefaultLogger()
+	validator := validation.NewDefaultValidator()
+	assetsRepo := assets_db.NewMemoryRepo()
+	chartsRepo := charts_db.NewMemoryRepo()
+	insightsRepo := insights_db.NewMemoryRepo()
+	audiencesRepo := audiences_db.NewMemoryRepo()
+	favouritesRepo := favourites_db.NewMemoryRepo()
+	auditRepo := audit_db.NewMemoryRepo()
+	versionsRepo := versions_db.NewMemoryRepo()
+	grantsRepo := grants_db.NewMemoryRepo()
+	collectionsRepo := collections_db.NewMemoryRepo()
+	usersRepo := users_db.NewMemoryRepo()
+
+	policy := policies.NewRolePolicy()
+
+	auditItc := audit_itc.NewInteractor(logger, validator, auditRepo, policy)
+	searchIndex := search.NewInvertedIndex()
+	renderer := &countingRenderer{renderer: render.NewChartRenderer()}
+
+	suite.interactor = NewInteractor(logger, validator, assetsRepo, chartsRepo, insightsRepo, audiencesRepo, favouritesRepo, versionsRepo, grantsRepo, collectionsRepo, usersRepo, searchIndex, render.NewCache(renderer, 16), auditItc, policy)
+	suite.renderer = renderer
+	suite.favouritesRepo = favouritesRepo
+	suite.searchIndex = searchIndex
+	suite.usersRepo = usersRepo
+	suite.ctx = identity.WithIdentity(context.Background(), identity.Identity{
+		UserId: uuid.NewString(),
+		Roles:  []string{users_dm.RoleEditor},
+	})
+}
+
+func (suite *InteractorSuite) SetupSuite() {
+	println("SetupSuite")
+}
+
+func (suite *InteractorSuite) SetupTest() {
+	println("SetupTest")
+	suite.SetupInteractor()
+}
+
+func (suite *InteractorSuite) setupSampleAssets() (models []assets_dm.AssetEntity) {
+
+	var err error
+	if models, err = suite.interactor.Insert(suite.ctx,
+		[]ports.InsertAssetItcParams{
+			{
+				Type:        assets_dm.TypeInsight,
+				Name:        "test name",
+				Description: "Nice Description",
+				AssetData: assets_dm.AssetData{
+					Insight: &assets_dm.Insight{
+						Text: "Nice Insight",
+					},
+				},
+			},
+			{
+				Type:        assets_dm.TypeChart,
+				Name:        "test name",
+				Description: "Nice Description",
+				AssetData: assets_dm.AssetData{
+					Chart: &assets_dm.Chart{
+						ChartTitle: "interesting title",
+						XAxisTitle: "important parameter",
+						YAxisTitle: "also important thing",
+						Data:       sampleChartData(),
+					},
+				},
+			},
+			{
+				Type:        assets_dm.TypeAudience,
+				Name:        "test name",
+				Description: "Nice Description",
+				AssetData: assets_dm.AssetData{
+					Audience: &assets_dm.Audience{
+						Gender:             assets_dm.GenderFemale,
+						BirthCountry:       "Greece",
+						AgeGroup:           "18-23",
+						SocialMediaHours:   3,
+						PurchasesLastMonth: 6,
+					},
+				},
+			},
+		}...,
+	); err != nil {
+		panic(err)
+	}
+
+	return models
+}
+
+func (suite *InteractorSuite) setupUser() users_dm.UserEntity {
+
+	user := users_dm.NewUserEntity()
+	user.Email = fmt.Sprintf("%s@example.com", randomString(8))
+	user.Roles = []users_dm.Role{users_dm.RoleEditor}
+
+	if _, err := suite.usersRepo.Insert(suite.ctx, user); err != nil {
+		panic(err)
+	}
+
+	return user
+}
+
+func (suite *InteractorSuite) AssertValidUuid(id string) {
+	parsed, err := uuid.Parse(id)
+	suite.NotEmpty(parsed)
+	suite.Nil(err)
+}
+
+// countingRenderer tells how many times the chart was actually drawn behind the cache.
+type countingRenderer struct {
+	renderer ports.Renderer
+	count    int
+}
+
+func (r *countingRenderer) Render(ctx context.Context, asset assets_dm.AssetEntity, params ports.RenderParams) ([]byte, error) {
+	r.count++
+	return r.renderer.Render(ctx, asset, params)
+}
+
+func sampleChartData() assets_dm.ChartData {
+	return assets_dm.ChartData{
+		Kind:  assets_dm.ChartKindLine,
+		XUnit: "°C",
+		YUnit: "%",
+		Series: []assets_dm.Series{
+			{
+				Name:   "humidity",
+				Points: []assets_dm.Point{{X: assets_dm.NumberX(1), Y: 2}, {X: assets_dm.NumberX(2), Y: 3}},
+			},
+		},
+	}
+}
+
+func editorContext(userId string) context.Context {
+	return identity.WithIdentity(context.Background(), identity.Identity{
+		UserId: userId,
+		Roles:  []string{users_dm.RoleEditor},
+	})
+}
+
+func adminContext() context.Context {
+	return identity.WithIdentity(context.Background(), identity.Identity{
+		UserId: uuid.NewString(),
+		Roles:  []string{users_dm.RoleAdmin},
+	})
+}
+
+func viewerContext() context.Context {
+	return identity.WithIdentity(context.Background(), identity.Identity{
+		UserId: uuid.NewString(),
+		Roles:  []string{users_dm.RoleViewer},
+	})
 }
 
 func randomString(length int) string {
@@ -2,21 +2,38 @@ package assets_itc
 
 import (
 	assets_dm "assets/internal/core/domain/assets"
+	audit_dm "assets/internal/core/domain/audit"
+	favourites_dm "assets/internal/core/domain/favourites"
+	grants_dm "assets/internal/core/domain/grants"
+	versions_dm "assets/internal/core/domain/versions"
 	"assets/internal/core/ports"
+	"assets/pkg/identity"
+	"assets/pkg/slices"
+	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
 )
 
-func prepareCreatableModels(params []ports.InsertAssetItcParams, mapper map[ports.InsertAssetItcParams]string) (results []assets_dm.AssetEntity, err error) {
+func prepareCreatableModels(ctx context.Context, params []ports.InsertAssetItcParams, mapper map[ports.InsertAssetItcParams]string, content dependencies) (results []assets_dm.AssetEntity, err error) {
+
+	caller, _ := identity.FromContext(ctx)
 
 	for _, param := range params {
 		obj := assets_dm.NewAssetEntity()
 
+		obj.CreatorId = caller.UserId
+		obj.OwnerId = caller.UserId
+
 		obj.Type = param.Type
 		obj.Name = param.Name
 		obj.Description = param.Description
 
 		if contentId, ok := mapper[param]; ok {
 			obj.ContentId = contentId
+			obj.AssetData = selectContent(obj, content)
 		} else {
 			return nil, errors.New("failed to map data")
 		}
@@ -27,15 +44,265 @@ func prepareCreatableModels(params []ports.InsertAssetItcParams, mapper map[port
 	return results, nil
 }
 
-func prepareUpdatableModels(params []ports.UpdateAssetItcParams, models []assets_dm.AssetEntity) (results []assets_dm.AssetEntity) {
+func prepareUpdatableModels(params []ports.UpdateAssetItcParams, models []assets_dm.AssetEntity, content dependencies) (results []assets_dm.AssetEntity) {
 	results = make([]assets_dm.AssetEntity, len(models))
 	copy(results, models)
 
+	now := time.Now()
+
 	for idx, param := range params {
-		if param.Description != nil && *param.Description != results[idx].Description {
+		if param.Name != nil {
+			results[idx].Name = *param.Name
+		}
+
+		if param.Description != nil {
 			results[idx].Description = *param.Description
 		}
+
+		if param.AssetData != nil {
+			results[idx].AssetData = selectContent(results[idx], content)
+		}
+
+		results[idx].UpdateTime = now
+	}
+
+	return results
+}
+
+// prepareUpdatableDependencies replaces data of the current content, identity and create time of the content are kept.
+func prepareUpdatableDependencies(params []ports.UpdateAssetItcParams, models []assets_dm.AssetEntity, current dependencies) (results dependencies) {
+
+	var (
+		now    = time.Now()
+		assets = make(map[string]ports.UpdateAssetItcParams)
+	)
+
+	for idx, param := range params {
+		if param.AssetData != nil {
+			assets[models[idx].ContentId] = param
+		}
+	}
+
+	for _, chart := range current.charts {
+		chart.Chart = *assets[chart.Id].AssetData.Chart
+		chart.UpdateTime = now
+		results.charts = append(results.charts, chart)
+	}
+
+	for _, insight := range current.insights {
+		insight.Insight = *assets[insight.Id].AssetData.Insight
+		insight.UpdateTime = now
+		results.insights = append(results.insights, insight)
+	}
+
+	for _, audience := range current.audiences {
+		audience.Audience = *assets[audience.Id].AssetData.Audience
+		audience.UpdateTime = now
+		results.audiences = append(results.audiences, audience)
+	}
+
+	return results
+}
+
+// selectContent picks content of the asset, fresh copies are returned so that the content isn't shared between models.
+func selectContent(model assets_dm.AssetEntity, content dependencies) (result assets_dm.AssetDataEntities) {
+
+	switch model.Type {
+	case assets_dm.TypeChart:
+		for _, chart := range content.charts {
+			if chart.Id == model.ContentId {
+				result.Chart = &chart
+				break
+			}
+		}
+	case assets_dm.TypeInsight:
+		for _, insight := range content.insights {
+			if insight.Id == model.ContentId {
+				result.Insight = &insight
+				break
+			}
+		}
+	case assets_dm.TypeAudience:
+		for _, audience := range content.audiences {
+			if audience.Id == model.ContentId {
+				result.Audience = &audience
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// matchesType reports whether asset data carries content of given asset type only.
+func matchesType(data assets_dm.AssetData, assetType assets_dm.Type) bool {
+
+	switch assetType {
+	case assets_dm.TypeChart:
+		return data.Chart != nil && data.Insight == nil && data.Audience == nil
+	case assets_dm.TypeInsight:
+		return data.Chart == nil && data.Insight != nil && data.Audience == nil
+	case assets_dm.TypeAudience:
+		return data.Chart == nil && data.Insight == nil && data.Audience != nil
+	}
+
+	return false
+}
+
+// prepareAssetsAuditParams pairs models by position, missing side is left empty for inserted and deleted assets.
+func prepareAssetsAuditParams(action audit_dm.Action, before []assets_dm.AssetEntity, after []assets_dm.AssetEntity) (results []ports.RecordAuditItcParams) {
+
+	for idx := 0; idx < len(before) || idx < len(after); idx++ {
+		param := ports.RecordAuditItcParams{Action: action, EntityType: audit_dm.EntityTypeAsset}
+
+		if idx < len(before) {
+			param.EntityId = before[idx].Id
+			param.Before = before[idx]
+		}
+
+		if idx < len(after) {
+			param.EntityId = after[idx].Id
+			param.After = after[idx]
+		}
+
+		results = append(results, param)
+	}
+
+	return results
+}
+
+func prepareFavouritesAuditParams(favourites []favourites_dm.FavouriteEntity) (results []ports.RecordAuditItcParams) {
+
+	for _, favourite := range favourites {
+		results = append(results, ports.RecordAuditItcParams{
+			Action:     audit_dm.ActionDelete,
+			EntityType: audit_dm.EntityTypeFavourite,
+			EntityId:   favourite.Id,
+			Before:     favourite,
+		})
+	}
+
+	return results
+}
+
+// prepareVersions numbers snapshots of the models following the latest versions of the assets.
+func prepareVersions(ctx context.Context, models []assets_dm.AssetEntity, latest []versions_dm.VersionEntity) (results []versions_dm.VersionEntity) {
+
+	caller, _ := identity.FromContext(ctx)
+
+	numbers := make(map[string]int, len(latest))
+	for _, version := range latest {
+		numbers[version.AssetId] = version.Number
+	}
+
+	for _, model := range models {
+		obj := versions_dm.NewVersionEntity()
+
+		obj.AssetId = model.Id
+		obj.Number = numbers[model.Id] + 1
+		obj.AuthorId = caller.UserId
+		obj.Snapshot = model
+
+		results = append(results, obj)
+	}
+
+	return results
+}
+
+// prepareRestoreParams turns the snapshot into the update which brings every editable field back.
+func prepareRestoreParams(snapshot assets_dm.AssetEntity) ports.UpdateAssetItcParams {
+
+	params := ports.UpdateAssetItcParams{
+		Id:          snapshot.Id,
+		Name:        &snapshot.Name,
+		Description: &snapshot.Description,
+	}
+
+	if snapshot.AssetData.Chart != nil {
+		params.AssetData = &assets_dm.AssetData{Chart: &snapshot.AssetData.Chart.Chart}
+	} else if snapshot.AssetData.Insight != nil {
+		params.AssetData = &assets_dm.AssetData{Insight: &snapshot.AssetData.Insight.Insight}
+	} else if snapshot.AssetData.Audience != nil {
+		params.AssetData = &assets_dm.AssetData{Audience: &snapshot.AssetData.Audience.Audience}
+	}
+
+	return params
+}
+
+// prepareTrashableModels moves models to the trash or takes them out of it.
+func prepareTrashableModels(models []assets_dm.AssetEntity, trashed bool) (results []assets_dm.AssetEntity) {
+	now := time.Now()
+
+	for _, model := range models {
+		model.UpdateTime = now
+		model.DeleteTime = nil
+		if trashed {
+			model.DeleteTime = &now
+		}
+		results = append(results, model)
 	}
 
 	return results
 }
+
+// maxTags limits number of tags the asset can be labelled with.
+const maxTags = 32
+
+func prepareTaggedModels(params []ports.UpdateAssetTagsItcParams, models []assets_dm.AssetEntity) (results []assets_dm.AssetEntity, err error) {
+	now := time.Now()
+
+	for idx, param := range params {
+		model := models[idx]
+		remove := normalizeTags(param.Remove)
+
+		var tags []string
+		for _, tag := range normalizeTags(append(model.Tags, normalizeTags(param.Add)...)) {
+			if !slices.Contains(remove, tag) {
+				tags = append(tags, tag)
+			}
+		}
+
+		if len(tags) > maxTags {
+			return nil, fmt.Errorf("asset cannot have more than %d tags", maxTags)
+		}
+
+		model.Tags = tags
+		model.UpdateTime = now
+		results = append(results, model)
+	}
+
+	return results, nil
+}
+
+// normalizeTags lower-cases and trims tags, empty tags and duplicates are dropped and the rest is sorted.
+func normalizeTags(tags []string) (results []string) {
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(results, tag) {
+			results = append(results, tag)
+		}
+	}
+
+	sort.Strings(results)
+
+	return results
+}
+
+// prepareGrant creates grant for the grantee, the grant replaces current grant of the grantee if there is one.
+func prepareGrant(params ports.InsertAssetGrantItcParams, current []grants_dm.GrantEntity) (result grants_dm.GrantEntity) {
+	result = grants_dm.NewGrantEntity()
+
+	for _, grant := range current {
+		if grant.GranteeType == params.GranteeType && grant.GranteeId == params.GranteeId {
+			result.Id = grant.Id
+			result.CreateTime = grant.CreateTime
+		}
+	}
+
+	result.AssetId = params.AssetId
+	result.GranteeType = params.GranteeType
+	result.GranteeId = params.GranteeId
+	result.Permission = params.Permission
+
+	return result
+}
@@ -0,0 +1,55 @@
+package assets_itc
+
+import (
+	assets_dm "assets/internal/core/domain/assets"
+	"assets/internal/core/ports"
+	errs "assets/pkg/errors"
+	"context"
+	"errors"
+)
+
+const (
+	defaultRenderWidth  = 800
+	defaultRenderHeight = 450
+)
+
+func (i *Interactor) Render(ctx context.Context, params ports.RenderAssetItcParams) (result assets_dm.Image, err error) {
+
+	i.logger.Info("assets_itc.Render() performed",
+		"params", params,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, errors.Join(errs.ValidationError, err)
+	}
+
+	var model assets_dm.AssetEntity
+	if model, err = i.selectAsset(ctx, params.Id, accessRead); err != nil {
+		return result, err
+	}
+
+	if model.Type != assets_dm.TypeChart || model.AssetData.Chart == nil {
+		return result, errors.Join(errs.ValidationError, errors.New("only charts can be rendered"))
+	}
+
+	if params.Format == "" {
+		params.Format = assets_dm.RenderFormatSvg
+	}
+	if params.Width == 0 {
+		params.Width = defaultRenderWidth
+	}
+	if params.Height == 0 {
+		params.Height = defaultRenderHeight
+	}
+
+	result.Format = params.Format
+	if result.Data, err = i.renderer.Render(ctx, model, ports.RenderParams{
+		Format: params.Format,
+		Width:  params.Width,
+		Height: params.Height,
+	}); err != nil {
+		return assets_dm.Image{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	return result, nil
+}
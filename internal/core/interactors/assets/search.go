@@ -0,0 +1,130 @@
+package assets_itc
+
+import (
+	assets_dm "assets/internal/core/domain/assets"
+	"assets/internal/core/ports"
+	errs "assets/pkg/errors"
+	"assets/pkg/slices"
+	"context"
+	"errors"
+)
+
+const (
+	defaultSearchLimit = 20
+	reindexPageSize    = 1000
+)
+
+func (i *Interactor) Search(ctx context.Context, params ports.SearchAssetsItcParams) (results []assets_dm.SearchResult, err error) {
+
+	i.logger.Info("assets_itc.Search() performed",
+		"params", params,
+		"results", results,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return nil, errors.Join(errs.ValidationError, err)
+	}
+
+	if params.Limit == 0 {
+		params.Limit = defaultSearchLimit
+	}
+
+	// hits the caller cannot read are dropped after the lookup, so the index is asked for more hits until the limit
+	// is filled or the index runs out of them
+	for fetch, seen := params.Limit, 0; ; fetch *= 2 {
+		var hits []ports.SearchHit
+		if hits, err = i.searchIndex.Search(ctx, ports.SearchParams{Query: params.Query, Limit: fetch}); err != nil {
+			return nil, errors.Join(errs.ProcessingError, err)
+		}
+
+		var found []assets_dm.SearchResult
+		if found, err = i.readableHits(ctx, hits[seen:]); err != nil {
+			return nil, errors.Join(errs.ProcessingError, err)
+		}
+
+		results = append(results, found...)
+		seen = len(hits)
+
+		if len(results) >= params.Limit {
+			return results[:params.Limit], nil
+		} else if len(hits) < fetch {
+			return results, nil
+		}
+	}
+}
+
+func (i *Interactor) Reindex(ctx context.Context) (count int, err error) {
+
+	i.logger.Info("assets_itc.Reindex() performed")
+
+	params := ports.SelectAssetsRepoParams{
+		Sort:  assets_dm.SortCreateTimeAsc,
+		Limit: reindexPageSize,
+	}
+
+	for {
+		var models []assets_dm.AssetEntity
+		if models, params.Cursor, err = i.assetsRepo.Select(ctx, params); err != nil {
+			return count, errors.Join(errs.ProcessingError, err)
+		}
+
+		if err = i.searchIndex.Index(ctx, models...); err != nil {
+			return count, errors.Join(errs.ProcessingError, err)
+		}
+
+		count += len(models)
+		if params.Cursor == "" {
+			return count, nil
+		}
+	}
+}
+
+// index brings search index in line with modified assets, failure is logged since the modification is already done.
+func (i *Interactor) index(ctx context.Context, models ...assets_dm.AssetEntity) {
+	if err := i.searchIndex.Index(ctx, models...); err != nil {
+		i.logger.Info("failed to index assets", "err", err)
+	}
+}
+
+// unindex removes deleted assets from search index, failure is logged since the deletion is already done.
+func (i *Interactor) unindex(ctx context.Context, models ...assets_dm.AssetEntity) {
+	ids := slices.Map(models, func(model assets_dm.AssetEntity) string {
+		return model.Id
+	})
+
+	if err := i.searchIndex.Remove(ctx, ids...); err != nil {
+		i.logger.Info("failed to remove assets from index", "err", err)
+	}
+}
+
+// readableHits loads assets behind the hits and keeps ones the caller can read, order of hits is preserved.
+func (i *Interactor) readableHits(ctx context.Context, hits []ports.SearchHit) (results []assets_dm.SearchResult, err error) {
+
+	if len(hits) == 0 {
+		return results, nil
+	}
+
+	var models []assets_dm.AssetEntity
+	if models, _, err = i.assetsRepo.Select(ctx, ports.SelectAssetsRepoParams{
+		Ids: slices.Map(hits, func(hit ports.SearchHit) string { return hit.Id }),
+	}); err != nil {
+		return nil, err
+	}
+
+	if models, err = i.accessible(ctx, models, accessRead); err != nil {
+		return nil, err
+	}
+
+	mapper := make(map[string]assets_dm.AssetEntity, len(models))
+	for _, model := range models {
+		mapper[model.Id] = model
+	}
+
+	for _, hit := range hits {
+		if model, ok := mapper[hit.Id]; ok {
+			results = append(results, assets_dm.SearchResult{Asset: model, Score: hit.Score})
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,171 @@
+package assets_itc
+
+import (
+	assets_dm "assets/internal/core/domain/assets"
+	audit_dm "assets/internal/core/domain/audit"
+	grants_dm "assets/internal/core/domain/grants"
+	users_dm "assets/internal/core/domain/users"
+	"assets/internal/core/ports"
+	errs "assets/pkg/errors"
+	"assets/pkg/identity"
+	"assets/pkg/slices"
+	"context"
+	"errors"
+	"sort"
+)
+
+func (i *Interactor) UpdateTags(ctx context.Context, params ...ports.UpdateAssetTagsItcParams) (results []assets_dm.AssetEntity, err error) {
+
+	i.logger.Info("assets_itc.UpdateTags() performed",
+		"params", params,
+		"results", results,
+	)
+
+	if err = i.policy.Authorize(ctx, ports.ActionUpdateAssets); err != nil {
+		return nil, err
+	}
+
+	if err = i.validator.Validate(params); err != nil {
+		return nil, errors.Join(errs.ValidationError, err)
+	}
+
+	ids := slices.Map(params, func(param ports.UpdateAssetTagsItcParams) string {
+		return param.Id
+	})
+
+	var models []assets_dm.AssetEntity
+	if models, _, err = i.assetsRepo.Select(ctx, ports.SelectAssetsRepoParams{
+		Ids: ids,
+	}); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	if models, err = slices.MatchOrder(params, models, func(e1 ports.UpdateAssetTagsItcParams, e2 assets_dm.AssetEntity) bool {
+		return e1.Id == e2.Id
+	}); err != nil {
+		return nil, errors.Join(errs.CannotBeFoundError, err)
+	}
+
+	if err = i.authorizeAccess(ctx, models, accessEdit); err != nil {
+		return nil, err
+	}
+
+	var updated []assets_dm.AssetEntity
+	if updated, err = prepareTaggedModels(params, models); err != nil {
+		return nil, errors.Join(errs.ValidationError, err)
+	}
+
+	if results, err = i.assetsRepo.Update(ctx, updated...); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	if err = i.recordVersions(ctx, results...); err != nil {
+		if _, err := i.assetsRepo.Update(ctx, models...); err != nil {
+			i.logger.Info("failed to recreate data")
+		}
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	i.index(ctx, results...)
+	i.record(ctx, prepareAssetsAuditParams(audit_dm.ActionUpdate, models, results)...)
+
+	return results, nil
+}
+
+// SelectTags counts tags of live assets the caller can read. Stored counters cover all assets for admins, others get
+// counters of their own and unowned assets, plus tags of assets granted to them or their groups.
+func (i *Interactor) SelectTags(ctx context.Context) (results []assets_dm.TagCount, err error) {
+
+	i.logger.Info("assets_itc.SelectTags() performed",
+		"results", results,
+	)
+
+	caller, _ := identity.FromContext(ctx)
+	if slices.Contains(caller.Roles, users_dm.RoleAdmin) {
+		if results, err = i.assetsRepo.SelectTags(ctx, ports.SelectTagsRepoParams{}); err != nil {
+			return nil, errors.Join(errs.ProcessingError, err)
+		}
+
+		return results, nil
+	}
+
+	var owned []assets_dm.TagCount
+	if owned, err = i.assetsRepo.SelectTags(ctx, ports.SelectTagsRepoParams{
+		OwnerIds: []string{caller.UserId, ""},
+	}); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	counts := make(map[string]int)
+	for _, count := range owned {
+		counts[count.Tag] += count.Count
+	}
+
+	var granted []assets_dm.AssetEntity
+	if granted, err = i.grantedModels(ctx, caller); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	for _, model := range granted {
+		for _, tag := range model.Tags {
+			counts[tag]++
+		}
+	}
+
+	for tag, count := range counts {
+		results = append(results, assets_dm.TagCount{Tag: tag, Count: count})
+	}
+
+	sort.Slice(results, func(a, b int) bool {
+		return results[a].Tag < results[b].Tag
+	})
+
+	return results, nil
+}
+
+// grantedModels returns live assets granted to the caller or their groups, leaving out the ones counted as owned.
+func (i *Interactor) grantedModels(ctx context.Context, caller identity.Identity) (results []assets_dm.AssetEntity, err error) {
+
+	if caller.UserId == "" && len(caller.Roles) == 0 {
+		return nil, nil
+	}
+
+	params := ports.SelectGrantsRepoParams{GranteeGroups: caller.Roles}
+
+	if caller.UserId != "" {
+		params.GranteeUsers = []string{caller.UserId}
+	}
+
+	var grants []grants_dm.GrantEntity
+	if grants, _, err = i.grantsRepo.Select(ctx, params); err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, grant := range grants {
+		if !slices.Contains(ids, grant.AssetId) {
+			ids = append(ids, grant.AssetId)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var models []assets_dm.AssetEntity
+	if models, _, err = i.assetsRepo.Select(ctx, ports.SelectAssetsRepoParams{
+		Ids: ids,
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, model := range models {
+		if model.DeleteTime != nil || model.OwnerId == "" || model.OwnerId == caller.UserId {
+			continue
+		}
+
+		results = append(results, model)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,194 @@
+package assets_itc
+
+import (
+	assets_dm "assets/internal/core/domain/assets"
+	audit_dm "assets/internal/core/domain/audit"
+	collections_dm "assets/internal/core/domain/collections"
+	favourites_dm "assets/internal/core/domain/favourites"
+	"assets/internal/core/ports"
+	errs "assets/pkg/errors"
+	"assets/pkg/slices"
+	"context"
+	"errors"
+	"time"
+)
+
+func (i *Interactor) SelectTrash(ctx context.Context, params ports.SelectTrashItcParams) (results []assets_dm.AssetEntity, cursor string, err error) {
+
+	i.logger.Info("assets_itc.SelectTrash() performed",
+		"params", params,
+		"results", results,
+	)
+
+	if err = i.policy.Authorize(ctx, ports.ActionDeleteAssets); err != nil {
+		return nil, cursor, err
+	}
+
+	if err = i.validator.Validate(params); err != nil {
+		return nil, cursor, errors.Join(errs.ValidationError, err)
+	}
+
+	if results, cursor, err = i.selectAccessible(ctx, ports.SelectAssetsRepoParams{
+		Deleted: true,
+		Cursor:  params.Cursor,
+		Limit:   params.Limit,
+	}, accessOwn); err != nil {
+		return nil, cursor, errors.Join(errs.ProcessingError, err)
+	}
+
+	return results, cursor, nil
+}
+
+func (i *Interactor) Restore(ctx context.Context, params ...ports.RestoreAssetItcParams) (results []assets_dm.AssetEntity, err error) {
+
+	i.logger.Info("assets_itc.Restore() performed",
+		"params", params,
+		"results", results,
+	)
+
+	if err = i.policy.Authorize(ctx, ports.ActionDeleteAssets); err != nil {
+		return nil, err
+	}
+
+	if err = i.validator.Validate(params); err != nil {
+		return nil, errors.Join(errs.ValidationError, err)
+	}
+
+	ids := slices.Map(params, func(param ports.RestoreAssetItcParams) string {
+		return param.Id
+	})
+
+	var models []assets_dm.AssetEntity
+	if models, _, err = i.assetsRepo.Select(ctx, ports.SelectAssetsRepoParams{
+		Ids:     ids,
+		Deleted: true,
+	}); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	if len(models) != len(ids) {
+		return nil, errors.Join(errs.CannotBeFoundError, errors.New("asset cannot be found in the trash"))
+	}
+
+	if err = i.authorizeAccess(ctx, models, accessOwn); err != nil {
+		return nil, err
+	}
+
+	if results, err = i.assetsRepo.Update(ctx, prepareTrashableModels(models, false)...); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	i.index(ctx, results...)
+	i.record(ctx, prepareAssetsAuditParams(audit_dm.ActionUpdate, models, results)...)
+
+	return results, nil
+}
+
+// purgePageSize bounds amount of assets purged at once, so that batches deleting their rows stay small.
+const purgePageSize = 100
+
+// Purge deletes assets trashed before given moment page by page, every page is purged as a whole before the next one
+// is selected. Failed page is left in the trash and purged again by the next run.
+func (i *Interactor) Purge(ctx context.Context, params ports.PurgeAssetsItcParams) (results []assets_dm.AssetEntity, err error) {
+
+	i.logger.Info("assets_itc.Purge() performed",
+		"params", params,
+		"results", results,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return nil, errors.Join(errs.ValidationError, err)
+	}
+
+	for {
+		// purged assets leave the trash, so the first page is always the next one
+		var models []assets_dm.AssetEntity
+		if models, _, err = i.assetsRepo.Select(ctx, ports.SelectAssetsRepoParams{
+			Deleted:       true,
+			DeletedBefore: params.DeletedBefore,
+			Limit:         purgePageSize,
+		}); err != nil {
+			return results, errors.Join(errs.ProcessingError, err)
+		}
+
+		if len(models) == 0 {
+			return results, nil
+		}
+
+		if err = i.purge(ctx, models...); err != nil {
+			return results, errors.Join(errs.ProcessingError, err)
+		}
+
+		results = append(results, models...)
+
+		if len(models) < purgePageSize {
+			return results, nil
+		}
+	}
+}
+
+// purge deletes everything referring to the assets before the assets themselves. Every step can be repeated, so when
+// any of them fails the assets stay in the trash and the next purge picks up where this one stopped.
+func (i *Interactor) purge(ctx context.Context, models ...assets_dm.AssetEntity) (err error) {
+
+	ids := slices.Map(models, func(model assets_dm.AssetEntity) string {
+		return model.Id
+	})
+
+	var favourites []favourites_dm.FavouriteEntity
+	if favourites, _, err = i.favouritesRepo.Select(ctx, ports.SelectFavouritesRepoParams{AssetIds: ids}); err != nil {
+		return err
+	}
+
+	if _, err = i.favouritesRepo.Delete(ctx, favourites...); err != nil {
+		return err
+	}
+
+	i.record(ctx, prepareFavouritesAuditParams(favourites)...)
+
+	if err = i.deleteGrants(ctx, ids); err != nil {
+		return err
+	}
+
+	if err = i.deleteVersions(ctx, ids); err != nil {
+		return err
+	}
+
+	if err = i.detachCollections(ctx, ids); err != nil {
+		return err
+	}
+
+	if err = i.deleteDependencies(ctx, models...); err != nil {
+		return err
+	}
+
+	if _, err = i.assetsRepo.Delete(ctx, models...); err != nil {
+		return err
+	}
+
+	i.unindex(ctx, models...)
+
+	return nil
+}
+
+// detachCollections removes purged assets from every collection holding them.
+func (i *Interactor) detachCollections(ctx context.Context, ids []string) (err error) {
+
+	var collections []collections_dm.CollectionEntity
+	if collections, _, err = i.collectionsRepo.Select(ctx, ports.SelectCollectionsRepoParams{AssetIds: ids}); err != nil {
+		return err
+	}
+
+	for idx := range collections {
+		collections[idx].Items = slices.Filter(collections[idx].Items, func(item collections_dm.Item) bool {
+			return !slices.Contains(ids, item.AssetId)
+		})
+		collections[idx].UpdateTime = time.Now()
+	}
+
+	if _, err = i.collectionsRepo.Update(ctx, collections...); err != nil {
+		return err
+	}
+
+	return nil
+}
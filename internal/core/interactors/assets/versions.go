@@ -0,0 +1,160 @@
+package assets_itc
+
+import (
+	assets_dm "assets/internal/core/domain/assets"
+	versions_dm "assets/internal/core/domain/versions"
+	"assets/internal/core/ports"
+	"assets/pkg/diff"
+	errs "assets/pkg/errors"
+	"context"
+	"errors"
+	"fmt"
+)
+
+func (i *Interactor) SelectVersions(ctx context.Context, params ports.SelectAssetVersionsItcParams) (results []versions_dm.VersionEntity, cursor string, err error) {
+
+	i.logger.Info("assets_itc.SelectVersions() performed",
+		"params", params,
+		"results", results,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return nil, cursor, errors.Join(errs.ValidationError, err)
+	}
+
+	if _, err = i.selectAsset(ctx, params.AssetId, accessRead); err != nil {
+		return nil, cursor, err
+	}
+
+	if results, cursor, err = i.versionsRepo.Select(ctx, ports.SelectVersionsRepoParams{
+		AssetIds: []string{params.AssetId},
+		Cursor:   params.Cursor,
+		Limit:    params.Limit,
+	}); err != nil {
+		return nil, cursor, errors.Join(errs.ProcessingError, err)
+	}
+
+	if len(results) == 0 && params.Cursor == "" {
+		return nil, cursor, errors.Join(errs.CannotBeFoundError, errors.New("asset cannot be found"))
+	}
+
+	return results, cursor, nil
+}
+
+func (i *Interactor) DiffVersions(ctx context.Context, params ports.DiffAssetVersionsItcParams) (results []diff.Change, err error) {
+
+	i.logger.Info("assets_itc.DiffVersions() performed",
+		"params", params,
+		"results", results,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return nil, errors.Join(errs.ValidationError, err)
+	}
+
+	if _, err = i.selectAsset(ctx, params.AssetId, accessRead); err != nil {
+		return nil, err
+	}
+
+	var versions map[int]versions_dm.VersionEntity
+	if versions, err = i.selectVersions(ctx, params.AssetId, params.From, params.To); err != nil {
+		return nil, err
+	}
+
+	if results, err = diff.Compute(versions[params.From].Snapshot, versions[params.To].Snapshot); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	return results, nil
+}
+
+func (i *Interactor) RestoreVersion(ctx context.Context, params ports.RestoreAssetVersionItcParams) (result assets_dm.AssetEntity, err error) {
+
+	i.logger.Info("assets_itc.RestoreVersion() performed",
+		"params", params,
+		"result", result,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, errors.Join(errs.ValidationError, err)
+	}
+
+	var versions map[int]versions_dm.VersionEntity
+	if versions, err = i.selectVersions(ctx, params.AssetId, params.Number); err != nil {
+		return result, err
+	}
+
+	var results []assets_dm.AssetEntity
+	if results, err = i.Update(ctx, prepareRestoreParams(versions[params.Number].Snapshot)); err != nil {
+		return result, err
+	}
+
+	return results[0], nil
+}
+
+// selectVersions loads requested versions of the asset keyed by number, every one of them has to exist.
+func (i *Interactor) selectVersions(ctx context.Context, assetId string, numbers ...int) (results map[int]versions_dm.VersionEntity, err error) {
+
+	var versions []versions_dm.VersionEntity
+	if versions, _, err = i.versionsRepo.Select(ctx, ports.SelectVersionsRepoParams{
+		AssetIds: []string{assetId},
+		Numbers:  numbers,
+	}); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	results = make(map[int]versions_dm.VersionEntity, len(versions))
+	for _, version := range versions {
+		results[version.Number] = version
+	}
+
+	for _, number := range numbers {
+		if _, ok := results[number]; !ok {
+			return nil, errors.Join(errs.CannotBeFoundError, fmt.Errorf("version %d cannot be found", number))
+		}
+	}
+
+	return results, nil
+}
+
+// recordVersions stores snapshots of the assets as their next versions.
+func (i *Interactor) recordVersions(ctx context.Context, models ...assets_dm.AssetEntity) (err error) {
+
+	if len(models) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(models))
+	for _, model := range models {
+		ids = append(ids, model.Id)
+	}
+
+	var latest []versions_dm.VersionEntity
+	if latest, _, err = i.versionsRepo.Select(ctx, ports.SelectVersionsRepoParams{
+		AssetIds: ids,
+		Latest:   true,
+	}); err != nil {
+		return err
+	}
+
+	if _, err = i.versionsRepo.Insert(ctx, prepareVersions(ctx, models, latest)...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// deleteVersions removes history of purged assets.
+func (i *Interactor) deleteVersions(ctx context.Context, ids []string) (err error) {
+
+	var versions []versions_dm.VersionEntity
+	if versions, _, err = i.versionsRepo.Select(ctx, ports.SelectVersionsRepoParams{AssetIds: ids}); err != nil {
+		return err
+	}
+
+	if _, err = i.versionsRepo.Delete(ctx, versions...); err != nil {
+		return err
+	}
+
+	return nil
+}
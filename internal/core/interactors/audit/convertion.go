@@ -0,0 +1,14 @@
+package audit_itc
+
+import "assets/internal/core/ports"
+
+func convertSelectParams(params ports.SelectAuditItcParams) (result ports.SelectAuditRepoParams) {
+	return ports.SelectAuditRepoParams{
+		Day:        params.Day,
+		ActorId:    params.ActorId,
+		EntityType: params.EntityType,
+		EntityId:   params.EntityId,
+		Cursor:     params.Cursor,
+		Limit:      params.Limit,
+	}
+}
@@ -0,0 +1,75 @@
+package audit_itc
+
+import (
+	audit_dm "assets/internal/core/domain/audit"
+	"assets/internal/core/ports"
+	errs "assets/pkg/errors"
+	"assets/pkg/logging"
+	"assets/pkg/validation"
+	"context"
+	"errors"
+)
+
+type Interactor struct {
+	logger    logging.Logger
+	validator validation.Validator
+	auditRepo ports.AuditRepository
+	policy    ports.Policy
+}
+
+func NewInteractor(logger logging.Logger, validator validation.Validator, auditRepo ports.AuditRepository, policy ports.Policy) *Interactor {
+	return &Interactor{
+		logger:    logger,
+		validator: validator,
+		auditRepo: auditRepo,
+		policy:    policy,
+	}
+}
+
+func (i *Interactor) Record(ctx context.Context, params ...ports.RecordAuditItcParams) (results []audit_dm.EntryEntity, err error) {
+
+	i.logger.Info("audit_itc.Record() performed",
+		"params", params,
+		"results", results,
+	)
+
+	if len(params) == 0 {
+		return results, nil
+	}
+
+	if err = i.validator.Validate(params); err != nil {
+		return nil, errors.Join(errs.ValidationError, err)
+	}
+
+	if results, err = prepareCreatableModels(ctx, params); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	if results, err = i.auditRepo.Insert(ctx, results...); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	return results, nil
+}
+
+func (i *Interactor) Select(ctx context.Context, params ports.SelectAuditItcParams) (results []audit_dm.EntryEntity, cursor string, err error) {
+
+	i.logger.Info("audit_itc.Select() performed",
+		"params", params,
+		"results", results,
+	)
+
+	if err = i.policy.Authorize(ctx, ports.ActionReadAudit); err != nil {
+		return nil, cursor, err
+	}
+
+	if err = i.validator.Validate(params); err != nil {
+		return nil, cursor, errors.Join(errs.ValidationError, err)
+	}
+
+	if results, cursor, err = i.auditRepo.Select(ctx, convertSelectParams(params)); err != nil {
+		return nil, cursor, errors.Join(errs.ProcessingError, err)
+	}
+
+	return results, cursor, nil
+}
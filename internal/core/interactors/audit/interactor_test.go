@@ -0,0 +1,303 @@
+package audit_itc
+
+import (
+	assets_dm "assets/internal/core/domain/assets"
+	audit_dm "assets/internal/core/domain/audit"
+	users_dm "assets/internal/core/domain/users"
+	assets_itc "assets/internal/core/interactors/assets"
+	favourites_itc "assets/internal/core/interactors/favourites"
+	users_itc "assets/internal/core/interactors/users"
+	"assets/internal/core/policies"
+	"assets/internal/core/ports"
+	"assets/internal/mailers"
+	"assets/internal/render"
+	assets_db "assets/internal/repositories/assets"
+	attempts_db "assets/internal/repositories/attempts"
+	audiences_db "assets/internal/repositories/audiences"
+	audit_db "assets/internal/repositories/audit"
+	charts_db "assets/internal/repositories/charts"
+	collections_db "assets/internal/repositories/collections"
+	favourites_db "assets/internal/repositories/favourites"
+	grants_db "assets/internal/repositories/grants"
+	insights_db "assets/internal/repositories/insights"
+	keys_db "assets/internal/repositories/keys"
+	onetimetokens_db "assets/internal/repositories/onetimetokens"
+	users_db "assets/internal/repositories/users"
+	versions_db "assets/internal/repositories/versions"
+	"assets/internal/search"
+	"assets/pkg/diff"
+	"assets/pkg/identity"
+	"assets/pkg/logging"
+	"assets/pkg/slices"
+	"assets/pkg/validation"
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type InteractorSuite struct {
+	suite.Suite
+	interactor ports.AuditInteractor
+
+	assetsItc     ports.AssetsInteractor
+	favouritesItc ports.FavouritesInteractor
+	usersItc      ports.UsersInteractor
+
+	editorCtx context.Context
+	adminCtx  context.Context
+	today     string
+}
+
+func TestInteractorSuite(t *testing.T) {
+	suite.Run(t, new(InteractorSuite))
+}
+
+/*
+* Tests
+ */
+
+/// Select
+
+func (suite *InteractorSuite) TestSelectShouldReturnErrorWhenInputDataAreIncorrect() {
+
+	params := []ports.SelectAuditItcParams{
+		// missing Day
+		{},
+		// incorrect Day
+		{
+			Day: "18.10.2026",
+		},
+		// incorrect ActorId
+		{
+			Day:     suite.today,
+			ActorId: "fooBar",
+		},
+		// incorrect EntityType
+		{
+			Day:        suite.today,
+			EntityType: "USER",
+		},
+		// incorrect Limit
+		{
+			Day:   suite.today,
+			Limit: -1,
+		},
+	}
+
+	for _, param := range params {
+		results, cursor, err := suite.interactor.Select(suite.adminCtx, param)
+
+		suite.Empty(results, "should return empty entries list when params are incorrect")
+		suite.Empty(cursor, "should return empty cursor when params are incorrect")
+		suite.ErrorContains(err, "validation error")
+	}
+}
+
+func (suite *InteractorSuite) TestSelectShouldReturnErrorWhenCallerIsNotAdmin() {
+
+	suite.setupSampleAsset()
+
+	results, _, err := suite.interactor.Select(suite.editorCtx, ports.SelectAuditItcParams{
+		Day: suite.today,
+	})
+
+	suite.Empty(results, "should return empty entries list when caller is not admin")
+	suite.ErrorContains(err, "permission denied")
+}
+
+func (suite *InteractorSuite) TestSelectShouldReturnEntriesByFilters() {
+
+	asset := suite.setupSampleAsset()
+
+	description := "New Description"
+	if _, err := suite.assetsItc.Update(suite.editorCtx, ports.UpdateAssetItcParams{
+		Id:          asset.Id,
+		Description: &description,
+	}); err != nil {
+		panic(err)
+	}
+
+	results, _, err := suite.interactor.Select(suite.adminCtx, ports.SelectAuditItcParams{
+		Day:      suite.today,
+		EntityId: asset.Id,
+	})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal(2, len(results), "should return entries of insertion and update")
+
+	results, _, err = suite.interactor.Select(suite.adminCtx, ports.SelectAuditItcParams{
+		Day:     suite.today,
+		ActorId: uuid.NewString(),
+	})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Empty(results, "should return no entries of unknown actor")
+
+	results, _, err = suite.interactor.Select(suite.adminCtx, ports.SelectAuditItcParams{
+		Day: time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02"),
+	})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Empty(results, "should return no entries of other day")
+}
+
+/// Record
+
+func (suite *InteractorSuite) TestInsertShouldRecordActorAndCreatedFields() {
+
+	asset := suite.setupSampleAsset()
+	caller, _ := identity.FromContext(suite.editorCtx)
+
+	results, _, err := suite.interactor.Select(suite.adminCtx, ports.SelectAuditItcParams{
+		Day:        suite.today,
+		EntityType: audit_dm.EntityTypeAsset,
+	})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal(1, len(results), "should record single entry")
+
+	suite.Equal(caller.UserId, results[0].ActorId, "should record caller as actor")
+	suite.Equal(audit_dm.ActionInsert, results[0].Action)
+	suite.Equal(asset.Id, results[0].EntityId)
+	suite.Contains(results[0].Diff, diff.Change{Path: "name", After: asset.Name}, "should record every created field")
+}
+
+func (suite *InteractorSuite) TestUpdateShouldRecordChangedFieldsOnly() {
+
+	asset := suite.setupSampleAsset()
+
+	description := "New Description"
+	if _, err := suite.assetsItc.Update(suite.editorCtx, ports.UpdateAssetItcParams{
+		Id:          asset.Id,
+		Description: &description,
+	}); err != nil {
+		panic(err)
+	}
+
+	results, _, err := suite.interactor.Select(suite.adminCtx, ports.SelectAuditItcParams{
+		Day:      suite.today,
+		EntityId: asset.Id,
+	})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal(2, len(results), "should record insertion and update")
+
+	update := results[0]
+	if update.Action != audit_dm.ActionUpdate {
+		update = results[1]
+	}
+
+	suite.Equal(audit_dm.ActionUpdate, update.Action)
+	suite.Contains(update.Diff, diff.Change{Path: "description", Before: asset.Description, After: description})
+	for _, change := range update.Diff {
+		suite.Contains([]string{"description", "update_time"}, change.Path, "should not record untouched fields")
+	}
+}
+
+func (suite *InteractorSuite) TestPurgeShouldRecordRemovedFavourites() {
+
+	asset := suite.setupSampleAsset()
+
+	user, err := suite.usersItc.Register(context.Background(), ports.RegisterUserItcParams{
+		Email:    "test123@test.com",
+		Password: "test123",
+	})
+	suite.Nil(err)
+
+	favourites, err := suite.favouritesItc.Insert(context.Background(), ports.InsertFavouriteItcParams{
+		UserId:  user.Id,
+		AssetId: asset.Id,
+	})
+	suite.Nil(err)
+
+	_, err = suite.assetsItc.Delete(suite.editorCtx, ports.DeleteAssetItcParams{Id: asset.Id})
+	suite.Nil(err)
+
+	_, err = suite.assetsItc.Purge(context.Background(), ports.PurgeAssetsItcParams{DeletedBefore: time.Now().Add(time.Minute)})
+	suite.Nil(err)
+
+	results, _, err := suite.interactor.Select(suite.adminCtx, ports.SelectAuditItcParams{
+		Day:      suite.today,
+		EntityId: favourites[0].Id,
+	})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal(2, len(results), "should record insertion and cascaded deletion of favourite")
+
+	results, _, err = suite.interactor.Select(suite.adminCtx, ports.SelectAuditItcParams{
+		Day:        suite.today,
+		EntityType: audit_dm.EntityTypeAsset,
+	})
+	suite.Nil(err)
+
+	deletions := 0
+	for _, result := range results {
+		if result.Action == audit_dm.ActionDelete {
+			deletions++
+			suite.Contains(slices.Map(result.Diff, func(change diff.Change) string { return change.Path }), "delete_time", "should record moving to the trash")
+		}
+	}
+	suite.Equal(1, deletions, "should record deletion of asset")
+}
+
+/*
+* SUITE SETUP
+ */
+
+func (suite *InteractorSuite) SetupInteractor() {
+	logger := logging.NewDefaultLogger()
+	validator := validation.NewDefaultValidator()
+	auditRepo := audit_db.NewMemoryRepo()
+	versionsRepo := versions_db.NewMemoryRepo()
+	assetsRepo := assets_db.NewMemoryRepo()
+	chartsRepo := charts_db.NewMemoryRepo()
+	insightsRepo := insights_db.NewMemoryRepo()
+	audiencesRepo := audiences_db.NewMemoryRepo()
+	favouritesRepo := favourites_db.NewMemoryRepo()
+	usersRepo := users_db.NewMemoryRepo()
+	tokensRepo := onetimetokens_db.NewMemoryRepo()
+	attemptsRepo := attempts_db.NewMemoryRepo()
+	keysRepo := keys_db.NewMemoryRepo()
+
+	mailer := mailers.NewMemoryMailer()
+	policy := policies.NewRolePolicy()
+
+	suite.interactor = NewInteractor(logger, validator, auditRepo, policy)
+	suite.assetsItc = assets_itc.NewInteractor(logger, validator, assetsRepo, chartsRepo, insightsRepo, audiencesRepo, favouritesRepo, versionsRepo, grants_db.NewMemoryRepo(), collections_db.NewMemoryRepo(), usersRepo, search.NewInvertedIndex(), render.NewCache(render.NewChartRenderer(), 16), suite.interactor, policy)
+	suite.favouritesItc = favourites_itc.NewInteractor(logger, validator, favouritesRepo, usersRepo, assetsRepo, suite.interactor)
+	suite.usersItc = users_itc.NewInteractor(logger, validator, usersRepo, tokensRepo, attemptsRepo, favouritesRepo, keysRepo, mailer, nil, policy, users_itc.Settings{})
+
+	suite.editorCtx = identity.WithIdentity(context.Background(), identity.Identity{
+		UserId: uuid.NewString(),
+		Roles:  []string{users_dm.RoleEditor},
+	})
+	suite.adminCtx = identity.WithIdentity(context.Background(), identity.Identity{
+		UserId: uuid.NewString(),
+		Roles:  []string{users_dm.RoleAdmin},
+	})
+	suite.today = audit_db.Day(time.Now())
+}
+
+func (suite *InteractorSuite) SetupSuite() {
+	println("SetupSuite")
+}
+
+func (suite *InteractorSuite) SetupTest() {
+	println("SetupTest")
+	suite.SetupInteractor()
+}
+
+func (suite *InteractorSuite) setupSampleAsset() assets_dm.AssetEntity {
+
+	models, err := suite.assetsItc.Insert(suite.editorCtx, ports.InsertAssetItcParams{
+		Type:        assets_dm.TypeInsight,
+		Name:        "test name",
+		Description: "Nice Description",
+		AssetData: assets_dm.AssetData{
+			Insight: &assets_dm.Insight{
+				Text: "Nice Insight",
+			},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return models[0]
+}
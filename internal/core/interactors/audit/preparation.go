@@ -0,0 +1,32 @@
+package audit_itc
+
+import (
+	audit_dm "assets/internal/core/domain/audit"
+	"assets/internal/core/ports"
+	"assets/pkg/diff"
+	"assets/pkg/identity"
+	"context"
+)
+
+func prepareCreatableModels(ctx context.Context, params []ports.RecordAuditItcParams) (results []audit_dm.EntryEntity, err error) {
+
+	// changes made outside of any request, i.e. by background jobs, have no actor
+	caller, _ := identity.FromContext(ctx)
+
+	for _, param := range params {
+		obj := audit_dm.NewEntryEntity()
+
+		obj.ActorId = caller.UserId
+		obj.Action = param.Action
+		obj.EntityType = param.EntityType
+		obj.EntityId = param.EntityId
+
+		if obj.Diff, err = diff.Compute(param.Before, param.After); err != nil {
+			return nil, err
+		}
+
+		results = append(results, obj)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,277 @@
+package collections_itc
+
+import (
+	assets_dm "assets/internal/core/domain/assets"
+	audit_dm "assets/internal/core/domain/audit"
+	collections_dm "assets/internal/core/domain/collections"
+	users_dm "assets/internal/core/domain/users"
+	"assets/internal/core/ports"
+	errs "assets/pkg/errors"
+	"assets/pkg/identity"
+	"assets/pkg/logging"
+	"assets/pkg/slices"
+	"assets/pkg/validation"
+	"context"
+	"errors"
+)
+
+type Interactor struct {
+	logger          logging.Logger
+	validator       validation.Validator
+	collectionsRepo ports.CollectionsRepository
+	assetsItc       ports.AssetsInteractor
+	auditItc        ports.AuditInteractor
+}
+
+func NewInteractor(logger logging.Logger, validator validation.Validator, collectionsRepo ports.CollectionsRepository, assetsItc ports.AssetsInteractor, auditItc ports.AuditInteractor) *Interactor {
+	return &Interactor{
+		logger:          logger,
+		validator:       validator,
+		collectionsRepo: collectionsRepo,
+		assetsItc:       assetsItc,
+		auditItc:        auditItc,
+	}
+}
+
+func (i *Interactor) Select(ctx context.Context, params ports.SelectCollectionsItcParams) (results []collections_dm.CollectionEntity, cursor string, err error) {
+
+	i.logger.Info("collections_itc.Select() performed",
+		"params", params,
+		"results", results,
+	)
+
+	var caller identity.Identity
+	if caller, err = callerOf(ctx); err != nil {
+		return nil, cursor, err
+	}
+
+	if err = i.validator.Validate(params); err != nil {
+		return nil, cursor, errors.Join(errs.ValidationError, err)
+	}
+
+	if results, cursor, err = i.collectionsRepo.Select(ctx, ports.SelectCollectionsRepoParams{
+		OwnerIds: []string{caller.UserId},
+		Cursor:   params.Cursor,
+		Limit:    params.Limit,
+	}); err != nil {
+		return nil, cursor, errors.Join(errs.ProcessingError, err)
+	}
+
+	if results, err = i.hideItems(ctx, results); err != nil {
+		return nil, cursor, err
+	}
+
+	return results, cursor, nil
+}
+
+func (i *Interactor) Populate(ctx context.Context, params ports.PopulateCollectionItcParams) (result collections_dm.PopulatedCollection, err error) {
+
+	i.logger.Info("collections_itc.Populate() performed",
+		"params", params,
+		"result", result,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, errors.Join(errs.ValidationError, err)
+	}
+
+	var models []collections_dm.CollectionEntity
+	if models, err = i.selectCollections(ctx, params.Id); err != nil {
+		return result, err
+	}
+
+	var assets []assets_dm.AssetEntity
+	if assets, err = i.selectAssets(ctx, models[0].Items); err != nil {
+		return result, err
+	}
+
+	return populate(models[0], assets), nil
+}
+
+func (i *Interactor) Insert(ctx context.Context, params ...ports.InsertCollectionItcParams) (results []collections_dm.CollectionEntity, err error) {
+
+	i.logger.Info("collections_itc.Insert() performed",
+		"params", params,
+		"results", results,
+	)
+
+	var caller identity.Identity
+	if caller, err = callerOf(ctx); err != nil {
+		return nil, err
+	}
+
+	if err = i.validator.Validate(params); err != nil {
+		return nil, errors.Join(errs.ValidationError, err)
+	}
+
+	for _, param := range params {
+		if err = i.authorizeItems(ctx, param.Items); err != nil {
+			return nil, err
+		}
+	}
+
+	if results, err = i.collectionsRepo.Insert(ctx, prepareCreatableModels(caller, params)...); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	i.record(ctx, prepareAuditParams(audit_dm.ActionInsert, nil, results)...)
+
+	return results, nil
+}
+
+func (i *Interactor) Update(ctx context.Context, params ...ports.UpdateCollectionItcParams) (results []collections_dm.CollectionEntity, err error) {
+
+	i.logger.Info("collections_itc.Update() performed",
+		"params", params,
+		"results", results,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return nil, errors.Join(errs.ValidationError, err)
+	}
+
+	ids := slices.Map(params, func(param ports.UpdateCollectionItcParams) string {
+		return param.Id
+	})
+
+	var models []collections_dm.CollectionEntity
+	if models, err = i.selectCollections(ctx, ids...); err != nil {
+		return nil, err
+	}
+
+	for _, param := range params {
+		if param.Items == nil {
+			continue
+		}
+		if err = i.authorizeItems(ctx, *param.Items); err != nil {
+			return nil, err
+		}
+	}
+
+	if results, err = i.collectionsRepo.Update(ctx, prepareUpdatableModels(models, params)...); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	i.record(ctx, prepareAuditParams(audit_dm.ActionUpdate, models, results)...)
+
+	return i.hideItems(ctx, results)
+}
+
+func (i *Interactor) Delete(ctx context.Context, params ...ports.DeleteCollectionItcParams) (results []collections_dm.CollectionEntity, err error) {
+
+	i.logger.Info("collections_itc.Delete() performed",
+		"params", params,
+		"results", results,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return nil, errors.Join(errs.ValidationError, err)
+	}
+
+	ids := slices.Map(params, func(param ports.DeleteCollectionItcParams) string {
+		return param.Id
+	})
+
+	var models []collections_dm.CollectionEntity
+	if models, err = i.selectCollections(ctx, ids...); err != nil {
+		return nil, err
+	}
+
+	if results, err = i.collectionsRepo.Delete(ctx, models...); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	i.record(ctx, prepareAuditParams(audit_dm.ActionDelete, results, nil)...)
+
+	return results, nil
+}
+
+// selectCollections loads collections which belong to the caller, admins can load collections of every user.
+func (i *Interactor) selectCollections(ctx context.Context, ids ...string) (results []collections_dm.CollectionEntity, err error) {
+	var caller identity.Identity
+	if caller, err = callerOf(ctx); err != nil {
+		return nil, err
+	}
+
+	if results, _, err = i.collectionsRepo.Select(ctx, ports.SelectCollectionsRepoParams{Ids: ids}); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	if len(results) != len(ids) {
+		return nil, errors.Join(errs.CannotBeFoundError, errors.New("collection cannot be found"))
+	}
+
+	for _, result := range results {
+		if result.OwnerId != caller.UserId && !slices.Contains(caller.Roles, users_dm.RoleAdmin) {
+			return nil, errors.Join(errs.PermissionError, errors.New("collection belongs to different user"))
+		}
+	}
+
+	return results, nil
+}
+
+// selectAssets loads live assets of the items which the caller can read. Assets are loaded by the assets interactor,
+// so they come populated with their data and filtered by sharing grants.
+func (i *Interactor) selectAssets(ctx context.Context, items []collections_dm.Item) (results []assets_dm.AssetEntity, err error) {
+	if len(items) == 0 {
+		return results, nil
+	}
+
+	if results, _, err = i.assetsItc.Select(ctx, ports.SelectAssetsItcParams{
+		Ids: slices.Map(items, func(item collections_dm.Item) string { return item.AssetId }),
+	}); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// hideItems leaves out items of the collections without live asset readable by the caller. Trashed assets stay in
+// stored collections, so they show up again in their places once they are restored.
+func (i *Interactor) hideItems(ctx context.Context, models []collections_dm.CollectionEntity) (results []collections_dm.CollectionEntity, err error) {
+	var items []collections_dm.Item
+	for _, model := range models {
+		items = append(items, model.Items...)
+	}
+
+	var assets []assets_dm.AssetEntity
+	if assets, err = i.selectAssets(ctx, items); err != nil {
+		return nil, err
+	}
+
+	for _, model := range models {
+		results = append(results, populate(model, assets).CollectionEntity)
+	}
+
+	return results, nil
+}
+
+// authorizeItems makes sure that only live assets readable by the caller are placed in the collection.
+func (i *Interactor) authorizeItems(ctx context.Context, items []collections_dm.Item) (err error) {
+	var assets []assets_dm.AssetEntity
+	if assets, err = i.selectAssets(ctx, items); err != nil {
+		return err
+	}
+
+	if len(assets) != len(items) {
+		return errors.Join(errs.CannotBeFoundError, errors.New("asset cannot be found"))
+	}
+
+	return nil
+}
+
+// record stores audit entries of completed modification, failure is logged since the modification cannot be undone.
+func (i *Interactor) record(ctx context.Context, params ...ports.RecordAuditItcParams) {
+	if _, err := i.auditItc.Record(ctx, params...); err != nil {
+		i.logger.Info("failed to record audit entries", "err", err)
+	}
+}
+
+func callerOf(ctx context.Context) (caller identity.Identity, err error) {
+	var ok bool
+	if caller, ok = identity.FromContext(ctx); !ok || caller.UserId == "" {
+		return caller, errors.Join(errs.AuthenticationError, errors.New("missing caller identity"))
+	}
+
+	return caller, nil
+}
@@ -0,0 +1,325 @@
+package collections_itc
+
+import (
+	assets_dm "assets/internal/core/domain/assets"
+	collections_dm "assets/internal/core/domain/collections"
+	users_dm "assets/internal/core/domain/users"
+	assets_itc "assets/internal/core/interactors/assets"
+	audit_itc "assets/internal/core/interactors/audit"
+	"assets/internal/core/policies"
+	"assets/internal/core/ports"
+	"assets/internal/render"
+	assets_db "assets/internal/repositories/assets"
+	audiences_db "assets/internal/repositories/audiences"
+	audit_db "assets/internal/repositories/audit"
+	charts_db "assets/internal/repositories/charts"
+	collections_db "assets/internal/repositories/collections"
+	favourites_db "assets/internal/repositories/favourites"
+	grants_db "assets/internal/repositories/grants"
+	insights_db "assets/internal/repositories/insights"
+	users_db "assets/internal/repositories/users"
+	versions_db "assets/internal/repositories/versions"
+	"assets/internal/search"
+	"assets/pkg/identity"
+	"assets/pkg/logging"
+	"assets/pkg/slices"
+	"assets/pkg/validation"
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type InteractorSuite struct {
+	suite.Suite
+	interactor ports.CollectionsInteractor
+
+	assetsItc ports.AssetsInteractor
+
+	ctx context.Context
+}
+
+func TestInteractorSuite(t *testing.T) {
+	suite.Run(t, new(InteractorSuite))
+}
+
+/*
+* Tests
+ */
+
+/// Insert
+
+func (suite *InteractorSuite) TestInsertShouldReturnErrorWhenInputDataAreIncorrect() {
+
+	assets := suite.setupSampleAssets()
+
+	params := []ports.InsertCollectionItcParams{
+		// missing Name
+		{
+			Items: []collections_dm.Item{{AssetId: assets[0].Id, Width: 1, Height: 1}},
+		},
+		// incorrect AssetId
+		{
+			Name:  "board",
+			Items: []collections_dm.Item{{AssetId: "fooBar", Width: 1, Height: 1}},
+		},
+		// incorrect Width
+		{
+			Name:  "board",
+			Items: []collections_dm.Item{{AssetId: assets[0].Id, Width: 0, Height: 1}},
+		},
+		// duplicated AssetId
+		{
+			Name:  "board",
+			Items: []collections_dm.Item{{AssetId: assets[0].Id, Width: 1, Height: 1}, {AssetId: assets[0].Id, Width: 1, Height: 1}},
+		},
+	}
+
+	for _, param := range params {
+		models, err := suite.interactor.Insert(suite.ctx, param)
+
+		suite.Empty(models)
+		suite.ErrorContains(err, "validation error")
+	}
+
+	_, err := suite.interactor.Insert(suite.ctx, ports.InsertCollectionItcParams{
+		Name:  "board",
+		Items: []collections_dm.Item{{AssetId: uuid.NewString(), Width: 1, Height: 1}},
+	})
+	suite.ErrorContains(err, "cannot be found", "collection should hold only existing assets")
+}
+
+func (suite *InteractorSuite) TestInsertShouldCreateCollectionOfCaller() {
+
+	assets := suite.setupSampleAssets()
+	caller, _ := identity.FromContext(suite.ctx)
+
+	models, err := suite.interactor.Insert(suite.ctx, ports.InsertCollectionItcParams{
+		Name:        "board",
+		Description: "weekly report",
+		Items:       itemsOf(assets),
+	})
+
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal(1, len(models))
+	suite.Equal(caller.UserId, models[0].OwnerId, "caller should own the collection")
+	suite.Equal(itemsOf(assets), models[0].Items)
+
+	listed, _, err := suite.interactor.Select(suite.ctx, ports.SelectCollectionsItcParams{})
+	suite.Nil(err)
+	suite.Equal(1, len(listed))
+
+	listed, _, err = suite.interactor.Select(editorContext(), ports.SelectCollectionsItcParams{})
+	suite.Nil(err)
+	suite.Empty(listed, "collections of other users shouldn't be listed")
+}
+
+/// Populate
+
+func (suite *InteractorSuite) TestPopulateShouldReturnAssetsInOrderOfItems() {
+
+	assets := suite.setupSampleAssets()
+	reversed := []assets_dm.AssetEntity{assets[2], assets[0], assets[1]}
+
+	models, err := suite.interactor.Insert(suite.ctx, ports.InsertCollectionItcParams{
+		Name:  "board",
+		Items: itemsOf(reversed),
+	})
+	suite.Nil(err)
+
+	populated, err := suite.interactor.Populate(suite.ctx, ports.PopulateCollectionItcParams{Id: models[0].Id})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal(itemsOf(reversed), populated.Items)
+	suite.Equal(slices.Map(reversed, idOf), slices.Map(populated.Assets, idOf), "assets should follow order of items")
+	suite.NotNil(populated.Assets[0].AssetData.Audience, "assets should come with their data")
+
+	_, err = suite.interactor.Populate(editorContext(), ports.PopulateCollectionItcParams{Id: models[0].Id})
+	suite.ErrorContains(err, "permission denied", "collection of other user cannot be read")
+
+	_, err = suite.interactor.Populate(suite.ctx, ports.PopulateCollectionItcParams{Id: uuid.NewString()})
+	suite.ErrorContains(err, "cannot be found")
+}
+
+/// Update
+
+func (suite *InteractorSuite) TestUpdateShouldReorderItems() {
+
+	assets := suite.setupSampleAssets()
+
+	models, err := suite.interactor.Insert(suite.ctx, ports.InsertCollectionItcParams{
+		Name:  "board",
+		Items: itemsOf(assets),
+	})
+	suite.Nil(err)
+
+	name := "renamed board"
+	items := itemsOf([]assets_dm.AssetEntity{assets[1], assets[0]})
+	updated, err := suite.interactor.Update(suite.ctx, ports.UpdateCollectionItcParams{
+		Id:    models[0].Id,
+		Name:  &name,
+		Items: &items,
+	})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal(name, updated[0].Name)
+	suite.Equal(items, updated[0].Items)
+
+	_, err = suite.interactor.Update(editorContext(), ports.UpdateCollectionItcParams{Id: models[0].Id, Name: &name})
+	suite.ErrorContains(err, "permission denied", "collection of other user cannot be changed")
+
+	_, err = suite.interactor.Update(suite.ctx, ports.UpdateCollectionItcParams{
+		Id:    models[0].Id,
+		Items: &[]collections_dm.Item{{AssetId: assets[0].Id, Width: 1, Height: 1}, {AssetId: assets[0].Id, Width: 1, Height: 1}},
+	})
+	suite.ErrorContains(err, "validation error", "asset can be placed only once")
+}
+
+/// Delete
+
+func (suite *InteractorSuite) TestDeleteShouldRemoveCollection() {
+
+	models, err := suite.interactor.Insert(suite.ctx, ports.InsertCollectionItcParams{Name: "board"})
+	suite.Nil(err)
+
+	_, err = suite.interactor.Delete(editorContext(), ports.DeleteCollectionItcParams{Id: models[0].Id})
+	suite.ErrorContains(err, "permission denied")
+
+	deleted, err := suite.interactor.Delete(suite.ctx, ports.DeleteCollectionItcParams{Id: models[0].Id})
+	suite.Nil(err)
+	suite.Equal(models[0].Id, deleted[0].Id)
+
+	listed, _, err := suite.interactor.Select(suite.ctx, ports.SelectCollectionsItcParams{})
+	suite.Nil(err)
+	suite.Empty(listed)
+}
+
+/// Assets
+
+func (suite *InteractorSuite) TestDeletedAssetsShouldLeaveCollections() {
+
+	assets := suite.setupSampleAssets()
+
+	models, err := suite.interactor.Insert(suite.ctx, ports.InsertCollectionItcParams{
+		Name:  "board",
+		Items: itemsOf(assets),
+	})
+	suite.Nil(err)
+
+	_, err = suite.assetsItc.Delete(suite.ctx, ports.DeleteAssetItcParams{Id: assets[0].Id})
+	suite.Nil(err)
+
+	populated, err := suite.interactor.Populate(suite.ctx, ports.PopulateCollectionItcParams{Id: models[0].Id})
+	suite.Nil(err)
+	suite.Equal(2, len(populated.Items), "trashed asset should be hidden")
+	suite.Equal(2, len(populated.Assets))
+
+	listed, _, err := suite.interactor.Select(suite.ctx, ports.SelectCollectionsItcParams{})
+	suite.Nil(err)
+	suite.Equal(itemsOf(assets)[1:], listed[0].Items, "trashed asset should be hidden from listed collections")
+
+	_, err = suite.assetsItc.Restore(suite.ctx, ports.RestoreAssetItcParams{Id: assets[0].Id})
+	suite.Nil(err)
+
+	listed, _, err = suite.interactor.Select(suite.ctx, ports.SelectCollectionsItcParams{})
+	suite.Nil(err)
+	suite.Equal(itemsOf(assets), listed[0].Items, "restored asset should be back in its place")
+
+	_, err = suite.assetsItc.Delete(suite.ctx, ports.DeleteAssetItcParams{Id: assets[0].Id})
+	suite.Nil(err)
+
+	_, err = suite.assetsItc.Purge(suite.ctx, ports.PurgeAssetsItcParams{DeletedBefore: time.Now().Add(time.Minute)})
+	suite.Nil(err)
+
+	listed, _, err = suite.interactor.Select(suite.ctx, ports.SelectCollectionsItcParams{})
+	suite.Nil(err)
+	suite.Equal(itemsOf(assets)[1:], listed[0].Items, "purged asset should be removed from the collection")
+}
+
+/*
+* SUITE SETUP
+ */
+
+func (suite *InteractorSuite) SetupInteractor() {
+	logger := logging.NewDefaultLogger()
+	validator := validation.NewDefaultValidator()
+	assetsRepo := assets_db.NewMemoryRepo()
+	chartsRepo := charts_db.NewMemoryRepo()
+	insightsRepo := insights_db.NewMemoryRepo()
+	audiencesRepo := audiences_db.NewMemoryRepo()
+	favouritesRepo := favourites_db.NewMemoryRepo()
+	auditRepo := audit_db.NewMemoryRepo()
+	versionsRepo := versions_db.NewMemoryRepo()
+	collectionsRepo := collections_db.NewMemoryRepo()
+
+	policy := policies.NewRolePolicy()
+
+	auditItc := audit_itc.NewInteractor(logger, validator, auditRepo, policy)
+	suite.assetsItc = assets_itc.NewInteractor(logger, validator, assetsRepo, chartsRepo, insightsRepo, audiencesRepo, favouritesRepo, versionsRepo, grants_db.NewMemoryRepo(), collectionsRepo, users_db.NewMemoryRepo(), search.NewInvertedIndex(), render.NewCache(render.NewChartRenderer(), 16), auditItc, policy)
+	suite.interactor = NewInteractor(logger, validator, collectionsRepo, suite.assetsItc, auditItc)
+	suite.ctx = editorContext()
+}
+
+func (suite *InteractorSuite) SetupSuite() {
+	println("SetupSuite")
+}
+
+func (suite *InteractorSuite) SetupTest() {
+	println("SetupTest")
+	suite.SetupInteractor()
+}
+
+func (suite *InteractorSuite) setupSampleAssets() (models []assets_dm.AssetEntity) {
+
+	var err error
+	if models, err = suite.assetsItc.Insert(suite.ctx,
+		[]ports.InsertAssetItcParams{
+			{
+				Type:        assets_dm.TypeInsight,
+				Name:        "insight",
+				Description: "Nice Description",
+				AssetData: assets_dm.AssetData{
+					Insight: &assets_dm.Insight{Text: "Nice Insight"},
+				},
+			},
+			{
+				Type:        assets_dm.TypeChart,
+				Name:        "chart",
+				Description: "Nice Description",
+				AssetData: assets_dm.AssetData{
+					Chart: &assets_dm.Chart{ChartTitle: "title", XAxisTitle: "x", YAxisTitle: "y", Data: assets_dm.ChartData{Kind: assets_dm.ChartKindBar, Series: []assets_dm.Series{{Name: "sales", Points: []assets_dm.Point{{X: assets_dm.NumberX(1), Y: 2}}}}}},
+				},
+			},
+			{
+				Type:        assets_dm.TypeAudience,
+				Name:        "audience",
+				Description: "Nice Description",
+				AssetData: assets_dm.AssetData{
+					Audience: &assets_dm.Audience{Gender: assets_dm.GenderFemale, BirthCountry: "Greece", AgeGroup: "18-23"},
+				},
+			},
+		}...,
+	); err != nil {
+		panic(err)
+	}
+
+	return models
+}
+
+func itemsOf(assets []assets_dm.AssetEntity) (results []collections_dm.Item) {
+	for idx, asset := range assets {
+		results = append(results, collections_dm.Item{AssetId: asset.Id, X: 0, Y: idx, Width: 12, Height: 1})
+	}
+
+	return results
+}
+
+func idOf(asset assets_dm.AssetEntity) string {
+	return asset.Id
+}
+
+func editorContext() context.Context {
+	return identity.WithIdentity(context.Background(), identity.Identity{
+		UserId: uuid.NewString(),
+		Roles:  []string{users_dm.RoleEditor},
+	})
+}
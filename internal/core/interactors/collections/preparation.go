@@ -0,0 +1,96 @@
+package collections_itc
+
+import (
+	assets_dm "assets/internal/core/domain/assets"
+	audit_dm "assets/internal/core/domain/audit"
+	collections_dm "assets/internal/core/domain/collections"
+	"assets/internal/core/ports"
+	"assets/pkg/identity"
+	"time"
+)
+
+func prepareCreatableModels(caller identity.Identity, params []ports.InsertCollectionItcParams) (results []collections_dm.CollectionEntity) {
+
+	for _, param := range params {
+		obj := collections_dm.NewCollectionEntity()
+
+		obj.OwnerId = caller.UserId
+		obj.Name = param.Name
+		obj.Description = param.Description
+		obj.Items = append([]collections_dm.Item{}, param.Items...)
+
+		results = append(results, obj)
+	}
+
+	return results
+}
+
+func prepareUpdatableModels(models []collections_dm.CollectionEntity, params []ports.UpdateCollectionItcParams) (results []collections_dm.CollectionEntity) {
+
+	changes := make(map[string]ports.UpdateCollectionItcParams, len(params))
+	for _, param := range params {
+		changes[param.Id] = param
+	}
+
+	for _, model := range models {
+		param := changes[model.Id]
+
+		if param.Name != nil {
+			model.Name = *param.Name
+		}
+		if param.Description != nil {
+			model.Description = *param.Description
+		}
+		if param.Items != nil {
+			model.Items = append([]collections_dm.Item{}, *param.Items...)
+		}
+		model.UpdateTime = time.Now()
+
+		results = append(results, model)
+	}
+
+	return results
+}
+
+// populate pairs items of the collection with their assets, items without readable asset are left out.
+func populate(model collections_dm.CollectionEntity, assets []assets_dm.AssetEntity) (result collections_dm.PopulatedCollection) {
+
+	byId := make(map[string]assets_dm.AssetEntity, len(assets))
+	for _, asset := range assets {
+		byId[asset.Id] = asset
+	}
+
+	result.CollectionEntity = model
+	result.Items = []collections_dm.Item{}
+	result.Assets = []assets_dm.AssetEntity{}
+
+	for _, item := range model.Items {
+		if asset, ok := byId[item.AssetId]; ok {
+			result.Items = append(result.Items, item)
+			result.Assets = append(result.Assets, asset)
+		}
+	}
+
+	return result
+}
+
+func prepareAuditParams(action audit_dm.Action, before []collections_dm.CollectionEntity, after []collections_dm.CollectionEntity) (results []ports.RecordAuditItcParams) {
+
+	for idx := 0; idx < len(before) || idx < len(after); idx++ {
+		param := ports.RecordAuditItcParams{Action: action, EntityType: audit_dm.EntityTypeCollection}
+
+		if idx < len(before) {
+			param.EntityId = before[idx].Id
+			param.Before = before[idx]
+		}
+
+		if idx < len(after) {
+			param.EntityId = after[idx].Id
+			param.After = after[idx]
+		}
+
+		results = append(results, param)
+	}
+
+	return results
+}
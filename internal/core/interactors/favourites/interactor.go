@@ -2,10 +2,12 @@ package favourites_itc
 
 import (
 	assets_dm "assets/internal/core/domain/assets"
+	audit_dm "assets/internal/core/domain/audit"
 	favourites_dm "assets/internal/core/domain/favourites"
 	users_dm "assets/internal/core/domain/users"
 	"assets/internal/core/ports"
 	errs "assets/pkg/errors"
+	"assets/pkg/identity"
 	"assets/pkg/logging"
 	"assets/pkg/slices"
 	"assets/pkg/validation"
@@ -19,15 +21,17 @@ type Interactor struct {
 	favouritesRepo ports.FavouritesRepository
 	usersRepo      ports.UsersRepository
 	assetsRepo     ports.AssetsRepository
+	auditItc       ports.AuditInteractor
 }
 
-func NewInteractor(logger logging.Logger, validator validation.Validator, favouritesRepo ports.FavouritesRepository, usersRepo ports.UsersRepository, assetsRepo ports.AssetsRepository) *Interactor {
+func NewInteractor(logger logging.Logger, validator validation.Validator, favouritesRepo ports.FavouritesRepository, usersRepo ports.UsersRepository, assetsRepo ports.AssetsRepository, auditItc ports.AuditInteractor) *Interactor {
 	return &Interactor{
 		logger:         logger,
 		validator:      validator,
 		favouritesRepo: favouritesRepo,
 		usersRepo:      usersRepo,
 		assetsRepo:     assetsRepo,
+		auditItc:       auditItc,
 	}
 }
 
@@ -105,6 +109,8 @@ func (i *Interactor) Insert(ctx context.Context, params ...ports.InsertFavourite
 		return nil, errors.Join(errs.ProcessingError, err)
 	}
 
+	i.record(ctx, prepareAuditParams(audit_dm.ActionInsert, results)...)
+
 	return results, err
 }
 
@@ -123,6 +129,11 @@ func (i *Interactor) Delete(ctx context.Context, params ...ports.DeleteFavourite
 		return param.Id
 	})
 
+	caller, ok := identity.FromContext(ctx)
+	if !ok || caller.UserId == "" {
+		return nil, errors.Join(errs.AuthenticationError, errors.New("missing caller identity"))
+	}
+
 	var models []favourites_dm.FavouriteEntity
 	if models, _, err = i.Select(ctx, ports.SelectFavouritesItcParams{
 		Ids: ids,
@@ -130,9 +141,25 @@ func (i *Interactor) Delete(ctx context.Context, params ...ports.DeleteFavourite
 		return nil, errors.Join(errs.ProcessingError, err)
 	}
 
+	// favourites can be removed only by the user they belong to
+	for _, model := range models {
+		if model.UserId != caller.UserId {
+			return nil, errors.Join(errs.PermissionError, errors.New("favourite belongs to different user"))
+		}
+	}
+
 	if results, err = i.favouritesRepo.Delete(ctx, models...); err != nil {
 		return nil, errors.Join(errs.ProcessingError, err)
 	}
 
+	i.record(ctx, prepareAuditParams(audit_dm.ActionDelete, results)...)
+
 	return results, err
 }
+
+// record stores audit entries of completed modification, failure is logged since the modification cannot be undone.
+func (i *Interactor) record(ctx context.Context, params ...ports.RecordAuditItcParams) {
+	if _, err := i.auditItc.Record(ctx, params...); err != nil {
+		i.logger.Info("failed to record audit entries", "err", err)
+	}
+}
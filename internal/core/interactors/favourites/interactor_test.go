@@ -5,14 +5,27 @@ import (
 	favourites_dm "assets/internal/core/domain/favourites"
 	users_dm "assets/internal/core/domain/users"
 	assets_itc "assets/internal/core/interactors/assets"
+	audit_itc "assets/internal/core/interactors/audit"
 	users_itc "assets/internal/core/interactors/users"
+	"assets/internal/core/policies"
 	"assets/internal/core/ports"
+	"assets/internal/mailers"
+	"assets/internal/render"
 	assets_db "assets/internal/repositories/assets"
+	attempts_db "assets/internal/repositories/attempts"
 	audiences_db "assets/internal/repositories/audiences"
+	audit_db "assets/internal/repositories/audit"
 	charts_db "assets/internal/repositories/charts"
+	collections_db "assets/internal/repositories/collections"
 	favourites_db "assets/internal/repositories/favourites"
+	grants_db "assets/internal/repositories/grants"
 	insights_db "assets/internal/repositories/insights"
+	keys_db "assets/internal/repositories/keys"
+	onetimetokens_db "assets/internal/repositories/onetimetokens"
 	users_db "assets/internal/repositories/users"
+	versions_db "assets/internal/repositories/versions"
+	"assets/internal/search"
+	"assets/pkg/identity"
 	"assets/pkg/logging"
 	"assets/pkg/slices"
 	"assets/pkg/validation"
@@ -190,12 +203,44 @@ func (suite *InteractorSuite) TestCreateShouldCreateModelsWithDefaultValues() {
 
 func (suite *InteractorSuite) TestDeleteShouldReturnErrorWhenInputDataAreIncorrect() {
 
-	deletedModels, err := suite.interactor.Delete(context.Background(), ports.DeleteFavouriteItcParams{
-		Id: "fooBar",
+	params := []ports.DeleteFavouriteItcParams{
+		// incorrect Id
+		{
+			Id: "fooBar",
+		},
+		// missing Id
+		{},
+	}
+
+	for _, param := range params {
+		deletedModels, err := suite.interactor.Delete(userContext(uuid.NewString()), param)
+
+		suite.Empty(deletedModels, "should return empty objects list when input data are incorrect")
+		suite.ErrorContains(err, "validation error")
+	}
+}
+
+func (suite *InteractorSuite) TestDeleteShouldReturnErrorWhenFavouriteBelongsToDifferentUser() {
+
+	createdModels := suite.setupSampleFavourites()
+
+	deletedModels, err := suite.interactor.Delete(userContext(createdModels[1].UserId), ports.DeleteFavouriteItcParams{
+		Id: createdModels[0].Id,
 	})
+	suite.Empty(deletedModels, "should return empty objects list when favourite belongs to different user")
+	suite.ErrorContains(err, "permission denied")
 
-	suite.Empty(deletedModels, "should return empty objects list when input data are incorrect")
-	suite.ErrorContains(err, "validation error")
+	deletedModels, err = suite.interactor.Delete(context.Background(), ports.DeleteFavouriteItcParams{
+		Id: createdModels[0].Id,
+	})
+	suite.Empty(deletedModels, "should return empty objects list when caller is unknown")
+	suite.ErrorContains(err, "failed to authenticate")
+
+	models, _, err := suite.interactor.Select(context.Background(), ports.SelectFavouritesItcParams{
+		Ids: []string{createdModels[0].Id},
+	})
+	suite.Nil(err, "error should be nil")
+	suite.Equal(1, len(models), "favourite of different user should not be deleted")
 }
 
 func (suite *InteractorSuite) TestDeleteShouldDeleteSpecifiedModel() {
@@ -204,11 +249,12 @@ func (suite *InteractorSuite) TestDeleteShouldDeleteSpecifiedModel() {
 	consideredModels := make([]favourites_dm.FavouriteEntity, 2)
 	copy(consideredModels, createdModels[:2])
 
-	deletedModels, err := suite.interactor.Delete(context.Background(), []ports.DeleteFavouriteItcParams{
-		{Id: consideredModels[0].Id},
-		{Id: consideredModels[1].Id},
-	}...)
-	suite.Nil(err, "should return empty error when provided params are correct")
+	var deletedModels []favourites_dm.FavouriteEntity
+	for _, model := range consideredModels {
+		deleted, err := suite.interactor.Delete(userContext(model.UserId), ports.DeleteFavouriteItcParams{Id: model.Id})
+		suite.Nil(err, "should return empty error when provided params are correct")
+		deletedModels = append(deletedModels, deleted...)
+	}
 	suite.EqualValues(consideredModels, deletedModels, "created and deleted objects should be the same")
 
 	models, cursor, err := suite.interactor.Select(context.Background(), ports.SelectFavouritesItcParams{
@@ -232,10 +278,19 @@ func (suite *InteractorSuite) SetupInteractor() {
 	chartsRepo := charts_db.NewMemoryRepo()
 	insightsRepo := insights_db.NewMemoryRepo()
 	audiencesRepo := audiences_db.NewMemoryRepo()
-
-	suite.usersItc = users_itc.NewInteractor(logger, validator, usersRepo)
-	suite.assetsItc = assets_itc.NewInteractor(logger, validator, assetsRepo, chartsRepo, insightsRepo, audiencesRepo, favouritesRepo)
-	suite.interactor = NewInteractor(logger, validator, favouritesRepo, usersRepo, assetsRepo)
+	tokensRepo := onetimetokens_db.NewMemoryRepo()
+	attemptsRepo := attempts_db.NewMemoryRepo()
+	keysRepo := keys_db.NewMemoryRepo()
+	auditRepo := audit_db.NewMemoryRepo()
+	versionsRepo := versions_db.NewMemoryRepo()
+
+	mailer := mailers.NewMemoryMailer()
+	policy := policies.NewRolePolicy()
+
+	auditItc := audit_itc.NewInteractor(logger, validator, auditRepo, policy)
+	suite.usersItc = users_itc.NewInteractor(logger, validator, usersRepo, tokensRepo, attemptsRepo, favouritesRepo, keysRepo, mailer, nil, policy, users_itc.Settings{})
+	suite.assetsItc = assets_itc.NewInteractor(logger, validator, assetsRepo, chartsRepo, insightsRepo, audiencesRepo, favouritesRepo, versionsRepo, grants_db.NewMemoryRepo(), collections_db.NewMemoryRepo(), usersRepo, search.NewInvertedIndex(), render.NewCache(render.NewChartRenderer(), 16), auditItc, policy)
+	suite.interactor = NewInteractor(logger, validator, favouritesRepo, usersRepo, assetsRepo, auditItc)
 }
 
 func (suite *InteractorSuite) SetupSuite() {
@@ -299,7 +354,12 @@ func (suite *InteractorSuite) setupSampleDependencies() (users []users_dm.UserEn
 
 	users = append(users, user)
 
-	if assets, err = suite.assetsItc.Insert(context.Background(), []ports.InsertAssetItcParams{
+	editorCtx := identity.WithIdentity(context.Background(), identity.Identity{
+		UserId: users[0].Id,
+		Roles:  []string{users_dm.RoleEditor},
+	})
+
+	if assets, err = suite.assetsItc.Insert(editorCtx, []ports.InsertAssetItcParams{
 		{
 			Type:        assets_dm.TypeInsight,
 			Name:        "test name",
@@ -319,7 +379,7 @@ func (suite *InteractorSuite) setupSampleDependencies() (users []users_dm.UserEn
 					ChartTitle: "interesting title",
 					XAxisTitle: "important parameter",
 					YAxisTitle: "also important thing",
-					Data:       "test",
+					Data:       assets_dm.ChartData{Kind: assets_dm.ChartKindBar, Series: []assets_dm.Series{{Name: "sales", Points: []assets_dm.Point{{X: assets_dm.NumberX(1), Y: 2}}}}},
 				},
 			},
 		},
@@ -335,3 +395,10 @@ func (suite *InteractorSuite) AssertValidUuid(id string) {
 	suite.NotEmpty(parsed)
 	suite.Nil(err)
 }
+
+func userContext(userId string) context.Context {
+	return identity.WithIdentity(context.Background(), identity.Identity{
+		UserId: userId,
+		Roles:  []string{users_dm.RoleViewer},
+	})
+}
@@ -1,6 +1,7 @@
 package favourites_itc
 
 import (
+	audit_dm "assets/internal/core/domain/audit"
 	favourites_dm "assets/internal/core/domain/favourites"
 	"assets/internal/core/ports"
 )
@@ -18,3 +19,20 @@ func prepareCreatableModels(params []ports.InsertFavouriteItcParams) (results []
 
 	return results
 }
+
+func prepareAuditParams(action audit_dm.Action, models []favourites_dm.FavouriteEntity) (results []ports.RecordAuditItcParams) {
+
+	for _, model := range models {
+		param := ports.RecordAuditItcParams{Action: action, EntityType: audit_dm.EntityTypeFavourite, EntityId: model.Id}
+
+		if action == audit_dm.ActionDelete {
+			param.Before = model
+		} else {
+			param.After = model
+		}
+
+		results = append(results, param)
+	}
+
+	return results
+}
@@ -0,0 +1,159 @@
+package keys_itc
+
+import (
+	keys_dm "assets/internal/core/domain/keys"
+	users_dm "assets/internal/core/domain/users"
+	"assets/internal/core/ports"
+	errs "assets/pkg/errors"
+	"assets/pkg/logging"
+	"assets/pkg/secrets"
+	"assets/pkg/validation"
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// lastUsedPrecision limits writes caused by tracking of key usage, busy keys are updated at most once per period.
+const lastUsedPrecision = time.Minute
+
+type Interactor struct {
+	logger    logging.Logger
+	validator validation.Validator
+	keysRepo  ports.ApiKeysRepository
+	usersRepo ports.UsersRepository
+	// requireVerified rejects keys of users who haven't verified their email
+	requireVerified bool
+}
+
+func NewInteractor(logger logging.Logger, validator validation.Validator, keysRepo ports.ApiKeysRepository, usersRepo ports.UsersRepository, requireVerified bool) *Interactor {
+	return &Interactor{
+		logger:          logger,
+		validator:       validator,
+		keysRepo:        keysRepo,
+		usersRepo:       usersRepo,
+		requireVerified: requireVerified,
+	}
+}
+
+func (i *Interactor) Select(ctx context.Context, params ports.SelectApiKeysItcParams) (results []keys_dm.ApiKeyEntity, err error) {
+
+	i.logger.Info("keys_itc.Select() performed",
+		"params", params,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return nil, errors.Join(errs.ValidationError, err)
+	}
+
+	if results, _, err = i.keysRepo.Select(ctx, ports.SelectApiKeysRepoParams{UserIds: []string{params.UserId}}); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	return results, nil
+}
+
+// Insert creates api key, raw key is returned only once.
+func (i *Interactor) Insert(ctx context.Context, params ports.InsertApiKeyItcParams) (result keys_dm.ApiKeyEntity, err error) {
+
+	i.logger.Info("keys_itc.Insert() performed",
+		"params", params,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, errors.Join(errs.ValidationError, err)
+	}
+
+	if result, err = prepareCreatableModel(params); err != nil {
+		return keys_dm.ApiKeyEntity{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	// raw key is never persisted
+	stored := result
+	stored.Key = ""
+
+	if _, err = i.keysRepo.Insert(ctx, stored); err != nil {
+		return keys_dm.ApiKeyEntity{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	return result, nil
+}
+
+// Delete revokes api key of the user.
+func (i *Interactor) Delete(ctx context.Context, params ports.DeleteApiKeyItcParams) (result keys_dm.ApiKeyEntity, err error) {
+
+	i.logger.Info("keys_itc.Delete() performed",
+		"params", params,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, errors.Join(errs.ValidationError, err)
+	}
+
+	var models []keys_dm.ApiKeyEntity
+	if models, _, err = i.keysRepo.Select(ctx, ports.SelectApiKeysRepoParams{Ids: []string{params.Id}}); err != nil {
+		return result, errors.Join(errs.ProcessingError, err)
+	}
+
+	// keys of other users are reported as missing, so their ids cannot be probed
+	if len(models) < 1 || models[0].UserId != params.UserId {
+		return result, errors.Join(errs.CannotBeFoundError, errors.New("api key cannot be found"))
+	}
+
+	if _, err = i.keysRepo.Delete(ctx, models[0]); err != nil {
+		return result, errors.Join(errs.ProcessingError, err)
+	}
+
+	return models[0], nil
+}
+
+// Authenticate verifies api key and returns it together with its owner, usage of the key is recorded.
+func (i *Interactor) Authenticate(ctx context.Context, params ports.AuthenticateApiKeyItcParams) (result keys_dm.ApiKeyEntity, user users_dm.UserEntity, err error) {
+
+	i.logger.Info("keys_itc.Authenticate() performed")
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, user, errors.Join(errs.ValidationError, err)
+	}
+
+	id, secret, err := secrets.Split(strings.TrimPrefix(params.Key, keys_dm.Prefix))
+	if err != nil || !strings.HasPrefix(params.Key, keys_dm.Prefix) {
+		return result, user, errors.Join(errs.AuthenticationError, errors.New("malformed api key"))
+	}
+
+	var models []keys_dm.ApiKeyEntity
+	if models, _, err = i.keysRepo.Select(ctx, ports.SelectApiKeysRepoParams{Ids: []string{id}}); err != nil {
+		return result, user, errors.Join(errs.ProcessingError, err)
+	}
+
+	if len(models) < 1 || !secrets.Matches(models[0].Hash, secret) {
+		return result, user, errors.Join(errs.AuthenticationError, errors.New("api key is invalid"))
+	}
+
+	var users []users_dm.UserEntity
+	if users, _, err = i.usersRepo.Select(ctx, ports.SelectUsersRepoParams{Ids: []string{models[0].UserId}}); err != nil {
+		return result, user, errors.Join(errs.ProcessingError, err)
+	}
+
+	if len(users) < 1 {
+		return result, user, errors.Join(errs.AuthenticationError, errors.New("owner of api key cannot be found"))
+	}
+
+	if users[0].Disabled {
+		return result, user, errors.Join(errs.AuthenticationError, errors.New("owner of api key is disabled"))
+	}
+
+	if i.requireVerified && !users[0].Verified {
+		return result, user, errors.Join(errs.PermissionError, errors.New("email address of api key owner has not been verified"))
+	}
+
+	result = models[0]
+	if now := time.Now(); now.Sub(result.LastUsedTime) >= lastUsedPrecision {
+		result.LastUsedTime = now
+		if _, err = i.keysRepo.Update(ctx, result); err != nil {
+			return keys_dm.ApiKeyEntity{}, user, errors.Join(errs.ProcessingError, err)
+		}
+	}
+
+	return result, users[0], nil
+}
@@ -0,0 +1,219 @@
+package keys_itc
+
+import (
+	keys_dm "assets/internal/core/domain/keys"
+	users_dm "assets/internal/core/domain/users"
+	"assets/internal/core/ports"
+	keys_db "assets/internal/repositories/keys"
+	users_db "assets/internal/repositories/users"
+	"assets/pkg/logging"
+	"assets/pkg/validation"
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"strings"
+	"testing"
+)
+
+type InteractorSuite struct {
+	suite.Suite
+	interactor ports.ApiKeysInteractor
+	usersRepo  ports.UsersRepository
+}
+
+func TestInteractorSuite(t *testing.T) {
+	suite.Run(t, new(InteractorSuite))
+}
+
+/*
+* Tests
+ */
+
+/// Insert
+
+func (suite *InteractorSuite) TestInsertShouldReturnErrorWhenInputDataAreIncorrect() {
+
+	user := suite.setupSampleUser()
+
+	params := []ports.InsertApiKeyItcParams{
+		// missing name
+		{UserId: user.Id, Scopes: []keys_dm.Scope{keys_dm.ScopeAssetsRead}},
+		// missing scopes
+		{UserId: user.Id, Name: "etl"},
+		// unknown scope
+		{UserId: user.Id, Name: "etl", Scopes: []keys_dm.Scope{"users:write"}},
+		// incorrect user id
+		{UserId: "fooBar", Name: "etl", Scopes: []keys_dm.Scope{keys_dm.ScopeAssetsRead}},
+	}
+
+	for _, param := range params {
+		testModel, err := suite.interactor.Insert(context.Background(), param)
+
+		suite.Empty(testModel, "should return empty object when params are incorrect")
+		suite.ErrorContains(err, "validation error")
+	}
+}
+
+func (suite *InteractorSuite) TestInsertShouldReturnKey() {
+
+	testModel := suite.setupSampleKey(suite.setupSampleUser())
+
+	suite.True(strings.HasPrefix(testModel.Key, keys_dm.Prefix), "should return raw key")
+	suite.NotContains(testModel.Key, testModel.Hash, "should not store raw key")
+	suite.AssertValidUuid(testModel.Id)
+}
+
+/// Select
+
+func (suite *InteractorSuite) TestSelectShouldReturnKeysOfUserWithoutRawKey() {
+
+	user := suite.setupSampleUser()
+	preparedModel := suite.setupSampleKey(user)
+	suite.setupSampleKey(suite.setupSampleUser())
+
+	testModels, err := suite.interactor.Select(context.Background(), ports.SelectApiKeysItcParams{UserId: user.Id})
+
+	suite.Nil(err, "should return empty error")
+	suite.Len(testModels, 1, "should return only keys of the user")
+	suite.Equal(preparedModel.Id, testModels[0].Id, "objects should match")
+	suite.Empty(testModels[0].Key, "should not return raw key")
+}
+
+/// Authenticate
+
+func (suite *InteractorSuite) TestAuthenticateShouldReturnKeyAndOwner() {
+
+	user := suite.setupSampleUser()
+	preparedModel := suite.setupSampleKey(user)
+
+	testModel, owner, err := suite.interactor.Authenticate(context.Background(), ports.AuthenticateApiKeyItcParams{Key: preparedModel.Key})
+
+	suite.Nil(err, "should return empty error")
+	suite.Equal(user.Id, owner.Id, "should return owner of the key")
+	suite.Equal(preparedModel.Scopes, testModel.Scopes, "should return scopes of the key")
+	suite.False(testModel.LastUsedTime.IsZero(), "should track usage of the key")
+
+	testModels, _ := suite.interactor.Select(context.Background(), ports.SelectApiKeysItcParams{UserId: user.Id})
+	suite.Equal(testModel.LastUsedTime, testModels[0].LastUsedTime, "usage should be persisted")
+}
+
+func (suite *InteractorSuite) TestAuthenticateShouldReturnErrorWhenKeyIsInvalid() {
+
+	preparedModel := suite.setupSampleKey(suite.setupSampleUser())
+
+	params := []ports.AuthenticateApiKeyItcParams{
+		{Key: "fooBar"},
+		{Key: strings.TrimPrefix(preparedModel.Key, keys_dm.Prefix)},
+		{Key: keys_dm.Prefix + preparedModel.Id + ".fooBar"},
+		{Key: keys_dm.Prefix + uuid.NewString() + ".fooBar"},
+	}
+
+	for _, param := range params {
+		testModel, _, err := suite.interactor.Authenticate(context.Background(), param)
+
+		suite.Empty(testModel, "should return empty object when key is invalid")
+		suite.ErrorContains(err, "failed to authenticate")
+	}
+}
+
+func (suite *InteractorSuite) TestAuthenticateShouldReturnErrorWhenOwnerIsDisabled() {
+
+	user := suite.setupSampleUser()
+	preparedModel := suite.setupSampleKey(user)
+
+	user.Disabled = true
+	_, _ = suite.usersRepo.Update(context.Background(), user)
+
+	testModel, _, err := suite.interactor.Authenticate(context.Background(), ports.AuthenticateApiKeyItcParams{Key: preparedModel.Key})
+
+	suite.Empty(testModel, "should return empty object when owner is disabled")
+	suite.ErrorContains(err, "owner of api key is disabled")
+}
+
+func (suite *InteractorSuite) TestAuthenticateShouldReturnErrorWhenOwnerIsNotVerified() {
+
+	user := suite.setupSampleUser()
+	preparedModel := suite.setupSampleKey(user)
+
+	user.Verified = false
+	_, _ = suite.usersRepo.Update(context.Background(), user)
+
+	testModel, _, err := suite.interactor.Authenticate(context.Background(), ports.AuthenticateApiKeyItcParams{Key: preparedModel.Key})
+
+	suite.Empty(testModel, "should return empty object when owner has not verified email")
+	suite.ErrorContains(err, "has not been verified")
+}
+
+/// Delete
+
+func (suite *InteractorSuite) TestDeleteShouldRevokeKey() {
+
+	user := suite.setupSampleUser()
+	preparedModel := suite.setupSampleKey(user)
+
+	_, err := suite.interactor.Delete(context.Background(), ports.DeleteApiKeyItcParams{Id: preparedModel.Id, UserId: uuid.NewString()})
+	suite.ErrorContains(err, "cannot be found", "keys of other users should not be revoked")
+
+	testModel, err := suite.interactor.Delete(context.Background(), ports.DeleteApiKeyItcParams{Id: preparedModel.Id, UserId: user.Id})
+	suite.Nil(err, "should return empty error")
+	suite.Equal(preparedModel.Id, testModel.Id, "should return revoked key")
+
+	_, _, err = suite.interactor.Authenticate(context.Background(), ports.AuthenticateApiKeyItcParams{Key: preparedModel.Key})
+	suite.ErrorContains(err, "failed to authenticate", "revoked key should be rejected")
+}
+
+/*
+* SUITE SETUP
+ */
+
+func (suite *InteractorSuite) SetupInteractor() {
+	logger := logging.NewDefaultLogger()
+	validator := validation.NewDefaultValidator()
+
+	suite.usersRepo = users_db.NewMemoryRepo()
+
+	suite.interactor = NewInteractor(logger, validator, keys_db.NewMemoryRepo(), suite.usersRepo, true)
+}
+
+func (suite *InteractorSuite) SetupSuite() {
+	println("SetupSuite")
+}
+
+func (suite *InteractorSuite) SetupTest() {
+	println("SetupTest")
+	suite.SetupInteractor()
+}
+
+func (suite *InteractorSuite) setupSampleUser() (model users_dm.UserEntity) {
+
+	model = users_dm.NewUserEntity()
+	model.Email = uuid.NewString() + "@test.com"
+	model.Password = "test123"
+	model.Verified = true
+
+	if _, err := suite.usersRepo.Insert(context.Background(), model); err != nil {
+		panic(err)
+	}
+
+	return model
+}
+
+func (suite *InteractorSuite) setupSampleKey(user users_dm.UserEntity) (model keys_dm.ApiKeyEntity) {
+
+	var err error
+	if model, err = suite.interactor.Insert(context.Background(), ports.InsertApiKeyItcParams{
+		UserId: user.Id,
+		Name:   "etl",
+		Scopes: []keys_dm.Scope{keys_dm.ScopeAssetsRead, keys_dm.ScopeFavouritesWrite},
+	}); err != nil {
+		panic(err)
+	}
+
+	return model
+}
+
+func (suite *InteractorSuite) AssertValidUuid(id string) {
+	parsed, err := uuid.Parse(id)
+	suite.NotEmpty(parsed)
+	suite.Nil(err)
+}
@@ -0,0 +1,26 @@
+package keys_itc
+
+import (
+	keys_dm "assets/internal/core/domain/keys"
+	"assets/internal/core/ports"
+	"assets/pkg/secrets"
+)
+
+// prepareCreatableModel creates api key, key handed out to the client has form of 'ak_<id>.<secret>', only hash of
+// the secret is stored.
+func prepareCreatableModel(params ports.InsertApiKeyItcParams) (result keys_dm.ApiKeyEntity, err error) {
+
+	secret, err := secrets.Generate()
+	if err != nil {
+		return result, err
+	}
+
+	result = keys_dm.NewApiKeyEntity()
+	result.UserId = params.UserId
+	result.Name = params.Name
+	result.Scopes = params.Scopes
+	result.Hash = secrets.Hash(secret)
+	result.Key = keys_dm.Prefix + secrets.Join(result.Id, secret)
+
+	return result, nil
+}
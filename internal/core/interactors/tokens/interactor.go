@@ -0,0 +1,214 @@
+package tokens_itc
+
+import (
+	tokens_dm "assets/internal/core/domain/tokens"
+	users_dm "assets/internal/core/domain/users"
+	"assets/internal/core/ports"
+	errs "assets/pkg/errors"
+	"assets/pkg/logging"
+	"assets/pkg/secrets"
+	"assets/pkg/validation"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"time"
+)
+
+type Interactor struct {
+	logger     logging.Logger
+	validator  validation.Validator
+	tokensRepo ports.RefreshTokensRepository
+	usersRepo  ports.UsersRepository
+	lifetime   time.Duration
+	// requireVerified stops refreshing sessions of users who haven't verified their email
+	requireVerified bool
+}
+
+// NewInteractor creates refresh tokens interactor, every issued token is valid for 'lifetime'. When 'requireVerified'
+// is set, tokens of users with unverified email cannot be refreshed.
+func NewInteractor(logger logging.Logger, validator validation.Validator, tokensRepo ports.RefreshTokensRepository, usersRepo ports.UsersRepository, lifetime time.Duration, requireVerified bool) *Interactor {
+	return &Interactor{
+		logger:          logger,
+		validator:       validator,
+		tokensRepo:      tokensRepo,
+		usersRepo:       usersRepo,
+		lifetime:        lifetime,
+		requireVerified: requireVerified,
+	}
+}
+
+// Issue starts new family of refresh tokens for the user.
+func (i *Interactor) Issue(ctx context.Context, params ports.IssueTokenItcParams) (result tokens_dm.RefreshTokenEntity, err error) {
+
+	i.logger.Info("tokens_itc.Issue() performed",
+		"params", params,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, errors.Join(errs.ValidationError, err)
+	}
+
+	var users []users_dm.UserEntity
+	if users, _, err = i.usersRepo.Select(ctx, ports.SelectUsersRepoParams{Ids: []string{params.UserId}}); err != nil {
+		return result, errors.Join(errs.ProcessingError, err)
+	}
+
+	if len(users) < 1 {
+		return result, errors.Join(errs.CannotBeFoundError, errors.New("user cannot be found"))
+	}
+
+	if result, err = prepareCreatableModel(params.UserId, uuid.NewString(), params.SessionId, i.lifetime); err != nil {
+		return tokens_dm.RefreshTokenEntity{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	if _, err = i.tokensRepo.Insert(ctx, result); err != nil {
+		return tokens_dm.RefreshTokenEntity{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	return result, nil
+}
+
+// Refresh exchanges refresh token for the next one from the same family and session. Every token can be used only
+// once, using it again is treated as theft and the whole family gets revoked.
+func (i *Interactor) Refresh(ctx context.Context, params ports.RefreshTokenItcParams) (user users_dm.UserEntity, result tokens_dm.RefreshTokenEntity, err error) {
+
+	i.logger.Info("tokens_itc.Refresh() performed")
+
+	if err = i.validator.Validate(params); err != nil {
+		return user, result, errors.Join(errs.ValidationError, err)
+	}
+
+	var model tokens_dm.RefreshTokenEntity
+	if model, err = i.selectByToken(ctx, params.Token); err != nil {
+		return user, result, err
+	}
+
+	if model.Used {
+		return user, result, i.revokeReused(ctx, model)
+	}
+
+	if model.ExpireTime.Before(time.Now()) {
+		return user, result, errors.Join(errs.AuthenticationError, errors.New("refresh token has expired"))
+	}
+
+	var users []users_dm.UserEntity
+	if users, _, err = i.usersRepo.Select(ctx, ports.SelectUsersRepoParams{Ids: []string{model.UserId}}); err != nil {
+		return user, result, errors.Join(errs.ProcessingError, err)
+	}
+
+	if len(users) < 1 {
+		return user, result, errors.Join(errs.AuthenticationError, errors.New("user cannot be found"))
+	}
+
+	if users[0].Disabled {
+		return user, result, errors.Join(errs.AuthenticationError, errors.New("account is disabled"))
+	}
+
+	if i.requireVerified && !users[0].Verified {
+		return user, result, errors.Join(errs.PermissionError, errors.New("email address has not been verified"))
+	}
+
+	// the token is marked conditionally, so only one of concurrent requests replaying it gets the next token
+	var marked bool
+	model.UpdateTime = time.Now()
+	if marked, err = i.tokensRepo.MarkUsed(ctx, model); err != nil {
+		return user, result, errors.Join(errs.ProcessingError, err)
+	}
+
+	if !marked {
+		return user, result, i.revokeReused(ctx, model)
+	}
+
+	if result, err = prepareCreatableModel(model.UserId, model.FamilyId, model.SessionId, i.lifetime); err != nil {
+		return user, tokens_dm.RefreshTokenEntity{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	if _, err = i.tokensRepo.Insert(ctx, result); err != nil {
+		return user, tokens_dm.RefreshTokenEntity{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	return users[0], result, nil
+}
+
+// Revoke removes family of provided token and all tokens of provided users.
+func (i *Interactor) Revoke(ctx context.Context, params ports.RevokeTokensItcParams) (results []tokens_dm.RefreshTokenEntity, err error) {
+
+	i.logger.Info("tokens_itc.Revoke() performed",
+		"userIds", params.UserIds,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return nil, errors.Join(errs.ValidationError, err)
+	}
+
+	if params.Token != "" {
+		var model tokens_dm.RefreshTokenEntity
+		if model, err = i.selectByToken(ctx, params.Token); err != nil {
+			return nil, err
+		}
+
+		if results, err = i.revokeFamilies(ctx, model.FamilyId); err != nil {
+			return nil, errors.Join(errs.ProcessingError, err)
+		}
+	}
+
+	if len(params.UserIds) == 0 {
+		return results, nil
+	}
+
+	var models []tokens_dm.RefreshTokenEntity
+	if models, _, err = i.tokensRepo.Select(ctx, ports.SelectRefreshTokensRepoParams{UserIds: params.UserIds}); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	if models, err = i.tokensRepo.Delete(ctx, models...); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	return append(results, models...), nil
+}
+
+func (i *Interactor) selectByToken(ctx context.Context, token string) (result tokens_dm.RefreshTokenEntity, err error) {
+
+	id, secret, err := secrets.Split(token)
+	if err != nil {
+		return result, errors.Join(errs.AuthenticationError, err)
+	}
+
+	var models []tokens_dm.RefreshTokenEntity
+	if models, _, err = i.tokensRepo.Select(ctx, ports.SelectRefreshTokensRepoParams{Ids: []string{id}}); err != nil {
+		return result, errors.Join(errs.ProcessingError, err)
+	}
+
+	if len(models) < 1 || !secrets.Matches(models[0].Hash, secret) {
+		return result, errors.Join(errs.AuthenticationError, errors.New("refresh token is invalid"))
+	}
+
+	return models[0], nil
+}
+
+// revokeReused treats another use of the token as theft, so the whole family of the token is revoked.
+func (i *Interactor) revokeReused(ctx context.Context, model tokens_dm.RefreshTokenEntity) error {
+
+	i.logger.Info("tokens_itc.Refresh() detected reuse of refresh token",
+		"id", model.Id,
+		"familyId", model.FamilyId,
+		"userId", model.UserId,
+	)
+
+	if _, err := i.revokeFamilies(ctx, model.FamilyId); err != nil {
+		return errors.Join(errs.ProcessingError, err)
+	}
+
+	return errors.Join(errs.AuthenticationError, errors.New("refresh token has been already used"))
+}
+
+func (i *Interactor) revokeFamilies(ctx context.Context, familyIds ...string) (results []tokens_dm.RefreshTokenEntity, err error) {
+
+	var models []tokens_dm.RefreshTokenEntity
+	if models, _, err = i.tokensRepo.Select(ctx, ports.SelectRefreshTokensRepoParams{FamilyIds: familyIds}); err != nil {
+		return nil, err
+	}
+
+	return i.tokensRepo.Delete(ctx, models...)
+}
@@ -0,0 +1,276 @@
+package tokens_itc
+
+import (
+	tokens_dm "assets/internal/core/domain/tokens"
+	users_dm "assets/internal/core/domain/users"
+	"assets/internal/core/ports"
+	tokens_db "assets/internal/repositories/tokens"
+	users_db "assets/internal/repositories/users"
+	"assets/pkg/logging"
+	"assets/pkg/validation"
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"sync"
+	"testing"
+	"time"
+)
+
+type InteractorSuite struct {
+	suite.Suite
+	interactor ports.TokensInteractor
+	tokensRepo ports.RefreshTokensRepository
+	usersRepo  ports.UsersRepository
+}
+
+func TestInteractorSuite(t *testing.T) {
+	suite.Run(t, new(InteractorSuite))
+}
+
+/*
+* Tests
+ */
+
+/// Issue
+
+func (suite *InteractorSuite) TestIssueShouldReturnErrorWhenInputDataAreIncorrect() {
+
+	params := []ports.IssueTokenItcParams{
+		{},
+		{UserId: "fooBar"},
+	}
+
+	for _, param := range params {
+		testModel, err := suite.interactor.Issue(context.Background(), param)
+
+		suite.Empty(testModel, "should return empty object when params are incorrect")
+		suite.ErrorContains(err, "validation error")
+	}
+}
+
+func (suite *InteractorSuite) TestIssueShouldReturnErrorWhenUserDoesNotExist() {
+
+	testModel, err := suite.interactor.Issue(context.Background(), ports.IssueTokenItcParams{UserId: uuid.NewString()})
+
+	suite.Empty(testModel, "should return empty object when user does not exist")
+	suite.ErrorContains(err, "cannot be found")
+}
+
+func (suite *InteractorSuite) TestIssueShouldReturnToken() {
+
+	user := suite.setupSampleUser()
+
+	testModel, err := suite.interactor.Issue(context.Background(), ports.IssueTokenItcParams{UserId: user.Id})
+
+	suite.Nil(err, "should return empty error")
+	suite.NotEmpty(testModel.Token, "should return raw token")
+	suite.NotContains(testModel.Hash, testModel.Token, "should not store raw token")
+	suite.Equal(user.Id, testModel.UserId, "should belong to the user")
+	suite.True(testModel.ExpireTime.After(time.Now()), "should expire in the future")
+	suite.AssertValidUuid(testModel.FamilyId)
+}
+
+/// Refresh
+
+func (suite *InteractorSuite) TestRefreshShouldReturnErrorWhenTokenIsInvalid() {
+
+	issued := suite.setupSampleToken()
+
+	params := []ports.RefreshTokenItcParams{
+		{Token: "fooBar"},
+		{Token: issued.Id + ".fooBar"},
+		{Token: uuid.NewString() + ".fooBar"},
+	}
+
+	for _, param := range params {
+		_, testModel, err := suite.interactor.Refresh(context.Background(), param)
+
+		suite.Empty(testModel, "should return empty object when token is invalid")
+		suite.ErrorContains(err, "failed to authenticate")
+	}
+}
+
+func (suite *InteractorSuite) TestRefreshShouldRotateToken() {
+
+	issued := suite.setupSampleToken()
+
+	user, testModel, err := suite.interactor.Refresh(context.Background(), ports.RefreshTokenItcParams{Token: issued.Token})
+
+	suite.Nil(err, "should return empty error")
+	suite.Equal(issued.UserId, user.Id, "should return owner of the token")
+	suite.Equal(issued.FamilyId, testModel.FamilyId, "should stay within the same family")
+	suite.NotEqual(issued.Token, testModel.Token, "should return new token")
+
+	_, _, err = suite.interactor.Refresh(context.Background(), ports.RefreshTokenItcParams{Token: testModel.Token})
+	suite.Nil(err, "rotated token should be accepted")
+}
+
+func (suite *InteractorSuite) TestRefreshShouldStayWithinSession() {
+
+	issued, err := suite.interactor.Issue(context.Background(), ports.IssueTokenItcParams{UserId: suite.setupSampleUser().Id, SessionId: "session"})
+	suite.Nil(err)
+
+	_, testModel, err := suite.interactor.Refresh(context.Background(), ports.RefreshTokenItcParams{Token: issued.Token})
+
+	suite.Nil(err, "should return empty error")
+	suite.Equal("session", testModel.SessionId, "should stay within the session of the token")
+}
+
+func (suite *InteractorSuite) TestRefreshShouldRevokeFamilyWhenTokenIsReused() {
+
+	issued := suite.setupSampleToken()
+
+	_, rotated, err := suite.interactor.Refresh(context.Background(), ports.RefreshTokenItcParams{Token: issued.Token})
+	suite.Nil(err, "should return empty error")
+
+	_, _, err = suite.interactor.Refresh(context.Background(), ports.RefreshTokenItcParams{Token: issued.Token})
+	suite.ErrorContains(err, "already used")
+
+	_, _, err = suite.interactor.Refresh(context.Background(), ports.RefreshTokenItcParams{Token: rotated.Token})
+	suite.ErrorContains(err, "failed to authenticate", "whole family should be revoked")
+}
+
+func (suite *InteractorSuite) TestRefreshShouldDetectConcurrentReuse() {
+
+	issued := suite.setupSampleToken()
+
+	const attempts = 8
+	errors := make(chan error, attempts)
+
+	var group sync.WaitGroup
+	for idx := 0; idx < attempts; idx++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			_, _, err := suite.interactor.Refresh(context.Background(), ports.RefreshTokenItcParams{Token: issued.Token})
+			errors <- err
+		}()
+	}
+	group.Wait()
+	close(errors)
+
+	succeeded := 0
+	for err := range errors {
+		if err == nil {
+			succeeded++
+		} else {
+			suite.Error(err, "every other request should be rejected")
+		}
+	}
+	suite.Equal(1, succeeded, "only one of concurrent requests should get the next token")
+}
+
+func (suite *InteractorSuite) TestRefreshShouldReturnErrorWhenTokenHasExpired() {
+
+	issued := suite.setupSampleToken()
+	issued.ExpireTime = time.Now().Add(-time.Minute)
+	if _, err := suite.tokensRepo.Update(context.Background(), issued); err != nil {
+		panic(err)
+	}
+
+	_, testModel, err := suite.interactor.Refresh(context.Background(), ports.RefreshTokenItcParams{Token: issued.Token})
+
+	suite.Empty(testModel, "should return empty object when token has expired")
+	suite.ErrorContains(err, "failed to authenticate")
+}
+
+func (suite *InteractorSuite) TestRefreshShouldReturnErrorWhenUserIsNotVerified() {
+
+	issued := suite.setupSampleToken()
+
+	users, _, _ := suite.usersRepo.Select(context.Background(), ports.SelectUsersRepoParams{Ids: []string{issued.UserId}})
+	users[0].Verified = false
+	if _, err := suite.usersRepo.Update(context.Background(), users[0]); err != nil {
+		panic(err)
+	}
+
+	_, testModel, err := suite.interactor.Refresh(context.Background(), ports.RefreshTokenItcParams{Token: issued.Token})
+
+	suite.Empty(testModel, "should return empty object when user has not verified email")
+	suite.ErrorContains(err, "has not been verified")
+}
+
+/// Revoke
+
+func (suite *InteractorSuite) TestRevokeShouldRemoveTokenFamily() {
+
+	issued := suite.setupSampleToken()
+	other, err := suite.interactor.Issue(context.Background(), ports.IssueTokenItcParams{UserId: issued.UserId})
+	suite.Nil(err)
+
+	testModels, err := suite.interactor.Revoke(context.Background(), ports.RevokeTokensItcParams{Token: issued.Token})
+
+	suite.Nil(err, "should return empty error")
+	suite.Len(testModels, 1, "should remove only tokens of given family")
+
+	_, _, err = suite.interactor.Refresh(context.Background(), ports.RefreshTokenItcParams{Token: issued.Token})
+	suite.ErrorContains(err, "failed to authenticate")
+
+	_, _, err = suite.interactor.Refresh(context.Background(), ports.RefreshTokenItcParams{Token: other.Token})
+	suite.Nil(err, "tokens of other families should remain valid")
+}
+
+func (suite *InteractorSuite) TestRevokeShouldRemoveUserTokens() {
+
+	issued := suite.setupSampleToken()
+	_, err := suite.interactor.Issue(context.Background(), ports.IssueTokenItcParams{UserId: issued.UserId})
+	suite.Nil(err)
+
+	testModels, err := suite.interactor.Revoke(context.Background(), ports.RevokeTokensItcParams{UserIds: []string{issued.UserId}})
+
+	suite.Nil(err, "should return empty error")
+	suite.Len(testModels, 2, "should remove all tokens of the user")
+}
+
+/*
+* Setup
+ */
+
+func (suite *InteractorSuite) SetupInteractor() {
+	logger := logging.NewDefaultLogger()
+	validator := validation.NewDefaultValidator()
+
+	suite.tokensRepo = tokens_db.NewMemoryRepo()
+	suite.usersRepo = users_db.NewMemoryRepo()
+
+	suite.interactor = NewInteractor(logger, validator, suite.tokensRepo, suite.usersRepo, time.Hour, true)
+}
+
+func (suite *InteractorSuite) SetupSuite() {
+	println("SetupSuite")
+}
+
+func (suite *InteractorSuite) SetupTest() {
+	println("SetupTest")
+	suite.SetupInteractor()
+}
+
+func (suite *InteractorSuite) setupSampleUser() (model users_dm.UserEntity) {
+
+	model = users_dm.NewUserEntity()
+	model.Email = "test@test.com"
+	model.Password = "test123"
+	model.Verified = true
+
+	if _, err := suite.usersRepo.Insert(context.Background(), model); err != nil {
+		panic(err)
+	}
+
+	return model
+}
+
+func (suite *InteractorSuite) setupSampleToken() (model tokens_dm.RefreshTokenEntity) {
+
+	var err error
+	if model, err = suite.interactor.Issue(context.Background(), ports.IssueTokenItcParams{UserId: suite.setupSampleUser().Id}); err != nil {
+		panic(err)
+	}
+
+	return model
+}
+
+func (suite *InteractorSuite) AssertValidUuid(id string) {
+	parsed, err := uuid.Parse(id)
+	suite.NotEmpty(parsed)
+	suite.Nil(err)
+}
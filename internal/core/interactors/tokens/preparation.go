@@ -0,0 +1,27 @@
+package tokens_itc
+
+import (
+	tokens_dm "assets/internal/core/domain/tokens"
+	"assets/pkg/secrets"
+	"time"
+)
+
+// prepareCreatableModel creates refresh token within given family and session. Token handed out to the client has form of
+// '<id>.<secret>', only hash of the secret is stored.
+func prepareCreatableModel(userId string, familyId string, sessionId string, lifetime time.Duration) (result tokens_dm.RefreshTokenEntity, err error) {
+
+	secret, err := secrets.Generate()
+	if err != nil {
+		return result, err
+	}
+
+	result = tokens_dm.NewRefreshTokenEntity()
+	result.UserId = userId
+	result.FamilyId = familyId
+	result.SessionId = sessionId
+	result.Hash = secrets.Hash(secret)
+	result.ExpireTime = result.CreateTime.Add(lifetime)
+	result.Token = secrets.Join(result.Id, secret)
+
+	return result, nil
+}
@@ -1,31 +1,91 @@
 package users_itc
 
 import (
+	attempts_dm "assets/internal/core/domain/attempts"
+	favourites_dm "assets/internal/core/domain/favourites"
+	keys_dm "assets/internal/core/domain/keys"
+	tokens_dm "assets/internal/core/domain/tokens"
 	users_dm "assets/internal/core/domain/users"
 	"assets/internal/core/ports"
 	errs "assets/pkg/errors"
+	"assets/pkg/identity"
 	"assets/pkg/logging"
+	"assets/pkg/secrets"
+	"assets/pkg/slices"
+	"assets/pkg/totp"
 	"assets/pkg/validation"
 	"context"
 	"errors"
 	"golang.org/x/crypto/bcrypt"
+	"time"
 )
 
+var errDisabled = errors.Join(errs.PermissionError, errors.New("account is disabled"))
+
 type Interactor struct {
-	logger    logging.Logger
-	validator validation.Validator
-	usersRepo ports.UsersRepository
+	logger         logging.Logger
+	validator      validation.Validator
+	usersRepo      ports.UsersRepository
+	tokensRepo     ports.OneTimeTokensRepository
+	attemptsRepo   ports.LoginAttemptsRepository
+	favouritesRepo ports.FavouritesRepository
+	keysRepo       ports.ApiKeysRepository
+	mailer         ports.Mailer
+	provider       ports.IdentityProvider
+	policy         ports.Policy
+	settings       Settings
+}
+
+// Settings groups configurable behaviour of users interactor.
+type Settings struct {
+	// Admins lists emails which are granted admin role during registration.
+	Admins []string
+	// ResetLifetime defines how long password reset tokens are valid.
+	ResetLifetime time.Duration
+	// VerificationLifetime defines how long email verification tokens are valid.
+	VerificationLifetime time.Duration
+	// VerificationUrl is the address of verification endpoint, token is appended as query parameter.
+	VerificationUrl string
+	// RequireVerified blocks login of accounts with unverified email.
+	RequireVerified bool
+	// LockoutEmailThreshold is amount of failed logins which locks the email, zero disables the lockout.
+	LockoutEmailThreshold int
+	// LockoutIpThreshold is amount of failed logins which locks client address, zero disables the lockout.
+	LockoutIpThreshold int
+	// LockoutWindow defines how long failed logins are remembered.
+	LockoutWindow time.Duration
+	// LockoutDuration defines how long login stays locked.
+	LockoutDuration time.Duration
+	// TotpIssuer is the name displayed by authenticator apps.
+	TotpIssuer string
+	// ChallengeLifetime defines how long users with two-factor authentication have to provide the code.
+	ChallengeLifetime time.Duration
+	// OidcStateLifetime defines how long users have to sign in at the identity provider.
+	OidcStateLifetime time.Duration
+	// OidcAutoProvision creates accounts for unknown users signed in by the identity provider.
+	OidcAutoProvision bool
 }
 
-func NewInteractor(logger logging.Logger, validator validation.Validator, usersRepo ports.UsersRepository) *Interactor {
+// NewInteractor creates users interactor, provider can be nil when OpenID Connect login is not configured.
+func NewInteractor(logger logging.Logger, validator validation.Validator, usersRepo ports.UsersRepository, tokensRepo ports.OneTimeTokensRepository, attemptsRepo ports.LoginAttemptsRepository, favouritesRepo ports.FavouritesRepository, keysRepo ports.ApiKeysRepository, mailer ports.Mailer, provider ports.IdentityProvider, policy ports.Policy, settings Settings) *Interactor {
 	return &Interactor{
-		logger:    logger,
-		validator: validator,
-		usersRepo: usersRepo,
+		logger:         logger,
+		validator:      validator,
+		usersRepo:      usersRepo,
+		tokensRepo:     tokensRepo,
+		attemptsRepo:   attemptsRepo,
+		favouritesRepo: favouritesRepo,
+		keysRepo:       keysRepo,
+		mailer:         mailer,
+		provider:       provider,
+		policy:         policy,
+		settings:       settings,
 	}
 }
 
-func (i *Interactor) Login(ctx context.Context, params ports.LoginUserItcParams) (result users_dm.UserEntity, err error) {
+// Login verifies credentials of the user. Users with two-factor authentication enabled get only a challenge, which
+// has to be exchanged together with the code in CompleteLogin.
+func (i *Interactor) Login(ctx context.Context, params ports.LoginUserItcParams) (result users_dm.UserEntity, challenge tokens_dm.OneTimeTokenEntity, err error) {
 
 	i.logger.Info("users_itc.Login() performed",
 		"params", params,
@@ -33,23 +93,228 @@ func (i *Interactor) Login(ctx context.Context, params ports.LoginUserItcParams)
 	)
 
 	if err = i.validator.Validate(params); err != nil {
-		return result, errors.Join(errs.ValidationError, err)
+		return result, challenge, errors.Join(errs.ValidationError, err)
+	}
+
+	thresholds := i.attemptThresholds(params.Email, params.ClientIp)
+
+	var attempts []attempts_dm.LoginAttemptEntity
+	if attempts, err = i.checkLockout(ctx, thresholds); err != nil {
+		return result, challenge, err
 	}
 
 	var users []users_dm.UserEntity
 	if users, _, err = i.usersRepo.Select(ctx, ports.SelectUsersRepoParams{Emails: []string{params.Email}}); err != nil {
-		return result, errors.Join(errs.ProcessingError, err)
+		return result, challenge, errors.Join(errs.ProcessingError, err)
 	}
 
 	if len(users) < 1 {
-		return result, errors.Join(errs.CannotBeFoundError, errors.New("user cannot be found"))
+		if err = i.registerFailure(ctx, attempts, thresholds); err != nil {
+			return result, challenge, err
+		}
+		return result, challenge, errors.Join(errs.CannotBeFoundError, errors.New("user cannot be found"))
 	}
 
 	if err = bcrypt.CompareHashAndPassword([]byte(users[0].Password), []byte(params.Password)); err != nil {
-		return result, errs.AuthenticationError
+		if err = i.registerFailure(ctx, attempts, thresholds); err != nil {
+			return result, challenge, err
+		}
+		return result, challenge, errs.AuthenticationError
+	}
+
+	if users[0].Disabled {
+		return result, challenge, errDisabled
+	}
+
+	if i.settings.RequireVerified && !users[0].Verified {
+		return result, challenge, errors.Join(errs.PermissionError, errors.New("email address has not been verified"))
+	}
+
+	// failed attempts are kept until the second factor is verified as well
+	if users[0].TotpEnabled {
+		if challenge, err = i.issueToken(ctx, users[0].Id, tokens_dm.PurposeLoginChallenge, i.settings.ChallengeLifetime); err != nil {
+			return result, tokens_dm.OneTimeTokenEntity{}, errors.Join(errs.ProcessingError, err)
+		}
+
+		return result, challenge, nil
+	}
+
+	if err = i.resetFailures(ctx, attempts); err != nil {
+		return result, challenge, err
 	}
 
-	return users[0], err
+	return users[0], challenge, err
+}
+
+// CompleteLogin exchanges login challenge and two-factor code for the user. Challenge can be used only once, so
+// wrong code requires logging in again.
+func (i *Interactor) CompleteLogin(ctx context.Context, params ports.CompleteLoginUserItcParams) (result users_dm.UserEntity, err error) {
+
+	i.logger.Info("users_itc.CompleteLogin() performed",
+		"clientIp", params.ClientIp,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, errors.Join(errs.ValidationError, err)
+	}
+
+	var challenge tokens_dm.OneTimeTokenEntity
+	if challenge, err = i.consumeToken(ctx, params.Challenge, tokens_dm.PurposeLoginChallenge); err != nil {
+		return result, err
+	}
+
+	if result, err = i.selectOne(ctx, challenge.UserId); err != nil {
+		return result, err
+	}
+
+	if result.Disabled {
+		return users_dm.UserEntity{}, errDisabled
+	}
+
+	thresholds := i.attemptThresholds(result.Email, params.ClientIp)
+
+	var attempts []attempts_dm.LoginAttemptEntity
+	if attempts, err = i.checkLockout(ctx, thresholds); err != nil {
+		return users_dm.UserEntity{}, err
+	}
+
+	var verified bool
+	if result, verified, err = i.verifySecondFactor(ctx, result, params.Code); err != nil {
+		return users_dm.UserEntity{}, err
+	}
+
+	if !verified {
+		if err = i.registerFailure(ctx, attempts, thresholds); err != nil {
+			return users_dm.UserEntity{}, err
+		}
+		return users_dm.UserEntity{}, errors.Join(errs.AuthenticationError, errors.New("invalid two-factor code"))
+	}
+
+	if err = i.resetFailures(ctx, attempts); err != nil {
+		return users_dm.UserEntity{}, err
+	}
+
+	return result, nil
+}
+
+// StartOidcLogin prepares redirect to the identity provider. PKCE verifier and nonce never leave the service, they are
+// kept with the state token until the user comes back.
+func (i *Interactor) StartOidcLogin(ctx context.Context) (result users_dm.OidcAuthorization, err error) {
+
+	i.logger.Info("users_itc.StartOidcLogin() performed")
+
+	if i.provider == nil {
+		return result, errors.Join(errs.CannotBeFoundError, errors.New("oidc login is not configured"))
+	}
+
+	var state tokens_dm.OneTimeTokenEntity
+	if state, err = prepareOidcState(i.settings.OidcStateLifetime); err != nil {
+		return result, errors.Join(errs.ProcessingError, err)
+	}
+
+	if result.Url, err = i.provider.AuthorizationUrl(ctx, ports.AuthorizationUrlParams{
+		State:         state.Token,
+		Nonce:         state.Payload[oidcNonceKey],
+		CodeChallenge: prepareCodeChallenge(state.Payload[oidcVerifierKey]),
+	}); err != nil {
+		return result, errors.Join(errs.ProcessingError, err)
+	}
+
+	if _, err = i.tokensRepo.Insert(ctx, state); err != nil {
+		return users_dm.OidcAuthorization{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	result.State = state.Token
+	result.ExpireTime = state.ExpireTime
+
+	return result, nil
+}
+
+// CompleteOidcLogin exchanges authorization code for the identity and links it to the user with the same email.
+// Emails not verified by the provider are rejected, otherwise anyone could take over an account by its address. Local
+// accounts are linked only once their owners verified the email, since unverified account could have been registered
+// by someone else, who would keep signing in with its password. Users with two-factor authentication enabled get login
+// challenge, just like Login returns.
+func (i *Interactor) CompleteOidcLogin(ctx context.Context, params ports.CompleteOidcLoginUserItcParams) (result users_dm.UserEntity, challenge tokens_dm.OneTimeTokenEntity, err error) {
+
+	i.logger.Info("users_itc.CompleteOidcLogin() performed")
+
+	if i.provider == nil {
+		return result, challenge, errors.Join(errs.CannotBeFoundError, errors.New("oidc login is not configured"))
+	}
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, challenge, errors.Join(errs.ValidationError, err)
+	}
+
+	var state tokens_dm.OneTimeTokenEntity
+	if state, err = i.consumeToken(ctx, params.State, tokens_dm.PurposeOidcLogin); err != nil {
+		return result, challenge, err
+	}
+
+	var external users_dm.ExternalIdentity
+	if external, err = i.provider.Exchange(ctx, ports.ExchangeCodeParams{
+		Code:         params.Code,
+		CodeVerifier: state.Payload[oidcVerifierKey],
+		Nonce:        state.Payload[oidcNonceKey],
+	}); err != nil {
+		return result, challenge, errors.Join(errs.AuthenticationError, err)
+	}
+
+	if !external.EmailVerified {
+		return result, challenge, errors.Join(errs.AuthenticationError, errors.New("email address has not been verified by identity provider"))
+	}
+
+	var users []users_dm.UserEntity
+	if users, _, err = i.usersRepo.Select(ctx, ports.SelectUsersRepoParams{Emails: []string{external.Email}}); err != nil {
+		return result, challenge, errors.Join(errs.ProcessingError, err)
+	}
+
+	if len(users) > 0 && users[0].Disabled {
+		return result, challenge, errDisabled
+	}
+
+	if len(users) > 0 {
+		if !users[0].Verified {
+			return result, challenge, errors.Join(errs.PermissionError, errors.New("email address has to be verified before signing in with identity provider"))
+		}
+
+		if users[0].TotpEnabled {
+			if challenge, err = i.issueToken(ctx, users[0].Id, tokens_dm.PurposeLoginChallenge, i.settings.ChallengeLifetime); err != nil {
+				return result, tokens_dm.OneTimeTokenEntity{}, errors.Join(errs.ProcessingError, err)
+			}
+
+			return result, challenge, nil
+		}
+
+		return users[0], challenge, nil
+	}
+
+	if !i.settings.OidcAutoProvision {
+		return result, challenge, errors.Join(errs.PermissionError, errors.New("user has no account"))
+	}
+
+	// provisioned users sign in only through the provider until they reset the password
+	var password string
+	if password, err = secrets.Generate(); err != nil {
+		return result, challenge, errors.Join(errs.ProcessingError, err)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return result, challenge, errors.Join(errs.ProcessingError, err)
+	}
+
+	// email has been verified by the provider, so configured admins get their role right away
+	result = prepareUser(external.Email, string(hashedPassword))
+	result.Verified = true
+	result = prepareAdmin(result, i.settings.Admins)
+
+	if _, err = i.usersRepo.Insert(ctx, result); err != nil {
+		return users_dm.UserEntity{}, challenge, errors.Join(errs.ProcessingError, err)
+	}
+
+	return result, challenge, nil
 }
 
 func (i *Interactor) Register(ctx context.Context, params ports.RegisterUserItcParams) (result users_dm.UserEntity, err error) {
@@ -77,13 +342,650 @@ func (i *Interactor) Register(ctx context.Context, params ports.RegisterUserItcP
 		return result, errors.Join(errs.ProcessingError, err)
 	}
 
-	obj := users_dm.NewUserEntity()
-	obj.Email = params.Email
-	obj.Password = string(hashedPassword)
+	obj := prepareUser(params.Email, string(hashedPassword))
 
 	if _, err = i.usersRepo.Insert(ctx, obj); err != nil {
 		return result, errors.Join(errs.ProcessingError, err)
 	}
 
+	if err = i.sendVerification(ctx, obj); err != nil {
+		return result, errors.Join(errs.ProcessingError, err)
+	}
+
 	return obj, err
 }
+
+func (i *Interactor) Get(ctx context.Context, params ports.GetUserItcParams) (result users_dm.UserEntity, err error) {
+
+	i.logger.Info("users_itc.Get() performed",
+		"params", params,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, errors.Join(errs.ValidationError, err)
+	}
+
+	return i.selectOne(ctx, params.UserId)
+}
+
+// Update changes email or password of the user, both require the current password. New email has to be verified
+// again.
+func (i *Interactor) Update(ctx context.Context, params ports.UpdateUserItcParams) (result users_dm.UserEntity, err error) {
+
+	i.logger.Info("users_itc.Update() performed",
+		"userId", params.UserId,
+		"email", params.Email,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, errors.Join(errs.ValidationError, err)
+	}
+
+	if result, err = i.selectOne(ctx, params.UserId); err != nil {
+		return result, err
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(result.Password), []byte(params.CurrentPassword)); err != nil {
+		return users_dm.UserEntity{}, errors.Join(errs.AuthenticationError, errors.New("current password is incorrect"))
+	}
+
+	emailChanged := params.Email != "" && params.Email != result.Email
+
+	if emailChanged {
+		var users []users_dm.UserEntity
+		if users, _, err = i.usersRepo.Select(ctx, ports.SelectUsersRepoParams{Emails: []string{params.Email}}); err != nil {
+			return users_dm.UserEntity{}, errors.Join(errs.ProcessingError, err)
+		}
+
+		if len(users) > 0 {
+			return users_dm.UserEntity{}, errors.Join(errs.AlreadyExistsError, errors.New("email is already taken"))
+		}
+
+		result.Email = params.Email
+		result.Verified = false
+	}
+
+	if params.Password != "" {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(params.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return users_dm.UserEntity{}, errors.Join(errs.ProcessingError, err)
+		}
+
+		result.Password = string(hashedPassword)
+	}
+
+	if _, err = i.usersRepo.Update(ctx, result); err != nil {
+		return users_dm.UserEntity{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	if emailChanged {
+		if err = i.sendVerification(ctx, result); err != nil {
+			return users_dm.UserEntity{}, errors.Join(errs.ProcessingError, err)
+		}
+	}
+
+	return result, nil
+}
+
+// Delete removes the user and everything owned by the user, dependent records go first, so a failure never leaves
+// them without the owner.
+func (i *Interactor) Delete(ctx context.Context, params ports.DeleteUserItcParams) (result users_dm.UserEntity, err error) {
+
+	i.logger.Info("users_itc.Delete() performed",
+		"userId", params.UserId,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, errors.Join(errs.ValidationError, err)
+	}
+
+	if result, err = i.selectOne(ctx, params.UserId); err != nil {
+		return result, err
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(result.Password), []byte(params.Password)); err != nil {
+		return users_dm.UserEntity{}, errors.Join(errs.AuthenticationError, errors.New("password is incorrect"))
+	}
+
+	userIds := []string{result.Id}
+
+	var favourites []favourites_dm.FavouriteEntity
+	if favourites, _, err = i.favouritesRepo.Select(ctx, ports.SelectFavouritesRepoParams{UserIds: userIds}); err != nil {
+		return users_dm.UserEntity{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	if _, err = i.favouritesRepo.Delete(ctx, favourites...); err != nil {
+		return users_dm.UserEntity{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	var keys []keys_dm.ApiKeyEntity
+	if keys, _, err = i.keysRepo.Select(ctx, ports.SelectApiKeysRepoParams{UserIds: userIds}); err != nil {
+		return users_dm.UserEntity{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	if _, err = i.keysRepo.Delete(ctx, keys...); err != nil {
+		return users_dm.UserEntity{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	var tokens []tokens_dm.OneTimeTokenEntity
+	if tokens, _, err = i.tokensRepo.Select(ctx, ports.SelectOneTimeTokensRepoParams{UserIds: userIds}); err != nil {
+		return users_dm.UserEntity{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	if _, err = i.tokensRepo.Delete(ctx, tokens...); err != nil {
+		return users_dm.UserEntity{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	if _, err = i.usersRepo.Delete(ctx, result); err != nil {
+		return users_dm.UserEntity{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	return result, nil
+}
+
+func (i *Interactor) Select(ctx context.Context, params ports.SelectUsersItcParams) (results []users_dm.UserEntity, cursor string, err error) {
+
+	i.logger.Info("users_itc.Select() performed",
+		"params", params,
+	)
+
+	if err = i.policy.Authorize(ctx, ports.ActionManageUsers); err != nil {
+		return nil, cursor, err
+	}
+
+	if err = i.validator.Validate(params); err != nil {
+		return nil, cursor, errors.Join(errs.ValidationError, err)
+	}
+
+	if results, cursor, err = i.usersRepo.Select(ctx, ports.SelectUsersRepoParams{
+		EmailPrefix: params.EmailPrefix,
+		Cursor:      params.Cursor,
+		Limit:       params.Limit,
+	}); err != nil {
+		return nil, cursor, errors.Join(errs.ProcessingError, err)
+	}
+
+	return results, cursor, nil
+}
+
+// Disable blocks every way of signing in, sessions and refresh tokens issued before are revoked by the caller.
+func (i *Interactor) Disable(ctx context.Context, params ports.DisableUserItcParams) (result users_dm.UserEntity, err error) {
+
+	i.logger.Info("users_itc.Disable() performed",
+		"params", params,
+	)
+
+	if err = i.policy.Authorize(ctx, ports.ActionManageUsers); err != nil {
+		return result, err
+	}
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, errors.Join(errs.ValidationError, err)
+	}
+
+	if caller, _ := identity.FromContext(ctx); caller.UserId == params.UserId {
+		return result, errors.Join(errs.PermissionError, errors.New("own account cannot be disabled"))
+	}
+
+	return i.setDisabled(ctx, params.UserId, true)
+}
+
+func (i *Interactor) Enable(ctx context.Context, params ports.EnableUserItcParams) (result users_dm.UserEntity, err error) {
+
+	i.logger.Info("users_itc.Enable() performed",
+		"params", params,
+	)
+
+	if err = i.policy.Authorize(ctx, ports.ActionManageUsers); err != nil {
+		return result, err
+	}
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, errors.Join(errs.ValidationError, err)
+	}
+
+	return i.setDisabled(ctx, params.UserId, false)
+}
+
+func (i *Interactor) setDisabled(ctx context.Context, userId string, disabled bool) (result users_dm.UserEntity, err error) {
+
+	if result, err = i.selectOne(ctx, userId); err != nil {
+		return result, err
+	}
+
+	if result.Disabled == disabled {
+		return result, nil
+	}
+
+	result.Disabled = disabled
+
+	if _, err = i.usersRepo.Update(ctx, result); err != nil {
+		return users_dm.UserEntity{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	return result, nil
+}
+
+func (i *Interactor) GrantRole(ctx context.Context, params ports.GrantRoleUserItcParams) (result users_dm.UserEntity, err error) {
+
+	i.logger.Info("users_itc.GrantRole() performed",
+		"params", params,
+		"result", result,
+	)
+
+	if err = i.policy.Authorize(ctx, ports.ActionManageRoles); err != nil {
+		return result, err
+	}
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, errors.Join(errs.ValidationError, err)
+	}
+
+	if result, err = i.selectOne(ctx, params.UserId); err != nil {
+		return result, err
+	}
+
+	if slices.Contains(result.Roles, params.Role) {
+		return result, nil
+	}
+
+	result.Roles = append(result.Roles, params.Role)
+
+	if _, err = i.usersRepo.Update(ctx, result); err != nil {
+		return users_dm.UserEntity{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	return result, err
+}
+
+func (i *Interactor) RevokeRole(ctx context.Context, params ports.RevokeRoleUserItcParams) (result users_dm.UserEntity, err error) {
+
+	i.logger.Info("users_itc.RevokeRole() performed",
+		"params", params,
+		"result", result,
+	)
+
+	if err = i.policy.Authorize(ctx, ports.ActionManageRoles); err != nil {
+		return result, err
+	}
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, errors.Join(errs.ValidationError, err)
+	}
+
+	if result, err = i.selectOne(ctx, params.UserId); err != nil {
+		return result, err
+	}
+
+	if !slices.Contains(result.Roles, params.Role) {
+		return result, nil
+	}
+
+	result.Roles = slices.Filter(result.Roles, func(role users_dm.Role) bool {
+		return role != params.Role
+	})
+
+	if _, err = i.usersRepo.Update(ctx, result); err != nil {
+		return users_dm.UserEntity{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	return result, err
+}
+
+// ForgotPassword sends password reset token to the user. Unknown emails are silently ignored, so the endpoint cannot
+// be used to check which emails are registered.
+func (i *Interactor) ForgotPassword(ctx context.Context, params ports.ForgotPasswordUserItcParams) (err error) {
+
+	i.logger.Info("users_itc.ForgotPassword() performed",
+		"params", params,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return errors.Join(errs.ValidationError, err)
+	}
+
+	var users []users_dm.UserEntity
+	if users, _, err = i.usersRepo.Select(ctx, ports.SelectUsersRepoParams{Emails: []string{params.Email}}); err != nil {
+		return errors.Join(errs.ProcessingError, err)
+	}
+
+	if len(users) < 1 {
+		return nil
+	}
+
+	var token tokens_dm.OneTimeTokenEntity
+	if token, err = i.issueToken(ctx, users[0].Id, tokens_dm.PurposePasswordReset, i.settings.ResetLifetime); err != nil {
+		return errors.Join(errs.ProcessingError, err)
+	}
+
+	if err = i.mailer.Send(ctx, prepareResetPasswordMail(users[0], token, i.settings.ResetLifetime)); err != nil {
+		return errors.Join(errs.ProcessingError, err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets new password of the user who owns the token, token can be used only once.
+func (i *Interactor) ResetPassword(ctx context.Context, params ports.ResetPasswordUserItcParams) (result users_dm.UserEntity, err error) {
+
+	i.logger.Info("users_itc.ResetPassword() performed")
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, errors.Join(errs.ValidationError, err)
+	}
+
+	var token tokens_dm.OneTimeTokenEntity
+	if token, err = i.consumeToken(ctx, params.Token, tokens_dm.PurposePasswordReset); err != nil {
+		return result, err
+	}
+
+	if result, err = i.selectOne(ctx, token.UserId); err != nil {
+		return result, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(params.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return users_dm.UserEntity{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	result.Password = string(hashedPassword)
+
+	if _, err = i.usersRepo.Update(ctx, result); err != nil {
+		return users_dm.UserEntity{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	return result, nil
+}
+
+// VerifyEmail marks email of the user who owns the token as verified, token can be used only once. Users registered with
+// one of configured admin emails become admins once the email is verified.
+func (i *Interactor) VerifyEmail(ctx context.Context, params ports.VerifyEmailUserItcParams) (result users_dm.UserEntity, err error) {
+
+	i.logger.Info("users_itc.VerifyEmail() performed")
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, errors.Join(errs.ValidationError, err)
+	}
+
+	var token tokens_dm.OneTimeTokenEntity
+	if token, err = i.consumeToken(ctx, params.Token, tokens_dm.PurposeEmailVerification); err != nil {
+		return result, err
+	}
+
+	if result, err = i.selectOne(ctx, token.UserId); err != nil {
+		return result, err
+	}
+
+	if result.Verified {
+		return result, nil
+	}
+
+	result.Verified = true
+	result = prepareAdmin(result, i.settings.Admins)
+
+	if _, err = i.usersRepo.Update(ctx, result); err != nil {
+		return users_dm.UserEntity{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	return result, nil
+}
+
+// ResendVerification sends new verification token to the user. Unknown and already verified emails are silently
+// ignored, so the endpoint cannot be used to check which emails are registered.
+func (i *Interactor) ResendVerification(ctx context.Context, params ports.ResendVerificationUserItcParams) (err error) {
+
+	i.logger.Info("users_itc.ResendVerification() performed",
+		"params", params,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return errors.Join(errs.ValidationError, err)
+	}
+
+	var users []users_dm.UserEntity
+	if users, _, err = i.usersRepo.Select(ctx, ports.SelectUsersRepoParams{Emails: []string{params.Email}}); err != nil {
+		return errors.Join(errs.ProcessingError, err)
+	}
+
+	if len(users) < 1 || users[0].Verified {
+		return nil
+	}
+
+	if err = i.sendVerification(ctx, users[0]); err != nil {
+		return errors.Join(errs.ProcessingError, err)
+	}
+
+	return nil
+}
+
+func (i *Interactor) sendVerification(ctx context.Context, user users_dm.UserEntity) (err error) {
+
+	var token tokens_dm.OneTimeTokenEntity
+	if token, err = i.issueToken(ctx, user.Id, tokens_dm.PurposeEmailVerification, i.settings.VerificationLifetime); err != nil {
+		return err
+	}
+
+	return i.mailer.Send(ctx, prepareVerificationMail(user, token, i.settings.VerificationUrl, i.settings.VerificationLifetime))
+}
+
+// EnrollTotp starts two-factor enrollment, generated secret becomes active once it's confirmed with a valid code.
+func (i *Interactor) EnrollTotp(ctx context.Context, params ports.EnrollTotpUserItcParams) (result users_dm.TotpEnrollment, err error) {
+
+	i.logger.Info("users_itc.EnrollTotp() performed",
+		"params", params,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, errors.Join(errs.ValidationError, err)
+	}
+
+	var user users_dm.UserEntity
+	if user, err = i.selectOne(ctx, params.UserId); err != nil {
+		return result, err
+	}
+
+	if user.TotpEnabled {
+		return result, errors.Join(errs.AlreadyExistsError, errors.New("two-factor authentication is already enabled"))
+	}
+
+	if user.TotpSecret, err = totp.NewSecret(); err != nil {
+		return result, errors.Join(errs.ProcessingError, err)
+	}
+
+	if _, err = i.usersRepo.Update(ctx, user); err != nil {
+		return result, errors.Join(errs.ProcessingError, err)
+	}
+
+	return users_dm.TotpEnrollment{
+		Secret: user.TotpSecret,
+		Uri:    totp.Uri(i.settings.TotpIssuer, user.Email, user.TotpSecret),
+	}, nil
+}
+
+// ConfirmTotp enables two-factor authentication and returns recovery codes, which are shown to the user only once.
+func (i *Interactor) ConfirmTotp(ctx context.Context, params ports.ConfirmTotpUserItcParams) (results []string, err error) {
+
+	i.logger.Info("users_itc.ConfirmTotp() performed",
+		"userId", params.UserId,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return nil, errors.Join(errs.ValidationError, err)
+	}
+
+	var user users_dm.UserEntity
+	if user, err = i.selectOne(ctx, params.UserId); err != nil {
+		return nil, err
+	}
+
+	if user.TotpEnabled {
+		return nil, errors.Join(errs.AlreadyExistsError, errors.New("two-factor authentication is already enabled"))
+	}
+
+	if user.TotpSecret == "" {
+		return nil, errors.Join(errs.CannotBeFoundError, errors.New("two-factor enrollment has not been started"))
+	}
+
+	var step int64
+	var ok bool
+	if step, ok = totp.Verify(user.TotpSecret, params.Code, time.Now(), user.TotpLastStep); !ok {
+		return nil, errors.Join(errs.AuthenticationError, errors.New("invalid two-factor code"))
+	}
+
+	var hashes []string
+	if results, hashes, err = prepareRecoveryCodes(); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	user.TotpEnabled = true
+	user.TotpLastStep = step
+	user.RecoveryCodes = hashes
+
+	if _, err = i.usersRepo.Update(ctx, user); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	return results, nil
+}
+
+// DisableTotp turns two-factor authentication off, it requires valid code or one of recovery codes.
+func (i *Interactor) DisableTotp(ctx context.Context, params ports.DisableTotpUserItcParams) (result users_dm.UserEntity, err error) {
+
+	i.logger.Info("users_itc.DisableTotp() performed",
+		"userId", params.UserId,
+	)
+
+	if err = i.validator.Validate(params); err != nil {
+		return result, errors.Join(errs.ValidationError, err)
+	}
+
+	if result, err = i.selectOne(ctx, params.UserId); err != nil {
+		return result, err
+	}
+
+	if !result.TotpEnabled {
+		return result, errors.Join(errs.CannotBeFoundError, errors.New("two-factor authentication is not enabled"))
+	}
+
+	var verified bool
+	if result, verified, err = i.verifySecondFactor(ctx, result, params.Code); err != nil {
+		return users_dm.UserEntity{}, err
+	} else if !verified {
+		return users_dm.UserEntity{}, errors.Join(errs.AuthenticationError, errors.New("invalid two-factor code"))
+	}
+
+	result.TotpEnabled = false
+	result.TotpSecret = ""
+	result.RecoveryCodes = nil
+
+	if _, err = i.usersRepo.Update(ctx, result); err != nil {
+		return users_dm.UserEntity{}, errors.Join(errs.ProcessingError, err)
+	}
+
+	return result, nil
+}
+
+// verifySecondFactor accepts current TOTP code or one of recovery codes. Period of accepted TOTP code is stored, so the
+// same or an older code cannot be used again, used recovery code is removed.
+func (i *Interactor) verifySecondFactor(ctx context.Context, user users_dm.UserEntity, code string) (result users_dm.UserEntity, verified bool, err error) {
+
+	if step, ok := totp.Verify(user.TotpSecret, code, time.Now(), user.TotpLastStep); ok {
+		user.TotpLastStep = step
+
+		if _, err = i.usersRepo.Update(ctx, user); err != nil {
+			return result, false, errors.Join(errs.ProcessingError, err)
+		}
+
+		return user, true, nil
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	for idx, hash := range user.RecoveryCodes {
+		if !secrets.Matches(hash, normalized) {
+			continue
+		}
+
+		user.RecoveryCodes = append(append([]string{}, user.RecoveryCodes[:idx]...), user.RecoveryCodes[idx+1:]...)
+
+		if _, err = i.usersRepo.Update(ctx, user); err != nil {
+			return result, false, errors.Join(errs.ProcessingError, err)
+		}
+
+		return user, true, nil
+	}
+
+	return user, false, nil
+}
+
+// issueToken replaces tokens of the user issued for the same purpose with the new one.
+func (i *Interactor) issueToken(ctx context.Context, userId string, purpose tokens_dm.Purpose, lifetime time.Duration) (result tokens_dm.OneTimeTokenEntity, err error) {
+
+	var tokens []tokens_dm.OneTimeTokenEntity
+	if tokens, _, err = i.tokensRepo.Select(ctx, ports.SelectOneTimeTokensRepoParams{UserIds: []string{userId}}); err != nil {
+		return result, err
+	}
+
+	tokens = slices.Filter(tokens, func(token tokens_dm.OneTimeTokenEntity) bool {
+		return token.Purpose == purpose
+	})
+
+	if _, err = i.tokensRepo.Delete(ctx, tokens...); err != nil {
+		return result, err
+	}
+
+	if result, err = prepareOneTimeToken(userId, purpose, lifetime); err != nil {
+		return result, err
+	}
+
+	if _, err = i.tokensRepo.Insert(ctx, result); err != nil {
+		return tokens_dm.OneTimeTokenEntity{}, err
+	}
+
+	return result, nil
+}
+
+// consumeToken verifies token issued for given purpose and removes it, so it cannot be used again.
+func (i *Interactor) consumeToken(ctx context.Context, token string, purpose tokens_dm.Purpose) (result tokens_dm.OneTimeTokenEntity, err error) {
+
+	id, secret, err := secrets.Split(token)
+	if err != nil {
+		return result, errors.Join(errs.AuthenticationError, err)
+	}
+
+	var tokens []tokens_dm.OneTimeTokenEntity
+	if tokens, _, err = i.tokensRepo.Select(ctx, ports.SelectOneTimeTokensRepoParams{Ids: []string{id}}); err != nil {
+		return result, errors.Join(errs.ProcessingError, err)
+	}
+
+	if len(tokens) < 1 || tokens[0].Purpose != purpose || !secrets.Matches(tokens[0].Hash, secret) {
+		return result, errors.Join(errs.AuthenticationError, errors.New("token is invalid"))
+	}
+
+	if tokens[0].ExpireTime.Before(time.Now()) {
+		return result, errors.Join(errs.AuthenticationError, errors.New("token has expired"))
+	}
+
+	// concurrent requests may have read the same token, only the one which removes it gets to use it
+	var consumed bool
+	if consumed, err = i.tokensRepo.Consume(ctx, tokens[0]); err != nil {
+		return result, errors.Join(errs.ProcessingError, err)
+	} else if !consumed {
+		return result, errors.Join(errs.AuthenticationError, errors.New("token has already been used"))
+	}
+
+	return tokens[0], nil
+}
+
+func (i *Interactor) selectOne(ctx context.Context, id string) (result users_dm.UserEntity, err error) {
+
+	var users []users_dm.UserEntity
+	if users, _, err = i.usersRepo.Select(ctx, ports.SelectUsersRepoParams{Ids: []string{id}}); err != nil {
+		return result, errors.Join(errs.ProcessingError, err)
+	}
+
+	if len(users) < 1 {
+		return result, errors.Join(errs.CannotBeFoundError, errors.New("user cannot be found"))
+	}
+
+	return users[0], nil
+}
@@ -1,10 +1,22 @@
 package users_itc
 
 import (
+	favourites_dm "assets/internal/core/domain/favourites"
+	keys_dm "assets/internal/core/domain/keys"
 	users_dm "assets/internal/core/domain/users"
+	"assets/internal/core/policies"
 	"assets/internal/core/ports"
+	"assets/internal/mailers"
+	"assets/internal/providers"
+	attempts_db "assets/internal/repositories/attempts"
+	favourites_db "assets/internal/repositories/favourites"
+	keys_db "assets/internal/repositories/keys"
+	onetimetokens_db "assets/internal/repositories/onetimetokens"
 	users_db "assets/internal/repositories/users"
+	errs "assets/pkg/errors"
+	"assets/pkg/identity"
 	"assets/pkg/logging"
+	"assets/pkg/totp"
 	"assets/pkg/validation"
 	"context"
 	r "crypto/rand"
@@ -12,15 +24,27 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/suite"
 	"math/rand"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
 
 type InteractorSuite struct {
 	suite.Suite
-	interactor ports.UsersInteractor
+	interactor     ports.UsersInteractor
+	usersRepo      ports.UsersRepository
+	tokensRepo     ports.OneTimeTokensRepository
+	attemptsRepo   ports.LoginAttemptsRepository
+	favouritesRepo ports.FavouritesRepository
+	keysRepo       ports.ApiKeysRepository
+	mailer         *mailers.MemoryMailer
+	issuer         *providers.StubIssuer
 }
 
+var tokenPattern = regexp.MustCompile(`[0-9a-f-]{36}\.[A-Za-z0-9_-]+`)
+
 func TestInteractorSuite(t *testing.T) {
 	suite.Run(t, new(InteractorSuite))
 }
@@ -45,7 +69,7 @@ func (suite *InteractorSuite) TestLoginShouldReturnErrorWhenInputDataAreIncorrec
 	}
 
 	for _, param := range params {
-		testsModels, err := suite.interactor.Login(context.Background(), param)
+		testsModels, _, err := suite.interactor.Login(context.Background(), param)
 
 		suite.Empty(testsModels, "should return empty objects list when params are empty")
 		suite.ErrorContains(err, "validation error")
@@ -56,7 +80,7 @@ func (suite *InteractorSuite) TestLoginShouldReturnUserObject() {
 
 	preparedModel := suite.setupSampleUser()
 
-	testModel, err := suite.interactor.Login(context.Background(), ports.LoginUserItcParams{
+	testModel, _, err := suite.interactor.Login(context.Background(), ports.LoginUserItcParams{
 		Email:    preparedModel.Email,
 		Password: "test123",
 	})
@@ -65,6 +89,85 @@ func (suite *InteractorSuite) TestLoginShouldReturnUserObject() {
 	suite.Equal(preparedModel, testModel, "objects should match")
 }
 
+func (suite *InteractorSuite) TestLoginShouldLockEmailAfterFailedAttempts() {
+
+	preparedModel := suite.setupSampleUser()
+
+	for idx := 0; idx < 3; idx++ {
+		_, _, err := suite.interactor.Login(context.Background(), ports.LoginUserItcParams{Email: preparedModel.Email, Password: "wrong"})
+		suite.ErrorContains(err, "failed to authenticate")
+	}
+
+	testModel, _, err := suite.interactor.Login(context.Background(), ports.LoginUserItcParams{Email: preparedModel.Email, Password: "test123"})
+
+	suite.Empty(testModel, "should return empty object when email is locked")
+	suite.ErrorContains(err, "too many requests", "should reject even correct password while locked")
+
+	var retryErr errs.RetryAfterError
+	suite.ErrorAs(err, &retryErr, "should tell when to retry")
+	suite.Greater(retryErr.RetryAfter, time.Duration(0))
+}
+
+func (suite *InteractorSuite) TestLoginShouldCountConcurrentFailedAttempts() {
+
+	preparedModel := suite.setupSampleUser()
+	params := ports.LoginUserItcParams{Email: preparedModel.Email, Password: "wrong123"}
+
+	const attempts = 3
+	errors := make(chan error, attempts)
+
+	var group sync.WaitGroup
+	for idx := 0; idx < attempts; idx++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			_, _, err := suite.interactor.Login(context.Background(), params)
+			errors <- err
+		}()
+	}
+	group.Wait()
+	close(errors)
+
+	for err := range errors {
+		suite.ErrorContains(err, "failed to authenticate")
+	}
+
+	_, _, err := suite.interactor.Login(context.Background(), ports.LoginUserItcParams{Email: preparedModel.Email, Password: "test123"})
+	suite.ErrorContains(err, "too many requests", "every concurrent failure should count towards the threshold")
+}
+
+func (suite *InteractorSuite) TestLoginShouldLockClientIpAfterFailedAttempts() {
+
+	suite.setupSampleUser()
+
+	for idx := 0; idx < 5; idx++ {
+		_, _, err := suite.interactor.Login(context.Background(), ports.LoginUserItcParams{
+			Email:    fmt.Sprintf("test%d@test.com", idx),
+			Password: "wrong",
+			ClientIp: "10.0.0.1",
+		})
+		suite.ErrorContains(err, "cannot be found")
+	}
+
+	_, _, err := suite.interactor.Login(context.Background(), ports.LoginUserItcParams{Email: "test@test.com", Password: "test123", ClientIp: "10.0.0.1"})
+	suite.ErrorContains(err, "too many requests", "should reject every email from locked address")
+
+	_, _, err = suite.interactor.Login(context.Background(), ports.LoginUserItcParams{Email: "test@test.com", Password: "test123", ClientIp: "10.0.0.2"})
+	suite.Nil(err, "should accept other addresses")
+}
+
+func (suite *InteractorSuite) TestLoginShouldResetFailedAttemptsOnSuccess() {
+
+	preparedModel := suite.setupSampleUser()
+
+	for _, password := range []string{"wrong", "wrong", "test123", "wrong", "wrong"} {
+		_, _, _ = suite.interactor.Login(context.Background(), ports.LoginUserItcParams{Email: preparedModel.Email, Password: password})
+	}
+
+	_, _, err := suite.interactor.Login(context.Background(), ports.LoginUserItcParams{Email: preparedModel.Email, Password: "test123"})
+	suite.Nil(err, "counter should be reset by successful login")
+}
+
 /// Register
 
 func (suite *InteractorSuite) TestRegisterShouldReturnErrorWhenInputDataAreIncorrect() {
@@ -113,6 +216,684 @@ func (suite *InteractorSuite) TestRegisterShouldReturnObject() {
 	suite.NotEmpty(testModel.UpdateTime, "field should be populated")
 }
 
+func (suite *InteractorSuite) TestRegisterShouldAssignDefaultRoles() {
+
+	testModel, err := suite.interactor.Register(context.Background(), ports.RegisterUserItcParams{
+		Email:    "test@test.com",
+		Password: "test123",
+	})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal([]users_dm.Role{users_dm.RoleViewer}, testModel.Roles, "regular user should be a viewer")
+
+	adminModel, err := suite.interactor.Register(context.Background(), ports.RegisterUserItcParams{
+		Email:    "admin@test.com",
+		Password: "test123",
+	})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal([]users_dm.Role{users_dm.RoleViewer}, adminModel.Roles, "configured admin should not be an admin before verification")
+
+	adminModel, err = suite.interactor.VerifyEmail(context.Background(), ports.VerifyEmailUserItcParams{Token: suite.mailedToken()})
+	suite.Nil(err, "should return empty error")
+	suite.ElementsMatch([]users_dm.Role{users_dm.RoleViewer, users_dm.RoleAdmin}, adminModel.Roles, "configured admin should be an admin once verified")
+}
+
+/// Roles
+
+func (suite *InteractorSuite) TestGrantRoleShouldReturnErrorWhenInputDataAreIncorrect() {
+
+	preparedModel := suite.setupSampleUser()
+
+	params := []ports.GrantRoleUserItcParams{
+		// incorrect UserId
+		{
+			UserId: "fooBar",
+			Role:   users_dm.RoleEditor,
+		},
+		// incorrect Role
+		{
+			UserId: preparedModel.Id,
+			Role:   "FooBar",
+		},
+	}
+
+	for _, param := range params {
+		testModel, err := suite.interactor.GrantRole(adminContext(), param)
+
+		suite.Empty(testModel, "should return empty object when input data are incorrect")
+		suite.ErrorContains(err, "validation error")
+	}
+}
+
+func (suite *InteractorSuite) TestGrantRoleShouldReturnErrorWhenCallerIsNotAdmin() {
+
+	preparedModel := suite.setupSampleUser()
+
+	testModel, err := suite.interactor.GrantRole(identity.WithIdentity(context.Background(), identity.Identity{
+		UserId: preparedModel.Id,
+		Roles:  preparedModel.Roles,
+	}), ports.GrantRoleUserItcParams{
+		UserId: preparedModel.Id,
+		Role:   users_dm.RoleAdmin,
+	})
+
+	suite.Empty(testModel, "should return empty object when caller is not an admin")
+	suite.ErrorContains(err, "permission denied")
+}
+
+func (suite *InteractorSuite) TestGrantAndRevokeRoleShouldUpdateUserRoles() {
+
+	preparedModel := suite.setupSampleUser()
+
+	testModel, err := suite.interactor.GrantRole(adminContext(), ports.GrantRoleUserItcParams{
+		UserId: preparedModel.Id,
+		Role:   users_dm.RoleEditor,
+	})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.ElementsMatch([]users_dm.Role{users_dm.RoleViewer, users_dm.RoleEditor}, testModel.Roles, "role should be granted")
+
+	testModel, _, err = suite.interactor.Login(context.Background(), ports.LoginUserItcParams{
+		Email:    preparedModel.Email,
+		Password: "test123",
+	})
+	suite.Nil(err, "should return empty error")
+	suite.ElementsMatch([]users_dm.Role{users_dm.RoleViewer, users_dm.RoleEditor}, testModel.Roles, "granted role should be persisted")
+
+	testModel, err = suite.interactor.RevokeRole(adminContext(), ports.RevokeRoleUserItcParams{
+		UserId: preparedModel.Id,
+		Role:   users_dm.RoleViewer,
+	})
+	suite.Nil(err, "should return empty error when provided params are correct")
+	suite.Equal([]users_dm.Role{users_dm.RoleEditor}, testModel.Roles, "role should be revoked")
+}
+
+/// ForgotPassword
+
+func (suite *InteractorSuite) TestForgotPasswordShouldIgnoreUnknownEmail() {
+
+	err := suite.interactor.ForgotPassword(context.Background(), ports.ForgotPasswordUserItcParams{Email: "unknown@test.com"})
+
+	suite.Nil(err, "should return empty error when email is unknown")
+	suite.Empty(suite.mailer.Messages, "should not send any message")
+}
+
+func (suite *InteractorSuite) TestForgotPasswordShouldSendToken() {
+
+	preparedModel := suite.setupSampleUser()
+
+	err := suite.interactor.ForgotPassword(context.Background(), ports.ForgotPasswordUserItcParams{Email: preparedModel.Email})
+
+	suite.Nil(err, "should return empty error")
+	suite.Len(suite.mailer.Messages, 2, "should send message next to verification message")
+	suite.Equal(preparedModel.Email, suite.mailer.Messages[1].To, "should send message to the user")
+	suite.NotEmpty(suite.mailedToken(), "message should contain token")
+}
+
+/// ResetPassword
+
+func (suite *InteractorSuite) TestResetPasswordShouldReturnErrorWhenTokenIsInvalid() {
+
+	preparedModel := suite.setupSampleUser()
+	suite.Nil(suite.interactor.ForgotPassword(context.Background(), ports.ForgotPasswordUserItcParams{Email: preparedModel.Email}))
+
+	id, _, _ := strings.Cut(suite.mailedToken(), ".")
+
+	params := []ports.ResetPasswordUserItcParams{
+		{Token: "fooBar", Password: "test456"},
+		{Token: id + ".fooBar", Password: "test456"},
+		{Token: uuid.NewString() + ".fooBar", Password: "test456"},
+	}
+
+	for _, param := range params {
+		testModel, err := suite.interactor.ResetPassword(context.Background(), param)
+
+		suite.Empty(testModel, "should return empty object when token is invalid")
+		suite.ErrorContains(err, "failed to authenticate")
+	}
+}
+
+func (suite *InteractorSuite) TestResetPasswordShouldUpdatePasswordOnce() {
+
+	preparedModel := suite.setupSampleUser()
+	suite.Nil(suite.interactor.ForgotPassword(context.Background(), ports.ForgotPasswordUserItcParams{Email: preparedModel.Email}))
+
+	params := ports.ResetPasswordUserItcParams{Token: suite.mailedToken(), Password: "test456"}
+
+	testModel, err := suite.interactor.ResetPassword(context.Background(), params)
+	suite.Nil(err, "should return empty error")
+	suite.Equal(preparedModel.Id, testModel.Id, "should return owner of the token")
+
+	_, _, err = suite.interactor.Login(context.Background(), ports.LoginUserItcParams{Email: preparedModel.Email, Password: "test123"})
+	suite.ErrorContains(err, "failed to authenticate", "old password should be rejected")
+
+	_, _, err = suite.interactor.Login(context.Background(), ports.LoginUserItcParams{Email: preparedModel.Email, Password: "test456"})
+	suite.Nil(err, "new password should be accepted")
+
+	_, err = suite.interactor.ResetPassword(context.Background(), params)
+	suite.ErrorContains(err, "failed to authenticate", "token should be single use")
+}
+
+func (suite *InteractorSuite) TestResetPasswordShouldRejectReplacedToken() {
+
+	preparedModel := suite.setupSampleUser()
+
+	suite.Nil(suite.interactor.ForgotPassword(context.Background(), ports.ForgotPasswordUserItcParams{Email: preparedModel.Email}))
+	replaced := suite.mailedToken()
+	suite.Nil(suite.interactor.ForgotPassword(context.Background(), ports.ForgotPasswordUserItcParams{Email: preparedModel.Email}))
+
+	_, err := suite.interactor.ResetPassword(context.Background(), ports.ResetPasswordUserItcParams{Token: replaced, Password: "test456"})
+	suite.ErrorContains(err, "failed to authenticate", "only the latest token should be valid")
+
+	_, err = suite.interactor.ResetPassword(context.Background(), ports.ResetPasswordUserItcParams{Token: suite.mailedToken(), Password: "test456"})
+	suite.Nil(err, "latest token should be accepted")
+}
+
+/// VerifyEmail
+
+func (suite *InteractorSuite) TestRegisterShouldSendVerificationToken() {
+
+	preparedModel := suite.setupSampleUser()
+
+	suite.False(preparedModel.Verified, "new account should not be verified")
+	suite.Len(suite.mailer.Messages, 1, "should send single message")
+	suite.Equal(preparedModel.Email, suite.mailer.Messages[0].To, "should send message to the user")
+	suite.Contains(suite.mailer.Messages[0].Body, "http://localhost:8080/api/users/verify?token=", "message should contain verification link")
+}
+
+func (suite *InteractorSuite) TestVerifyEmailShouldMarkUserAsVerifiedOnce() {
+
+	preparedModel := suite.setupSampleUser()
+	params := ports.VerifyEmailUserItcParams{Token: suite.mailedToken()}
+
+	testModel, err := suite.interactor.VerifyEmail(context.Background(), params)
+	suite.Nil(err, "should return empty error")
+	suite.Equal(preparedModel.Id, testModel.Id, "should return owner of the token")
+	suite.True(testModel.Verified, "user should be verified")
+
+	_, err = suite.interactor.VerifyEmail(context.Background(), params)
+	suite.ErrorContains(err, "failed to authenticate", "token should be single use")
+}
+
+func (suite *InteractorSuite) TestVerifyEmailShouldAcceptConcurrentTokenOnce() {
+
+	suite.setupSampleUser()
+	params := ports.VerifyEmailUserItcParams{Token: suite.mailedToken()}
+
+	const attempts = 8
+	errors := make(chan error, attempts)
+
+	var group sync.WaitGroup
+	for idx := 0; idx < attempts; idx++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			_, err := suite.interactor.VerifyEmail(context.Background(), params)
+			errors <- err
+		}()
+	}
+	group.Wait()
+	close(errors)
+
+	succeeded := 0
+	for err := range errors {
+		if err == nil {
+			succeeded++
+		} else {
+			suite.ErrorContains(err, "failed to authenticate", "every other request should be rejected")
+		}
+	}
+	suite.Equal(1, succeeded, "only one of concurrent requests should use the token")
+}
+
+func (suite *InteractorSuite) TestVerifyEmailShouldRejectPasswordResetToken() {
+
+	preparedModel := suite.setupSampleUser()
+	suite.Nil(suite.interactor.ForgotPassword(context.Background(), ports.ForgotPasswordUserItcParams{Email: preparedModel.Email}))
+
+	_, err := suite.interactor.VerifyEmail(context.Background(), ports.VerifyEmailUserItcParams{Token: suite.mailedToken()})
+	suite.ErrorContains(err, "failed to authenticate", "tokens should not be usable for different purpose")
+}
+
+func (suite *InteractorSuite) TestResendVerificationShouldIgnoreVerifiedUser() {
+
+	preparedModel := suite.setupSampleUser()
+
+	suite.Nil(suite.interactor.ResendVerification(context.Background(), ports.ResendVerificationUserItcParams{Email: preparedModel.Email}))
+	suite.Len(suite.mailer.Messages, 2, "should resend verification message")
+
+	_, err := suite.interactor.VerifyEmail(context.Background(), ports.VerifyEmailUserItcParams{Token: suite.mailedToken()})
+	suite.Nil(err, "latest token should be accepted")
+
+	suite.Nil(suite.interactor.ResendVerification(context.Background(), ports.ResendVerificationUserItcParams{Email: preparedModel.Email}))
+	suite.Len(suite.mailer.Messages, 2, "should not send message to verified user")
+}
+
+func (suite *InteractorSuite) TestLoginShouldReturnErrorWhenEmailIsNotVerified() {
+
+	preparedModel := suite.setupSampleUser()
+
+	interactor := NewInteractor(logging.NewDefaultLogger(), validation.NewDefaultValidator(), suite.usersRepo, suite.tokensRepo, suite.attemptsRepo, suite.favouritesRepo, suite.keysRepo, suite.mailer, nil, policies.NewRolePolicy(), Settings{
+		RequireVerified: true,
+	})
+	params := ports.LoginUserItcParams{Email: preparedModel.Email, Password: "test123"}
+
+	_, _, err := interactor.Login(context.Background(), params)
+	suite.ErrorContains(err, "permission denied", "unverified user should not be able to log in")
+
+	_, err = interactor.VerifyEmail(context.Background(), ports.VerifyEmailUserItcParams{Token: suite.mailedToken()})
+	suite.Nil(err, "should return empty error")
+
+	_, _, err = interactor.Login(context.Background(), params)
+	suite.Nil(err, "verified user should be able to log in")
+}
+
+/// Totp
+
+func (suite *InteractorSuite) TestConfirmTotpShouldEnableTwoFactorAuthentication() {
+
+	preparedModel := suite.setupSampleUser()
+
+	enrollment, err := suite.interactor.EnrollTotp(context.Background(), ports.EnrollTotpUserItcParams{UserId: preparedModel.Id})
+	suite.Nil(err, "should return empty error")
+	suite.NotEmpty(enrollment.Secret, "should return secret")
+	suite.Contains(enrollment.Uri, "otpauth://totp/assets:test@test.com", "should return key uri")
+
+	_, err = suite.interactor.ConfirmTotp(context.Background(), ports.ConfirmTotpUserItcParams{UserId: preparedModel.Id, Code: "000000"})
+	suite.ErrorContains(err, "invalid two-factor code")
+
+	code, _ := totp.Code(enrollment.Secret, time.Now())
+	recoveryCodes, err := suite.interactor.ConfirmTotp(context.Background(), ports.ConfirmTotpUserItcParams{UserId: preparedModel.Id, Code: code})
+	suite.Nil(err, "should return empty error")
+	suite.Len(recoveryCodes, 10, "should return recovery codes")
+
+	_, err = suite.interactor.EnrollTotp(context.Background(), ports.EnrollTotpUserItcParams{UserId: preparedModel.Id})
+	suite.ErrorContains(err, "already enabled")
+}
+
+func (suite *InteractorSuite) TestLoginShouldReturnChallengeWhenTotpIsEnabled() {
+
+	preparedModel, secret, _ := suite.setupTotpUser()
+
+	testModel, challenge, err := suite.interactor.Login(context.Background(), ports.LoginUserItcParams{Email: preparedModel.Email, Password: "test123"})
+	suite.Nil(err, "should return empty error")
+	suite.Empty(testModel, "should not return user before second factor is verified")
+	suite.NotEmpty(challenge.Token, "should return challenge")
+
+	code, _ := totp.Code(secret, time.Now().Add(totp.Period*time.Second))
+	params := ports.CompleteLoginUserItcParams{Challenge: challenge.Token, Code: code}
+
+	testModel, err = suite.interactor.CompleteLogin(context.Background(), params)
+	suite.Nil(err, "should return empty error")
+	suite.Equal(preparedModel.Id, testModel.Id, "should return user")
+
+	_, err = suite.interactor.CompleteLogin(context.Background(), params)
+	suite.ErrorContains(err, "failed to authenticate", "challenge should be single use")
+}
+
+func (suite *InteractorSuite) TestCompleteLoginShouldReturnErrorWhenCodeIsInvalid() {
+
+	preparedModel, _, _ := suite.setupTotpUser()
+
+	_, challenge, err := suite.interactor.Login(context.Background(), ports.LoginUserItcParams{Email: preparedModel.Email, Password: "test123"})
+	suite.Nil(err, "should return empty error")
+
+	testModel, err := suite.interactor.CompleteLogin(context.Background(), ports.CompleteLoginUserItcParams{Challenge: challenge.Token, Code: "000000"})
+	suite.Empty(testModel, "should return empty object when code is invalid")
+	suite.ErrorContains(err, "invalid two-factor code")
+}
+
+func (suite *InteractorSuite) TestCompleteLoginShouldAcceptRecoveryCodeOnce() {
+
+	preparedModel, _, recoveryCodes := suite.setupTotpUser()
+
+	for _, expectation := range []bool{true, false} {
+		_, challenge, err := suite.interactor.Login(context.Background(), ports.LoginUserItcParams{Email: preparedModel.Email, Password: "test123"})
+		suite.Nil(err, "should return empty error")
+
+		_, err = suite.interactor.CompleteLogin(context.Background(), ports.CompleteLoginUserItcParams{Challenge: challenge.Token, Code: strings.ToLower(recoveryCodes[0])})
+		suite.Equal(expectation, err == nil, "recovery code should be accepted only once")
+	}
+}
+
+func (suite *InteractorSuite) TestCompleteLoginShouldRejectReplayedCode() {
+
+	preparedModel, secret, _ := suite.setupTotpUser()
+
+	users, _, _ := suite.usersRepo.Select(context.Background(), ports.SelectUsersRepoParams{Ids: []string{preparedModel.Id}})
+	confirmedAt := time.Unix(users[0].TotpLastStep*totp.Period, 0)
+
+	confirmed, _ := totp.Code(secret, confirmedAt)
+	next, _ := totp.Code(secret, confirmedAt.Add(totp.Period*time.Second))
+
+	for _, testCase := range []struct {
+		code     string
+		accepted bool
+	}{
+		{code: confirmed, accepted: false},
+		{code: next, accepted: true},
+		{code: next, accepted: false},
+		{code: confirmed, accepted: false},
+	} {
+		_, challenge, err := suite.interactor.Login(context.Background(), ports.LoginUserItcParams{Email: preparedModel.Email, Password: "test123"})
+		suite.Nil(err, "should return empty error")
+
+		_, err = suite.interactor.CompleteLogin(context.Background(), ports.CompleteLoginUserItcParams{Challenge: challenge.Token, Code: testCase.code})
+		suite.Equal(testCase.accepted, err == nil, "code of the same or earlier period should not be accepted again")
+	}
+}
+
+func (suite *InteractorSuite) TestDisableTotpShouldRequireValidCode() {
+
+	preparedModel, secret, _ := suite.setupTotpUser()
+
+	_, err := suite.interactor.DisableTotp(context.Background(), ports.DisableTotpUserItcParams{UserId: preparedModel.Id, Code: "000000"})
+	suite.ErrorContains(err, "invalid two-factor code")
+
+	code, _ := totp.Code(secret, time.Now().Add(totp.Period*time.Second))
+	testModel, err := suite.interactor.DisableTotp(context.Background(), ports.DisableTotpUserItcParams{UserId: preparedModel.Id, Code: code})
+	suite.Nil(err, "should return empty error")
+	suite.False(testModel.TotpEnabled, "two-factor authentication should be disabled")
+
+	testModel, _, err = suite.interactor.Login(context.Background(), ports.LoginUserItcParams{Email: preparedModel.Email, Password: "test123"})
+	suite.Nil(err, "should return empty error")
+	suite.Equal(preparedModel.Id, testModel.Id, "should log in without challenge")
+}
+
+/// Profile
+
+func (suite *InteractorSuite) TestGetShouldReturnUserObject() {
+
+	preparedModel := suite.setupSampleUser()
+
+	testModel, err := suite.interactor.Get(context.Background(), ports.GetUserItcParams{UserId: preparedModel.Id})
+	suite.Nil(err, "should return empty error")
+	suite.Equal(preparedModel, testModel, "objects should match")
+
+	_, err = suite.interactor.Get(context.Background(), ports.GetUserItcParams{UserId: uuid.NewString()})
+	suite.ErrorContains(err, "cannot be found")
+}
+
+func (suite *InteractorSuite) TestUpdateShouldRequireCurrentPassword() {
+
+	preparedModel := suite.setupSampleUser()
+
+	testModel, err := suite.interactor.Update(context.Background(), ports.UpdateUserItcParams{
+		UserId:          preparedModel.Id,
+		Password:        "newSecret",
+		CurrentPassword: "wrong",
+	})
+	suite.Empty(testModel, "should return empty object when current password is incorrect")
+	suite.ErrorContains(err, "current password is incorrect")
+}
+
+func (suite *InteractorSuite) TestUpdateShouldChangePassword() {
+
+	preparedModel := suite.setupSampleUser()
+
+	_, err := suite.interactor.Update(context.Background(), ports.UpdateUserItcParams{
+		UserId:          preparedModel.Id,
+		Password:        "newSecret",
+		CurrentPassword: "test123",
+	})
+	suite.Nil(err, "should return empty error")
+
+	_, _, err = suite.interactor.Login(context.Background(), ports.LoginUserItcParams{Email: preparedModel.Email, Password: "test123"})
+	suite.ErrorContains(err, "failed to authenticate", "old password should not be accepted")
+
+	testModel, _, err := suite.interactor.Login(context.Background(), ports.LoginUserItcParams{Email: preparedModel.Email, Password: "newSecret"})
+	suite.Nil(err, "should return empty error")
+	suite.Equal(preparedModel.Id, testModel.Id, "should log in with new password")
+}
+
+func (suite *InteractorSuite) TestUpdateShouldChangeEmailAndRequireVerification() {
+
+	preparedModel := suite.setupSampleUser()
+
+	_, err := suite.interactor.VerifyEmail(context.Background(), ports.VerifyEmailUserItcParams{Token: suite.mailedToken()})
+	suite.Nil(err, "should return empty error")
+
+	testModel, err := suite.interactor.Update(context.Background(), ports.UpdateUserItcParams{
+		UserId:          preparedModel.Id,
+		Email:           "changed@test.com",
+		CurrentPassword: "test123",
+	})
+	suite.Nil(err, "should return empty error")
+	suite.Equal("changed@test.com", testModel.Email, "email should be changed")
+	suite.False(testModel.Verified, "changed email should not be verified")
+	suite.Equal("changed@test.com", suite.mailer.Messages[len(suite.mailer.Messages)-1].To, "should send verification to new email")
+}
+
+func (suite *InteractorSuite) TestUpdateShouldReturnErrorWhenEmailIsTaken() {
+
+	preparedModel := suite.setupSampleUser()
+
+	_, err := suite.interactor.Register(context.Background(), ports.RegisterUserItcParams{Email: "other@test.com", Password: "test123"})
+	suite.Nil(err, "should return empty error")
+
+	_, err = suite.interactor.Update(context.Background(), ports.UpdateUserItcParams{
+		UserId:          preparedModel.Id,
+		Email:           "other@test.com",
+		CurrentPassword: "test123",
+	})
+	suite.ErrorContains(err, "already taken")
+}
+
+func (suite *InteractorSuite) TestDeleteShouldRemoveUserAndDependentRecords() {
+
+	preparedModel := suite.setupSampleUser()
+
+	favourite := favourites_dm.NewFavouriteEntity()
+	favourite.UserId = preparedModel.Id
+	favourite.AssetId = uuid.NewString()
+
+	key := keys_dm.NewApiKeyEntity()
+	key.UserId = preparedModel.Id
+
+	_, _ = suite.favouritesRepo.Insert(context.Background(), favourite)
+	_, _ = suite.keysRepo.Insert(context.Background(), key)
+
+	_, err := suite.interactor.Delete(context.Background(), ports.DeleteUserItcParams{UserId: preparedModel.Id, Password: "wrong"})
+	suite.ErrorContains(err, "password is incorrect")
+
+	testModel, err := suite.interactor.Delete(context.Background(), ports.DeleteUserItcParams{UserId: preparedModel.Id, Password: "test123"})
+	suite.Nil(err, "should return empty error")
+	suite.Equal(preparedModel.Id, testModel.Id, "should return deleted user")
+
+	users, _, _ := suite.usersRepo.Select(context.Background(), ports.SelectUsersRepoParams{Ids: []string{preparedModel.Id}})
+	suite.Empty(users, "user should be removed")
+
+	favourites, _, _ := suite.favouritesRepo.Select(context.Background(), ports.SelectFavouritesRepoParams{UserIds: []string{preparedModel.Id}})
+	suite.Empty(favourites, "favourites of the user should be removed")
+
+	keys, _, _ := suite.keysRepo.Select(context.Background(), ports.SelectApiKeysRepoParams{UserIds: []string{preparedModel.Id}})
+	suite.Empty(keys, "api keys of the user should be removed")
+
+	tokens, _, _ := suite.tokensRepo.Select(context.Background(), ports.SelectOneTimeTokensRepoParams{UserIds: []string{preparedModel.Id}})
+	suite.Empty(tokens, "pending tokens of the user should be removed")
+}
+
+/// Directory
+
+func (suite *InteractorSuite) TestSelectShouldReturnErrorWhenCallerIsNotAdmin() {
+
+	results, _, err := suite.interactor.Select(context.Background(), ports.SelectUsersItcParams{})
+	suite.Empty(results, "should return empty list")
+	suite.ErrorContains(err, "missing caller identity")
+}
+
+func (suite *InteractorSuite) TestSelectShouldFilterByEmailPrefixWithCursor() {
+
+	for _, email := range []string{"bob@test.com", "Alice@test.com", "alan@test.com", "albert@test.com"} {
+		_, err := suite.interactor.Register(context.Background(), ports.RegisterUserItcParams{Email: email, Password: "test123"})
+		suite.Nil(err, "should return empty error")
+	}
+
+	var emails []string
+	params := ports.SelectUsersItcParams{EmailPrefix: "al", Limit: 2}
+
+	for page := 0; page < 3; page++ {
+		results, cursor, err := suite.interactor.Select(adminContext(), params)
+		suite.Nil(err, "should return empty error")
+
+		for _, result := range results {
+			emails = append(emails, result.Email)
+		}
+
+		if params.Cursor = cursor; cursor == "" {
+			break
+		}
+	}
+
+	suite.Equal([]string{"alan@test.com", "albert@test.com", "Alice@test.com"}, emails, "should return matching users ordered by email")
+}
+
+func (suite *InteractorSuite) TestDisableShouldBlockLoginUntilEnabled() {
+
+	preparedModel := suite.setupSampleUser()
+	params := ports.LoginUserItcParams{Email: preparedModel.Email, Password: "test123"}
+
+	testModel, err := suite.interactor.Disable(adminContext(), ports.DisableUserItcParams{UserId: preparedModel.Id})
+	suite.Nil(err, "should return empty error")
+	suite.True(testModel.Disabled, "user should be disabled")
+
+	_, _, err = suite.interactor.Login(context.Background(), params)
+	suite.ErrorContains(err, "account is disabled")
+
+	testModel, err = suite.interactor.Enable(adminContext(), ports.EnableUserItcParams{UserId: preparedModel.Id})
+	suite.Nil(err, "should return empty error")
+	suite.False(testModel.Disabled, "user should be enabled")
+
+	_, _, err = suite.interactor.Login(context.Background(), params)
+	suite.Nil(err, "enabled user should be able to log in")
+}
+
+func (suite *InteractorSuite) TestDisableShouldReturnErrorForOwnAccount() {
+
+	preparedModel := suite.setupSampleUser()
+
+	ctx := identity.WithIdentity(context.Background(), identity.Identity{
+		UserId: preparedModel.Id,
+		Roles:  []string{users_dm.RoleAdmin},
+	})
+
+	_, err := suite.interactor.Disable(ctx, ports.DisableUserItcParams{UserId: preparedModel.Id})
+	suite.ErrorContains(err, "own account cannot be disabled")
+}
+
+/// Oidc
+
+func (suite *InteractorSuite) TestStartOidcLoginShouldReturnErrorWhenProviderIsNotConfigured() {
+
+	interactor := NewInteractor(logging.NewDefaultLogger(), validation.NewDefaultValidator(), suite.usersRepo, suite.tokensRepo, suite.attemptsRepo, suite.favouritesRepo, suite.keysRepo, suite.mailer, nil, policies.NewRolePolicy(), Settings{})
+
+	_, err := interactor.StartOidcLogin(context.Background())
+	suite.ErrorContains(err, "not configured")
+}
+
+func (suite *InteractorSuite) TestCompleteOidcLoginShouldProvisionUser() {
+
+	external := users_dm.ExternalIdentity{Subject: "42", Email: "admin@test.com", EmailVerified: true}
+
+	testModel, _, err := suite.interactor.CompleteOidcLogin(context.Background(), suite.authorizeOidc(external))
+	suite.Nil(err, "should return empty error")
+	suite.AssertValidUuid(testModel.Id)
+	suite.Equal(external.Email, testModel.Email, "should provision user with email of the identity")
+	suite.True(testModel.Verified, "email verified by provider should be marked as verified")
+	suite.ElementsMatch([]string{users_dm.RoleViewer, users_dm.RoleAdmin}, testModel.Roles, "should assign default roles")
+
+	linkedModel, _, err := suite.interactor.CompleteOidcLogin(context.Background(), suite.authorizeOidc(external))
+	suite.Nil(err, "should return empty error")
+	suite.Equal(testModel.Id, linkedModel.Id, "should return provisioned user on the next login")
+}
+
+func (suite *InteractorSuite) TestCompleteOidcLoginShouldLinkExistingUser() {
+
+	preparedModel := suite.setupSampleUser()
+	_, err := suite.interactor.VerifyEmail(context.Background(), ports.VerifyEmailUserItcParams{Token: suite.mailedToken()})
+	suite.Nil(err)
+
+	testModel, challenge, err := suite.interactor.CompleteOidcLogin(context.Background(), suite.authorizeOidc(users_dm.ExternalIdentity{
+		Subject:       "42",
+		Email:         preparedModel.Email,
+		EmailVerified: true,
+	}))
+	suite.Nil(err, "should return empty error")
+	suite.Empty(challenge, "should not return challenge when two-factor authentication is disabled")
+	suite.Equal(preparedModel.Id, testModel.Id, "should link user by email")
+}
+
+func (suite *InteractorSuite) TestCompleteOidcLoginShouldRefuseUnverifiedUser() {
+
+	preparedModel := suite.setupSampleUser()
+
+	testModel, _, err := suite.interactor.CompleteOidcLogin(context.Background(), suite.authorizeOidc(users_dm.ExternalIdentity{
+		Subject:       "42",
+		Email:         preparedModel.Email,
+		EmailVerified: true,
+	}))
+	suite.Empty(testModel, "should return empty object when local account is not verified")
+	suite.ErrorIs(err, errs.PermissionError)
+
+	users, _, _ := suite.usersRepo.Select(context.Background(), ports.SelectUsersRepoParams{Ids: []string{preparedModel.Id}})
+	suite.False(users[0].Verified, "should not mark the account as verified")
+}
+
+func (suite *InteractorSuite) TestCompleteOidcLoginShouldRequireSecondFactor() {
+
+	preparedModel, secret, _ := suite.setupTotpUser()
+	_, err := suite.interactor.VerifyEmail(context.Background(), ports.VerifyEmailUserItcParams{Token: suite.mailedToken()})
+	suite.Nil(err)
+
+	testModel, challenge, err := suite.interactor.CompleteOidcLogin(context.Background(), suite.authorizeOidc(users_dm.ExternalIdentity{
+		Subject:       "42",
+		Email:         preparedModel.Email,
+		EmailVerified: true,
+	}))
+	suite.Nil(err, "should return empty error")
+	suite.Empty(testModel, "should not return user before the second factor is verified")
+	suite.NotEmpty(challenge.Token, "should return login challenge")
+
+	code, _ := totp.Code(secret, time.Now().Add(totp.Period*time.Second))
+	completedModel, err := suite.interactor.CompleteLogin(context.Background(), ports.CompleteLoginUserItcParams{Challenge: challenge.Token, Code: code})
+	suite.Nil(err, "should complete login with the second factor")
+	suite.Equal(preparedModel.Id, completedModel.Id)
+}
+
+func (suite *InteractorSuite) TestCompleteOidcLoginShouldRejectUnverifiedEmail() {
+
+	preparedModel := suite.setupSampleUser()
+
+	testModel, _, err := suite.interactor.CompleteOidcLogin(context.Background(), suite.authorizeOidc(users_dm.ExternalIdentity{
+		Subject: "42",
+		Email:   preparedModel.Email,
+	}))
+	suite.Empty(testModel, "should return empty object when email is not verified")
+	suite.ErrorContains(err, "has not been verified by identity provider")
+}
+
+func (suite *InteractorSuite) TestCompleteOidcLoginShouldAcceptStateOnce() {
+
+	params := suite.authorizeOidc(users_dm.ExternalIdentity{Subject: "42", Email: "test@test.com", EmailVerified: true})
+
+	_, _, err := suite.interactor.CompleteOidcLogin(context.Background(), params)
+	suite.Nil(err, "should return empty error")
+
+	_, _, err = suite.interactor.CompleteOidcLogin(context.Background(), params)
+	suite.ErrorContains(err, "token is invalid", "state should be single use")
+}
+
+func (suite *InteractorSuite) TestCompleteOidcLoginShouldRejectCodeOfAnotherLogin() {
+
+	params := suite.authorizeOidc(users_dm.ExternalIdentity{Subject: "42", Email: "test@test.com", EmailVerified: true})
+
+	other, err := suite.interactor.StartOidcLogin(context.Background())
+	suite.Nil(err, "should return empty error")
+
+	// code was issued for challenge of the first login, so verifier bound to the other state doesn't match
+	testModel, _, err := suite.interactor.CompleteOidcLogin(context.Background(), ports.CompleteOidcLoginUserItcParams{Code: params.Code, State: other.State})
+	suite.Empty(testModel, "should return empty object when verifier doesn't match")
+	suite.ErrorContains(err, "invalid_grant")
+}
+
 /*
 * SUITE SETUP
  */
@@ -120,13 +901,44 @@ func (suite *InteractorSuite) TestRegisterShouldReturnObject() {
 func (suite *InteractorSuite) SetupInteractor() {
 	logger := logging.NewDefaultLogger()
 	validator := validation.NewDefaultValidator()
-	usersRepo := users_db.NewMemoryRepo()
+	suite.usersRepo = users_db.NewMemoryRepo()
+	suite.tokensRepo = onetimetokens_db.NewMemoryRepo()
+	suite.attemptsRepo = attempts_db.NewMemoryRepo()
+	suite.favouritesRepo = favourites_db.NewMemoryRepo()
+	suite.keysRepo = keys_db.NewMemoryRepo()
+	suite.mailer = mailers.NewMemoryMailer()
+
+	policy := policies.NewRolePolicy()
+	provider := providers.NewOidcProvider(logger, suite.issuer.Server.Client(), providers.OidcConfig{
+		Issuer:       suite.issuer.Url(),
+		ClientId:     suite.issuer.ClientId,
+		ClientSecret: suite.issuer.ClientSecret,
+		RedirectUrl:  "http://localhost:8080/api/users/oidc/callback",
+	})
 
-	suite.interactor = NewInteractor(logger, validator, usersRepo)
+	suite.interactor = NewInteractor(logger, validator, suite.usersRepo, suite.tokensRepo, suite.attemptsRepo, suite.favouritesRepo, suite.keysRepo, suite.mailer, provider, policy, Settings{
+		Admins:                []string{"admin@test.com"},
+		ResetLifetime:         time.Hour,
+		VerificationLifetime:  time.Hour,
+		VerificationUrl:       "http://localhost:8080/api/users/verify",
+		LockoutEmailThreshold: 3,
+		LockoutIpThreshold:    5,
+		LockoutWindow:         15 * time.Minute,
+		LockoutDuration:       15 * time.Minute,
+		TotpIssuer:            "assets",
+		ChallengeLifetime:     5 * time.Minute,
+		OidcStateLifetime:     10 * time.Minute,
+		OidcAutoProvision:     true,
+	})
 }
 
 func (suite *InteractorSuite) SetupSuite() {
 	println("SetupSuite")
+	suite.issuer = providers.NewStubIssuer("assets", "topSecret")
+}
+
+func (suite *InteractorSuite) TearDownSuite() {
+	suite.issuer.Close()
 }
 
 func (suite *InteractorSuite) SetupTest() {
@@ -147,12 +959,61 @@ func (suite *InteractorSuite) setupSampleUser() (model users_dm.UserEntity) {
 	return model
 }
 
+func (suite *InteractorSuite) setupTotpUser() (model users_dm.UserEntity, secret string, recoveryCodes []string) {
+
+	model = suite.setupSampleUser()
+
+	enrollment, err := suite.interactor.EnrollTotp(context.Background(), ports.EnrollTotpUserItcParams{UserId: model.Id})
+	if err != nil {
+		panic(err)
+	}
+
+	code, _ := totp.Code(enrollment.Secret, time.Now())
+	if recoveryCodes, err = suite.interactor.ConfirmTotp(context.Background(), ports.ConfirmTotpUserItcParams{UserId: model.Id, Code: code}); err != nil {
+		panic(err)
+	}
+
+	return model, enrollment.Secret, recoveryCodes
+}
+
+// authorizeOidc starts login and signs the identity in at the stub issuer, returned params complete the login.
+func (suite *InteractorSuite) authorizeOidc(external users_dm.ExternalIdentity) ports.CompleteOidcLoginUserItcParams {
+
+	authorization, err := suite.interactor.StartOidcLogin(context.Background())
+	if err != nil {
+		panic(err)
+	}
+
+	code, state, err := suite.issuer.Authorize(authorization.Url, external)
+	if err != nil {
+		panic(err)
+	}
+
+	return ports.CompleteOidcLoginUserItcParams{Code: code, State: state}
+}
+
+// mailedToken extracts token from the last message sent by the interactor.
+func (suite *InteractorSuite) mailedToken() string {
+	if len(suite.mailer.Messages) == 0 {
+		return ""
+	}
+
+	return tokenPattern.FindString(suite.mailer.Messages[len(suite.mailer.Messages)-1].Body)
+}
+
 func (suite *InteractorSuite) AssertValidUuid(id string) {
 	parsed, err := uuid.Parse(id)
 	suite.NotEmpty(parsed)
 	suite.Nil(err)
 }
 
+func adminContext() context.Context {
+	return identity.WithIdentity(context.Background(), identity.Identity{
+		UserId: uuid.NewString(),
+		Roles:  []string{users_dm.RoleAdmin},
+	})
+}
+
 func randomString(length int) string {
 	rand.NewSource(time.Now().UnixNano())
 	b := make([]byte, length+2)
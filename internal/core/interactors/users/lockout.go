@@ -0,0 +1,145 @@
+package users_itc
+
+import (
+	attempts_dm "assets/internal/core/domain/attempts"
+	"assets/internal/core/ports"
+	errs "assets/pkg/errors"
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// attemptThresholds returns keys under which failed logins are tracked together with amount of failures which
+// locks the key. Keys with zero threshold are not tracked at all.
+func (i *Interactor) attemptThresholds(email string, clientIp string) map[string]int {
+
+	thresholds := make(map[string]int)
+
+	if i.settings.LockoutEmailThreshold > 0 {
+		thresholds["email:"+strings.ToLower(email)] = i.settings.LockoutEmailThreshold
+	}
+
+	if i.settings.LockoutIpThreshold > 0 && clientIp != "" {
+		thresholds["ip:"+clientIp] = i.settings.LockoutIpThreshold
+	}
+
+	return thresholds
+}
+
+// checkLockout rejects login when any of tracked keys is locked, otherwise it returns current attempts.
+func (i *Interactor) checkLockout(ctx context.Context, thresholds map[string]int) (results []attempts_dm.LoginAttemptEntity, err error) {
+
+	if len(thresholds) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(thresholds))
+	for id := range thresholds {
+		ids = append(ids, id)
+	}
+
+	if results, err = i.attemptsRepo.Select(ctx, ports.SelectLoginAttemptsRepoParams{Ids: ids}); err != nil {
+		return nil, errors.Join(errs.ProcessingError, err)
+	}
+
+	now := time.Now()
+
+	var retryAfter time.Duration
+	for _, attempt := range results {
+		if attempt.Locked(now) && attempt.LockExpireTime.Sub(now) > retryAfter {
+			retryAfter = attempt.LockExpireTime.Sub(now)
+		}
+	}
+
+	if retryAfter > 0 {
+		return nil, errors.Join(errs.TooManyRequestsError, errs.RetryAfterError{RetryAfter: retryAfter})
+	}
+
+	return results, nil
+}
+
+// failureRetries bounds how many times counting of a failure is retried when concurrent logins change the same key.
+const failureRetries = 10
+
+// registerFailure counts failed login for every tracked key, keys which reach their threshold get locked. Counts are
+// compared and set, so failures of concurrent logins are never lost, each of them is retried on fresh attempt instead.
+func (i *Interactor) registerFailure(ctx context.Context, attempts []attempts_dm.LoginAttemptEntity, thresholds map[string]int) (err error) {
+
+	existing := make(map[string]attempts_dm.LoginAttemptEntity)
+	for _, attempt := range attempts {
+		existing[attempt.Id] = attempt
+	}
+
+	for id, threshold := range thresholds {
+		attempt, found := existing[id]
+
+		for retry := 0; ; retry++ {
+			var applied bool
+			if applied, err = i.countFailure(ctx, id, attempt, found, threshold); err != nil {
+				return errors.Join(errs.ProcessingError, err)
+			} else if applied {
+				break
+			}
+
+			if retry == failureRetries {
+				return errors.Join(errs.ProcessingError, errors.New("failed to count login failure"))
+			}
+
+			var results []attempts_dm.LoginAttemptEntity
+			if results, err = i.attemptsRepo.Select(ctx, ports.SelectLoginAttemptsRepoParams{Ids: []string{id}}); err != nil {
+				return errors.Join(errs.ProcessingError, err)
+			}
+
+			if found = len(results) > 0; found {
+				attempt = results[0]
+			}
+		}
+	}
+
+	return nil
+}
+
+// countFailure adds failure to the attempt read before, false is returned when the stored attempt has changed since.
+func (i *Interactor) countFailure(ctx context.Context, id string, attempt attempts_dm.LoginAttemptEntity, found bool, threshold int) (applied bool, err error) {
+
+	if !found {
+		attempt = attempts_dm.NewLoginAttemptEntity(id)
+	}
+
+	now := time.Now()
+	previous := attempt.Failures
+
+	attempt.Failures++
+	attempt.ExpireTime = now.Add(i.settings.LockoutWindow)
+
+	if attempt.Failures >= threshold {
+		i.logger.Info("users_itc.Login() locked login attempts",
+			"id", id,
+			"failures", attempt.Failures,
+		)
+
+		attempt.Failures = 0
+		attempt.LockExpireTime = now.Add(i.settings.LockoutDuration)
+
+		if attempt.LockExpireTime.After(attempt.ExpireTime) {
+			attempt.ExpireTime = attempt.LockExpireTime
+		}
+	}
+
+	if !found {
+		return i.attemptsRepo.InsertIfNotExists(ctx, attempt)
+	}
+
+	return i.attemptsRepo.UpdateIfFailures(ctx, attempt, previous)
+}
+
+// resetFailures forgets failed logins once the user has authenticated successfully.
+func (i *Interactor) resetFailures(ctx context.Context, attempts []attempts_dm.LoginAttemptEntity) (err error) {
+
+	if _, err = i.attemptsRepo.Delete(ctx, attempts...); err != nil {
+		return errors.Join(errs.ProcessingError, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package users_itc
+
+import (
+	tokens_dm "assets/internal/core/domain/tokens"
+	users_dm "assets/internal/core/domain/users"
+	"assets/internal/core/ports"
+	"assets/pkg/secrets"
+	"assets/pkg/slices"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// prepareOneTimeToken creates token for given purpose, only hash of the secret is stored.
+func prepareOneTimeToken(userId string, purpose tokens_dm.Purpose, lifetime time.Duration) (result tokens_dm.OneTimeTokenEntity, err error) {
+
+	secret, err := secrets.Generate()
+	if err != nil {
+		return result, err
+	}
+
+	result = tokens_dm.NewOneTimeTokenEntity()
+	result.UserId = userId
+	result.Purpose = purpose
+	result.Hash = secrets.Hash(secret)
+	result.ExpireTime = result.CreateTime.Add(lifetime)
+	result.Token = secrets.Join(result.Id, secret)
+
+	return result, nil
+}
+
+func prepareUser(email string, hashedPassword string) (result users_dm.UserEntity) {
+
+	result = users_dm.NewUserEntity()
+	result.Email = email
+	result.Password = hashedPassword
+	result.Roles = []users_dm.Role{users_dm.RoleViewer}
+
+	return result
+}
+
+// prepareAdmin grants admin role to the user with one of configured admin emails. It has to be called only once the
+// email is verified, otherwise anyone who registers the address first would become an admin.
+func prepareAdmin(user users_dm.UserEntity, admins []string) (result users_dm.UserEntity) {
+
+	result = user
+	if slices.Contains(admins, user.Email) && !slices.Contains(user.Roles, users_dm.RoleAdmin) {
+		result.Roles = append(append([]users_dm.Role{}, user.Roles...), users_dm.RoleAdmin)
+	}
+
+	return result
+}
+
+const (
+	oidcVerifierKey = "verifier"
+	oidcNonceKey    = "nonce"
+)
+
+// prepareOidcState creates state token of the OpenID Connect login, it's not bound to any user yet.
+func prepareOidcState(lifetime time.Duration) (result tokens_dm.OneTimeTokenEntity, err error) {
+
+	if result, err = prepareOneTimeToken("", tokens_dm.PurposeOidcLogin, lifetime); err != nil {
+		return result, err
+	}
+
+	var verifier, nonce string
+	if verifier, err = secrets.Generate(); err != nil {
+		return tokens_dm.OneTimeTokenEntity{}, err
+	}
+
+	if nonce, err = secrets.Generate(); err != nil {
+		return tokens_dm.OneTimeTokenEntity{}, err
+	}
+
+	result.Payload = map[string]string{oidcVerifierKey: verifier, oidcNonceKey: nonce}
+
+	return result, nil
+}
+
+// prepareCodeChallenge derives PKCE challenge using S256 method.
+func prepareCodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func prepareResetPasswordMail(user users_dm.UserEntity, token tokens_dm.OneTimeTokenEntity, lifetime time.Duration) ports.SendMailParams {
+	return ports.SendMailParams{
+		To:      user.Email,
+		Subject: "Password reset",
+		Body: fmt.Sprintf("Password reset was requested for your account. Use following token to set new password, "+
+			"it is valid for %s and can be used only once:\n\n%s\n\nIf you didn't request password reset, ignore this message.",
+			lifetime, token.Token),
+	}
+}
+
+func prepareVerificationMail(user users_dm.UserEntity, token tokens_dm.OneTimeTokenEntity, verificationUrl string, lifetime time.Duration) ports.SendMailParams {
+	return ports.SendMailParams{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Thank you for registering. Open following link to verify your email address, "+
+			"it is valid for %s:\n\n%s?token=%s\n\nIf you didn't create an account, ignore this message.",
+			lifetime, verificationUrl, url.QueryEscape(token.Token)),
+	}
+}
+
+const recoveryCodesCount = 10
+
+// prepareRecoveryCodes returns recovery codes in form of 'XXXXX-XXXXX' together with their hashes.
+func prepareRecoveryCodes() (codes []string, hashes []string, err error) {
+
+	for idx := 0; idx < recoveryCodesCount; idx++ {
+		random := make([]byte, 10)
+		if _, err = rand.Read(random); err != nil {
+			return nil, nil, err
+		}
+
+		encoded := base32.StdEncoding.EncodeToString(random)[:10]
+
+		codes = append(codes, encoded[:5]+"-"+encoded[5:])
+		hashes = append(hashes, secrets.Hash(encoded))
+	}
+
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode makes recovery codes insensitive to letter case and separators.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
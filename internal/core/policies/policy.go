@@ -0,0 +1,51 @@
+package policies
+
+import (
+	users_dm "assets/internal/core/domain/users"
+	"assets/internal/core/ports"
+	errs "assets/pkg/errors"
+	"assets/pkg/identity"
+	"assets/pkg/slices"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type RolePolicy struct {
+	rules map[ports.Action][]users_dm.Role
+}
+
+func NewRolePolicy() *RolePolicy {
+	return &RolePolicy{
+		rules: map[ports.Action][]users_dm.Role{
+			ports.ActionInsertAssets: {users_dm.RoleEditor, users_dm.RoleAdmin},
+			ports.ActionUpdateAssets: {users_dm.RoleEditor, users_dm.RoleAdmin},
+			ports.ActionDeleteAssets: {users_dm.RoleEditor, users_dm.RoleAdmin},
+			ports.ActionManageRoles:  {users_dm.RoleAdmin},
+			ports.ActionManageUsers:  {users_dm.RoleAdmin},
+			ports.ActionReadAudit:    {users_dm.RoleAdmin},
+		},
+	}
+}
+
+// Authorize checks whether caller stored in the context holds any of the roles required by the action.
+// Actions without defined rule are denied.
+func (p *RolePolicy) Authorize(ctx context.Context, action ports.Action) error {
+
+	caller, ok := identity.FromContext(ctx)
+	if !ok || caller.UserId == "" {
+		return errors.Join(errs.AuthenticationError, errors.New("missing caller identity"))
+	}
+
+	allowed, ok := p.rules[action]
+	if !ok {
+		return errors.Join(errs.PermissionError, fmt.Errorf("action %s is not permitted", action))
+	}
+
+	if slices.HasCommon(allowed, caller.Roles) {
+		return nil
+	}
+
+	return errors.Join(errs.PermissionError, fmt.Errorf("action %s requires one of roles: %s", action, strings.Join(allowed, ", ")))
+}
@@ -2,9 +2,17 @@ package ports
 
 import (
 	assets_dm "assets/internal/core/domain/assets"
+	audit_dm "assets/internal/core/domain/audit"
+	collections_dm "assets/internal/core/domain/collections"
 	favourites_dm "assets/internal/core/domain/favourites"
+	grants_dm "assets/internal/core/domain/grants"
+	keys_dm "assets/internal/core/domain/keys"
+	tokens_dm "assets/internal/core/domain/tokens"
 	users_dm "assets/internal/core/domain/users"
+	versions_dm "assets/internal/core/domain/versions"
+	"assets/pkg/diff"
 	"context"
+	"time"
 )
 
 /*
@@ -16,6 +24,7 @@ import (
 type LoginUserItcParams struct {
 	Email    string `validate:"required,email,max=64" json:"email"`
 	Password string `validate:"required,max=64" json:"password"`
+	ClientIp string `validate:"omitempty,ip" json:"-"`
 }
 
 type RegisterUserItcParams struct {
@@ -23,11 +32,180 @@ type RegisterUserItcParams struct {
 	Password string `validate:"required,max=64" json:"password"`
 }
 
+type GetUserItcParams struct {
+	UserId string `validate:"required,uuid" json:"user_id"`
+}
+
+// UpdateUserItcParams changes credentials of the user, empty fields are left untouched.
+type UpdateUserItcParams struct {
+	UserId          string `validate:"required,uuid" json:"user_id"`
+	Email           string `validate:"omitempty,email,max=64" json:"email"`
+	Password        string `validate:"omitempty,max=64" json:"password"`
+	CurrentPassword string `validate:"required,max=64" json:"current_password"`
+}
+
+type DeleteUserItcParams struct {
+	UserId   string `validate:"required,uuid" json:"user_id"`
+	Password string `validate:"required,max=64" json:"password"`
+}
+
+type SelectUsersItcParams struct {
+	EmailPrefix string `validate:"omitempty,max=64" json:"email_prefix" query:"email"`
+	Cursor      string `json:"cursor" query:"cursor"`
+	Limit       int    `validate:"gte=0,lte=100" json:"limit" query:"limit"`
+}
+
+type DisableUserItcParams struct {
+	UserId string `validate:"required,uuid" json:"user_id"`
+}
+
+type EnableUserItcParams struct {
+	UserId string `validate:"required,uuid" json:"user_id"`
+}
+
+type GrantRoleUserItcParams struct {
+	UserId string        `validate:"required,uuid" json:"user_id"`
+	Role   users_dm.Role `validate:"required,oneof=VIEWER EDITOR ADMIN" json:"role"`
+}
+
+type RevokeRoleUserItcParams struct {
+	UserId string        `validate:"required,uuid" json:"user_id"`
+	Role   users_dm.Role `validate:"required,oneof=VIEWER EDITOR ADMIN" json:"role"`
+}
+
+type ForgotPasswordUserItcParams struct {
+	Email string `validate:"required,email,max=64" json:"email"`
+}
+
+type ResetPasswordUserItcParams struct {
+	Token    string `validate:"required,max=256" json:"token"`
+	Password string `validate:"required,max=64" json:"password"`
+}
+
+type VerifyEmailUserItcParams struct {
+	Token string `validate:"required,max=256" json:"token" query:"token"`
+}
+
+type ResendVerificationUserItcParams struct {
+	Email string `validate:"required,email,max=64" json:"email"`
+}
+
+type CompleteLoginUserItcParams struct {
+	Challenge string `validate:"required,max=256" json:"challenge"`
+	Code      string `validate:"required,max=16" json:"code"`
+	ClientIp  string `validate:"omitempty,ip" json:"-"`
+}
+
+type CompleteOidcLoginUserItcParams struct {
+	Code  string `validate:"required,max=2048" query:"code"`
+	State string `validate:"required,max=256" query:"state"`
+}
+
+type EnrollTotpUserItcParams struct {
+	UserId string `validate:"required,uuid" json:"user_id"`
+}
+
+type ConfirmTotpUserItcParams struct {
+	UserId string `validate:"required,uuid" json:"user_id"`
+	Code   string `validate:"required,max=16" json:"code"`
+}
+
+type DisableTotpUserItcParams struct {
+	UserId string `validate:"required,uuid" json:"user_id"`
+	Code   string `validate:"required,max=16" json:"code"`
+}
+
 /// interactor
 
 type UsersInteractor interface {
-	Login(ctx context.Context, params LoginUserItcParams) (users_dm.UserEntity, error)
+	// Login returns the user, or login challenge when the user has two-factor authentication enabled.
+	Login(ctx context.Context, params LoginUserItcParams) (users_dm.UserEntity, tokens_dm.OneTimeTokenEntity, error)
+	CompleteLogin(ctx context.Context, params CompleteLoginUserItcParams) (users_dm.UserEntity, error)
+	// StartOidcLogin prepares redirect to the identity provider, CompleteOidcLogin exchanges the code it returns for
+	// the user, who is linked or created by the email.
+	StartOidcLogin(ctx context.Context) (users_dm.OidcAuthorization, error)
+	CompleteOidcLogin(ctx context.Context, params CompleteOidcLoginUserItcParams) (users_dm.UserEntity, tokens_dm.OneTimeTokenEntity, error)
 	Register(ctx context.Context, params RegisterUserItcParams) (users_dm.UserEntity, error)
+	Get(ctx context.Context, params GetUserItcParams) (users_dm.UserEntity, error)
+	Update(ctx context.Context, params UpdateUserItcParams) (users_dm.UserEntity, error)
+	// Delete removes the user together with favourites, API keys and pending tokens. Sessions and refresh tokens are
+	// revoked by the caller.
+	Delete(ctx context.Context, params DeleteUserItcParams) (users_dm.UserEntity, error)
+	// Select, Disable and Enable form the admin directory, disabled users cannot sign in.
+	Select(ctx context.Context, params SelectUsersItcParams) ([]users_dm.UserEntity, string, error)
+	Disable(ctx context.Context, params DisableUserItcParams) (users_dm.UserEntity, error)
+	Enable(ctx context.Context, params EnableUserItcParams) (users_dm.UserEntity, error)
+	GrantRole(ctx context.Context, params GrantRoleUserItcParams) (users_dm.UserEntity, error)
+	RevokeRole(ctx context.Context, params RevokeRoleUserItcParams) (users_dm.UserEntity, error)
+	ForgotPassword(ctx context.Context, params ForgotPasswordUserItcParams) error
+	ResetPassword(ctx context.Context, params ResetPasswordUserItcParams) (users_dm.UserEntity, error)
+	VerifyEmail(ctx context.Context, params VerifyEmailUserItcParams) (users_dm.UserEntity, error)
+	ResendVerification(ctx context.Context, params ResendVerificationUserItcParams) error
+	EnrollTotp(ctx context.Context, params EnrollTotpUserItcParams) (users_dm.TotpEnrollment, error)
+	ConfirmTotp(ctx context.Context, params ConfirmTotpUserItcParams) ([]string, error)
+	DisableTotp(ctx context.Context, params DisableTotpUserItcParams) (users_dm.UserEntity, error)
+}
+
+/*
+ * Tokens
+ */
+
+/// params
+
+type IssueTokenItcParams struct {
+	UserId    string `validate:"required,uuid" json:"user_id"`
+	SessionId string `validate:"max=64" json:"session_id"`
+}
+
+type RefreshTokenItcParams struct {
+	Token string `validate:"required,max=256" json:"refresh_token"`
+}
+
+type RevokeTokensItcParams struct {
+	Token   string   `validate:"omitempty,max=256" json:"refresh_token"`
+	UserIds []string `validate:"dive,uuid" json:"user_ids"`
+}
+
+/// interactor
+
+type TokensInteractor interface {
+	Issue(ctx context.Context, params IssueTokenItcParams) (tokens_dm.RefreshTokenEntity, error)
+	Refresh(ctx context.Context, params RefreshTokenItcParams) (users_dm.UserEntity, tokens_dm.RefreshTokenEntity, error)
+	Revoke(ctx context.Context, params RevokeTokensItcParams) ([]tokens_dm.RefreshTokenEntity, error)
+}
+
+/*
+ * ApiKeys
+ */
+
+/// params
+
+type SelectApiKeysItcParams struct {
+	UserId string `validate:"required,uuid" json:"user_id"`
+}
+
+type InsertApiKeyItcParams struct {
+	UserId string          `validate:"required,uuid" json:"user_id"`
+	Name   string          `validate:"required,max=64" json:"name"`
+	Scopes []keys_dm.Scope `validate:"required,min=1,dive,oneof=assets:read assets:write favourites:read favourites:write collections:read collections:write" json:"scopes"`
+}
+
+type DeleteApiKeyItcParams struct {
+	Id     string `validate:"required,uuid" json:"id"`
+	UserId string `validate:"required,uuid" json:"user_id"`
+}
+
+type AuthenticateApiKeyItcParams struct {
+	Key string `validate:"required,max=256" json:"key"`
+}
+
+/// interactor
+
+type ApiKeysInteractor interface {
+	Select(ctx context.Context, params SelectApiKeysItcParams) ([]keys_dm.ApiKeyEntity, error)
+	Insert(ctx context.Context, params InsertApiKeyItcParams) (keys_dm.ApiKeyEntity, error)
+	Delete(ctx context.Context, params DeleteApiKeyItcParams) (keys_dm.ApiKeyEntity, error)
+	Authenticate(ctx context.Context, params AuthenticateApiKeyItcParams) (keys_dm.ApiKeyEntity, users_dm.UserEntity, error)
 }
 
 /*
@@ -36,10 +214,17 @@ type UsersInteractor interface {
 
 /// params
 
+// SelectAssetsItcParams narrows down listed assets, assets matching all provided filters are returned. Listing
+// filtered by type or create time comes sorted by create time, newest first, unless Sort says otherwise.
 type SelectAssetsItcParams struct {
-	Ids    []string `validate:"dive,uuid" json:"ids"`
-	Cursor string   `json:"cursor"`
-	Limit  int      `validate:"gte=0,lte=100" json:"limit"`
+	Ids           []string       `validate:"dive,uuid" json:"ids"`
+	Tags          []string       `validate:"max=8,dive,min=1,max=32" json:"tags" query:"tag"`
+	Type          assets_dm.Type `validate:"omitempty,oneof=CHART INSIGHT AUDIENCE" json:"type" query:"type"`
+	CreatedAfter  time.Time      `json:"created_after" query:"created_after"`
+	CreatedBefore time.Time      `json:"created_before" query:"created_before"`
+	Sort          assets_dm.Sort `validate:"omitempty,oneof=create_time -create_time name -name" json:"sort" query:"sort"`
+	Cursor        string         `json:"cursor" query:"cursor"`
+	Limit         int            `validate:"gte=0,lte=100" json:"limit" query:"limit"`
 }
 
 type InsertAssetItcParams struct {
@@ -49,22 +234,126 @@ type InsertAssetItcParams struct {
 	AssetData   assets_dm.AssetData `validate:"required" json:"asset_data"`
 }
 
+// UpdateAssetItcParams patches the asset, nil fields are left untouched. Provided asset data replaces content of the
+// asset and has to match its type.
 type UpdateAssetItcParams struct {
-	Id          string  `validate:"required,uuid" json:"id"`
-	Description *string `validate:"omitempty,max=64" json:"description"`
+	Id          string               `validate:"required,uuid" json:"id"`
+	Name        *string              `validate:"omitempty,min=1,max=128" json:"name"`
+	Description *string              `validate:"omitempty,max=8192" json:"description"`
+	AssetData   *assets_dm.AssetData `json:"asset_data"`
 }
 
 type DeleteAssetItcParams struct {
 	Id string `validate:"required,uuid" json:"id"`
 }
 
+// UpdateAssetTagsItcParams labels the asset with Add tags and removes Remove tags from it. Tags are lower-cased and
+// trimmed before they are stored.
+type UpdateAssetTagsItcParams struct {
+	Id     string   `validate:"required,uuid" json:"id"`
+	Add    []string `validate:"max=32,dive,min=1,max=32" json:"add"`
+	Remove []string `validate:"max=32,dive,min=1,max=32" json:"remove"`
+}
+
+type SearchAssetsItcParams struct {
+	Query string `validate:"required,min=1,max=256" json:"q" query:"q"`
+	Limit int    `validate:"gte=0,lte=100" json:"limit" query:"limit"`
+}
+
+// RenderAssetItcParams draws the chart as svg unless other format is given, size of the image defaults to 800x450.
+type RenderAssetItcParams struct {
+	Id     string                 `validate:"required,uuid" json:"id"`
+	Format assets_dm.RenderFormat `validate:"omitempty,oneof=svg png" json:"format" query:"format"`
+	Width  int                    `validate:"omitempty,gte=100,lte=4096" json:"width" query:"width"`
+	Height int                    `validate:"omitempty,gte=100,lte=4096" json:"height" query:"height"`
+}
+
+type TransferAssetOwnershipItcParams struct {
+	Id      string `validate:"required,uuid" json:"id"`
+	OwnerId string `validate:"required,uuid" json:"owner_id"`
+}
+
+type SelectAssetGrantsItcParams struct {
+	AssetId string `validate:"required,uuid" json:"asset_id"`
+}
+
+// InsertAssetGrantItcParams shares the asset with a user, identified by id, or with a group, identified by the role.
+type InsertAssetGrantItcParams struct {
+	AssetId     string                `validate:"required,uuid" json:"asset_id"`
+	GranteeType grants_dm.GranteeType `validate:"required,oneof=USER GROUP" json:"grantee_type"`
+	GranteeId   string                `validate:"required,max=64" json:"grantee_id"`
+	Permission  grants_dm.Permission  `validate:"required,oneof=READ EDIT" json:"permission"`
+}
+
+type DeleteAssetGrantItcParams struct {
+	AssetId string `validate:"required,uuid" json:"asset_id"`
+	GrantId string `validate:"required,uuid" json:"grant_id"`
+}
+
+type SelectTrashItcParams struct {
+	Cursor string `json:"cursor"`
+	Limit  int    `validate:"gte=0,lte=100" json:"limit"`
+}
+
+type RestoreAssetItcParams struct {
+	Id string `validate:"required,uuid" json:"id"`
+}
+
+type PurgeAssetsItcParams struct {
+	DeletedBefore time.Time `validate:"required" json:"deleted_before"`
+}
+
+type SelectAssetVersionsItcParams struct {
+	AssetId string `validate:"required,uuid" json:"asset_id"`
+	Cursor  string `json:"cursor"`
+	Limit   int    `validate:"gte=0,lte=100" json:"limit"`
+}
+
+type DiffAssetVersionsItcParams struct {
+	AssetId string `validate:"required,uuid" json:"asset_id"`
+	From    int    `validate:"required,gt=0" json:"from" query:"from"`
+	To      int    `validate:"required,gt=0" json:"to" query:"to"`
+}
+
+type RestoreAssetVersionItcParams struct {
+	AssetId string `validate:"required,uuid" json:"asset_id"`
+	Number  int    `validate:"required,gt=0" json:"number"`
+}
+
 /// interactor
 
 type AssetsInteractor interface {
 	Select(ctx context.Context, params SelectAssetsItcParams) ([]assets_dm.AssetEntity, string, error)
 	Insert(ctx context.Context, params ...InsertAssetItcParams) ([]assets_dm.AssetEntity, error)
 	Update(ctx context.Context, params ...UpdateAssetItcParams) ([]assets_dm.AssetEntity, error)
+	// Delete moves assets to the trash, content and favourites of trashed assets are kept until they are purged.
 	Delete(ctx context.Context, params ...DeleteAssetItcParams) ([]assets_dm.AssetEntity, error)
+	UpdateTags(ctx context.Context, params ...UpdateAssetTagsItcParams) ([]assets_dm.AssetEntity, error)
+	// SelectTags lists tags of live assets together with number of assets labelled with them.
+	SelectTags(ctx context.Context) ([]assets_dm.TagCount, error)
+	// Search finds live assets by words of their names, descriptions, insight texts and chart titles, most relevant
+	// first.
+	Search(ctx context.Context, params SearchAssetsItcParams) ([]assets_dm.SearchResult, error)
+	// Render draws the chart asset as an image, renders are cached until the asset is updated.
+	Render(ctx context.Context, params RenderAssetItcParams) (assets_dm.Image, error)
+	// Reindex rebuilds the search index from the repository, it is run at startup, so it isn't guarded by the policy.
+	Reindex(ctx context.Context) (int, error)
+	// TransferOwnership hands the asset over to another user, only the owner and admins can do it.
+	TransferOwnership(ctx context.Context, params TransferAssetOwnershipItcParams) (assets_dm.AssetEntity, error)
+	// SelectGrants, InsertGrant and DeleteGrant manage sharing of the asset, only the owner and admins can do it.
+	SelectGrants(ctx context.Context, params SelectAssetGrantsItcParams) ([]grants_dm.GrantEntity, error)
+	InsertGrant(ctx context.Context, params InsertAssetGrantItcParams) (grants_dm.GrantEntity, error)
+	DeleteGrant(ctx context.Context, params DeleteAssetGrantItcParams) (grants_dm.GrantEntity, error)
+	SelectTrash(ctx context.Context, params SelectTrashItcParams) ([]assets_dm.AssetEntity, string, error)
+	Restore(ctx context.Context, params ...RestoreAssetItcParams) ([]assets_dm.AssetEntity, error)
+	// Purge permanently removes assets trashed before given moment together with their content, favourites and
+	// versions. It is run by background job, so it isn't guarded by the policy.
+	Purge(ctx context.Context, params PurgeAssetsItcParams) ([]assets_dm.AssetEntity, error)
+	// SelectVersions lists snapshots taken after every change of the asset, newest first.
+	SelectVersions(ctx context.Context, params SelectAssetVersionsItcParams) ([]versions_dm.VersionEntity, string, error)
+	DiffVersions(ctx context.Context, params DiffAssetVersionsItcParams) ([]diff.Change, error)
+	// RestoreVersion brings the asset back to the state of given version, which is recorded as a new version.
+	RestoreVersion(ctx context.Context, params RestoreAssetVersionItcParams) (assets_dm.AssetEntity, error)
 }
 
 /*
@@ -97,3 +386,81 @@ type FavouritesInteractor interface {
 	Insert(ctx context.Context, params ...InsertFavouriteItcParams) ([]favourites_dm.FavouriteEntity, error)
 	Delete(ctx context.Context, params ...DeleteFavouriteItcParams) ([]favourites_dm.FavouriteEntity, error)
 }
+
+/*
+ * Collections
+ */
+
+/// params
+
+type SelectCollectionsItcParams struct {
+	Cursor string `json:"cursor" query:"cursor"`
+	Limit  int    `validate:"gte=0,lte=100" json:"limit" query:"limit"`
+}
+
+type PopulateCollectionItcParams struct {
+	Id string `validate:"required,uuid" json:"id"`
+}
+
+type InsertCollectionItcParams struct {
+	Name        string                `validate:"required,min=1,max=128" json:"name"`
+	Description string                `validate:"max=8192" json:"description"`
+	Items       []collections_dm.Item `validate:"max=100,unique=AssetId,dive" json:"items"`
+}
+
+// UpdateCollectionItcParams changes only provided fields, Items replace the whole list, so that they can be reordered.
+type UpdateCollectionItcParams struct {
+	Id          string                 `validate:"required,uuid" json:"id"`
+	Name        *string                `validate:"omitempty,min=1,max=128" json:"name"`
+	Description *string                `validate:"omitempty,max=8192" json:"description"`
+	Items       *[]collections_dm.Item `validate:"omitempty,max=100,unique=AssetId,dive" json:"items"`
+}
+
+type DeleteCollectionItcParams struct {
+	Id string `validate:"required,uuid" json:"id"`
+}
+
+/// interactor
+
+// CollectionsInteractor manages collections of the caller, admins can access collections of every user.
+type CollectionsInteractor interface {
+	Select(ctx context.Context, params SelectCollectionsItcParams) ([]collections_dm.CollectionEntity, string, error)
+	// Populate loads the collection together with its assets. Assets the caller cannot read and trashed ones are
+	// left out of both items and assets.
+	Populate(ctx context.Context, params PopulateCollectionItcParams) (collections_dm.PopulatedCollection, error)
+	Insert(ctx context.Context, params ...InsertCollectionItcParams) ([]collections_dm.CollectionEntity, error)
+	Update(ctx context.Context, params ...UpdateCollectionItcParams) ([]collections_dm.CollectionEntity, error)
+	Delete(ctx context.Context, params ...DeleteCollectionItcParams) ([]collections_dm.CollectionEntity, error)
+}
+
+/*
+ * Audit
+ */
+
+/// params
+
+// RecordAuditItcParams describes single modification, Before is nil for inserted entities and After for deleted ones.
+type RecordAuditItcParams struct {
+	Action     audit_dm.Action     `validate:"required,oneof=INSERT UPDATE DELETE" json:"action"`
+	EntityType audit_dm.EntityType `validate:"required,oneof=ASSET FAVOURITE COLLECTION" json:"entity_type"`
+	EntityId   string              `validate:"required" json:"entity_id"`
+	Before     any                 `json:"before"`
+	After      any                 `json:"after"`
+}
+
+type SelectAuditItcParams struct {
+	Day        string              `validate:"required,datetime=2006-01-02" json:"day" query:"day"`
+	ActorId    string              `validate:"omitempty,uuid" json:"actor_id" query:"actor_id"`
+	EntityType audit_dm.EntityType `validate:"omitempty,oneof=ASSET FAVOURITE COLLECTION" json:"entity_type" query:"entity_type"`
+	EntityId   string              `validate:"omitempty,max=64" json:"entity_id" query:"entity_id"`
+	Cursor     string              `json:"cursor" query:"cursor"`
+	Limit      int                 `validate:"gte=0,lte=100" json:"limit" query:"limit"`
+}
+
+/// interactor
+
+type AuditInteractor interface {
+	// Record stores entries on behalf of the caller found in the context.
+	Record(ctx context.Context, params ...RecordAuditItcParams) ([]audit_dm.EntryEntity, error)
+	Select(ctx context.Context, params SelectAuditItcParams) ([]audit_dm.EntryEntity, string, error)
+}
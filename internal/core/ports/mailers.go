@@ -0,0 +1,21 @@
+package ports
+
+import "context"
+
+/*
+ * Mailers
+ */
+
+/// params
+
+type SendMailParams struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+/// mailer
+
+type Mailer interface {
+	Send(ctx context.Context, params SendMailParams) error
+}
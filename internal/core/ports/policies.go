@@ -0,0 +1,26 @@
+package ports
+
+import "context"
+
+/*
+ * Policies
+ */
+
+/// actions
+
+type Action = string
+
+const (
+	ActionInsertAssets Action = "assets:insert"
+	ActionUpdateAssets Action = "assets:update"
+	ActionDeleteAssets Action = "assets:delete"
+	ActionManageRoles  Action = "users:roles"
+	ActionManageUsers  Action = "users:manage"
+	ActionReadAudit    Action = "audit:read"
+)
+
+/// policy
+
+type Policy interface {
+	Authorize(ctx context.Context, action Action) error
+}
@@ -0,0 +1,31 @@
+package ports
+
+import (
+	users_dm "assets/internal/core/domain/users"
+	"context"
+)
+
+/*
+ * Identity providers
+ */
+
+/// params
+
+type AuthorizationUrlParams struct {
+	State         string
+	Nonce         string
+	CodeChallenge string
+}
+
+type ExchangeCodeParams struct {
+	Code         string
+	CodeVerifier string
+	Nonce        string
+}
+
+/// provider
+
+type IdentityProvider interface {
+	AuthorizationUrl(ctx context.Context, params AuthorizationUrlParams) (string, error)
+	Exchange(ctx context.Context, params ExchangeCodeParams) (users_dm.ExternalIdentity, error)
+}
@@ -0,0 +1,25 @@
+package ports
+
+import (
+	assets_dm "assets/internal/core/domain/assets"
+	"context"
+)
+
+/*
+ * Render
+ */
+
+/// params
+
+type RenderParams struct {
+	Format assets_dm.RenderFormat
+	Width  int
+	Height int
+}
+
+/// renderer
+
+// Renderer draws chart of the asset as image of given format and size in pixels.
+type Renderer interface {
+	Render(ctx context.Context, asset assets_dm.AssetEntity, params RenderParams) ([]byte, error)
+}
@@ -2,9 +2,18 @@ package ports
 
 import (
 	assets_dm "assets/internal/core/domain/assets"
+	attempts_dm "assets/internal/core/domain/attempts"
+	audit_dm "assets/internal/core/domain/audit"
+	collections_dm "assets/internal/core/domain/collections"
 	favourites_dm "assets/internal/core/domain/favourites"
+	grants_dm "assets/internal/core/domain/grants"
+	keys_dm "assets/internal/core/domain/keys"
+	tokens_dm "assets/internal/core/domain/tokens"
 	users_dm "assets/internal/core/domain/users"
+	versions_dm "assets/internal/core/domain/versions"
 	"context"
+	"github.com/gorilla/sessions"
+	"time"
 )
 
 /*
@@ -14,10 +23,11 @@ import (
 /// params
 
 type SelectUsersRepoParams struct {
-	Ids    []string `validate:"dive,uuid" json:"ids"`
-	Emails []string `validate:"dive,email" json:"emails"`
-	Cursor string   `json:"cursor"`
-	Limit  int      `validate:"gte=0,lte=100" json:"limit"`
+	Ids         []string `validate:"dive,uuid" json:"ids"`
+	Emails      []string `validate:"dive,email" json:"emails"`
+	EmailPrefix string   `validate:"max=64" json:"email_prefix"`
+	Cursor      string   `json:"cursor"`
+	Limit       int      `validate:"gte=0,lte=100" json:"limit"`
 }
 
 /// repository
@@ -25,6 +35,8 @@ type SelectUsersRepoParams struct {
 type UsersRepository interface {
 	Select(ctx context.Context, params SelectUsersRepoParams) ([]users_dm.UserEntity, string, error)
 	Insert(ctx context.Context, models ...users_dm.UserEntity) ([]users_dm.UserEntity, error)
+	Update(ctx context.Context, models ...users_dm.UserEntity) ([]users_dm.UserEntity, error)
+	Delete(ctx context.Context, models ...users_dm.UserEntity) ([]users_dm.UserEntity, error)
 }
 
 /*
@@ -33,10 +45,27 @@ type UsersRepository interface {
 
 /// params
 
+// SelectAssetsRepoParams lists live assets, or trashed ones when Deleted is set. Trash comes ordered by delete time,
+// newest first, and can be narrowed down to assets deleted before given moment. Live assets can be narrowed down to
+// ones labelled with all given Tags, of given Type or created within given range. Live assets filtered by type or
+// create time, or with Sort set, come in order of Sort, newest first by default.
 type SelectAssetsRepoParams struct {
-	Ids    []string
-	Cursor string
-	Limit  int
+	Ids           []string
+	Tags          []string
+	Type          assets_dm.Type
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	Sort          assets_dm.Sort
+	Deleted       bool
+	DeletedBefore time.Time
+	Cursor        string
+	Limit         int
+}
+
+// SelectTagsRepoParams narrows tag counts down to live assets of given owners, empty owner stands for assets without
+// owner. Tags of all live assets are counted when no owner is given.
+type SelectTagsRepoParams struct {
+	OwnerIds []string
 }
 
 /// repository
@@ -46,6 +75,7 @@ type AssetsRepository interface {
 	Insert(ctx context.Context, models ...assets_dm.AssetEntity) ([]assets_dm.AssetEntity, error)
 	Update(ctx context.Context, models ...assets_dm.AssetEntity) ([]assets_dm.AssetEntity, error)
 	Delete(ctx context.Context, models ...assets_dm.AssetEntity) ([]assets_dm.AssetEntity, error)
+	SelectTags(ctx context.Context, params SelectTagsRepoParams) ([]assets_dm.TagCount, error)
 }
 
 /*
@@ -65,6 +95,7 @@ type SelectChartsRepoParams struct {
 type ChartsRepository interface {
 	Select(ctx context.Context, params SelectChartsRepoParams) ([]assets_dm.ChartEntity, string, error)
 	Insert(ctx context.Context, models ...assets_dm.ChartEntity) ([]assets_dm.ChartEntity, error)
+	Update(ctx context.Context, models ...assets_dm.ChartEntity) ([]assets_dm.ChartEntity, error)
 	Delete(ctx context.Context, models ...assets_dm.ChartEntity) ([]assets_dm.ChartEntity, error)
 }
 
@@ -85,6 +116,7 @@ type SelectInsightsRepoParams struct {
 type InsightsRepository interface {
 	Select(ctx context.Context, params SelectInsightsRepoParams) ([]assets_dm.InsightEntity, string, error)
 	Insert(ctx context.Context, models ...assets_dm.InsightEntity) ([]assets_dm.InsightEntity, error)
+	Update(ctx context.Context, models ...assets_dm.InsightEntity) ([]assets_dm.InsightEntity, error)
 	Delete(ctx context.Context, models ...assets_dm.InsightEntity) ([]assets_dm.InsightEntity, error)
 }
 
@@ -105,9 +137,60 @@ type SelectAudiencesRepoParams struct {
 type AudiencesRepository interface {
 	Select(ctx context.Context, params SelectAudiencesRepoParams) ([]assets_dm.AudienceEntity, string, error)
 	Insert(ctx context.Context, models ...assets_dm.AudienceEntity) ([]assets_dm.AudienceEntity, error)
+	Update(ctx context.Context, models ...assets_dm.AudienceEntity) ([]assets_dm.AudienceEntity, error)
 	Delete(ctx context.Context, models ...assets_dm.AudienceEntity) ([]assets_dm.AudienceEntity, error)
 }
 
+/*
+ * Grants
+ */
+
+/// params
+
+// SelectGrantsRepoParams lists grants of the assets, or grants given to the users and groups. Grants of grantees are
+// returned all at once, they aren't paged.
+type SelectGrantsRepoParams struct {
+	AssetIds      []string
+	GranteeUsers  []string
+	GranteeGroups []string
+	Cursor        string
+	Limit         int
+}
+
+/// repository
+
+// GrantsRepository keeps single grant per grantee of the asset, inserting another grant for the grantee replaces the
+// previous one.
+type GrantsRepository interface {
+	Select(ctx context.Context, params SelectGrantsRepoParams) ([]grants_dm.GrantEntity, string, error)
+	Insert(ctx context.Context, models ...grants_dm.GrantEntity) ([]grants_dm.GrantEntity, error)
+	Delete(ctx context.Context, models ...grants_dm.GrantEntity) ([]grants_dm.GrantEntity, error)
+}
+
+/*
+ * Versions
+ */
+
+/// params
+
+// SelectVersionsRepoParams lists versions of the assets, newest first. Latest limits results to single, most recent
+// version per asset.
+type SelectVersionsRepoParams struct {
+	AssetIds []string
+	Numbers  []int
+	Latest   bool
+	Cursor   string
+	Limit    int
+}
+
+/// repository
+
+type VersionsRepository interface {
+	Select(ctx context.Context, params SelectVersionsRepoParams) ([]versions_dm.VersionEntity, string, error)
+	Insert(ctx context.Context, models ...versions_dm.VersionEntity) ([]versions_dm.VersionEntity, error)
+	Delete(ctx context.Context, models ...versions_dm.VersionEntity) ([]versions_dm.VersionEntity, error)
+}
+
 /*
  * Favourites
  */
@@ -129,3 +212,160 @@ type FavouritesRepository interface {
 	Insert(ctx context.Context, models ...favourites_dm.FavouriteEntity) ([]favourites_dm.FavouriteEntity, error)
 	Delete(ctx context.Context, models ...favourites_dm.FavouriteEntity) ([]favourites_dm.FavouriteEntity, error)
 }
+
+/*
+ * Collections
+ */
+
+/// params
+
+// SelectCollectionsRepoParams lists collections of given owners, or collections holding any of given assets.
+type SelectCollectionsRepoParams struct {
+	Ids      []string
+	OwnerIds []string
+	AssetIds []string
+	Cursor   string
+	Limit    int
+}
+
+/// repository
+
+type CollectionsRepository interface {
+	Select(ctx context.Context, params SelectCollectionsRepoParams) ([]collections_dm.CollectionEntity, string, error)
+	Insert(ctx context.Context, models ...collections_dm.CollectionEntity) ([]collections_dm.CollectionEntity, error)
+	Update(ctx context.Context, models ...collections_dm.CollectionEntity) ([]collections_dm.CollectionEntity, error)
+	Delete(ctx context.Context, models ...collections_dm.CollectionEntity) ([]collections_dm.CollectionEntity, error)
+}
+
+/*
+ * RefreshTokens
+ */
+
+/// params
+
+type SelectRefreshTokensRepoParams struct {
+	Ids       []string
+	FamilyIds []string
+	UserIds   []string
+	Cursor    string
+	Limit     int
+}
+
+/// repository
+
+type RefreshTokensRepository interface {
+	Select(ctx context.Context, params SelectRefreshTokensRepoParams) ([]tokens_dm.RefreshTokenEntity, string, error)
+	Insert(ctx context.Context, models ...tokens_dm.RefreshTokenEntity) ([]tokens_dm.RefreshTokenEntity, error)
+	Update(ctx context.Context, models ...tokens_dm.RefreshTokenEntity) ([]tokens_dm.RefreshTokenEntity, error)
+	Delete(ctx context.Context, models ...tokens_dm.RefreshTokenEntity) ([]tokens_dm.RefreshTokenEntity, error)
+	// MarkUsed atomically marks the token as used, it returns false when the token had been used before.
+	MarkUsed(ctx context.Context, model tokens_dm.RefreshTokenEntity) (bool, error)
+}
+
+/*
+ * OneTimeTokens
+ */
+
+/// params
+
+type SelectOneTimeTokensRepoParams struct {
+	Ids     []string
+	UserIds []string
+	Cursor  string
+	Limit   int
+}
+
+/// repository
+
+type OneTimeTokensRepository interface {
+	Select(ctx context.Context, params SelectOneTimeTokensRepoParams) ([]tokens_dm.OneTimeTokenEntity, string, error)
+	Insert(ctx context.Context, models ...tokens_dm.OneTimeTokenEntity) ([]tokens_dm.OneTimeTokenEntity, error)
+	// Consume removes the token unless it has already been removed, false is returned when it has.
+	Consume(ctx context.Context, model tokens_dm.OneTimeTokenEntity) (bool, error)
+	Delete(ctx context.Context, models ...tokens_dm.OneTimeTokenEntity) ([]tokens_dm.OneTimeTokenEntity, error)
+}
+
+/*
+ * LoginAttempts
+ */
+
+/// params
+
+type SelectLoginAttemptsRepoParams struct {
+	Ids []string
+}
+
+/// repository
+
+type LoginAttemptsRepository interface {
+	Select(ctx context.Context, params SelectLoginAttemptsRepoParams) ([]attempts_dm.LoginAttemptEntity, error)
+	Insert(ctx context.Context, models ...attempts_dm.LoginAttemptEntity) ([]attempts_dm.LoginAttemptEntity, error)
+	Update(ctx context.Context, models ...attempts_dm.LoginAttemptEntity) ([]attempts_dm.LoginAttemptEntity, error)
+	// InsertIfNotExists stores the attempt unless its key is tracked already, false is returned when it is.
+	InsertIfNotExists(ctx context.Context, model attempts_dm.LoginAttemptEntity) (bool, error)
+	// UpdateIfFailures stores the attempt only when stored failures still equal 'failures', false is returned otherwise.
+	UpdateIfFailures(ctx context.Context, model attempts_dm.LoginAttemptEntity, failures int) (bool, error)
+	Delete(ctx context.Context, models ...attempts_dm.LoginAttemptEntity) ([]attempts_dm.LoginAttemptEntity, error)
+}
+
+/*
+ * ApiKeys
+ */
+
+/// params
+
+type SelectApiKeysRepoParams struct {
+	Ids     []string
+	UserIds []string
+	Cursor  string
+	Limit   int
+}
+
+/// repository
+
+type ApiKeysRepository interface {
+	Select(ctx context.Context, params SelectApiKeysRepoParams) ([]keys_dm.ApiKeyEntity, string, error)
+	Insert(ctx context.Context, models ...keys_dm.ApiKeyEntity) ([]keys_dm.ApiKeyEntity, error)
+	Update(ctx context.Context, models ...keys_dm.ApiKeyEntity) ([]keys_dm.ApiKeyEntity, error)
+	Delete(ctx context.Context, models ...keys_dm.ApiKeyEntity) ([]keys_dm.ApiKeyEntity, error)
+}
+
+/*
+ * Audit
+ */
+
+/// params
+
+// SelectAuditRepoParams lists entries of a single day, newest first. Remaining fields narrow results down.
+type SelectAuditRepoParams struct {
+	Day        string
+	ActorId    string
+	EntityType string
+	EntityId   string
+	Cursor     string
+	Limit      int
+}
+
+/// repository
+
+type AuditRepository interface {
+	Select(ctx context.Context, params SelectAuditRepoParams) ([]audit_dm.EntryEntity, string, error)
+	Insert(ctx context.Context, models ...audit_dm.EntryEntity) ([]audit_dm.EntryEntity, error)
+}
+
+/*
+ * Sessions
+ */
+
+/// keys
+
+const SessionUserIdKey = "userId"
+
+/// repository
+
+type SessionsRepository interface {
+	sessions.Store
+	Exists(ctx context.Context, id string) (bool, error)
+	Revoke(ctx context.Context, ids ...string) error
+	RevokeUsers(ctx context.Context, userIds ...string) error
+}
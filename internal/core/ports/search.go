@@ -0,0 +1,32 @@
+package ports
+
+import (
+	assets_dm "assets/internal/core/domain/assets"
+	"context"
+)
+
+/*
+ * Search
+ */
+
+/// params
+
+type SearchParams struct {
+	Query string
+	Limit int
+}
+
+type SearchHit struct {
+	Id    string
+	Score float64
+}
+
+/// index
+
+// SearchIndex finds assets by text of their names, descriptions and content. Indexing an asset which is already
+// indexed replaces its previous entry.
+type SearchIndex interface {
+	Index(ctx context.Context, assets ...assets_dm.AssetEntity) error
+	Remove(ctx context.Context, ids ...string) error
+	Search(ctx context.Context, params SearchParams) ([]SearchHit, error)
+}
@@ -2,10 +2,11 @@ package assets_hl
 
 import (
 	assets_dm "assets/internal/core/domain/assets"
+	keys_dm "assets/internal/core/domain/keys"
 	"assets/internal/core/ports"
+	auth_hl "assets/internal/handlers/auth"
 	errs "assets/pkg/errors"
 	"assets/pkg/logging"
-	"context"
 	"errors"
 	"github.com/labstack/echo/v4"
 	"net/http"
@@ -18,7 +19,13 @@ type Handler struct {
 	assetsItc ports.AssetsInteractor
 }
 
-func Init(webServer *echo.Echo, logger logging.Logger, interactor ports.AssetsInteractor) *Handler {
+// contentTypes tells media types of rendered images.
+var contentTypes = map[assets_dm.RenderFormat]string{
+	assets_dm.RenderFormatSvg: "image/svg+xml",
+	assets_dm.RenderFormatPng: "image/png",
+}
+
+func Init(webServer *echo.Echo, logger logging.Logger, interactor ports.AssetsInteractor, authMiddleware echo.MiddlewareFunc) *Handler {
 
 	instance := &Handler{
 		webServer: webServer,
@@ -26,11 +33,27 @@ func Init(webServer *echo.Echo, logger logging.Logger, interactor ports.AssetsIn
 		assetsItc: interactor,
 	}
 
-	instance.webServer.GET("/api/assets", instance.HandleSelectMany)
-	instance.webServer.GET("/api/assets/:id", instance.HandleSelectOne)
-	instance.webServer.POST("/api/assets/create", instance.HandleInsert)
-	instance.webServer.PATCH("/api/assets/update", instance.HandleUpdate)
-	instance.webServer.DELETE("/api/assets/delete/:id", instance.HandleDelete)
+	readScope := auth_hl.RequireScope(keys_dm.ScopeAssetsRead)
+	writeScope := auth_hl.RequireScope(keys_dm.ScopeAssetsWrite)
+
+	instance.webServer.GET("/api/assets", instance.HandleSelectMany, authMiddleware, readScope)
+	instance.webServer.GET("/api/assets/trash", instance.HandleSelectTrash, authMiddleware, readScope)
+	instance.webServer.GET("/api/assets/tags", instance.HandleSelectTags, authMiddleware, readScope)
+	instance.webServer.GET("/api/assets/search", instance.HandleSearch, authMiddleware, readScope)
+	instance.webServer.GET("/api/assets/:id", instance.HandleSelectOne, authMiddleware, readScope)
+	instance.webServer.POST("/api/assets/create", instance.HandleInsert, authMiddleware, writeScope)
+	instance.webServer.PATCH("/api/assets/update", instance.HandleUpdate, authMiddleware, writeScope)
+	instance.webServer.DELETE("/api/assets/delete/:id", instance.HandleDelete, authMiddleware, writeScope)
+	instance.webServer.PATCH("/api/assets/:id/tags", instance.HandleUpdateTags, authMiddleware, writeScope)
+	instance.webServer.POST("/api/assets/:id/owner", instance.HandleTransferOwnership, authMiddleware, writeScope)
+	instance.webServer.GET("/api/assets/:id/grants", instance.HandleSelectGrants, authMiddleware, readScope)
+	instance.webServer.POST("/api/assets/:id/grants", instance.HandleInsertGrant, authMiddleware, writeScope)
+	instance.webServer.DELETE("/api/assets/:id/grants/:grant_id", instance.HandleDeleteGrant, authMiddleware, writeScope)
+	instance.webServer.POST("/api/assets/:id/restore", instance.HandleRestore, authMiddleware, writeScope)
+	instance.webServer.GET("/api/assets/:id/render", instance.HandleRender, authMiddleware, readScope)
+	instance.webServer.GET("/api/assets/:id/versions", instance.HandleSelectVersions, authMiddleware, readScope)
+	instance.webServer.GET("/api/assets/:id/versions/diff", instance.HandleDiffVersions, authMiddleware, readScope)
+	instance.webServer.POST("/api/assets/:id/versions/:number/restore", instance.HandleRestoreVersion, authMiddleware, writeScope)
 
 	return instance
 }
@@ -45,7 +68,7 @@ func (h *Handler) HandleSelectOne(ctx echo.Context) (err error) {
 	)
 
 	id := ctx.Param("id")
-	results, _, err = h.assetsItc.Select(context.Background(), ports.SelectAssetsItcParams{
+	results, _, err = h.assetsItc.Select(ctx.Request().Context(), ports.SelectAssetsItcParams{
 		Ids:   []string{id},
 		Limit: 1,
 	})
@@ -63,19 +86,20 @@ func (h *Handler) HandleSelectOne(ctx echo.Context) (err error) {
 
 func (h *Handler) HandleSelectMany(ctx echo.Context) (err error) {
 
-	var cursor string
 	var nextCursor string
 	var results []assets_dm.AssetEntity
 
+	var selectParams ports.SelectAssetsItcParams
+	if err = (&echo.DefaultBinder{}).BindQueryParams(ctx, &selectParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
 	h.logger.Info("assets_hl.HandleSelectMany() performed",
+		"request", selectParams,
 		"results", results,
 	)
 
-	cursor, limit := parseCursorAndLimit(ctx)
-	results, nextCursor, err = h.assetsItc.Select(context.Background(), ports.SelectAssetsItcParams{
-		Cursor: cursor,
-		Limit:  limit,
-	})
+	results, nextCursor, err = h.assetsItc.Select(ctx.Request().Context(), selectParams)
 
 	if err != nil && errors.Is(err, errs.ValidationError) {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
@@ -102,10 +126,14 @@ func (h *Handler) HandleInsert(ctx echo.Context) (err error) {
 		"results", results,
 	)
 
-	results, err = h.assetsItc.Insert(context.Background(), insertParams)
+	results, err = h.assetsItc.Insert(ctx.Request().Context(), insertParams)
 
 	if err != nil && errors.Is(err, errs.ValidationError) {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	} else if err != nil && errors.Is(err, errs.AuthenticationError) {
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	} else if err != nil && errors.Is(err, errs.PermissionError) {
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
 	} else if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	} else if len(results) == 0 {
@@ -129,12 +157,16 @@ func (h *Handler) HandleUpdate(ctx echo.Context) (err error) {
 		"results", results,
 	)
 
-	results, err = h.assetsItc.Update(context.Background(), updateParams)
+	results, err = h.assetsItc.Update(ctx.Request().Context(), updateParams)
 
-	if len(results) == 0 {
-		return echo.NewHTTPError(http.StatusNotFound, "record cannot be found")
-	} else if err != nil && errors.Is(err, errs.ValidationError) {
+	if err != nil && errors.Is(err, errs.ValidationError) {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	} else if err != nil && errors.Is(err, errs.AuthenticationError) {
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	} else if err != nil && errors.Is(err, errs.PermissionError) {
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	} else if len(results) == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, "record cannot be found")
 	} else if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
@@ -153,7 +185,7 @@ func (h *Handler) HandleDelete(ctx echo.Context) (err error) {
 	)
 
 	id := ctx.Param("id")
-	results, err = h.assetsItc.Delete(context.Background(), ports.DeleteAssetItcParams{
+	results, err = h.assetsItc.Delete(ctx.Request().Context(), ports.DeleteAssetItcParams{
 		Id: id,
 	})
 
@@ -161,6 +193,10 @@ func (h *Handler) HandleDelete(ctx echo.Context) (err error) {
 		return echo.NewHTTPError(http.StatusNotFound, "record cannot be found")
 	} else if err != nil && errors.Is(err, errs.ValidationError) {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	} else if err != nil && errors.Is(err, errs.AuthenticationError) {
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	} else if err != nil && errors.Is(err, errs.PermissionError) {
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
 	} else if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
@@ -168,6 +204,284 @@ func (h *Handler) HandleDelete(ctx echo.Context) (err error) {
 	return ctx.JSON(http.StatusOK, results[0])
 }
 
+func (h *Handler) HandleSelectVersions(ctx echo.Context) (err error) {
+
+	cursor, limit := parseCursorAndLimit(ctx)
+	selectParams := ports.SelectAssetVersionsItcParams{
+		AssetId: ctx.Param("id"),
+		Cursor:  cursor,
+		Limit:   limit,
+	}
+
+	h.logger.Info("assets_hl.HandleSelectVersions() performed",
+		"request", selectParams,
+	)
+
+	results, nextCursor, err := h.assetsItc.SelectVersions(ctx.Request().Context(), selectParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"versions": results,
+		"cursor":   nextCursor,
+	})
+}
+
+func (h *Handler) HandleDiffVersions(ctx echo.Context) (err error) {
+
+	var diffParams ports.DiffAssetVersionsItcParams
+	if err = (&echo.DefaultBinder{}).BindQueryParams(ctx, &diffParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	diffParams.AssetId = ctx.Param("id")
+
+	h.logger.Info("assets_hl.HandleDiffVersions() performed",
+		"request", diffParams,
+	)
+
+	results, err := h.assetsItc.DiffVersions(ctx.Request().Context(), diffParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"changes": results,
+	})
+}
+
+func (h *Handler) HandleRestoreVersion(ctx echo.Context) (err error) {
+
+	number, err := strconv.Atoi(ctx.Param("number"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "version number has to be an integer")
+	}
+
+	restoreParams := ports.RestoreAssetVersionItcParams{
+		AssetId: ctx.Param("id"),
+		Number:  number,
+	}
+
+	h.logger.Info("assets_hl.HandleRestoreVersion() performed",
+		"request", restoreParams,
+	)
+
+	result, err := h.assetsItc.RestoreVersion(ctx.Request().Context(), restoreParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) HandleSearch(ctx echo.Context) (err error) {
+
+	var searchParams ports.SearchAssetsItcParams
+	if err = (&echo.DefaultBinder{}).BindQueryParams(ctx, &searchParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	h.logger.Info("assets_hl.HandleSearch() performed",
+		"request", searchParams,
+	)
+
+	results, err := h.assetsItc.Search(ctx.Request().Context(), searchParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"results": results,
+	})
+}
+
+func (h *Handler) HandleRender(ctx echo.Context) (err error) {
+
+	var renderParams ports.RenderAssetItcParams
+	if err = (&echo.DefaultBinder{}).BindQueryParams(ctx, &renderParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	renderParams.Id = ctx.Param("id")
+
+	h.logger.Info("assets_hl.HandleRender() performed",
+		"request", renderParams,
+	)
+
+	result, err := h.assetsItc.Render(ctx.Request().Context(), renderParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.Blob(http.StatusOK, contentTypes[result.Format], result.Data)
+}
+
+func (h *Handler) HandleUpdateTags(ctx echo.Context) (err error) {
+
+	var updateParams ports.UpdateAssetTagsItcParams
+	if err = ctx.Bind(&updateParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	updateParams.Id = ctx.Param("id")
+
+	h.logger.Info("assets_hl.HandleUpdateTags() performed",
+		"request", updateParams,
+	)
+
+	results, err := h.assetsItc.UpdateTags(ctx.Request().Context(), updateParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, results[0])
+}
+
+func (h *Handler) HandleSelectTags(ctx echo.Context) (err error) {
+
+	h.logger.Info("assets_hl.HandleSelectTags() performed")
+
+	results, err := h.assetsItc.SelectTags(ctx.Request().Context())
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"tags": results,
+	})
+}
+
+func (h *Handler) HandleTransferOwnership(ctx echo.Context) (err error) {
+
+	var transferParams ports.TransferAssetOwnershipItcParams
+	if err = ctx.Bind(&transferParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	transferParams.Id = ctx.Param("id")
+
+	h.logger.Info("assets_hl.HandleTransferOwnership() performed",
+		"request", transferParams,
+	)
+
+	result, err := h.assetsItc.TransferOwnership(ctx.Request().Context(), transferParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) HandleSelectGrants(ctx echo.Context) (err error) {
+
+	selectParams := ports.SelectAssetGrantsItcParams{
+		AssetId: ctx.Param("id"),
+	}
+
+	h.logger.Info("assets_hl.HandleSelectGrants() performed",
+		"request", selectParams,
+	)
+
+	results, err := h.assetsItc.SelectGrants(ctx.Request().Context(), selectParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"grants": results,
+	})
+}
+
+func (h *Handler) HandleInsertGrant(ctx echo.Context) (err error) {
+
+	var insertParams ports.InsertAssetGrantItcParams
+	if err = ctx.Bind(&insertParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	insertParams.AssetId = ctx.Param("id")
+
+	h.logger.Info("assets_hl.HandleInsertGrant() performed",
+		"request", insertParams,
+	)
+
+	result, err := h.assetsItc.InsertGrant(ctx.Request().Context(), insertParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) HandleDeleteGrant(ctx echo.Context) (err error) {
+
+	deleteParams := ports.DeleteAssetGrantItcParams{
+		AssetId: ctx.Param("id"),
+		GrantId: ctx.Param("grant_id"),
+	}
+
+	h.logger.Info("assets_hl.HandleDeleteGrant() performed",
+		"request", deleteParams,
+	)
+
+	result, err := h.assetsItc.DeleteGrant(ctx.Request().Context(), deleteParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) HandleSelectTrash(ctx echo.Context) (err error) {
+
+	cursor, limit := parseCursorAndLimit(ctx)
+	selectParams := ports.SelectTrashItcParams{
+		Cursor: cursor,
+		Limit:  limit,
+	}
+
+	h.logger.Info("assets_hl.HandleSelectTrash() performed",
+		"request", selectParams,
+	)
+
+	results, nextCursor, err := h.assetsItc.SelectTrash(ctx.Request().Context(), selectParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"assets": results,
+		"cursor": nextCursor,
+	})
+}
+
+func (h *Handler) HandleRestore(ctx echo.Context) (err error) {
+
+	restoreParams := ports.RestoreAssetItcParams{
+		Id: ctx.Param("id"),
+	}
+
+	h.logger.Info("assets_hl.HandleRestore() performed",
+		"request", restoreParams,
+	)
+
+	results, err := h.assetsItc.Restore(ctx.Request().Context(), restoreParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, results[0])
+}
+
 func parseCursorAndLimit(ctx echo.Context) (cursor string, limit int) {
 	var err error
 
@@ -179,3 +493,19 @@ func parseCursorAndLimit(ctx echo.Context) (cursor string, limit int) {
 
 	return cursor, limit
 }
+
+func mapError(err error) error {
+	if err != nil && errors.Is(err, errs.ValidationError) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	} else if err != nil && errors.Is(err, errs.AuthenticationError) {
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	} else if err != nil && errors.Is(err, errs.PermissionError) {
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	} else if err != nil && errors.Is(err, errs.CannotBeFoundError) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return nil
+}
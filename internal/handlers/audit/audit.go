@@ -0,0 +1,67 @@
+package audit_hl
+
+import (
+	"assets/internal/core/ports"
+	auth_hl "assets/internal/handlers/auth"
+	errs "assets/pkg/errors"
+	"assets/pkg/logging"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type Handler struct {
+	webServer *echo.Echo
+	logger    logging.Logger
+	auditItc  ports.AuditInteractor
+}
+
+func Init(webServer *echo.Echo, logger logging.Logger, interactor ports.AuditInteractor, authMiddleware echo.MiddlewareFunc) *Handler {
+
+	instance := &Handler{
+		webServer: webServer,
+		logger:    logger,
+		auditItc:  interactor,
+	}
+
+	instance.webServer.GET("/api/audit", instance.HandleSelectMany, authMiddleware, auth_hl.RequireSession)
+
+	return instance
+}
+
+func (h *Handler) HandleSelectMany(ctx echo.Context) (err error) {
+
+	var selectParams ports.SelectAuditItcParams
+	if err = (&echo.DefaultBinder{}).BindQueryParams(ctx, &selectParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	h.logger.Info("audit_hl.HandleSelectMany() performed",
+		"request", selectParams,
+	)
+
+	results, cursor, err := h.auditItc.Select(ctx.Request().Context(), selectParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"entries": results,
+		"cursor":  cursor,
+	})
+}
+
+func mapError(err error) error {
+	if err != nil && errors.Is(err, errs.ValidationError) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	} else if err != nil && errors.Is(err, errs.AuthenticationError) {
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	} else if err != nil && errors.Is(err, errs.PermissionError) {
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return nil
+}
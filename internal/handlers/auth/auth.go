@@ -0,0 +1,260 @@
+package auth_hl
+
+import (
+	keys_dm "assets/internal/core/domain/keys"
+	"assets/internal/core/ports"
+	errs "assets/pkg/errors"
+	"assets/pkg/identity"
+	"assets/pkg/logging"
+	"errors"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gorilla/sessions"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	SessionName = "session-name"
+	TokenKey    = "token"
+
+	ClaimUserId    = "userId"
+	ClaimEmail     = "email"
+	ClaimRoles     = "roles"
+	ClaimSessionId = "sid"
+	ClaimIssuedAt  = "iat"
+	ClaimExpiry    = "exp"
+)
+
+var errTokenExpired = errors.New("token has expired")
+
+type Authenticator struct {
+	logger      logging.Logger
+	cookieStore ports.SessionsRepository
+	keysItc     ports.ApiKeysInteractor
+	usersRepo   ports.UsersRepository
+	secret      string
+	lifetime    time.Duration
+}
+
+// NewAuthenticator creates authenticator which issues access tokens valid for 'lifetime', tokens kept in session
+// cookie are renewed on activity for as long as the session lives and its user stays active. Api keys are accepted
+// in place of tokens.
+func NewAuthenticator(logger logging.Logger, cookieStore ports.SessionsRepository, keysItc ports.ApiKeysInteractor, usersRepo ports.UsersRepository, secret string, lifetime time.Duration) *Authenticator {
+	return &Authenticator{
+		logger:      logger,
+		cookieStore: cookieStore,
+		keysItc:     keysItc,
+		usersRepo:   usersRepo,
+		secret:      secret,
+		lifetime:    lifetime,
+	}
+}
+
+// Authenticate verifies token or api key attached to the request and puts caller identity into request context.
+func (a *Authenticator) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) (err error) {
+
+		var tokenString string
+		var session *sessions.Session
+		if tokenString, session, err = a.extractToken(ctx); err != nil {
+			return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+		}
+
+		if session == nil && strings.HasPrefix(tokenString, keys_dm.Prefix) {
+			return a.authenticateKey(ctx, tokenString, next)
+		}
+
+		var claims jwt.MapClaims
+		if claims, err = a.parseToken(tokenString); errors.Is(err, errTokenExpired) && session != nil && !session.IsNew {
+			// session outlives access token, token of the live session gets reissued below
+		} else if err != nil {
+			a.logger.Info("auth_hl.Authenticate() rejected token", "err", err)
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid authentication token")
+		}
+
+		userId, _ := claims[ClaimUserId].(string)
+		sessionId, _ := claims[ClaimSessionId].(string)
+
+		if userId == "" || sessionId == "" {
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid authentication token")
+		}
+
+		if err = a.verifySession(ctx, session, sessionId); err != nil {
+			return err
+		}
+
+		if session != nil {
+			if claims, err = a.renew(ctx, session, claims); err != nil {
+				return err
+			}
+		}
+
+		email, _ := claims[ClaimEmail].(string)
+
+		var roles []string
+		switch values := claims[ClaimRoles].(type) {
+		case []string:
+			roles = values
+		case []interface{}:
+			for _, value := range values {
+				if role, ok := value.(string); ok {
+					roles = append(roles, role)
+				}
+			}
+		}
+
+		request := ctx.Request()
+		ctx.SetRequest(request.WithContext(identity.WithIdentity(request.Context(), identity.Identity{
+			UserId:    userId,
+			Email:     email,
+			Roles:     roles,
+			SessionId: sessionId,
+		})))
+
+		return next(ctx)
+	}
+}
+
+// RequireScope rejects callers authenticated with api key which doesn't grant the scope.
+func RequireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+
+			caller, err := Identity(ctx)
+			if err != nil {
+				return err
+			}
+
+			if !caller.Allows(scope) {
+				return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("api key is missing '%s' scope", scope))
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// RequireSession rejects callers authenticated with api key, it guards account management which keys should never
+// be able to perform.
+func RequireSession(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+
+		caller, err := Identity(ctx)
+		if err != nil {
+			return err
+		}
+
+		if caller.ApiKeyId != "" {
+			return echo.NewHTTPError(http.StatusForbidden, "endpoint cannot be used with api key")
+		}
+
+		return next(ctx)
+	}
+}
+
+// Identity returns identity of the caller attached to the request by Authenticate middleware.
+func Identity(ctx echo.Context) (identity.Identity, error) {
+	if caller, ok := identity.FromContext(ctx.Request().Context()); ok && caller.UserId != "" {
+		return caller, nil
+	}
+
+	return identity.Identity{}, echo.NewHTTPError(http.StatusUnauthorized, "missing caller identity")
+}
+
+func (a *Authenticator) authenticateKey(ctx echo.Context, key string, next echo.HandlerFunc) error {
+
+	result, user, err := a.keysItc.Authenticate(ctx.Request().Context(), ports.AuthenticateApiKeyItcParams{Key: key})
+
+	if err != nil && (errors.Is(err, errs.AuthenticationError) || errors.Is(err, errs.ValidationError)) {
+		return echo.NewHTTPError(http.StatusUnauthorized, "invalid api key")
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	request := ctx.Request()
+	ctx.SetRequest(request.WithContext(identity.WithIdentity(request.Context(), identity.Identity{
+		UserId:   user.Id,
+		Email:    user.Email,
+		Roles:    user.Roles,
+		ApiKeyId: result.Id,
+		Scopes:   result.Scopes,
+	})))
+
+	return next(ctx)
+}
+
+func (a *Authenticator) extractToken(ctx echo.Context) (string, *sessions.Session, error) {
+
+	if header := ctx.Request().Header.Get(echo.HeaderAuthorization); header != "" {
+		scheme, token, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			return "", nil, errors.New("malformed authorization header")
+		}
+		return token, nil, nil
+	}
+
+	session, err := a.cookieStore.Get(ctx.Request(), SessionName)
+	if err != nil {
+		return "", nil, errors.New("failed to load session")
+	}
+
+	if token, ok := session.Values[TokenKey].(string); ok && token != "" {
+		return token, session, nil
+	}
+
+	return "", nil, errors.New("missing authentication token")
+}
+
+// verifySession rejects tokens which belong to logged out or revoked sessions. Session loaded from the cookie was
+// already read from the database, so additional lookup is needed only for tokens sent in the header.
+func (a *Authenticator) verifySession(ctx echo.Context, session *sessions.Session, sessionId string) error {
+
+	if session != nil && !session.IsNew && session.ID == sessionId {
+		return nil
+	}
+
+	exists, err := a.cookieStore.Exists(ctx.Request().Context(), sessionId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	} else if !exists {
+		return echo.NewHTTPError(http.StatusUnauthorized, "session has been revoked")
+	}
+
+	return nil
+}
+
+// parseToken verifies signature and expiration of the token. Claims of the token which expired but is otherwise valid
+// are returned along with errTokenExpired, so that token of the live session can be reissued.
+func (a *Authenticator) parseToken(tokenString string) (jwt.MapClaims, error) {
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(a.secret), nil
+	})
+	var validationErr *jwt.ValidationError
+	if errors.As(err, &validationErr) && validationErr.Errors == jwt.ValidationErrorExpired {
+		if claims, ok := token.Claims.(jwt.MapClaims); ok {
+			return claims, errTokenExpired
+		}
+	}
+	if err != nil {
+		return nil, errors.Join(errs.AuthenticationError, err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.Join(errs.AuthenticationError, errors.New("invalid token claims"))
+	}
+
+	// tokens without expiration pass validation of the library, they are never issued here
+	if _, ok = claims[ClaimExpiry]; !ok {
+		return nil, errors.Join(errs.AuthenticationError, errors.New("token has no expiration time"))
+	}
+
+	return claims, nil
+}
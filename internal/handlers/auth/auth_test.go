@@ -0,0 +1,430 @@
+package auth_hl
+
+import (
+	keys_dm "assets/internal/core/domain/keys"
+	users_dm "assets/internal/core/domain/users"
+	keys_itc "assets/internal/core/interactors/keys"
+	"assets/internal/core/ports"
+	keys_db "assets/internal/repositories/keys"
+	sessions_db "assets/internal/repositories/sessions"
+	users_db "assets/internal/repositories/users"
+	"assets/pkg/identity"
+	"assets/pkg/logging"
+	"assets/pkg/validation"
+	"context"
+	"errors"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const testSecret = "test-secret"
+
+type AuthenticatorSuite struct {
+	suite.Suite
+	authenticator *Authenticator
+	cookieStore   ports.SessionsRepository
+	usersRepo     ports.UsersRepository
+	keysItc       ports.ApiKeysInteractor
+}
+
+func TestAuthenticatorSuite(t *testing.T) {
+	suite.Run(t, new(AuthenticatorSuite))
+}
+
+/*
+* Tests
+ */
+
+/// Authenticate
+
+func (suite *AuthenticatorSuite) TestAuthenticateShouldAcceptSessionCookie() {
+
+	user := suite.setupSampleUser()
+	cookie, _ := suite.setupSession(user)
+
+	caller, err := suite.perform(func(request *http.Request) { request.AddCookie(cookie) })
+
+	suite.Nil(err, "should return empty error")
+	suite.Equal(user.Id, caller.UserId, "should identify owner of the session")
+	suite.Equal(user.Roles, caller.Roles, "should carry roles of the user")
+	suite.NotEmpty(caller.SessionId, "should carry id of the session")
+}
+
+func (suite *AuthenticatorSuite) TestAuthenticateShouldAcceptBearerToken() {
+
+	user := suite.setupSampleUser()
+	_, tokenString := suite.setupSession(user)
+
+	caller, err := suite.perform(func(request *http.Request) {
+		request.Header.Set(echo.HeaderAuthorization, "Bearer "+tokenString)
+	})
+
+	suite.Nil(err, "should return empty error")
+	suite.Equal(user.Id, caller.UserId, "should identify owner of the token")
+}
+
+func (suite *AuthenticatorSuite) TestAuthenticateShouldAcceptApiKey() {
+
+	user := suite.setupSampleUser()
+	key, err := suite.keysItc.Insert(context.Background(), ports.InsertApiKeyItcParams{
+		UserId: user.Id,
+		Name:   "etl",
+		Scopes: []keys_dm.Scope{keys_dm.ScopeAssetsRead},
+	})
+	suite.Nil(err)
+
+	caller, err := suite.perform(func(request *http.Request) {
+		request.Header.Set(echo.HeaderAuthorization, "Bearer "+key.Key)
+	})
+
+	suite.Nil(err, "should return empty error")
+	suite.Equal(user.Id, caller.UserId, "should identify owner of the key")
+	suite.Equal(key.Id, caller.ApiKeyId, "should carry id of the key")
+}
+
+func (suite *AuthenticatorSuite) TestAuthenticateShouldRejectInvalidCredentials() {
+
+	user := suite.setupSampleUser()
+	_, tokenString := suite.setupSession(user)
+
+	headers := []string{
+		"",
+		"Bearer",
+		"Basic " + tokenString,
+		"Bearer fooBar",
+		"Bearer " + tokenString + "fooBar",
+		"Bearer " + keys_dm.Prefix + uuid.NewString() + ".fooBar",
+	}
+
+	for _, header := range headers {
+		_, err := suite.perform(func(request *http.Request) {
+			if header != "" {
+				request.Header.Set(echo.HeaderAuthorization, header)
+			}
+		})
+
+		suite.AssertStatus(http.StatusUnauthorized, err)
+	}
+}
+
+func (suite *AuthenticatorSuite) TestParseTokenShouldReportCauseOfRejection() {
+
+	user := suite.setupSampleUser()
+
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{ClaimUserId: user.Id}).SignedString([]byte("fooBar"))
+	suite.Nil(err)
+
+	_, err = suite.authenticator.parseToken(forged)
+	suite.ErrorContains(err, "failed to authenticate")
+	suite.ErrorContains(err, "signature is invalid", "should carry error of the validation")
+
+	unlimited, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{ClaimUserId: user.Id}).SignedString([]byte(testSecret))
+	suite.Nil(err)
+
+	_, err = suite.authenticator.parseToken(unlimited)
+	suite.ErrorContains(err, "failed to authenticate")
+	suite.ErrorContains(err, "token has no expiration time")
+}
+
+func (suite *AuthenticatorSuite) TestAuthenticateShouldRejectExpiredBearerToken() {
+
+	user := suite.setupSampleUser()
+	_, tokenString := suite.setupSession(user)
+
+	claims, _ := suite.authenticator.parseToken(tokenString)
+	expired := suite.signAt(claims, time.Now().Add(-2*suite.authenticator.Lifetime()))
+
+	_, err := suite.perform(func(request *http.Request) {
+		request.Header.Set(echo.HeaderAuthorization, "Bearer "+expired)
+	})
+
+	suite.AssertStatus(http.StatusUnauthorized, err)
+}
+
+func (suite *AuthenticatorSuite) TestAuthenticateShouldRejectRevokedSession() {
+
+	user := suite.setupSampleUser()
+	cookie, tokenString := suite.setupSession(user)
+
+	if err := suite.cookieStore.RevokeUsers(context.Background(), user.Id); err != nil {
+		panic(err)
+	}
+
+	_, err := suite.perform(func(request *http.Request) {
+		request.Header.Set(echo.HeaderAuthorization, "Bearer "+tokenString)
+	})
+	suite.AssertStatus(http.StatusUnauthorized, err)
+
+	_, err = suite.perform(func(request *http.Request) { request.AddCookie(cookie) })
+	suite.AssertStatus(http.StatusUnauthorized, err)
+}
+
+/// renew
+
+func (suite *AuthenticatorSuite) TestAuthenticateShouldNotRenewFreshToken() {
+
+	user := suite.setupSampleUser()
+	cookie, tokenString := suite.setupSession(user)
+
+	_, err := suite.perform(func(request *http.Request) { request.AddCookie(cookie) })
+
+	suite.Nil(err, "should return empty error")
+	suite.Equal(tokenString, suite.sessionToken(cookie), "fresh token should be kept")
+}
+
+func (suite *AuthenticatorSuite) TestAuthenticateShouldRenewExpiredTokenOfLiveSession() {
+
+	user := suite.setupSampleUser()
+	cookie := suite.setupAgedSession(user, 2*suite.authenticator.Lifetime())
+	agedToken := suite.sessionToken(cookie)
+
+	caller, err := suite.perform(func(request *http.Request) { request.AddCookie(cookie) })
+
+	suite.Nil(err, "should return empty error")
+	suite.Equal(user.Id, caller.UserId, "should identify owner of the session")
+
+	renewedToken := suite.sessionToken(cookie)
+	suite.NotEqual(agedToken, renewedToken, "token should be reissued")
+
+	claims, err := suite.authenticator.parseToken(renewedToken)
+	suite.Nil(err, "reissued token should be valid")
+	suite.Equal(caller.SessionId, claims[ClaimSessionId], "reissued token should stay within the session")
+}
+
+func (suite *AuthenticatorSuite) TestAuthenticateShouldReloadUserWhenRenewing() {
+
+	user := suite.setupSampleUser()
+	cookie := suite.setupAgedSession(user, suite.authenticator.Lifetime())
+
+	user.Roles = []users_dm.Role{users_dm.RoleViewer, users_dm.RoleEditor}
+	if _, err := suite.usersRepo.Update(context.Background(), user); err != nil {
+		panic(err)
+	}
+
+	caller, err := suite.perform(func(request *http.Request) { request.AddCookie(cookie) })
+
+	suite.Nil(err, "should return empty error")
+	suite.Equal(user.Roles, caller.Roles, "should carry current roles of the user")
+
+	claims, _ := suite.authenticator.parseToken(suite.sessionToken(cookie))
+	suite.Len(claims[ClaimRoles], 2, "reissued token should carry current roles of the user")
+}
+
+func (suite *AuthenticatorSuite) TestAuthenticateShouldEndSessionWhenUserIsDisabled() {
+
+	user := suite.setupSampleUser()
+	cookie := suite.setupAgedSession(user, suite.authenticator.Lifetime())
+
+	user.Disabled = true
+	if _, err := suite.usersRepo.Update(context.Background(), user); err != nil {
+		panic(err)
+	}
+
+	_, err := suite.perform(func(request *http.Request) { request.AddCookie(cookie) })
+	suite.AssertStatus(http.StatusUnauthorized, err)
+
+	exists, _ := suite.cookieStore.Exists(context.Background(), cookie.Value)
+	suite.False(exists, "session of disabled user should be removed")
+}
+
+func (suite *AuthenticatorSuite) TestAuthenticateShouldEndSessionWhenUserIsRemoved() {
+
+	user := suite.setupSampleUser()
+	cookie := suite.setupAgedSession(user, suite.authenticator.Lifetime())
+
+	if _, err := suite.usersRepo.Delete(context.Background(), user); err != nil {
+		panic(err)
+	}
+
+	_, err := suite.perform(func(request *http.Request) { request.AddCookie(cookie) })
+	suite.AssertStatus(http.StatusUnauthorized, err)
+
+	exists, _ := suite.cookieStore.Exists(context.Background(), cookie.Value)
+	suite.False(exists, "session of removed user should be removed")
+}
+
+/// EndSession
+
+func (suite *AuthenticatorSuite) TestEndSessionShouldRevokeSession() {
+
+	user := suite.setupSampleUser()
+	cookie, tokenString := suite.setupSession(user)
+
+	request := httptest.NewRequest(http.MethodPost, "/", nil)
+	request.AddCookie(cookie)
+	ctx := echo.New().NewContext(request, httptest.NewRecorder())
+
+	err := suite.authenticator.Authenticate(suite.authenticator.EndSession)(ctx)
+	suite.Nil(err, "should return empty error")
+
+	_, err = suite.perform(func(request *http.Request) {
+		request.Header.Set(echo.HeaderAuthorization, "Bearer "+tokenString)
+	})
+	suite.AssertStatus(http.StatusUnauthorized, err)
+}
+
+/// StartSession
+
+func (suite *AuthenticatorSuite) TestStartSessionShouldContinueProvidedSession() {
+
+	user := suite.setupSampleUser()
+	_, tokenString := suite.setupSession(user)
+	claims, _ := suite.authenticator.parseToken(tokenString)
+	sessionId := claims[ClaimSessionId].(string)
+
+	other, _ := suite.setupSession(user)
+
+	request := httptest.NewRequest(http.MethodPost, "/", nil)
+	request.AddCookie(other)
+	ctx := echo.New().NewContext(request, httptest.NewRecorder())
+
+	renewedString, id, err := suite.authenticator.StartSession(ctx, user, sessionId)
+	suite.Nil(err, "should return empty error")
+	suite.Equal(sessionId, id, "should continue provided session")
+
+	renewed, _ := suite.authenticator.parseToken(renewedString)
+	suite.Equal(sessionId, renewed[ClaimSessionId], "token should belong to continued session")
+
+	_, err = suite.perform(func(request *http.Request) {
+		request.Header.Set(echo.HeaderAuthorization, "Bearer "+renewedString)
+	})
+	suite.Nil(err, "token of continued session should be accepted")
+
+	exists, err := suite.cookieStore.Exists(context.Background(), other.Value)
+	suite.Nil(err)
+	suite.False(exists, "session the cookie pointed to should be revoked")
+}
+
+/*
+* Setup
+ */
+
+func (suite *AuthenticatorSuite) SetupAuthenticator() {
+	logger := logging.NewDefaultLogger()
+	validator := validation.NewDefaultValidator()
+
+	suite.cookieStore = sessions_db.NewMemoryRepo()
+	suite.usersRepo = users_db.NewMemoryRepo()
+	suite.keysItc = keys_itc.NewInteractor(logger, validator, keys_db.NewMemoryRepo(), suite.usersRepo, true)
+
+	suite.authenticator = NewAuthenticator(logger, suite.cookieStore, suite.keysItc, suite.usersRepo, testSecret, time.Hour)
+}
+
+func (suite *AuthenticatorSuite) SetupSuite() {
+	println("SetupSuite")
+}
+
+func (suite *AuthenticatorSuite) SetupTest() {
+	println("SetupTest")
+	suite.SetupAuthenticator()
+}
+
+func (suite *AuthenticatorSuite) setupSampleUser() (model users_dm.UserEntity) {
+
+	model = users_dm.NewUserEntity()
+	model.Email = uuid.NewString() + "@test.com"
+	model.Password = "test123"
+	model.Roles = []users_dm.Role{users_dm.RoleViewer}
+	model.Verified = true
+
+	if _, err := suite.usersRepo.Insert(context.Background(), model); err != nil {
+		panic(err)
+	}
+
+	return model
+}
+
+// setupSession starts session of the user and returns its cookie together with issued token.
+func (suite *AuthenticatorSuite) setupSession(user users_dm.UserEntity) (*http.Cookie, string) {
+
+	recorder := httptest.NewRecorder()
+	ctx := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), recorder)
+
+	tokenString, _, err := suite.authenticator.StartSession(ctx, user, "")
+	if err != nil {
+		panic(err)
+	}
+
+	return recorder.Result().Cookies()[0], tokenString
+}
+
+// setupAgedSession starts session of the user which token was issued 'age' ago.
+func (suite *AuthenticatorSuite) setupAgedSession(user users_dm.UserEntity, age time.Duration) *http.Cookie {
+
+	cookie, tokenString := suite.setupSession(user)
+	claims, _ := suite.authenticator.parseToken(tokenString)
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.AddCookie(cookie)
+
+	session, err := suite.cookieStore.Get(request, SessionName)
+	if err != nil {
+		panic(err)
+	}
+
+	session.Values[TokenKey] = suite.signAt(claims, time.Now().Add(-age))
+	if err = session.Save(request, httptest.NewRecorder()); err != nil {
+		panic(err)
+	}
+
+	return cookie
+}
+
+func (suite *AuthenticatorSuite) sessionToken(cookie *http.Cookie) string {
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.AddCookie(cookie)
+
+	session, err := suite.cookieStore.Get(request, SessionName)
+	if err != nil {
+		panic(err)
+	}
+
+	tokenString, _ := session.Values[TokenKey].(string)
+	return tokenString
+}
+
+func (suite *AuthenticatorSuite) signAt(claims jwt.MapClaims, issuedAt time.Time) string {
+
+	signed := make(jwt.MapClaims)
+	for k, v := range claims {
+		signed[k] = v
+	}
+	signed[ClaimIssuedAt] = issuedAt.Unix()
+	signed[ClaimExpiry] = issuedAt.Add(suite.authenticator.Lifetime()).Unix()
+
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, signed).SignedString([]byte(testSecret))
+	if err != nil {
+		panic(err)
+	}
+
+	return tokenString
+}
+
+// perform runs request prepared by 'prepare' through Authenticate and returns identity seen by the next handler.
+func (suite *AuthenticatorSuite) perform(prepare func(request *http.Request)) (caller identity.Identity, err error) {
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	prepare(request)
+	ctx := echo.New().NewContext(request, httptest.NewRecorder())
+
+	err = suite.authenticator.Authenticate(func(ctx echo.Context) (err error) {
+		caller, err = Identity(ctx)
+		return err
+	})(ctx)
+
+	return caller, err
+}
+
+func (suite *AuthenticatorSuite) AssertStatus(status int, err error) {
+	var httpErr *echo.HTTPError
+	if suite.True(errors.As(err, &httpErr), "should return http error") {
+		suite.Equal(status, httpErr.Code)
+	}
+}
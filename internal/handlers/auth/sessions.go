@@ -0,0 +1,151 @@
+package auth_hl
+
+import (
+	users_dm "assets/internal/core/domain/users"
+	"assets/internal/core/ports"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gorilla/sessions"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"time"
+)
+
+// StartSession issues token for the user and stores it in the session cookie, id of the session is returned along with
+// the token. Session with provided 'sessionId' is continued in place, so refreshing the token doesn't leave previous
+// session behind.
+func (a *Authenticator) StartSession(ctx echo.Context, user users_dm.UserEntity, sessionId string) (tokenString string, id string, err error) {
+
+	session, err := a.cookieStore.Get(ctx.Request(), SessionName)
+	if err != nil {
+		return "", "", echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	// the cookie is moved over to the continued session, so session it pointed to so far is revoked
+	if sessionId != "" && sessionId != session.ID {
+		if !session.IsNew {
+			if err = a.cookieStore.Revoke(ctx.Request().Context(), session.ID); err != nil {
+				return "", "", echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+			}
+		}
+
+		session.ID = sessionId
+		session.IsNew = false
+		session.Values = make(map[interface{}]interface{})
+	}
+
+	if tokenString, err = a.sign(jwt.MapClaims{
+		ClaimUserId:    user.Id,
+		ClaimEmail:     user.Email,
+		ClaimRoles:     user.Roles,
+		ClaimSessionId: session.ID,
+	}); err != nil {
+		return "", "", echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	session.Values[TokenKey] = tokenString
+	session.Values[ports.SessionUserIdKey] = user.Id
+	if err = session.Save(ctx.Request(), ctx.Response().Writer); err != nil {
+		return "", "", echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return tokenString, session.ID, nil
+}
+
+// EndSession removes session of the caller and expires session cookie.
+func (a *Authenticator) EndSession(ctx echo.Context) (err error) {
+
+	caller, err := Identity(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = a.cookieStore.Revoke(ctx.Request().Context(), caller.SessionId); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	session, err := a.cookieStore.Get(ctx.Request(), SessionName)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	session.Options.MaxAge = -1
+	if err = session.Save(ctx.Request(), ctx.Response().Writer); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// RevokeSessions removes all sessions of provided users.
+func (a *Authenticator) RevokeSessions(ctx echo.Context, userIds ...string) (err error) {
+
+	if err = a.cookieStore.RevokeUsers(ctx.Request().Context(), userIds...); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// Lifetime returns how long issued access tokens are valid.
+func (a *Authenticator) Lifetime() time.Duration {
+	return a.lifetime
+}
+
+// renew extends session which is actively used. Token is reissued once half of its lifetime has passed, saving the
+// session moves expiration of both database record and the cookie. Claims are rebuilt from the current state of the
+// user, so role changes reach live sessions and sessions of removed or disabled users end instead of being renewed.
+func (a *Authenticator) renew(ctx echo.Context, session *sessions.Session, claims jwt.MapClaims) (jwt.MapClaims, error) {
+
+	issuedAt, _ := claims[ClaimIssuedAt].(float64)
+	if time.Since(time.Unix(int64(issuedAt), 0)) < a.lifetime/2 {
+		return claims, nil
+	}
+
+	userId, _ := claims[ClaimUserId].(string)
+	users, _, err := a.usersRepo.Select(ctx.Request().Context(), ports.SelectUsersRepoParams{Ids: []string{userId}})
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	if len(users) == 0 || users[0].Disabled {
+		if err = a.cookieStore.Revoke(ctx.Request().Context(), session.ID); err != nil {
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+
+		session.Options.MaxAge = -1
+		if err = session.Save(ctx.Request(), ctx.Response().Writer); err != nil {
+			a.logger.Info("auth_hl.renew() failed to expire session", "err", err)
+		}
+
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "session has ended")
+	}
+
+	renewed := jwt.MapClaims{
+		ClaimUserId:    users[0].Id,
+		ClaimEmail:     users[0].Email,
+		ClaimRoles:     users[0].Roles,
+		ClaimSessionId: session.ID,
+	}
+
+	tokenString, err := a.sign(renewed)
+	if err != nil {
+		a.logger.Info("auth_hl.renew() failed to sign token", "err", err)
+		return claims, nil
+	}
+
+	session.Values[TokenKey] = tokenString
+	if err = session.Save(ctx.Request(), ctx.Response().Writer); err != nil {
+		a.logger.Info("auth_hl.renew() failed to save session", "err", err)
+	}
+
+	return renewed, nil
+}
+
+func (a *Authenticator) sign(claims jwt.MapClaims) (string, error) {
+	now := time.Now()
+
+	claims[ClaimIssuedAt] = now.Unix()
+	claims[ClaimExpiry] = now.Add(a.lifetime).Unix()
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(a.secret))
+}
@@ -0,0 +1,173 @@
+package collections_hl
+
+import (
+	collections_dm "assets/internal/core/domain/collections"
+	keys_dm "assets/internal/core/domain/keys"
+	"assets/internal/core/ports"
+	auth_hl "assets/internal/handlers/auth"
+	errs "assets/pkg/errors"
+	"assets/pkg/logging"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type Handler struct {
+	webServer      *echo.Echo
+	logger         logging.Logger
+	collectionsItc ports.CollectionsInteractor
+}
+
+func Init(webServer *echo.Echo, logger logging.Logger, interactor ports.CollectionsInteractor, authMiddleware echo.MiddlewareFunc) *Handler {
+
+	instance := &Handler{
+		webServer:      webServer,
+		logger:         logger,
+		collectionsItc: interactor,
+	}
+
+	readScope := auth_hl.RequireScope(keys_dm.ScopeCollectionsRead)
+	writeScope := auth_hl.RequireScope(keys_dm.ScopeCollectionsWrite)
+
+	instance.webServer.GET("/api/collections", instance.HandleSelectMany, authMiddleware, readScope)
+	instance.webServer.GET("/api/collections/:id", instance.HandleSelectOne, authMiddleware, readScope)
+	instance.webServer.POST("/api/collections", instance.HandleInsert, authMiddleware, writeScope)
+	instance.webServer.PATCH("/api/collections/:id", instance.HandleUpdate, authMiddleware, writeScope)
+	instance.webServer.DELETE("/api/collections/:id", instance.HandleDelete, authMiddleware, writeScope)
+
+	return instance
+}
+
+func (h *Handler) HandleSelectMany(ctx echo.Context) (err error) {
+
+	var nextCursor string
+	var results []collections_dm.CollectionEntity
+
+	var selectParams ports.SelectCollectionsItcParams
+	if err = (&echo.DefaultBinder{}).BindQueryParams(ctx, &selectParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	h.logger.Info("collections_hl.HandleSelectMany() performed",
+		"request", selectParams,
+		"results", results,
+	)
+
+	results, nextCursor, err = h.collectionsItc.Select(ctx.Request().Context(), selectParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"collections": results,
+		"cursor":      nextCursor,
+	})
+}
+
+func (h *Handler) HandleSelectOne(ctx echo.Context) (err error) {
+
+	populateParams := ports.PopulateCollectionItcParams{
+		Id: ctx.Param("id"),
+	}
+
+	h.logger.Info("collections_hl.HandleSelectOne() performed",
+		"request", populateParams,
+	)
+
+	result, err := h.collectionsItc.Populate(ctx.Request().Context(), populateParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) HandleInsert(ctx echo.Context) (err error) {
+	var results []collections_dm.CollectionEntity
+
+	var insertParams ports.InsertCollectionItcParams
+	if err = ctx.Bind(&insertParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	h.logger.Info("collections_hl.HandleInsert() performed",
+		"request", insertParams,
+		"results", results,
+	)
+
+	results, err = h.collectionsItc.Insert(ctx.Request().Context(), insertParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	} else if len(results) == 0 {
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return ctx.JSON(http.StatusCreated, results[0])
+}
+
+func (h *Handler) HandleUpdate(ctx echo.Context) (err error) {
+	var results []collections_dm.CollectionEntity
+
+	var updateParams ports.UpdateCollectionItcParams
+	if err = ctx.Bind(&updateParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	updateParams.Id = ctx.Param("id")
+
+	h.logger.Info("collections_hl.HandleUpdate() performed",
+		"request", updateParams,
+		"results", results,
+	)
+
+	results, err = h.collectionsItc.Update(ctx.Request().Context(), updateParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	} else if len(results) == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, "record cannot be found")
+	}
+
+	return ctx.JSON(http.StatusOK, results[0])
+}
+
+func (h *Handler) HandleDelete(ctx echo.Context) (err error) {
+	var results []collections_dm.CollectionEntity
+
+	deleteParams := ports.DeleteCollectionItcParams{
+		Id: ctx.Param("id"),
+	}
+
+	h.logger.Info("collections_hl.HandleDelete() performed",
+		"request", deleteParams,
+		"results", results,
+	)
+
+	results, err = h.collectionsItc.Delete(ctx.Request().Context(), deleteParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	} else if len(results) == 0 {
+		return echo.NewHTTPError(http.StatusNotFound, "record cannot be found")
+	}
+
+	return ctx.JSON(http.StatusOK, results[0])
+}
+
+func mapError(err error) error {
+	if err != nil && errors.Is(err, errs.ValidationError) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	} else if err != nil && errors.Is(err, errs.AuthenticationError) {
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	} else if err != nil && errors.Is(err, errs.PermissionError) {
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	} else if err != nil && errors.Is(err, errs.CannotBeFoundError) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return nil
+}
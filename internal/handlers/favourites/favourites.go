@@ -2,10 +2,11 @@ package favourites_hl
 
 import (
 	favourites_dm "assets/internal/core/domain/favourites"
+	keys_dm "assets/internal/core/domain/keys"
 	"assets/internal/core/ports"
+	auth_hl "assets/internal/handlers/auth"
 	errs "assets/pkg/errors"
 	"assets/pkg/logging"
-	"context"
 	"errors"
 	"github.com/labstack/echo/v4"
 	"net/http"
@@ -18,7 +19,7 @@ type Handler struct {
 	favouritesItc ports.FavouritesInteractor
 }
 
-func Init(webServer *echo.Echo, logger logging.Logger, interactor ports.FavouritesInteractor) *Handler {
+func Init(webServer *echo.Echo, logger logging.Logger, interactor ports.FavouritesInteractor, authMiddleware echo.MiddlewareFunc) *Handler {
 
 	instance := &Handler{
 		webServer:     webServer,
@@ -26,10 +27,17 @@ func Init(webServer *echo.Echo, logger logging.Logger, interactor ports.Favourit
 		favouritesItc: interactor,
 	}
 
-	instance.webServer.GET("/api/favourites/:id", instance.HandleSelectOne)
-	instance.webServer.GET("/api/favourites/user/:userId", instance.HandleSelectMany)
-	instance.webServer.POST("/api/favourites/add", instance.HandleInsert)
-	instance.webServer.DELETE("/api/favourites/delete/:id", instance.HandleDelete)
+	readScope := auth_hl.RequireScope(keys_dm.ScopeFavouritesRead)
+	writeScope := auth_hl.RequireScope(keys_dm.ScopeFavouritesWrite)
+
+	instance.webServer.GET("/api/favourites/:id", instance.HandleSelectOne, authMiddleware, readScope)
+	instance.webServer.GET("/api/favourites/user/:userId", instance.HandleSelectMany, authMiddleware, readScope)
+	instance.webServer.POST("/api/favourites/add", instance.HandleInsert, authMiddleware, writeScope)
+	instance.webServer.DELETE("/api/favourites/delete/:id", instance.HandleDelete, authMiddleware, writeScope)
+
+	instance.webServer.GET("/api/me/favourites", instance.HandleSelectMany, authMiddleware, readScope)
+	instance.webServer.POST("/api/me/favourites", instance.HandleInsert, authMiddleware, writeScope)
+	instance.webServer.DELETE("/api/me/favourites/:id", instance.HandleDelete, authMiddleware, writeScope)
 
 	return instance
 }
@@ -43,13 +51,20 @@ func (h *Handler) HandleSelectOne(ctx echo.Context) (err error) {
 		"results", results,
 	)
 
+	caller, err := auth_hl.Identity(ctx)
+	if err != nil {
+		return err
+	}
+
 	id := ctx.Param("id")
-	results, _, err = h.favouritesItc.Select(context.Background(), ports.SelectFavouritesItcParams{
+	results, _, err = h.favouritesItc.Select(ctx.Request().Context(), ports.SelectFavouritesItcParams{
 		Ids:   []string{id},
 		Limit: 1,
 	})
 
-	if err == nil && id != "" && len(results) == 0 {
+	if err == nil && len(results) > 0 && results[0].UserId != caller.UserId {
+		return echo.NewHTTPError(http.StatusNotFound, "record cannot be found")
+	} else if err == nil && id != "" && len(results) == 0 {
 		return echo.NewHTTPError(http.StatusNotFound, "record cannot be found")
 	} else if err != nil && errors.Is(err, errs.ValidationError) {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
@@ -70,9 +85,20 @@ func (h *Handler) HandleSelectMany(ctx echo.Context) (err error) {
 		"results", results,
 	)
 
+	caller, err := auth_hl.Identity(ctx)
+	if err != nil {
+		return err
+	}
+
 	userId := ctx.Param("userId")
+	if userId == "" {
+		userId = caller.UserId
+	} else if userId != caller.UserId {
+		return echo.NewHTTPError(http.StatusForbidden, "favourites of other users cannot be listed")
+	}
+
 	cursor, limit := parseCursorAndLimit(ctx)
-	results, nextCursor, err = h.favouritesItc.Select(context.Background(), ports.SelectFavouritesItcParams{
+	results, nextCursor, err = h.favouritesItc.Select(ctx.Request().Context(), ports.SelectFavouritesItcParams{
 		UserIds: []string{userId},
 		Cursor:  cursor,
 		Limit:   limit,
@@ -93,17 +119,28 @@ func (h *Handler) HandleSelectMany(ctx echo.Context) (err error) {
 func (h *Handler) HandleInsert(ctx echo.Context) (err error) {
 	var results []favourites_dm.FavouriteEntity
 
+	caller, err := auth_hl.Identity(ctx)
+	if err != nil {
+		return err
+	}
+
 	var insertParams ports.InsertFavouriteItcParams
 	if err = ctx.Bind(&insertParams); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
+	if insertParams.UserId == "" {
+		insertParams.UserId = caller.UserId
+	} else if insertParams.UserId != caller.UserId {
+		return echo.NewHTTPError(http.StatusForbidden, "favourites cannot be added for other users")
+	}
+
 	h.logger.Info("favourites_hl.HandleInsert() performed",
 		"request", insertParams,
 		"results", results,
 	)
 
-	results, err = h.favouritesItc.Insert(context.Background(), insertParams)
+	results, err = h.favouritesItc.Insert(ctx.Request().Context(), insertParams)
 
 	if err != nil && errors.Is(err, errs.ValidationError) {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
@@ -128,8 +165,12 @@ func (h *Handler) HandleDelete(ctx echo.Context) (err error) {
 		"results", results,
 	)
 
+	if _, err = auth_hl.Identity(ctx); err != nil {
+		return err
+	}
+
 	id := ctx.Param("id")
-	results, err = h.favouritesItc.Delete(context.Background(), ports.DeleteFavouriteItcParams{
+	results, err = h.favouritesItc.Delete(ctx.Request().Context(), ports.DeleteFavouriteItcParams{
 		Id: id,
 	})
 
@@ -137,6 +178,10 @@ func (h *Handler) HandleDelete(ctx echo.Context) (err error) {
 		return echo.NewHTTPError(http.StatusNotFound, "record cannot be found")
 	} else if err != nil && errors.Is(err, errs.ValidationError) {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	} else if err != nil && errors.Is(err, errs.AuthenticationError) {
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	} else if err != nil && errors.Is(err, errs.PermissionError) {
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
 	} else if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
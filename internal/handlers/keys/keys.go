@@ -0,0 +1,124 @@
+package keys_hl
+
+import (
+	keys_dm "assets/internal/core/domain/keys"
+	"assets/internal/core/ports"
+	auth_hl "assets/internal/handlers/auth"
+	errs "assets/pkg/errors"
+	"assets/pkg/logging"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+type Handler struct {
+	webServer *echo.Echo
+	logger    logging.Logger
+	keysItc   ports.ApiKeysInteractor
+}
+
+func Init(webServer *echo.Echo, logger logging.Logger, interactor ports.ApiKeysInteractor, authMiddleware echo.MiddlewareFunc) *Handler {
+
+	instance := &Handler{
+		webServer: webServer,
+		logger:    logger,
+		keysItc:   interactor,
+	}
+
+	// keys can be managed only within the session, so a leaked key cannot be used to mint new ones
+	instance.webServer.GET("/api/users/me/keys", instance.HandleSelectMany, authMiddleware, auth_hl.RequireSession)
+	instance.webServer.POST("/api/users/me/keys", instance.HandleInsert, authMiddleware, auth_hl.RequireSession)
+	instance.webServer.DELETE("/api/users/me/keys/:id", instance.HandleDelete, authMiddleware, auth_hl.RequireSession)
+
+	return instance
+}
+
+func (h *Handler) HandleSelectMany(ctx echo.Context) (err error) {
+
+	var results []keys_dm.ApiKeyEntity
+
+	caller, err := auth_hl.Identity(ctx)
+	if err != nil {
+		return err
+	}
+
+	h.logger.Info("keys_hl.HandleSelectMany() performed",
+		"userId", caller.UserId,
+	)
+
+	results, err = h.keysItc.Select(ctx.Request().Context(), ports.SelectApiKeysItcParams{
+		UserId: caller.UserId,
+	})
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"keys": results,
+	})
+}
+
+func (h *Handler) HandleInsert(ctx echo.Context) (err error) {
+
+	caller, err := auth_hl.Identity(ctx)
+	if err != nil {
+		return err
+	}
+
+	var insertParams ports.InsertApiKeyItcParams
+	if err = ctx.Bind(&insertParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	insertParams.UserId = caller.UserId
+
+	h.logger.Info("keys_hl.HandleInsert() performed",
+		"request", insertParams,
+	)
+
+	result, err := h.keysItc.Insert(ctx.Request().Context(), insertParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusCreated, result)
+}
+
+func (h *Handler) HandleDelete(ctx echo.Context) (err error) {
+
+	caller, err := auth_hl.Identity(ctx)
+	if err != nil {
+		return err
+	}
+
+	deleteParams := ports.DeleteApiKeyItcParams{
+		Id:     ctx.Param("id"),
+		UserId: caller.UserId,
+	}
+
+	h.logger.Info("keys_hl.HandleDelete() performed",
+		"request", deleteParams,
+	)
+
+	result, err := h.keysItc.Delete(ctx.Request().Context(), deleteParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func mapError(err error) error {
+	if err != nil && errors.Is(err, errs.ValidationError) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	} else if err != nil && errors.Is(err, errs.CannotBeFoundError) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return nil
+}
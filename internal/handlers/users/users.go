@@ -1,39 +1,127 @@
 package users_hl
 
 import (
+	tokens_dm "assets/internal/core/domain/tokens"
 	users_dm "assets/internal/core/domain/users"
 	"assets/internal/core/ports"
+	auth_hl "assets/internal/handlers/auth"
 	errs "assets/pkg/errors"
 	"assets/pkg/logging"
-	"context"
+	"assets/pkg/slices"
 	"errors"
-	"github.com/dgrijalva/jwt-go"
-	"github.com/gorilla/sessions"
 	"github.com/labstack/echo/v4"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 )
 
 type Handler struct {
-	webServer   *echo.Echo
-	logger      logging.Logger
-	usersItc    ports.UsersInteractor
-	cookieStore sessions.Store
-	secret      string
+	webServer     *echo.Echo
+	logger        logging.Logger
+	usersItc      ports.UsersInteractor
+	tokensItc     ports.TokensInteractor
+	authenticator *auth_hl.Authenticator
+	// requireVerified keeps registered users without session until they verify their email
+	requireVerified bool
 }
 
-func Init(webServer *echo.Echo, logger logging.Logger, interactor ports.UsersInteractor, cookieStore sessions.Store, secret string) *Handler {
+// userResponse is the public representation of the user, credentials are never part of it.
+type userResponse struct {
+	Id          string          `json:"id"`
+	Email       string          `json:"email"`
+	Roles       []users_dm.Role `json:"roles"`
+	Verified    bool            `json:"verified"`
+	Disabled    bool            `json:"disabled"`
+	TotpEnabled bool            `json:"totp_enabled"`
+	CreateTime  time.Time       `json:"create_time"`
+	UpdateTime  time.Time       `json:"update_time"`
+}
+
+func newUserResponse(user users_dm.UserEntity) userResponse {
+	return userResponse{
+		Id:          user.Id,
+		Email:       user.Email,
+		Roles:       user.Roles,
+		Verified:    user.Verified,
+		Disabled:    user.Disabled,
+		TotpEnabled: user.TotpEnabled,
+		CreateTime:  user.CreateTime,
+		UpdateTime:  user.UpdateTime,
+	}
+}
+
+type usersResponse struct {
+	Users  []userResponse `json:"users"`
+	Cursor string         `json:"cursor"`
+}
+
+// sessionResponse is returned by endpoints which start new session, tokens are meant for clients which cannot
+// rely on the session cookie.
+type sessionResponse struct {
+	userResponse
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// challengeResponse is returned by login of users with two-factor authentication, challenge has to be exchanged
+// together with the code for the session.
+type challengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	Challenge         string `json:"challenge"`
+	ExpiresIn         int64  `json:"expires_in"`
+}
+
+type confirmTotpResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// oidcStateCookie binds OpenID Connect login to the browser which started it, so a callback with somebody else's
+// code is rejected.
+const oidcStateCookie = "oidc_state"
+
+type logoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func Init(webServer *echo.Echo, logger logging.Logger, interactor ports.UsersInteractor, tokensItc ports.TokensInteractor, authenticator *auth_hl.Authenticator, requireVerified bool) *Handler {
 
 	instance := &Handler{
-		webServer:   webServer,
-		logger:      logger,
-		usersItc:    interactor,
-		cookieStore: cookieStore,
-		secret:      secret,
+		webServer:       webServer,
+		logger:          logger,
+		usersItc:        interactor,
+		tokensItc:       tokensItc,
+		authenticator:   authenticator,
+		requireVerified: requireVerified,
 	}
 
+	authMiddleware := authenticator.Authenticate
+	sessionMiddleware := auth_hl.RequireSession
+
 	instance.webServer.POST("/api/users/login", instance.HandleLogin)
 	instance.webServer.POST("/api/users/register", instance.HandleRegister)
+	instance.webServer.POST("/api/users/login/totp", instance.HandleCompleteLogin)
+	instance.webServer.GET("/api/users/oidc/login", instance.HandleStartOidcLogin)
+	instance.webServer.GET("/api/users/oidc/callback", instance.HandleCompleteOidcLogin)
+	instance.webServer.POST("/api/users/token/refresh", instance.HandleRefreshToken)
+	instance.webServer.POST("/api/users/password/forgot", instance.HandleForgotPassword)
+	instance.webServer.POST("/api/users/password/reset", instance.HandleResetPassword)
+	instance.webServer.GET("/api/users/verify", instance.HandleVerifyEmail)
+	instance.webServer.POST("/api/users/verify/resend", instance.HandleResendVerification)
+	instance.webServer.POST("/api/users/logout", instance.HandleLogout, authMiddleware, sessionMiddleware)
+	instance.webServer.GET("/api/users/me", instance.HandleGetMe, authMiddleware, sessionMiddleware)
+	instance.webServer.PATCH("/api/users/me", instance.HandleUpdateMe, authMiddleware, sessionMiddleware)
+	instance.webServer.DELETE("/api/users/me", instance.HandleDeleteMe, authMiddleware, sessionMiddleware)
+	instance.webServer.POST("/api/users/me/totp", instance.HandleEnrollTotp, authMiddleware, sessionMiddleware)
+	instance.webServer.POST("/api/users/me/totp/confirm", instance.HandleConfirmTotp, authMiddleware, sessionMiddleware)
+	instance.webServer.DELETE("/api/users/me/totp", instance.HandleDisableTotp, authMiddleware, sessionMiddleware)
+	instance.webServer.GET("/api/users", instance.HandleSelectMany, authMiddleware, sessionMiddleware)
+	instance.webServer.POST("/api/users/:id/disable", instance.HandleDisable, authMiddleware, sessionMiddleware)
+	instance.webServer.POST("/api/users/:id/enable", instance.HandleEnable, authMiddleware, sessionMiddleware)
+	instance.webServer.DELETE("/api/users/:id/sessions", instance.HandleRevokeSessions, authMiddleware, sessionMiddleware)
+	instance.webServer.POST("/api/users/:id/roles", instance.HandleGrantRole, authMiddleware, sessionMiddleware)
+	instance.webServer.DELETE("/api/users/:id/roles/:role", instance.HandleRevokeRole, authMiddleware, sessionMiddleware)
 
 	return instance
 }
@@ -46,28 +134,143 @@ func (h *Handler) HandleLogin(ctx echo.Context) (err error) {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
+	loginParams.ClientIp = ctx.RealIP()
+
 	h.logger.Info("users_hl.HandleLogin() performed",
 		"request", loginParams,
 		"result", result,
 	)
 
-	result, err = h.usersItc.Login(context.Background(), loginParams)
+	var challenge tokens_dm.OneTimeTokenEntity
+	result, challenge, err = h.usersItc.Login(ctx.Request().Context(), loginParams)
 
 	if err != nil && errors.Is(err, errs.ValidationError) {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	} else if err != nil && errors.Is(err, errs.AuthenticationError) {
 		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	} else if err != nil && errors.Is(err, errs.PermissionError) {
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	} else if err != nil && errors.Is(err, errs.TooManyRequestsError) {
+		return tooManyRequests(ctx, err)
 	} else if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	if err = h.authenticate(ctx, result.Id, result.Email); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	if challenge.Token != "" {
+		return ctx.JSON(http.StatusOK, challengeResponse{
+			TwoFactorRequired: true,
+			Challenge:         challenge.Token,
+			ExpiresIn:         int64(time.Until(challenge.ExpireTime).Seconds()),
+		})
 	}
 
-	result.Password = "<top secret>"
+	var response sessionResponse
+	if response, err = h.startSession(ctx, result); err != nil {
+		return err
+	}
 
-	return ctx.JSON(http.StatusOK, result)
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) HandleCompleteLogin(ctx echo.Context) (err error) {
+
+	var completeParams ports.CompleteLoginUserItcParams
+	if err = ctx.Bind(&completeParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	completeParams.ClientIp = ctx.RealIP()
+
+	h.logger.Info("users_hl.HandleCompleteLogin() performed",
+		"clientIp", completeParams.ClientIp,
+	)
+
+	result, err := h.usersItc.CompleteLogin(ctx.Request().Context(), completeParams)
+
+	if err != nil && errors.Is(err, errs.TooManyRequestsError) {
+		return tooManyRequests(ctx, err)
+	} else if err = mapError(err); err != nil {
+		return err
+	}
+
+	var response sessionResponse
+	if response, err = h.startSession(ctx, result); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) HandleStartOidcLogin(ctx echo.Context) (err error) {
+
+	h.logger.Info("users_hl.HandleStartOidcLogin() performed")
+
+	result, err := h.usersItc.StartOidcLogin(ctx.Request().Context())
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	ctx.SetCookie(&http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    result.State,
+		Path:     "/api/users/oidc",
+		MaxAge:   int(time.Until(result.ExpireTime).Seconds()),
+		HttpOnly: true,
+		Secure:   ctx.IsTLS(),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return ctx.Redirect(http.StatusFound, result.Url)
+}
+
+func (h *Handler) HandleCompleteOidcLogin(ctx echo.Context) (err error) {
+
+	h.logger.Info("users_hl.HandleCompleteOidcLogin() performed",
+		"error", ctx.QueryParam("error"),
+	)
+
+	if providerErr := ctx.QueryParam("error"); providerErr != "" {
+		return echo.NewHTTPError(http.StatusUnauthorized, "identity provider rejected login: "+providerErr)
+	}
+
+	completeParams := ports.CompleteOidcLoginUserItcParams{
+		Code:  ctx.QueryParam("code"),
+		State: ctx.QueryParam("state"),
+	}
+
+	cookie, err := ctx.Cookie(oidcStateCookie)
+	if err != nil || cookie.Value != completeParams.State {
+		return echo.NewHTTPError(http.StatusUnauthorized, "login was started in another browser")
+	}
+
+	ctx.SetCookie(&http.Cookie{
+		Name:     oidcStateCookie,
+		Path:     "/api/users/oidc",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+
+	result, challenge, err := h.usersItc.CompleteOidcLogin(ctx.Request().Context(), completeParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	if challenge.Token != "" {
+		return ctx.JSON(http.StatusOK, challengeResponse{
+			TwoFactorRequired: true,
+			Challenge:         challenge.Token,
+			ExpiresIn:         int64(time.Until(challenge.ExpireTime).Seconds()),
+		})
+	}
+
+	var response sessionResponse
+	if response, err = h.startSession(ctx, result); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, response)
 }
 
 func (h *Handler) HandleRegister(ctx echo.Context) (err error) {
@@ -83,7 +286,7 @@ func (h *Handler) HandleRegister(ctx echo.Context) (err error) {
 		"result", result,
 	)
 
-	result, err = h.usersItc.Register(context.Background(), insertParams)
+	result, err = h.usersItc.Register(ctx.Request().Context(), insertParams)
 
 	if err != nil && errors.Is(err, errs.ValidationError) {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
@@ -93,38 +296,550 @@ func (h *Handler) HandleRegister(ctx echo.Context) (err error) {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	if err = h.authenticate(ctx, result.Id, result.Email); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	// unverified users cannot sign in, so they get no session until they open the verification link
+	if h.requireVerified {
+		return ctx.JSON(http.StatusCreated, newUserResponse(result))
 	}
 
-	result.Password = "<top secret>"
+	var response sessionResponse
+	if response, err = h.startSession(ctx, result); err != nil {
+		return err
+	}
 
-	return ctx.JSON(http.StatusCreated, result)
+	return ctx.JSON(http.StatusCreated, response)
 
 }
 
-func (h *Handler) authenticate(ctx echo.Context, userId, email string) (err error) {
+func (h *Handler) HandleRefreshToken(ctx echo.Context) (err error) {
+
+	var refreshParams ports.RefreshTokenItcParams
+	if err = ctx.Bind(&refreshParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"userId": userId,
-		"email":  email,
-		"exp":    time.Now().Add(time.Hour * 24).Unix(),
+	h.logger.Info("users_hl.HandleRefreshToken() performed")
+
+	user, token, err := h.tokensItc.Refresh(ctx.Request().Context(), refreshParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	// the session of the refresh token is continued, so every refresh doesn't start another one
+	accessToken, _, err := h.authenticator.StartSession(ctx, user, token.SessionId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, sessionResponse{
+		userResponse: newUserResponse(user),
+		AccessToken:  accessToken,
+		RefreshToken: token.Token,
+		ExpiresIn:    int64(h.authenticator.Lifetime().Seconds()),
 	})
+}
+
+func (h *Handler) HandleForgotPassword(ctx echo.Context) (err error) {
+
+	var forgotParams ports.ForgotPasswordUserItcParams
+	if err = ctx.Bind(&forgotParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	h.logger.Info("users_hl.HandleForgotPassword() performed",
+		"request", forgotParams,
+	)
+
+	err = h.usersItc.ForgotPassword(ctx.Request().Context(), forgotParams)
 
-	tokenString, err := token.SignedString([]byte(h.secret))
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.NoContent(http.StatusAccepted)
+}
+
+func (h *Handler) HandleResetPassword(ctx echo.Context) (err error) {
+
+	var resetParams ports.ResetPasswordUserItcParams
+	if err = ctx.Bind(&resetParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	h.logger.Info("users_hl.HandleResetPassword() performed")
+
+	result, err := h.usersItc.ResetPassword(ctx.Request().Context(), resetParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	// credentials have changed, so everything issued with the old password is revoked
+	if err = h.authenticator.RevokeSessions(ctx, result.Id); err != nil {
+		return err
+	}
+
+	_, err = h.tokensItc.Revoke(ctx.Request().Context(), ports.RevokeTokensItcParams{UserIds: []string{result.Id}})
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, newUserResponse(result))
+}
+
+func (h *Handler) HandleVerifyEmail(ctx echo.Context) (err error) {
+
+	verifyParams := ports.VerifyEmailUserItcParams{
+		Token: ctx.QueryParam("token"),
+	}
+
+	h.logger.Info("users_hl.HandleVerifyEmail() performed")
+
+	result, err := h.usersItc.VerifyEmail(ctx.Request().Context(), verifyParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, newUserResponse(result))
+}
+
+func (h *Handler) HandleResendVerification(ctx echo.Context) (err error) {
+
+	var resendParams ports.ResendVerificationUserItcParams
+	if err = ctx.Bind(&resendParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	h.logger.Info("users_hl.HandleResendVerification() performed",
+		"request", resendParams,
+	)
+
+	err = h.usersItc.ResendVerification(ctx.Request().Context(), resendParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.NoContent(http.StatusAccepted)
+}
+
+func (h *Handler) HandleGetMe(ctx echo.Context) (err error) {
+
+	caller, err := auth_hl.Identity(ctx)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
+	}
+
+	getParams := ports.GetUserItcParams{
+		UserId: caller.UserId,
+	}
+
+	h.logger.Info("users_hl.HandleGetMe() performed",
+		"request", getParams,
+	)
+
+	result, err := h.usersItc.Get(ctx.Request().Context(), getParams)
+
+	if err = mapError(err); err != nil {
+		return err
 	}
 
-	session, err := h.cookieStore.Get(ctx.Request(), "session-name")
+	return ctx.JSON(http.StatusOK, newUserResponse(result))
+}
+
+func (h *Handler) HandleUpdateMe(ctx echo.Context) (err error) {
+
+	caller, err := auth_hl.Identity(ctx)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
+	}
+
+	var updateParams ports.UpdateUserItcParams
+	if err = ctx.Bind(&updateParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	updateParams.UserId = caller.UserId
+
+	h.logger.Info("users_hl.HandleUpdateMe() performed",
+		"userId", updateParams.UserId,
+		"email", updateParams.Email,
+	)
+
+	result, err := h.usersItc.Update(ctx.Request().Context(), updateParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	// credentials have changed, so other sessions are revoked and the caller gets a new one
+	if err = h.authenticator.RevokeSessions(ctx, result.Id); err != nil {
+		return err
 	}
 
-	session.Values["token"] = tokenString
-	if err = session.Save(ctx.Request(), ctx.Response().Writer); err != nil {
+	_, err = h.tokensItc.Revoke(ctx.Request().Context(), ports.RevokeTokensItcParams{UserIds: []string{result.Id}})
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	var response sessionResponse
+	if response, err = h.startSession(ctx, result); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) HandleDeleteMe(ctx echo.Context) (err error) {
+
+	caller, err := auth_hl.Identity(ctx)
+	if err != nil {
+		return err
+	}
+
+	var deleteParams ports.DeleteUserItcParams
+	if err = ctx.Bind(&deleteParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	deleteParams.UserId = caller.UserId
+
+	h.logger.Info("users_hl.HandleDeleteMe() performed",
+		"userId", deleteParams.UserId,
+	)
+
+	result, err := h.usersItc.Delete(ctx.Request().Context(), deleteParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	if err = h.authenticator.EndSession(ctx); err != nil {
+		return err
+	}
+
+	if err = h.authenticator.RevokeSessions(ctx, result.Id); err != nil {
+		return err
+	}
+
+	_, err = h.tokensItc.Revoke(ctx.Request().Context(), ports.RevokeTokensItcParams{UserIds: []string{result.Id}})
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) HandleSelectMany(ctx echo.Context) (err error) {
+
+	var selectParams ports.SelectUsersItcParams
+	if err = (&echo.DefaultBinder{}).BindQueryParams(ctx, &selectParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	h.logger.Info("users_hl.HandleSelectMany() performed",
+		"request", selectParams,
+	)
+
+	results, cursor, err := h.usersItc.Select(ctx.Request().Context(), selectParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, usersResponse{
+		Users:  slices.Map(results, newUserResponse),
+		Cursor: cursor,
+	})
+}
+
+func (h *Handler) HandleDisable(ctx echo.Context) (err error) {
+
+	disableParams := ports.DisableUserItcParams{
+		UserId: ctx.Param("id"),
+	}
+
+	h.logger.Info("users_hl.HandleDisable() performed",
+		"request", disableParams,
+	)
+
+	result, err := h.usersItc.Disable(ctx.Request().Context(), disableParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	// disabled user is signed out everywhere, api keys are rejected on their next use
+	if err = h.authenticator.RevokeSessions(ctx, result.Id); err != nil {
+		return err
+	}
+
+	_, err = h.tokensItc.Revoke(ctx.Request().Context(), ports.RevokeTokensItcParams{UserIds: []string{result.Id}})
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, newUserResponse(result))
+}
+
+func (h *Handler) HandleEnable(ctx echo.Context) (err error) {
+
+	enableParams := ports.EnableUserItcParams{
+		UserId: ctx.Param("id"),
+	}
+
+	h.logger.Info("users_hl.HandleEnable() performed",
+		"request", enableParams,
+	)
+
+	result, err := h.usersItc.Enable(ctx.Request().Context(), enableParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, newUserResponse(result))
+}
+
+func (h *Handler) HandleGrantRole(ctx echo.Context) (err error) {
+	var result users_dm.UserEntity
+
+	var grantParams ports.GrantRoleUserItcParams
+	if err = ctx.Bind(&grantParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	grantParams.UserId = ctx.Param("id")
+
+	h.logger.Info("users_hl.HandleGrantRole() performed",
+		"request", grantParams,
+		"result", result,
+	)
+
+	result, err = h.usersItc.GrantRole(ctx.Request().Context(), grantParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, newUserResponse(result))
+}
+
+func (h *Handler) HandleRevokeRole(ctx echo.Context) (err error) {
+	var result users_dm.UserEntity
+
+	revokeParams := ports.RevokeRoleUserItcParams{
+		UserId: ctx.Param("id"),
+		Role:   ctx.Param("role"),
+	}
+
+	h.logger.Info("users_hl.HandleRevokeRole() performed",
+		"request", revokeParams,
+		"result", result,
+	)
+
+	result, err = h.usersItc.RevokeRole(ctx.Request().Context(), revokeParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, newUserResponse(result))
+}
+
+func (h *Handler) HandleEnrollTotp(ctx echo.Context) (err error) {
+
+	caller, err := auth_hl.Identity(ctx)
+	if err != nil {
+		return err
+	}
+
+	enrollParams := ports.EnrollTotpUserItcParams{
+		UserId: caller.UserId,
+	}
+
+	h.logger.Info("users_hl.HandleEnrollTotp() performed",
+		"request", enrollParams,
+	)
+
+	result, err := h.usersItc.EnrollTotp(ctx.Request().Context(), enrollParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) HandleConfirmTotp(ctx echo.Context) (err error) {
+
+	caller, err := auth_hl.Identity(ctx)
+	if err != nil {
+		return err
+	}
+
+	var confirmParams ports.ConfirmTotpUserItcParams
+	if err = ctx.Bind(&confirmParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	confirmParams.UserId = caller.UserId
+
+	h.logger.Info("users_hl.HandleConfirmTotp() performed",
+		"userId", confirmParams.UserId,
+	)
+
+	results, err := h.usersItc.ConfirmTotp(ctx.Request().Context(), confirmParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, confirmTotpResponse{RecoveryCodes: results})
+}
+
+func (h *Handler) HandleDisableTotp(ctx echo.Context) (err error) {
+
+	caller, err := auth_hl.Identity(ctx)
+	if err != nil {
+		return err
+	}
+
+	var disableParams ports.DisableTotpUserItcParams
+	if err = ctx.Bind(&disableParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	disableParams.UserId = caller.UserId
+
+	h.logger.Info("users_hl.HandleDisableTotp() performed",
+		"userId", disableParams.UserId,
+	)
+
+	result, err := h.usersItc.DisableTotp(ctx.Request().Context(), disableParams)
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, newUserResponse(result))
+}
+
+func (h *Handler) HandleLogout(ctx echo.Context) (err error) {
+
+	caller, err := auth_hl.Identity(ctx)
+	if err != nil {
+		return err
+	}
+
+	var logoutParams logoutRequest
+	if err = ctx.Bind(&logoutParams); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	h.logger.Info("users_hl.HandleLogout() performed",
+		"userId", caller.UserId,
+		"sessionId", caller.SessionId,
+	)
+
+	if logoutParams.RefreshToken != "" {
+		_, err = h.tokensItc.Revoke(ctx.Request().Context(), ports.RevokeTokensItcParams{Token: logoutParams.RefreshToken})
+
+		if err = mapError(err); err != nil {
+			return err
+		}
+	}
+
+	if err = h.authenticator.EndSession(ctx); err != nil {
+		return err
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) HandleRevokeSessions(ctx echo.Context) (err error) {
+
+	caller, err := auth_hl.Identity(ctx)
+	if err != nil {
+		return err
+	}
+
+	userId := ctx.Param("id")
+
+	h.logger.Info("users_hl.HandleRevokeSessions() performed",
+		"userId", userId,
+		"callerId", caller.UserId,
+	)
+
+	if userId != caller.UserId && !slices.Contains(caller.Roles, users_dm.RoleAdmin) {
+		return echo.NewHTTPError(http.StatusForbidden, "sessions of other users can be revoked only by admin")
+	}
+
+	if err = h.authenticator.RevokeSessions(ctx, userId); err != nil {
+		return err
+	}
+
+	_, err = h.tokensItc.Revoke(ctx.Request().Context(), ports.RevokeTokensItcParams{UserIds: []string{userId}})
+
+	if err = mapError(err); err != nil {
+		return err
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// startSession starts cookie session and issues new family of refresh tokens for the user, tied to the session.
+func (h *Handler) startSession(ctx echo.Context, user users_dm.UserEntity) (response sessionResponse, err error) {
+
+	var sessionId string
+	if response.AccessToken, sessionId, err = h.authenticator.StartSession(ctx, user, ""); err != nil {
+		return response, err
+	}
+
+	var token tokens_dm.RefreshTokenEntity
+	token, err = h.tokensItc.Issue(ctx.Request().Context(), ports.IssueTokenItcParams{UserId: user.Id, SessionId: sessionId})
+
+	if err = mapError(err); err != nil {
+		return response, err
+	}
+
+	response.userResponse = newUserResponse(user)
+	response.RefreshToken = token.Token
+	response.ExpiresIn = int64(h.authenticator.Lifetime().Seconds())
+
+	return response, nil
+}
+
+func mapError(err error) error {
+	if err != nil && errors.Is(err, errs.ValidationError) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	} else if err != nil && errors.Is(err, errs.AuthenticationError) {
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	} else if err != nil && errors.Is(err, errs.PermissionError) {
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	} else if err != nil && errors.Is(err, errs.CannotBeFoundError) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	} else if err != nil && errors.Is(err, errs.AlreadyExistsError) {
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	} else if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
 	return nil
 }
+
+// tooManyRequests tells the client when the request can be retried.
+func tooManyRequests(ctx echo.Context, err error) error {
+
+	var retryErr errs.RetryAfterError
+	if errors.As(err, &retryErr) {
+		ctx.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryErr.RetryAfter.Seconds()))))
+	}
+
+	return echo.NewHTTPError(http.StatusTooManyRequests, err.Error())
+}
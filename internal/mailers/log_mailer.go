@@ -0,0 +1,63 @@
+package mailers
+
+import (
+	"assets/internal/core/ports"
+	"assets/pkg/logging"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogMailer doesn't deliver any emails, it's meant for local development. Messages are appended to the file under
+// 'path' or written to the log when path is empty.
+type LogMailer struct {
+	logger logging.Logger
+	path   string
+	mutex  sync.Mutex
+}
+
+func NewLogMailer(logger logging.Logger, path string) *LogMailer {
+	return &LogMailer{
+		logger: logger,
+		path:   path,
+	}
+}
+
+func (m *LogMailer) Send(_ context.Context, params ports.SendMailParams) (err error) {
+
+	if m.path == "" {
+		m.logger.Info("mailers.Send() performed",
+			"to", params.To,
+			"subject", params.Subject,
+			"body", params.Body,
+		)
+
+		return nil
+	}
+
+	m.logger.Info("mailers.Send() performed",
+		"to", params.To,
+		"subject", params.Subject,
+		"path", m.path,
+	)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	file, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	_, err = fmt.Fprintf(file, "Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n---\n\n",
+		time.Now().Format(time.RFC1123Z), params.To, params.Subject, params.Body)
+
+	return err
+}
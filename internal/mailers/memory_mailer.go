@@ -0,0 +1,21 @@
+package mailers
+
+import (
+	"assets/internal/core/ports"
+	"context"
+)
+
+/// test purposes mailer
+
+type MemoryMailer struct {
+	Messages []ports.SendMailParams
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(_ context.Context, params ports.SendMailParams) error {
+	m.Messages = append(m.Messages, params)
+	return nil
+}
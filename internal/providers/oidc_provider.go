@@ -0,0 +1,335 @@
+package providers
+
+import (
+	users_dm "assets/internal/core/domain/users"
+	"assets/internal/core/ports"
+	"assets/pkg/logging"
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OidcConfig describes client registered at the identity provider.
+type OidcConfig struct {
+	Issuer       string
+	ClientId     string
+	ClientSecret string
+	RedirectUrl  string
+	Scopes       []string
+}
+
+// OidcProvider implements authorization code flow with PKCE against OpenID Connect issuer. Issuer metadata and
+// signing keys are fetched lazily, so the service starts even when the issuer is not reachable.
+type OidcProvider struct {
+	logger    logging.Logger
+	client    *http.Client
+	config    OidcConfig
+	mutex     sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]*rsa.PublicKey
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+}
+
+type oidcTokenResponse struct {
+	IdToken     string `json:"id_token"`
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+}
+
+type oidcJwks struct {
+	Keys []struct {
+		Kid string `json:"kid"`
+		Kty string `json:"kty"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+func NewOidcProvider(logger logging.Logger, client *http.Client, config OidcConfig) *OidcProvider {
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	return &OidcProvider{
+		logger: logger,
+		client: client,
+		config: config,
+	}
+}
+
+func (p *OidcProvider) AuthorizationUrl(ctx context.Context, params ports.AuthorizationUrlParams) (result string, err error) {
+
+	p.logger.Info("providers.AuthorizationUrl() performed",
+		"issuer", p.config.Issuer,
+	)
+
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	endpoint, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return result, err
+	}
+
+	query := endpoint.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientId)
+	query.Set("redirect_uri", p.config.RedirectUrl)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", params.State)
+	query.Set("nonce", params.Nonce)
+	query.Set("code_challenge", params.CodeChallenge)
+	query.Set("code_challenge_method", "S256")
+	endpoint.RawQuery = query.Encode()
+
+	return endpoint.String(), nil
+}
+
+func (p *OidcProvider) Exchange(ctx context.Context, params ports.ExchangeCodeParams) (result users_dm.ExternalIdentity, err error) {
+
+	p.logger.Info("providers.Exchange() performed",
+		"issuer", p.config.Issuer,
+	)
+
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", params.Code)
+	form.Set("redirect_uri", p.config.RedirectUrl)
+	form.Set("client_id", p.config.ClientId)
+	form.Set("code_verifier", params.CodeVerifier)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return result, err
+	}
+
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		request.SetBasicAuth(url.QueryEscape(p.config.ClientId), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	var token oidcTokenResponse
+	if err = p.do(request, &token); err != nil {
+		return result, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	if token.IdToken == "" {
+		return result, errors.New("token response doesn't contain id_token")
+	}
+
+	return p.verify(ctx, discovery, token.IdToken, params.Nonce)
+}
+
+// verify checks signature and claims of the id token and extracts the identity.
+func (p *OidcProvider) verify(ctx context.Context, discovery *oidcDiscovery, idToken string, nonce string) (result users_dm.ExternalIdentity, err error) {
+
+	parsed, err := jwt.Parse(idToken, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, discovery, kid)
+	})
+	if err != nil {
+		return result, fmt.Errorf("invalid id_token: %w", err)
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok || !parsed.Valid {
+		return result, errors.New("invalid id_token")
+	}
+
+	if !claims.VerifyIssuer(discovery.Issuer, true) {
+		return result, errors.New("id_token has unexpected issuer")
+	}
+
+	if !audienceContains(claims["aud"], p.config.ClientId) {
+		return result, errors.New("id_token has unexpected audience")
+	}
+
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return result, errors.New("id_token has expired")
+	}
+
+	if value, _ := claims["nonce"].(string); value != nonce {
+		return result, errors.New("id_token has unexpected nonce")
+	}
+
+	result.Subject, _ = claims["sub"].(string)
+	result.Email, _ = claims["email"].(string)
+
+	// some issuers send the flag as a string
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		result.EmailVerified = verified
+	case string:
+		result.EmailVerified = verified == "true"
+	}
+
+	if result.Subject == "" || result.Email == "" {
+		return users_dm.ExternalIdentity{}, errors.New("id_token doesn't contain subject or email")
+	}
+
+	return result, nil
+}
+
+// discover fetches issuer metadata once, failed attempts are retried on the next call.
+func (p *OidcProvider) discover(ctx context.Context) (result *oidcDiscovery, err error) {
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.config.Issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result = &oidcDiscovery{}
+	if err = p.do(request, result); err != nil {
+		return nil, fmt.Errorf("failed to discover issuer: %w", err)
+	}
+
+	if strings.TrimSuffix(result.Issuer, "/") != strings.TrimSuffix(p.config.Issuer, "/") {
+		return nil, fmt.Errorf("issuer metadata belongs to %q", result.Issuer)
+	}
+
+	p.discovery = result
+
+	return result, nil
+}
+
+// key returns signing key of the issuer, keys are fetched again when the issuer rotates them.
+func (p *OidcProvider) key(ctx context.Context, discovery *oidcDiscovery, kid string) (result *rsa.PublicKey, err error) {
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if result = p.lookup(kid); result != nil {
+		return result, nil
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, discovery.JwksUri, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var jwks oidcJwks
+	if err = p.do(request, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+
+	p.keys = make(map[string]*rsa.PublicKey)
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			continue
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			continue
+		}
+
+		p.keys[jwk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+
+	if result = p.lookup(kid); result == nil {
+		return nil, fmt.Errorf("signing key %q cannot be found", kid)
+	}
+
+	return result, nil
+}
+
+// lookup finds key by id, tokens without key id are accepted only when the issuer publishes a single key.
+func (p *OidcProvider) lookup(kid string) *rsa.PublicKey {
+	if key, ok := p.keys[kid]; ok {
+		return key
+	}
+
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key
+		}
+	}
+
+	return nil
+}
+
+func (p *OidcProvider) do(request *http.Request, result any) (err error) {
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := response.Body.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("issuer responded with %d: %s", response.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return json.Unmarshal(body, result)
+}
+
+// audienceContains accepts both forms of the aud claim, a string and a list of strings.
+func audienceContains(aud any, clientId string) bool {
+	switch value := aud.(type) {
+	case string:
+		return value == clientId
+	case []any:
+		for _, item := range value {
+			if item == clientId {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,180 @@
+package providers
+
+import (
+	users_dm "assets/internal/core/domain/users"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+)
+
+/// test purposes issuer
+
+// StubIssuer is a minimal OpenID Connect issuer serving discovery, signing keys and token endpoint on a local
+// address. Authorize plays the part of the user signing in at the issuer.
+type StubIssuer struct {
+	Server       *httptest.Server
+	ClientId     string
+	ClientSecret string
+
+	key   *rsa.PrivateKey
+	mutex sync.Mutex
+	codes map[string]stubGrant
+}
+
+type stubGrant struct {
+	identity    users_dm.ExternalIdentity
+	nonce       string
+	challenge   string
+	redirectUrl string
+}
+
+func NewStubIssuer(clientId string, clientSecret string) *StubIssuer {
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+
+	issuer := &StubIssuer{
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+		key:          key,
+		codes:        make(map[string]stubGrant),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", issuer.handleDiscovery)
+	mux.HandleFunc("/jwks", issuer.handleJwks)
+	mux.HandleFunc("/token", issuer.handleToken)
+
+	issuer.Server = httptest.NewServer(mux)
+
+	return issuer
+}
+
+func (s *StubIssuer) Url() string {
+	return s.Server.URL
+}
+
+func (s *StubIssuer) Close() {
+	s.Server.Close()
+}
+
+// Authorize accepts authorization request for the identity and returns the code together with the state, just like
+// the redirect back to the client does.
+func (s *StubIssuer) Authorize(authorizationUrl string, identity users_dm.ExternalIdentity) (code string, state string, err error) {
+
+	parsed, err := url.Parse(authorizationUrl)
+	if err != nil {
+		return "", "", err
+	}
+
+	query := parsed.Query()
+	if query.Get("client_id") != s.ClientId || query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" {
+		return "", "", errors.New("invalid authorization request")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	code = uuid.NewString()
+	s.codes[code] = stubGrant{
+		identity:    identity,
+		nonce:       query.Get("nonce"),
+		challenge:   query.Get("code_challenge"),
+		redirectUrl: query.Get("redirect_uri"),
+	}
+
+	return code, query.Get("state"), nil
+}
+
+func (s *StubIssuer) handleDiscovery(w http.ResponseWriter, _ *http.Request) {
+	writeJson(w, http.StatusOK, map[string]string{
+		"issuer":                 s.Url(),
+		"authorization_endpoint": s.Url() + "/authorize",
+		"token_endpoint":         s.Url() + "/token",
+		"jwks_uri":               s.Url() + "/jwks",
+	})
+}
+
+func (s *StubIssuer) handleJwks(w http.ResponseWriter, _ *http.Request) {
+	writeJson(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kid": "stub",
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (s *StubIssuer) handleToken(w http.ResponseWriter, r *http.Request) {
+
+	if err := r.ParseForm(); err != nil || r.Method != http.MethodPost {
+		writeJson(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	if clientId, clientSecret, ok := r.BasicAuth(); !ok || clientId != s.ClientId || clientSecret != s.ClientSecret {
+		writeJson(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	s.mutex.Lock()
+	grant, ok := s.codes[r.PostForm.Get("code")]
+	delete(s.codes, r.PostForm.Get("code"))
+	s.mutex.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+
+	if !ok || r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("redirect_uri") != grant.redirectUrl ||
+		base64.RawURLEncoding.EncodeToString(verifier[:]) != grant.challenge {
+		writeJson(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            s.Url(),
+		"aud":            s.ClientId,
+		"sub":            grant.identity.Subject,
+		"email":          grant.identity.Email,
+		"email_verified": grant.identity.EmailVerified,
+		"nonce":          grant.nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Minute).Unix(),
+	})
+	token.Header["kid"] = "stub"
+
+	idToken, err := token.SignedString(s.key)
+	if err != nil {
+		writeJson(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJson(w, http.StatusOK, map[string]any{
+		"id_token":     idToken,
+		"access_token": uuid.NewString(),
+		"token_type":   "Bearer",
+		"expires_in":   60,
+	})
+}
+
+func writeJson(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,94 @@
+package render
+
+import (
+	assets_dm "assets/internal/core/domain/assets"
+	"assets/internal/core/ports"
+	"container/list"
+	"context"
+	"sync"
+)
+
+// key identifies render of single state of the asset, every update of the asset refreshes its update time, so stale
+// renders are never hit again and they fall out of the cache as least recently used.
+type key struct {
+	assetId    string
+	updateTime int64
+	params     ports.RenderParams
+}
+
+type entry struct {
+	key  key
+	data []byte
+}
+
+// Cache keeps the most recently used renders of wrapped renderer in memory. Renders are kept per asset id and update
+// time, so updated assets are drawn again.
+type Cache struct {
+	renderer ports.Renderer
+	size     int
+	mutex    sync.Mutex
+	order    *list.List
+	entries  map[key]*list.Element
+}
+
+func NewCache(renderer ports.Renderer, size int) *Cache {
+	return &Cache{
+		renderer: renderer,
+		size:     size,
+		order:    list.New(),
+		entries:  make(map[key]*list.Element),
+	}
+}
+
+func (c *Cache) Render(ctx context.Context, asset assets_dm.AssetEntity, params ports.RenderParams) ([]byte, error) {
+	k := key{assetId: asset.Id, updateTime: asset.UpdateTime.UnixNano(), params: params}
+
+	if data, ok := c.get(k); ok {
+		return data, nil
+	}
+
+	// assets are drawn outside of the lock, concurrent misses of the same render only waste some work
+	data, err := c.renderer.Render(ctx, asset, params)
+	if err != nil {
+		return nil, err
+	}
+
+	c.put(k, data)
+
+	return data, nil
+}
+
+func (c *Cache) get(k key) ([]byte, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[k]
+	if !ok {
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return element.Value.(*entry).data, true
+}
+
+func (c *Cache) put(k key, data []byte) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.size <= 0 {
+		return
+	}
+
+	if element, ok := c.entries[k]; ok {
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[k] = c.order.PushFront(&entry{key: k, data: data})
+
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry).key)
+	}
+}
@@ -0,0 +1,45 @@
+package render
+
+import (
+	"image/color"
+)
+
+// anchor tells which point of the text is placed at given position.
+type anchor int
+
+const (
+	anchorStart anchor = iota
+	anchorMiddle
+	anchorEnd
+)
+
+type point struct {
+	x, y float64
+}
+
+// canvas is the drawing surface shared by vector and raster output. Coordinates are in pixels with the origin in
+// the top left corner, angles of wedges are in radians measured clockwise from 12 o'clock. Text is vertically
+// centered at given position, vertical text is rotated counterclockwise and reads bottom to top.
+type canvas interface {
+	rect(x, y, width, height float64, fill color.RGBA)
+	line(x1, y1, x2, y2, width float64, stroke color.RGBA)
+	polyline(points []point, width float64, stroke color.RGBA)
+	circle(cx, cy, radius float64, fill color.RGBA)
+	wedge(cx, cy, radius, from, to float64, fill color.RGBA)
+	text(x, y float64, value string, size float64, fill color.RGBA, anchor anchor, vertical bool)
+	// measure returns width of the text written in given size.
+	measure(value string, size float64) float64
+	encode() ([]byte, error)
+}
+
+// shift returns distance along the text between its anchor and its start.
+func shift(anchor anchor, width float64) float64 {
+	switch anchor {
+	case anchorMiddle:
+		return width / 2
+	case anchorEnd:
+		return width
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,409 @@
+package render
+
+import (
+	assets_dm "assets/internal/core/domain/assets"
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// Sizes of text and spacing of chart elements in pixels.
+const (
+	margin        = 16.0
+	gap           = 8.0
+	titleSize     = 18.0
+	axisTitleSize = 13.0
+	tickSize      = 11.0
+	legendSize    = 12.0
+	swatchSize    = 10.0
+	tickLength    = 4.0
+	markerRadius  = 3.0
+	lineWidth     = 2.0
+	minPlotSize   = 10.0
+)
+
+var (
+	background = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	foreground = color.RGBA{R: 0x33, G: 0x33, B: 0x33, A: 0xff}
+	grid       = color.RGBA{R: 0xe0, G: 0xe0, B: 0xe0, A: 0xff}
+	empty      = color.RGBA{R: 0xee, G: 0xee, B: 0xee, A: 0xff}
+)
+
+// palette colors series in order, it is repeated for charts with more series.
+var palette = []color.RGBA{
+	{R: 0x4e, G: 0x79, B: 0xa7, A: 0xff},
+	{R: 0xf2, G: 0x8e, B: 0x2b, A: 0xff},
+	{R: 0xe1, G: 0x57, B: 0x59, A: 0xff},
+	{R: 0x76, G: 0xb7, B: 0xb2, A: 0xff},
+	{R: 0x59, G: 0xa1, B: 0x4f, A: 0xff},
+	{R: 0xed, G: 0xc9, B: 0x48, A: 0xff},
+	{R: 0xb0, G: 0x7a, B: 0xa1, A: 0xff},
+	{R: 0xff, G: 0x9d, B: 0xa7, A: 0xff},
+	{R: 0x9c, G: 0x75, B: 0x5f, A: 0xff},
+	{R: 0xba, G: 0xb0, B: 0xac, A: 0xff},
+}
+
+func colorOf(idx int) color.RGBA {
+	return palette[idx%len(palette)]
+}
+
+// area is rectangle of the canvas left for chart elements which weren't drawn yet.
+type area struct {
+	left, top, right, bottom float64
+}
+
+func (a area) width() float64 {
+	return a.right - a.left
+}
+
+func (a area) height() float64 {
+	return a.bottom - a.top
+}
+
+// drawChart draws the title on top, the legend at the bottom and the plot between them. Pie charts have no axes, so
+// their axis titles are left out.
+func drawChart(c canvas, chart assets_dm.Chart, width, height int) {
+	free := area{left: margin, top: margin, right: float64(width) - margin, bottom: float64(height) - margin}
+
+	c.rect(0, 0, float64(width), float64(height), background)
+	c.text(float64(width)/2, free.top+titleSize/2, chart.ChartTitle, titleSize, foreground, anchorMiddle, false)
+	free.top += titleSize + gap
+
+	free.bottom = drawLegend(c, chart.Data, free) - gap
+
+	if free.width() < minPlotSize || free.height() < minPlotSize {
+		return
+	}
+
+	if chart.Data.Kind == assets_dm.ChartKindPie {
+		drawPie(c, chart.Data, free)
+	} else {
+		drawCartesian(c, chart, free)
+	}
+}
+
+/*
+ * Legend
+ */
+
+// drawLegend lays out entries in centered rows at the bottom of the area and returns top of the legend.
+func drawLegend(c canvas, data assets_dm.ChartData, free area) float64 {
+	labels := make([]string, len(data.Series))
+	for idx, series := range data.Series {
+		labels[idx] = series.Name
+	}
+
+	if data.Kind == assets_dm.ChartKindPie {
+		sums, total := pieSums(data)
+		for idx := range labels {
+			if total > 0 {
+				labels[idx] = fmt.Sprintf("%s (%.1f%%)", labels[idx], sums[idx]/total*100)
+			}
+		}
+	}
+
+	widths := make([]float64, len(labels))
+	for idx, label := range labels {
+		widths[idx] = swatchSize + gap/2 + c.measure(label, legendSize)
+	}
+
+	// every row holds indexes of its entries
+	var rows [][]int
+	var row []int
+	rowWidth := 0.0
+	for idx, width := range widths {
+		if len(row) != 0 && rowWidth+2*gap+width > free.width() {
+			rows, row, rowWidth = append(rows, row), nil, 0
+		}
+		if len(row) != 0 {
+			rowWidth += 2 * gap
+		}
+		row, rowWidth = append(row, idx), rowWidth+width
+	}
+	rows = append(rows, row)
+
+	rowHeight := legendSize + gap/2
+	top := free.bottom - float64(len(rows))*rowHeight
+
+	for number, row := range rows {
+		total := float64(len(row)-1) * 2 * gap
+		for _, idx := range row {
+			total += widths[idx]
+		}
+
+		x := free.left + (free.width()-total)/2
+		y := top + float64(number)*rowHeight + rowHeight/2
+		for _, idx := range row {
+			c.rect(x, y-swatchSize/2, swatchSize, swatchSize, colorOf(idx))
+			c.text(x+swatchSize+gap/2, y, labels[idx], legendSize, foreground, anchorStart, false)
+			x += widths[idx] + 2*gap
+		}
+	}
+
+	return top
+}
+
+/*
+ * Pie
+ */
+
+func pieSums(data assets_dm.ChartData) (sums []float64, total float64) {
+	sums = make([]float64, len(data.Series))
+	for idx, series := range data.Series {
+		for _, p := range series.Points {
+			sums[idx] += p.Y
+		}
+		total += sums[idx]
+	}
+
+	return sums, total
+}
+
+func drawPie(c canvas, data assets_dm.ChartData, free area) {
+	cx, cy := free.left+free.width()/2, free.top+free.height()/2
+	radius := math.Min(free.width(), free.height()) / 2
+
+	sums, total := pieSums(data)
+	if total <= 0 {
+		c.circle(cx, cy, radius, empty)
+		return
+	}
+
+	angle := 0.0
+	for idx, sum := range sums {
+		if sum <= 0 {
+			continue
+		}
+
+		next := angle + sum/total*2*math.Pi
+		c.wedge(cx, cy, radius, angle, next, colorOf(idx))
+		angle = next
+	}
+}
+
+/*
+ * Cartesian
+ */
+
+// drawCartesian draws axes with ticks and gridlines and plots series of line, bar and scatter charts. Moments in time
+// are placed on the x axis as seconds since the epoch.
+func drawCartesian(c canvas, chart assets_dm.Chart, free area) {
+	data := chart.Data
+
+	temporal := false
+	var xs, ys []float64
+	for _, series := range data.Series {
+		for _, p := range series.Points {
+			xs = append(xs, xOf(p.X))
+			ys = append(ys, p.Y)
+			temporal = temporal || p.X.Time != nil
+		}
+	}
+
+	yMin, yMax := bounds(ys)
+	if data.Kind == assets_dm.ChartKindBar {
+		yMin, yMax = math.Min(yMin, 0), math.Max(yMax, 0)
+	}
+
+	yTicks, yStep := numberTicks(yMin, yMax, int(math.Max(free.height()/50, 2)))
+	yLabels := make([]string, len(yTicks))
+	yLabelsWidth := 0.0
+	for idx, tick := range yTicks {
+		yLabels[idx] = formatNumber(tick, yStep)
+		yLabelsWidth = math.Max(yLabelsWidth, c.measure(yLabels[idx], tickSize))
+	}
+
+	plot := area{
+		left:   free.left + axisTitleSize + gap + yLabelsWidth + tickLength + gap/2,
+		top:    free.top,
+		right:  free.right - gap,
+		bottom: free.bottom - axisTitleSize - gap - tickSize - tickLength - gap/2,
+	}
+	if plot.width() < minPlotSize || plot.height() < minPlotSize {
+		return
+	}
+
+	c.text(free.left+axisTitleSize/2, plot.top+plot.height()/2, axisTitle(chart.YAxisTitle, data.YUnit), axisTitleSize, foreground, anchorMiddle, true)
+	c.text(plot.left+plot.width()/2, free.bottom-axisTitleSize/2, axisTitle(chart.XAxisTitle, data.XUnit), axisTitleSize, foreground, anchorMiddle, false)
+
+	y0, y1 := yTicks[0], yTicks[len(yTicks)-1]
+	sy := func(value float64) float64 {
+		return plot.bottom - (value-y0)/(y1-y0)*plot.height()
+	}
+
+	for idx, tick := range yTicks {
+		y := sy(tick)
+		c.line(plot.left, y, plot.right, y, 1, grid)
+		c.line(plot.left-tickLength, y, plot.left, y, 1, foreground)
+		c.text(plot.left-tickLength-gap/2, y, yLabels[idx], tickSize, foreground, anchorEnd, false)
+	}
+
+	labelY := plot.bottom + tickLength + gap/2 + tickSize/2
+	xTick := func(x float64, label string) {
+		c.line(x, plot.bottom, x, plot.bottom+tickLength, 1, foreground)
+		c.text(x, labelY, label, tickSize, foreground, anchorMiddle, false)
+	}
+
+	if data.Kind == assets_dm.ChartKindBar {
+		drawBars(c, data, plot, sy, temporal, xTick)
+	} else {
+		xMin, xMax := bounds(xs)
+
+		var xTicks []float64
+		var xLabels []string
+		if temporal {
+			xTicks, xLabels = timeTicks(xMin, xMax, plot.width(), func(label string) float64 {
+				return c.measure(label, tickSize)
+			})
+		} else {
+			var xStep float64
+			xTicks, xStep = numberTicks(xMin, xMax, int(math.Max(plot.width()/80, 2)))
+			xMin, xMax = xTicks[0], xTicks[len(xTicks)-1]
+			for _, tick := range xTicks {
+				xLabels = append(xLabels, formatNumber(tick, xStep))
+			}
+		}
+
+		if xMin == xMax {
+			xMin, xMax = xMin-1, xMax+1
+		}
+
+		sx := func(value float64) float64 {
+			return plot.left + (value-xMin)/(xMax-xMin)*plot.width()
+		}
+
+		for idx, tick := range xTicks {
+			xTick(sx(tick), xLabels[idx])
+		}
+
+		drawPoints(c, data, sx, sy)
+	}
+
+	c.line(plot.left, plot.top, plot.left, plot.bottom, 1, foreground)
+	c.line(plot.left, plot.bottom, plot.right, plot.bottom, 1, foreground)
+}
+
+// drawPoints connects points of line charts ordered by x and marks points of scatter charts.
+func drawPoints(c canvas, data assets_dm.ChartData, sx, sy func(float64) float64) {
+	for idx, series := range data.Series {
+		points := make([]point, len(series.Points))
+		for pdx, p := range series.Points {
+			points[pdx] = point{x: sx(xOf(p.X)), y: sy(p.Y)}
+		}
+
+		if data.Kind == assets_dm.ChartKindLine && len(points) > 1 {
+			sort.SliceStable(points, func(i, j int) bool {
+				return points[i].x < points[j].x
+			})
+			c.polyline(points, lineWidth, colorOf(idx))
+			continue
+		}
+
+		for _, p := range points {
+			c.circle(p.x, p.y, markerRadius, colorOf(idx))
+		}
+	}
+}
+
+// drawBars groups bars of all series by distinct x values, which are treated as categories. Labels of categories
+// are thinned out when they don't fit under the plot.
+func drawBars(c canvas, data assets_dm.ChartData, plot area, sy func(float64) float64, temporal bool, xTick func(x float64, label string)) {
+	values := make(map[float64]assets_dm.XValue)
+	for _, series := range data.Series {
+		for _, p := range series.Points {
+			values[xOf(p.X)] = p.X
+		}
+	}
+
+	categories := make([]float64, 0, len(values))
+	for category := range values {
+		categories = append(categories, category)
+	}
+	sort.Float64s(categories)
+
+	labels := make([]string, len(categories))
+	labelsWidth := 0.0
+	for idx, category := range categories {
+		labels[idx] = categoryLabel(values[category], categories, temporal)
+		labelsWidth = math.Max(labelsWidth, c.measure(labels[idx], tickSize)+gap)
+	}
+
+	band := plot.width() / float64(len(categories))
+	every := int(math.Ceil(labelsWidth / band))
+	for idx := range categories {
+		if idx%every == 0 {
+			xTick(plot.left+band*(float64(idx)+0.5), labels[idx])
+		}
+	}
+
+	index := make(map[float64]int, len(categories))
+	for idx, category := range categories {
+		index[category] = idx
+	}
+
+	bar := band * 0.8 / float64(len(data.Series))
+	base := sy(0)
+	base = math.Max(math.Min(base, plot.bottom), plot.top)
+
+	for sdx, series := range data.Series {
+		for _, p := range series.Points {
+			x := plot.left + band*(float64(index[xOf(p.X)])+0.1) + bar*float64(sdx)
+			y := sy(p.Y)
+			c.rect(x, math.Min(y, base), bar, math.Abs(base-y), colorOf(sdx))
+		}
+	}
+}
+
+func categoryLabel(value assets_dm.XValue, categories []float64, temporal bool) string {
+	if !temporal {
+		return formatNumber(*value.Number, 0)
+	}
+
+	if len(categories) < 2 {
+		return value.Time.UTC().Format(fullLayout)
+	}
+
+	spacing := math.Inf(1)
+	for idx := 1; idx < len(categories); idx++ {
+		spacing = math.Min(spacing, categories[idx]-categories[idx-1])
+	}
+
+	return value.Time.UTC().Format(timeLayout(spacing))
+}
+
+/*
+ * Helpers
+ */
+
+func axisTitle(title, unit string) string {
+	if unit == "" {
+		return title
+	}
+
+	return fmt.Sprintf("%s (%s)", title, unit)
+}
+
+func xOf(value assets_dm.XValue) float64 {
+	if value.Time != nil {
+		return seconds(*value.Time)
+	}
+	if value.Number != nil {
+		return *value.Number
+	}
+
+	return 0
+}
+
+func bounds(values []float64) (min, max float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	min, max = values[0], values[0]
+	for _, value := range values[1:] {
+		min, max = math.Min(min, value), math.Max(max, value)
+	}
+
+	return min, max
+}
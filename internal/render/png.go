@@ -0,0 +1,141 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+)
+
+// pngPixelSize is font size covered by single pixel of the bitmap font, glyphs are scaled by whole pixels.
+const pngPixelSize = 7
+
+type pngCanvas struct {
+	image *image.RGBA
+}
+
+func newPngCanvas(width, height int) *pngCanvas {
+	return &pngCanvas{image: image.NewRGBA(image.Rect(0, 0, width, height))}
+}
+
+func (c *pngCanvas) rect(x, y, width, height float64, fill color.RGBA) {
+	for py := round(y); py < round(y+height); py++ {
+		for px := round(x); px < round(x+width); px++ {
+			c.set(px, py, fill)
+		}
+	}
+}
+
+// line is drawn by stamping discs along the segment, which gives round caps and joins to polylines.
+func (c *pngCanvas) line(x1, y1, x2, y2, width float64, stroke color.RGBA) {
+	length := math.Hypot(x2-x1, y2-y1)
+	steps := int(math.Ceil(length*2)) + 1
+	// thin discs could miss every pixel center, so they are widened to always cover one
+	radius := math.Max(width/2, 0.75)
+
+	for step := 0; step <= steps; step++ {
+		t := float64(step) / float64(steps)
+		c.circle(x1+(x2-x1)*t, y1+(y2-y1)*t, radius, stroke)
+	}
+}
+
+func (c *pngCanvas) polyline(points []point, width float64, stroke color.RGBA) {
+	for idx := 1; idx < len(points); idx++ {
+		c.line(points[idx-1].x, points[idx-1].y, points[idx].x, points[idx].y, width, stroke)
+	}
+}
+
+func (c *pngCanvas) circle(cx, cy, radius float64, fill color.RGBA) {
+	c.fill(cx, cy, radius, fill, func(dx, dy float64) bool {
+		return true
+	})
+}
+
+func (c *pngCanvas) wedge(cx, cy, radius, from, to float64, fill color.RGBA) {
+	c.fill(cx, cy, radius, fill, func(dx, dy float64) bool {
+		angle := math.Atan2(dx, -dy)
+		if angle < 0 {
+			angle += 2 * math.Pi
+		}
+		return angle >= from && angle < to
+	})
+}
+
+func (c *pngCanvas) text(x, y float64, value string, size float64, fill color.RGBA, anchor anchor, vertical bool) {
+	scale := fontScale(size)
+	start := -shift(anchor, c.measure(value, size))
+	top := -float64(glyphHeight*scale) / 2
+
+	for idx, r := range []rune(value) {
+		rows := glyph(r)
+		for row := 0; row < glyphHeight; row++ {
+			for column := 0; column < glyphWidth; column++ {
+				if rows[row]&(1<<(glyphWidth-1-column)) == 0 {
+					continue
+				}
+
+				for sv := 0; sv < scale; sv++ {
+					for su := 0; su < scale; su++ {
+						u := start + float64((idx*(glyphWidth+glyphSpacing)+column)*scale+su)
+						v := top + float64(row*scale+sv)
+
+						if vertical {
+							c.set(round(x+v), round(y-u), fill)
+						} else {
+							c.set(round(x+u), round(y+v), fill)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func (c *pngCanvas) measure(value string, size float64) float64 {
+	count := len([]rune(value))
+	if count == 0 {
+		return 0
+	}
+
+	return float64((count*(glyphWidth+glyphSpacing) - glyphSpacing) * fontScale(size))
+}
+
+func (c *pngCanvas) encode() ([]byte, error) {
+	var result bytes.Buffer
+	if err := png.Encode(&result, c.image); err != nil {
+		return nil, err
+	}
+
+	return result.Bytes(), nil
+}
+
+// fill paints pixels of the disc which centers pass given test of their offset from the center of the disc.
+func (c *pngCanvas) fill(cx, cy, radius float64, fill color.RGBA, test func(dx, dy float64) bool) {
+	for py := int(math.Floor(cy - radius)); py <= int(math.Ceil(cy+radius)); py++ {
+		for px := int(math.Floor(cx - radius)); px <= int(math.Ceil(cx+radius)); px++ {
+			dx, dy := float64(px)+0.5-cx, float64(py)+0.5-cy
+			if dx*dx+dy*dy <= radius*radius && test(dx, dy) {
+				c.set(px, py, fill)
+			}
+		}
+	}
+}
+
+func (c *pngCanvas) set(x, y int, value color.RGBA) {
+	if (image.Point{X: x, Y: y}).In(c.image.Rect) {
+		c.image.SetRGBA(x, y, value)
+	}
+}
+
+func fontScale(size float64) int {
+	if scale := round(size / pngPixelSize); scale > 1 {
+		return scale
+	}
+
+	return 1
+}
+
+func round(value float64) int {
+	return int(math.Round(value))
+}
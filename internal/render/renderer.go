@@ -0,0 +1,37 @@
+package render
+
+import (
+	assets_dm "assets/internal/core/domain/assets"
+	"assets/internal/core/ports"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ChartRenderer draws charts in pure Go, svg documents use generic monospace font and png images use built-in
+// bitmap font, so neither of them depends on fonts installed on the host.
+type ChartRenderer struct{}
+
+func NewChartRenderer() *ChartRenderer {
+	return &ChartRenderer{}
+}
+
+func (r *ChartRenderer) Render(_ context.Context, asset assets_dm.AssetEntity, params ports.RenderParams) ([]byte, error) {
+	if asset.AssetData.Chart == nil {
+		return nil, errors.New("asset has no chart")
+	}
+
+	var c canvas
+	switch params.Format {
+	case assets_dm.RenderFormatSvg:
+		c = newSvgCanvas(params.Width, params.Height)
+	case assets_dm.RenderFormatPng:
+		c = newPngCanvas(params.Width, params.Height)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", params.Format)
+	}
+
+	drawChart(c, asset.AssetData.Chart.Chart, params.Width, params.Height)
+
+	return c.encode()
+}
@@ -0,0 +1,105 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+)
+
+// svgCharWidth is advance of monospace characters relative to the font size.
+const svgCharWidth = 0.6
+
+var svgAnchors = map[anchor]string{anchorStart: "start", anchorMiddle: "middle", anchorEnd: "end"}
+
+type svgCanvas struct {
+	width, height int
+	body          bytes.Buffer
+}
+
+func newSvgCanvas(width, height int) *svgCanvas {
+	return &svgCanvas{width: width, height: height}
+}
+
+func (c *svgCanvas) rect(x, y, width, height float64, fill color.RGBA) {
+	fmt.Fprintf(&c.body, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"/>`+"\n",
+		num(x), num(y), num(width), num(height), hex(fill))
+}
+
+func (c *svgCanvas) line(x1, y1, x2, y2, width float64, stroke color.RGBA) {
+	fmt.Fprintf(&c.body, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="%s" stroke-width="%s"/>`+"\n",
+		num(x1), num(y1), num(x2), num(y2), hex(stroke), num(width))
+}
+
+func (c *svgCanvas) polyline(points []point, width float64, stroke color.RGBA) {
+	coordinates := make([]string, len(points))
+	for idx, p := range points {
+		coordinates[idx] = num(p.x) + "," + num(p.y)
+	}
+
+	fmt.Fprintf(&c.body, `<polyline points="%s" fill="none" stroke="%s" stroke-width="%s" stroke-linejoin="round"/>`+"\n",
+		strings.Join(coordinates, " "), hex(stroke), num(width))
+}
+
+func (c *svgCanvas) circle(cx, cy, radius float64, fill color.RGBA) {
+	fmt.Fprintf(&c.body, `<circle cx="%s" cy="%s" r="%s" fill="%s"/>`+"\n",
+		num(cx), num(cy), num(radius), hex(fill))
+}
+
+func (c *svgCanvas) wedge(cx, cy, radius, from, to float64, fill color.RGBA) {
+	if to-from >= 2*math.Pi {
+		c.circle(cx, cy, radius, fill)
+		return
+	}
+
+	large := 0
+	if to-from > math.Pi {
+		large = 1
+	}
+
+	fmt.Fprintf(&c.body, `<path d="M %s %s L %s %s A %s %s 0 %d 1 %s %s Z" fill="%s"/>`+"\n",
+		num(cx), num(cy),
+		num(cx+radius*math.Sin(from)), num(cy-radius*math.Cos(from)),
+		num(radius), num(radius), large,
+		num(cx+radius*math.Sin(to)), num(cy-radius*math.Cos(to)),
+		hex(fill))
+}
+
+func (c *svgCanvas) text(x, y float64, value string, size float64, fill color.RGBA, anchor anchor, vertical bool) {
+	transform := ""
+	if vertical {
+		transform = fmt.Sprintf(` transform="rotate(-90 %s %s)"`, num(x), num(y))
+	}
+
+	var escaped bytes.Buffer
+	_ = xml.EscapeText(&escaped, []byte(value))
+
+	fmt.Fprintf(&c.body, `<text x="%s" y="%s" font-family="monospace" font-size="%s" fill="%s" text-anchor="%s" dominant-baseline="central"%s>%s</text>`+"\n",
+		num(x), num(y), num(size), hex(fill), svgAnchors[anchor], transform, escaped.String())
+}
+
+func (c *svgCanvas) measure(value string, size float64) float64 {
+	return float64(len([]rune(value))) * size * svgCharWidth
+}
+
+func (c *svgCanvas) encode() ([]byte, error) {
+	var result bytes.Buffer
+
+	fmt.Fprintf(&result, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		c.width, c.height, c.width, c.height)
+	result.Write(c.body.Bytes())
+	result.WriteString("</svg>\n")
+
+	return result.Bytes(), nil
+}
+
+// num formats coordinate with at most two decimals, which is enough for pixel output and keeps documents small.
+func num(value float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", value), "0"), ".")
+}
+
+func hex(value color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", value.R, value.G, value.B)
+}
@@ -0,0 +1,174 @@
+package render
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// numberTicks splits range of values into at most count+1 steps of 1, 2 or 5 times power of ten. The first and last
+// tick enclose the range.
+func numberTicks(min, max float64, count int) (ticks []float64, step float64) {
+	if min == max {
+		delta := math.Abs(min) / 2
+		if delta == 0 {
+			delta = 1
+		}
+		min, max = min-delta, max+delta
+	}
+
+	if math.IsInf(max-min, 0) {
+		return []float64{min, max}, math.MaxFloat64
+	}
+
+	step = niceStep((max - min) / float64(count))
+	for k := math.Floor(min / step); k <= math.Ceil(max/step); k++ {
+		ticks = append(ticks, k*step)
+	}
+
+	return ticks, step
+}
+
+func niceStep(raw float64) float64 {
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+
+	switch fraction := raw / magnitude; {
+	case fraction <= 1:
+		return magnitude
+	case fraction <= 2:
+		return 2 * magnitude
+	case fraction <= 5:
+		return 5 * magnitude
+	default:
+		return 10 * magnitude
+	}
+}
+
+var suffixes = []struct {
+	scale  float64
+	suffix string
+}{
+	{scale: 1e12, suffix: "T"},
+	{scale: 1e9, suffix: "B"},
+	{scale: 1e6, suffix: "M"},
+	{scale: 1e3, suffix: "k"},
+}
+
+// formatNumber writes tick value with as many decimals as the step between ticks needs, large values are shortened
+// with suffixes. Values without step are written in the shortest exact form.
+func formatNumber(value, step float64) string {
+	if value == 0 {
+		value = 0 // drops sign of negative zero
+	}
+
+	if step <= 0 {
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+
+	for _, s := range suffixes {
+		if step >= s.scale {
+			return formatNumber(value/s.scale, step/s.scale) + s.suffix
+		}
+	}
+
+	decimals := int(math.Ceil(-math.Log10(step)))
+	if decimals < 0 {
+		decimals = 0
+	}
+
+	return strconv.FormatFloat(value, 'f', decimals, 64)
+}
+
+// timeSteps are candidate distances between ticks of time axes, steps of whole months follow the calendar.
+var timeSteps = []struct {
+	seconds float64
+	months  int
+}{
+	{seconds: 1}, {seconds: 5}, {seconds: 15}, {seconds: 30},
+	{seconds: 60}, {seconds: 5 * 60}, {seconds: 15 * 60}, {seconds: 30 * 60},
+	{seconds: 3600}, {seconds: 3 * 3600}, {seconds: 6 * 3600}, {seconds: 12 * 3600},
+	{seconds: 86400}, {seconds: 2 * 86400}, {seconds: 7 * 86400}, {seconds: 14 * 86400},
+	{months: 1}, {months: 3}, {months: 12}, {months: 24}, {months: 60}, {months: 120}, {months: 600}, {months: 1200},
+}
+
+// widest is the moment which gives the longest labels.
+var widest = time.Date(2000, time.December, 30, 23, 59, 59, 0, time.UTC)
+
+// monthSeconds approximates length of the month when steps are compared.
+const monthSeconds = 30 * 86400
+
+// timeTicks places ticks at round moments in UTC between min and max, given as seconds since the epoch. The shortest
+// step is picked, which labels fit given width when they are measured with given function.
+func timeTicks(min, max, width float64, measure func(string) float64) (ticks []float64, labels []string) {
+	if min == max {
+		return []float64{min}, []string{moment(min).Format(fullLayout)}
+	}
+
+	step := timeSteps[len(timeSteps)-1]
+	for _, candidate := range timeSteps {
+		spacing := candidate.seconds + float64(candidate.months)*monthSeconds
+		label := measure(widest.Format(timeLayout(spacing))) + 2*gap
+		if ((max-min)/spacing+1)*label <= width {
+			step = candidate
+			break
+		}
+	}
+
+	layout := timeLayout(step.seconds + float64(step.months)*monthSeconds)
+
+	if step.months == 0 {
+		for k := math.Ceil(min / step.seconds); k*step.seconds <= max; k++ {
+			ticks = append(ticks, k*step.seconds)
+			labels = append(labels, moment(k*step.seconds).Format(layout))
+		}
+		return ticks, labels
+	}
+
+	start := moment(min)
+	months := start.Year()*12 + int(start.Month()) - 1
+	months += (step.months - months%step.months) % step.months
+
+	for ; ; months += step.months {
+		tick := time.Date(months/12, time.Month(months%12+1), 1, 0, 0, 0, 0, time.UTC)
+		value := seconds(tick)
+		if value < min {
+			continue
+		}
+		if value > max {
+			return ticks, labels
+		}
+
+		ticks = append(ticks, value)
+		labels = append(labels, tick.Format(layout))
+	}
+}
+
+// fullLayout formats moments which cannot be compared with others.
+const fullLayout = "2006-01-02 15:04"
+
+// timeLayout picks format of moments which are given number of seconds apart.
+func timeLayout(spacing float64) string {
+	switch {
+	case spacing < 60:
+		return "15:04:05"
+	case spacing < 86400:
+		return "Jan 2 15:04"
+	case spacing < 28*86400:
+		return "Jan 2"
+	case spacing < 365*86400:
+		return "Jan 2006"
+	default:
+		return "2006"
+	}
+}
+
+// seconds and moment convert between moments and seconds since the epoch, whole seconds are kept apart from the
+// fraction, so moments far from the epoch don't overflow nanoseconds.
+func seconds(value time.Time) float64 {
+	return float64(value.Unix()) + float64(value.Nanosecond())/1e9
+}
+
+func moment(value float64) time.Time {
+	whole := math.Floor(value)
+	return time.Unix(int64(whole), int64((value-whole)*1e9)).UTC()
+}
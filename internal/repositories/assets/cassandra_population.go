@@ -5,7 +5,6 @@ import (
 	"assets/internal/core/ports"
 	"context"
 	"errors"
-	"fmt"
 	"sync"
 )
 
@@ -92,26 +91,29 @@ func (cr *CassandraRepo) populate(ctx context.Context, assets ...assets_dm.Asset
 	}
 
 	for idx, asset := range assets {
+		// content of trashed asset is deleted before the asset itself, so interrupted purge leaves assets without it
+		trashed := asset.DeleteTime != nil
+
 		switch asset.Type {
 		case assets_dm.TypeChart:
 			if val, ok := chartsMap[asset.ContentId]; ok {
 				assets[idx].AssetData.Chart = &val
-			} else {
-				fmt.Println("herexd")
+			} else if !trashed {
+				cr.logger.Info("missing chart of asset", "id", asset.Id, "contentId", asset.ContentId)
 				return nil, errors.New("failed to populate data")
 			}
 			break
 		case assets_dm.TypeInsight:
 			if val, ok := insightsMap[asset.ContentId]; ok {
 				assets[idx].AssetData.Insight = &val
-			} else {
+			} else if !trashed {
 				return nil, errors.New("failed to populate data")
 			}
 			break
 		case assets_dm.TypeAudience:
 			if val, ok := audiencesMap[asset.ContentId]; ok {
 				assets[idx].AssetData.Audience = &val
-			} else {
+			} else if !trashed {
 				return nil, errors.New("failed to populate data")
 			}
 			break
@@ -5,13 +5,55 @@ import (
 	"fmt"
 	"github.com/gocql/gocql"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 /*
  * Select
  */
 
-var tableName = "assets"
+var (
+	tableName           = "assets"
+	trashTableName      = "assets_trash_by_month"
+	tagsTableName       = "assets_by_tag"
+	tagCountsTableName  = "assets_tag_counts"
+	ownerTagsTableName  = "assets_tag_counts_by_owner"
+	createTimeTableName = "assets_by_create_month"
+	nameTableName       = "assets_by_name_initial"
+	partitionsTableName = "assets_lookup_partitions"
+)
+
+// trashBucket is the only bucket of the trash, trash is split into partitions by month of deletion.
+const trashBucket = "trash"
+
+// unownedPartition keeps tag counters of assets stored before ownership was introduced, which have no owner.
+const unownedPartition = "-"
+
+// allBucket keeps live assets of all types in order tables, while every type has its own bucket as well. Buckets are
+// split into partitions by month of creation or by initial of the name, so that listings read partitions one after
+// another in order of clustering columns.
+const allBucket = "ALL"
+
+// lookups which partitions are listed in the partitions table, so that they can be read in order
+const (
+	trashLookup      = "trash"
+	createTimeLookup = "create_time"
+	nameLookup       = "name"
+)
+
+// column is the column added to the assets table after its first release, tables created before have to be altered.
+type column struct {
+	name string
+	kind string
+}
+
+var addedColumns = []column{
+	{name: "delete_time", kind: "timestamp"},
+	{name: "tags", kind: "set<text>"},
+	{name: "creator_id", kind: "text"},
+	{name: "owner_id", kind: "text"},
+}
 
 func SelectRecords(session *gocql.Session) (query *gocql.Query) {
 	return session.Query(fmt.Sprintf("SELECT * FROM %s", tableName))
@@ -22,12 +64,135 @@ func SelectRecordsByIds(session *gocql.Session, ids []string) (query *gocql.Quer
 	return session.Query(fmt.Sprintf("SELECT * FROM %s WHERE id IN (%s)", tableName, idList))
 }
 
+// SelectColumnQuery fails unless the assets table has given column.
+func SelectColumnQuery(name string) string {
+	return fmt.Sprintf("SELECT %s FROM %s LIMIT 1", name, tableName)
+}
+
+// SelectPartitions lists partitions of the bucket of given lookup table in ascending order.
+func SelectPartitions(session *gocql.Session, lookup string, bucket string) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("SELECT partition_key FROM %s WHERE lookup = ? AND bucket = ?", partitionsTableName), lookup, bucket)
+}
+
+func SelectTrashedIds(session *gocql.Session, month string, deletedBefore time.Time) (query *gocql.Query) {
+	if deletedBefore.IsZero() {
+		return session.Query(fmt.Sprintf("SELECT id FROM %s WHERE month = ?", trashTableName), month)
+	}
+	return session.Query(fmt.Sprintf("SELECT id FROM %s WHERE month = ? AND delete_time < ?", trashTableName), month, deletedBefore)
+}
+
+func SelectIdsByTag(session *gocql.Session, tag string) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("SELECT asset_id FROM %s WHERE tag = ?", tagsTableName), tag)
+}
+
+// SelectTagCounts reads counters kept along the tag lookup table, the table has single row per tag.
+func SelectTagCounts(session *gocql.Session) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("SELECT tag, total FROM %s", tagCountsTableName))
+}
+
+// SelectOwnerTagCounts reads counters of tags used by assets of the owner.
+func SelectOwnerTagCounts(session *gocql.Session, ownerId string) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("SELECT tag, total FROM %s WHERE owner_id = ?", ownerTagsTableName), ownerPartition(ownerId))
+}
+
+// SelectAllOwnerTagCounts reads counters of tags of all owners.
+func SelectAllOwnerTagCounts(session *gocql.Session) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("SELECT owner_id, tag, total FROM %s", ownerTagsTableName))
+}
+
+func SelectIdsByCreateTime(session *gocql.Session, bucket string, month string, after time.Time, before time.Time, ascending bool) (query *gocql.Query) {
+	var (
+		clauses = []string{"bucket = ?", "month = ?"}
+		values  = []any{bucket, month}
+	)
+
+	if !after.IsZero() {
+		clauses = append(clauses, "create_time > ?")
+		values = append(values, after)
+	}
+
+	if !before.IsZero() {
+		clauses = append(clauses, "create_time < ?")
+		values = append(values, before)
+	}
+
+	return session.Query(fmt.Sprintf("SELECT id FROM %s WHERE %s ORDER BY create_time %s", createTimeTableName, strings.Join(clauses, " AND "), direction(ascending)), values...)
+}
+
+func SelectIdsByName(session *gocql.Session, bucket string, initial string, ascending bool) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("SELECT id FROM %s WHERE bucket = ? AND initial = ? ORDER BY \"name\" %s", nameTableName, direction(ascending)), bucket, initial)
+}
+
+func direction(ascending bool) string {
+	if ascending {
+		return "ASC"
+	}
+	return "DESC"
+}
+
+// monthPartition returns partition of time ordered tables, which is the month of given time.
+func monthPartition(moment time.Time) string {
+	return moment.UTC().Format("2006-01")
+}
+
+// ownerPartition returns partition of the owner tag counters, partition key cannot be empty so assets without owner
+// share partition of their own.
+func ownerPartition(ownerId string) string {
+	if ownerId == "" {
+		return unownedPartition
+	}
+	return ownerId
+}
+
+// namePartition returns partition of the name table, which is the first letter of the name. Partitions come in the
+// same order as names they start, so reading them one after another keeps names in order.
+func namePartition(name string) string {
+	if name == "" {
+		return ""
+	}
+
+	first, _ := utf8.DecodeRuneInString(name)
+	return string(first)
+}
+
 /*
  * Table
  */
 
 func CreateTableQuery() string {
-	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id text PRIMARY KEY, content_id text, \"type\" text, \"name\" text, description text, create_time timestamp, update_time timestamp)", tableName)
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id text PRIMARY KEY, content_id text, \"type\" text, \"name\" text, description text, create_time timestamp, update_time timestamp, delete_time timestamp, tags set<text>, creator_id text, owner_id text)", tableName)
+}
+
+func CreateTrashTableQuery() string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (month text, delete_time timestamp, id text, PRIMARY KEY ((month), delete_time, id)) WITH CLUSTERING ORDER BY (delete_time DESC, id ASC)", trashTableName)
+}
+
+func CreateTagsTableQuery() string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (tag text, asset_id text, PRIMARY KEY ((tag), asset_id))", tagsTableName)
+}
+
+func CreateTagCountsTableQuery() string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (tag text PRIMARY KEY, total counter)", tagCountsTableName)
+}
+
+func CreateOwnerTagsTableQuery() string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (owner_id text, tag text, total counter, PRIMARY KEY ((owner_id), tag))", ownerTagsTableName)
+}
+
+func CreateCreateTimeTableQuery() string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (bucket text, month text, create_time timestamp, id text, PRIMARY KEY ((bucket, month), create_time, id)) WITH CLUSTERING ORDER BY (create_time DESC, id ASC)", createTimeTableName)
+}
+
+func CreateNameTableQuery() string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (bucket text, initial text, \"name\" text, id text, PRIMARY KEY ((bucket, initial), \"name\", id)) WITH CLUSTERING ORDER BY (\"name\" ASC, id ASC)", nameTableName)
+}
+
+func CreatePartitionsTableQuery() string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (lookup text, bucket text, partition_key text, PRIMARY KEY ((lookup, bucket), partition_key))", partitionsTableName)
+}
+
+func AddColumnQuery(added column) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD %s %s", tableName, added.name, added.kind)
 }
 
 func DropTableQuery() string {
@@ -39,16 +204,53 @@ func DropTableQuery() string {
  */
 
 func AppendInsertQuery(batch *gocql.Batch, obj assets_dm.AssetEntity) {
-	batch.Query(fmt.Sprintf("INSERT INTO %s (id, content_id, \"type\", \"name\", description, create_time, update_time) VALUES (?, ?, ?, ?, ?, ?, ?)", tableName),
-		obj.Id, obj.ContentId, obj.Type, obj.Name, obj.Description, obj.CreateTime, obj.UpdateTime)
+	batch.Query(fmt.Sprintf("INSERT INTO %s (id, content_id, \"type\", \"name\", description, create_time, update_time, tags, creator_id, owner_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", tableName),
+		obj.Id, obj.ContentId, obj.Type, obj.Name, obj.Description, obj.CreateTime, obj.UpdateTime, obj.Tags, obj.CreatorId, obj.OwnerId)
+}
+
+func AppendInsertOrderQueries(batch *gocql.Batch, obj assets_dm.AssetEntity) {
+	month, initial := monthPartition(obj.CreateTime), namePartition(obj.Name)
+	for _, bucket := range []string{allBucket, obj.Type} {
+		batch.Query(fmt.Sprintf("INSERT INTO %s (bucket, month, create_time, id) VALUES (?, ?, ?, ?)", createTimeTableName), bucket, month, obj.CreateTime, obj.Id)
+		batch.Query(fmt.Sprintf("INSERT INTO %s (bucket, initial, \"name\", id) VALUES (?, ?, ?, ?)", nameTableName), bucket, initial, obj.Name, obj.Id)
+		AppendInsertPartitionQuery(batch, createTimeLookup, bucket, month)
+		AppendInsertPartitionQuery(batch, nameLookup, bucket, initial)
+	}
+}
+
+// AppendInsertPartitionQuery lists partition of lookup table, partitions stay listed once they are emptied.
+func AppendInsertPartitionQuery(batch *gocql.Batch, lookup string, bucket string, partition string) {
+	batch.Query(fmt.Sprintf("INSERT INTO %s (lookup, bucket, partition_key) VALUES (?, ?, ?)", partitionsTableName), lookup, bucket, partition)
+}
+
+func AppendInsertTagQuery(batch *gocql.Batch, tag string, assetId string) {
+	batch.Query(fmt.Sprintf("INSERT INTO %s (tag, asset_id) VALUES (?, ?)", tagsTableName), tag, assetId)
 }
 
 /*
  * Update
  */
 
+// AppendUpdateTagCountQuery changes counter of the tag by delta, counters can be changed only within counter batch.
+func AppendUpdateTagCountQuery(batch *gocql.Batch, tag string, delta int) {
+	batch.Query(fmt.Sprintf("UPDATE %s SET total = total + ? WHERE tag = ?", tagCountsTableName), int64(delta), tag)
+}
+
+// AppendUpdateOwnerTagCountQuery changes counter of the tag among assets of the owner by delta.
+func AppendUpdateOwnerTagCountQuery(batch *gocql.Batch, ownerId string, tag string, delta int) {
+	batch.Query(fmt.Sprintf("UPDATE %s SET total = total + ? WHERE owner_id = ? AND tag = ?", ownerTagsTableName), int64(delta), ownerPartition(ownerId), tag)
+}
+
 func AppendUpdateQuery(batch *gocql.Batch, obj assets_dm.AssetEntity) {
-	batch.Query(fmt.Sprintf("UPDATE %s SET description = ? WHERE id = ?", tableName), obj.Description, obj.Id)
+	batch.Query(fmt.Sprintf("UPDATE %s SET \"name\" = ?, description = ?, update_time = ?, delete_time = ?, tags = ?, owner_id = ? WHERE id = ?", tableName),
+		obj.Name, obj.Description, obj.UpdateTime, obj.DeleteTime, obj.Tags, obj.OwnerId, obj.Id)
+}
+
+func AppendInsertTrashQuery(batch *gocql.Batch, obj assets_dm.AssetEntity) {
+	month := monthPartition(*obj.DeleteTime)
+	batch.Query(fmt.Sprintf("INSERT INTO %s (month, delete_time, id) VALUES (?, ?, ?)", trashTableName),
+		month, *obj.DeleteTime, obj.Id)
+	AppendInsertPartitionQuery(batch, trashLookup, trashBucket, month)
 }
 
 /*
@@ -58,3 +260,26 @@ func AppendUpdateQuery(batch *gocql.Batch, obj assets_dm.AssetEntity) {
 func AppendDeleteQuery(batch *gocql.Batch, obj assets_dm.AssetEntity) {
 	batch.Query(fmt.Sprintf("DELETE FROM %s WHERE id = ?", tableName), obj.Id)
 }
+
+func AppendDeleteTrashQuery(batch *gocql.Batch, obj assets_dm.AssetEntity) {
+	batch.Query(fmt.Sprintf("DELETE FROM %s WHERE month = ? AND delete_time = ? AND id = ?", trashTableName),
+		monthPartition(*obj.DeleteTime), *obj.DeleteTime, obj.Id)
+}
+
+func AppendDeleteTagQuery(batch *gocql.Batch, tag string, assetId string) {
+	batch.Query(fmt.Sprintf("DELETE FROM %s WHERE tag = ? AND asset_id = ?", tagsTableName), tag, assetId)
+}
+
+func AppendDeleteCreateTimeQueries(batch *gocql.Batch, obj assets_dm.AssetEntity) {
+	for _, bucket := range []string{allBucket, obj.Type} {
+		batch.Query(fmt.Sprintf("DELETE FROM %s WHERE bucket = ? AND month = ? AND create_time = ? AND id = ?", createTimeTableName),
+			bucket, monthPartition(obj.CreateTime), obj.CreateTime, obj.Id)
+	}
+}
+
+func AppendDeleteNameQueries(batch *gocql.Batch, obj assets_dm.AssetEntity) {
+	for _, bucket := range []string{allBucket, obj.Type} {
+		batch.Query(fmt.Sprintf("DELETE FROM %s WHERE bucket = ? AND initial = ? AND \"name\" = ? AND id = ?", nameTableName),
+			bucket, namePartition(obj.Name), obj.Name, obj.Id)
+	}
+}
@@ -4,15 +4,20 @@ import (
 	assets_dm "assets/internal/core/domain/assets"
 	"assets/internal/core/ports"
 	"assets/pkg/logging"
+	"assets/pkg/slices"
 	"context"
 	"encoding/base64"
-	"fmt"
+	"encoding/json"
 	"github.com/gocql/gocql"
 	"github.com/pkg/errors"
+	"sort"
 	"time"
 )
 
-const cassandraMaxLimit = 10_000
+const (
+	cassandraMaxLimit = 10_000
+	walkPageSize      = 1000
+)
 
 type CassandraRepo struct {
 	logger        logging.Logger
@@ -31,6 +36,45 @@ func NewCassandraRepo(logger logging.Logger, session *gocql.Session, chartsRepo
 		panic(errors.Wrap(err, "failed to inspect/create assets table"))
 	}
 
+	// tables created by previous versions lack columns added since, they have to exist before any asset is read
+	for _, added := range addedColumns {
+		if err := session.Query(SelectColumnQuery(added.name)).WithContext(ctx).Exec(); err == nil {
+			continue
+		}
+
+		if err := session.Query(AddColumnQuery(added)).WithContext(ctx).Exec(); err != nil {
+			panic(errors.Wrap(err, "failed to add "+added.name+" column to assets table"))
+		}
+	}
+
+	if err := session.Query(CreateTrashTableQuery()).WithContext(ctx).Exec(); err != nil {
+		panic(errors.Wrap(err, "failed to inspect/create assets trash table"))
+	}
+
+	if err := session.Query(CreateTagsTableQuery()).WithContext(ctx).Exec(); err != nil {
+		panic(errors.Wrap(err, "failed to inspect/create assets tags table"))
+	}
+
+	if err := session.Query(CreateTagCountsTableQuery()).WithContext(ctx).Exec(); err != nil {
+		panic(errors.Wrap(err, "failed to inspect/create assets tag counts table"))
+	}
+
+	if err := session.Query(CreateOwnerTagsTableQuery()).WithContext(ctx).Exec(); err != nil {
+		panic(errors.Wrap(err, "failed to inspect/create assets tag counts by owner table"))
+	}
+
+	if err := session.Query(CreateCreateTimeTableQuery()).WithContext(ctx).Exec(); err != nil {
+		panic(errors.Wrap(err, "failed to inspect/create assets create time table"))
+	}
+
+	if err := session.Query(CreateNameTableQuery()).WithContext(ctx).Exec(); err != nil {
+		panic(errors.Wrap(err, "failed to inspect/create assets name table"))
+	}
+
+	if err := session.Query(CreatePartitionsTableQuery()).WithContext(ctx).Exec(); err != nil {
+		panic(errors.Wrap(err, "failed to inspect/create assets lookup partitions table"))
+	}
+
 	return &CassandraRepo{logger: logger, session: session, chartsRepo: chartsRepo, insightsRepo: insightsRepo, audiencesRepo: audiencesRepo}
 }
 
@@ -41,69 +85,444 @@ func (cr *CassandraRepo) Select(ctx context.Context, params ports.SelectAssetsRe
 		"results", results,
 	)
 
-	var (
-		limit  = cassandraMaxLimit
-		cursor = make([]byte, 0)
-	)
-
+	limit := cassandraMaxLimit
 	if params.Limit != 0 {
 		limit = params.Limit
 	}
 
-	if params.Cursor != "" {
-		if cursor, err = base64.URLEncoding.DecodeString(params.Cursor); err != nil {
+	keep := func(asset assets_dm.AssetEntity) bool {
+		return matchesParams(asset, params)
+	}
+
+	if len(params.Ids) != 0 || (!params.Deleted && !ordered(params) && len(params.Tags) == 0) {
+		if results, next, err = cr.selectRecords(ctx, params, limit, keep); err != nil {
+			return nil, next, err
+		}
+	} else {
+		// assets are paged through the lookup table, filters the table cannot apply are checked on loaded assets
+		var table lookup
+		if table, err = cr.lookup(ctx, params); err != nil {
+			return nil, next, err
+		}
+
+		if results, next, err = cr.selectByLookup(ctx, table, limit, params.Cursor, keep); err != nil {
 			return nil, next, err
 		}
 	}
 
-	var query *gocql.Query
-	if len(params.Ids) != 0 {
-		query = SelectRecordsByIds(cr.session, params.Ids)
-	} else {
-		query = SelectRecords(cr.session)
+	if results, err = cr.populate(ctx, results...); err != nil {
+		cr.logger.Info("failed to populate assets", "err", err)
+		return nil, next, err
 	}
 
+	return results, next, nil
+}
+
+// lookup is the lookup table returning asset ids, which is read partition after partition in the listed order.
+type lookup struct {
+	partitions []string
+	query      func(partition string) *gocql.Query
+}
+
+// lookupCursor points at the page of the lookup table, it's handed out encoded as the cursor of the listing.
+type lookupCursor struct {
+	Partition string `json:"p"`
+	State     []byte `json:"s,omitempty"`
+}
+
+// lookup picks the lookup table serving the listing. Trash and order tables are split into partitions by month or by
+// initial of the name, while tags are read from the partition of the first tag and remaining tags are checked on
+// loaded assets.
+func (cr *CassandraRepo) lookup(ctx context.Context, params ports.SelectAssetsRepoParams) (table lookup, err error) {
+
+	bucket := allBucket
+	if params.Type != "" {
+		bucket = params.Type
+	}
+
+	switch {
+	case params.Deleted:
+		if table.partitions, err = cr.partitions(ctx, trashLookup, trashBucket, false); err != nil {
+			return table, err
+		}
+
+		if !params.DeletedBefore.IsZero() {
+			table.partitions = slices.Filter(table.partitions, func(month string) bool {
+				return month <= monthPartition(params.DeletedBefore)
+			})
+		}
+
+		table.query = func(month string) *gocql.Query {
+			return SelectTrashedIds(cr.session, month, params.DeletedBefore)
+		}
+	case params.Sort == assets_dm.SortNameAsc || params.Sort == assets_dm.SortNameDesc:
+		ascending := params.Sort == assets_dm.SortNameAsc
+		if table.partitions, err = cr.partitions(ctx, nameLookup, bucket, ascending); err != nil {
+			return table, err
+		}
+
+		table.query = func(initial string) *gocql.Query {
+			return SelectIdsByName(cr.session, bucket, initial, ascending)
+		}
+	case ordered(params):
+		ascending := params.Sort == assets_dm.SortCreateTimeAsc
+		if table.partitions, err = cr.partitions(ctx, createTimeLookup, bucket, ascending); err != nil {
+			return table, err
+		}
+
+		table.partitions = slices.Filter(table.partitions, func(month string) bool {
+			return (params.CreatedAfter.IsZero() || month >= monthPartition(params.CreatedAfter)) &&
+				(params.CreatedBefore.IsZero() || month <= monthPartition(params.CreatedBefore))
+		})
+
+		table.query = func(month string) *gocql.Query {
+			return SelectIdsByCreateTime(cr.session, bucket, month, params.CreatedAfter, params.CreatedBefore, ascending)
+		}
+	default:
+		table.partitions = []string{params.Tags[0]}
+		table.query = func(tag string) *gocql.Query {
+			return SelectIdsByTag(cr.session, tag)
+		}
+	}
+
+	return table, nil
+}
+
+// partitions lists partitions of the bucket of lookup table in ascending or descending order.
+func (cr *CassandraRepo) partitions(ctx context.Context, lookup string, bucket string, ascending bool) (results []string, err error) {
+
 	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel()
 
-	iter := query.WithContext(ctx).PageSize(limit).PageState(cursor).Iter()
-	defer func() {
-		if err = iter.Close(); err != nil {
-			cr.logger.Info("failed to close iterator", "err", err)
+	iter := SelectPartitions(cr.session, lookup, bucket).WithContext(ctx).Iter()
+
+	var partition string
+	for iter.Scan(&partition) {
+		results = append(results, partition)
+	}
+
+	if err = iter.Close(); err != nil {
+		return nil, err
+	}
+
+	if !ascending {
+		for a, b := 0, len(results)-1; a < b; a, b = a+1, b-1 {
+			results[a], results[b] = results[b], results[a]
 		}
-	}()
+	}
 
-	if len(iter.PageState()) > 0 {
-		next = base64.URLEncoding.EncodeToString(iter.PageState())
+	return results, nil
+}
+
+// selectByLookup pages through lookup table returning asset ids and loads assets found on the page, so the cursor
+// belongs to the lookup table and assets come in its order. Assets which don't pass 'keep' are dropped, so pages are
+// read until the limit is filled or the table runs out of ids.
+func (cr *CassandraRepo) selectByLookup(ctx context.Context, table lookup, limit int, cursor string, keep func(asset assets_dm.AssetEntity) bool) (results []assets_dm.AssetEntity, next string, err error) {
+
+	var position lookupCursor
+	if cursor != "" {
+		var data []byte
+		if data, err = base64.URLEncoding.DecodeString(cursor); err != nil {
+			return nil, next, err
+		}
+
+		if err = json.Unmarshal(data, &position); err != nil {
+			return nil, next, err
+		}
 	}
 
-	var obj assets_dm.AssetEntity
+	idx := 0
+	if position.Partition != "" {
+		for idx < len(table.partitions) && table.partitions[idx] != position.Partition {
+			idx++
+		}
 
-	scanner := iter.Scanner()
-	for scanner.Next() {
-		if err = scanner.Scan(&obj.Id, &obj.ContentId, &obj.CreateTime, &obj.Description, &obj.Name, &obj.Type, &obj.UpdateTime); err != nil {
-			fmt.Println("xdxd", err)
+		if idx == len(table.partitions) {
+			return nil, next, errors.New("cursor doesn't belong to the listing")
+		}
+	}
+
+	state := position.State
+	for idx < len(table.partitions) && len(results) < limit {
+		var ids []string
+		if ids, state, err = cr.scanIds(ctx, table.query(table.partitions[idx]), limit-len(results), state); err != nil {
+			return nil, next, err
+		}
+
+		var assets []assets_dm.AssetEntity
+		if assets, err = cr.load(ctx, ids, keep); err != nil {
 			return nil, next, err
-		} else {
-			results = append(results, obj)
+		}
+		results = append(results, assets...)
+
+		// partition is done once it has no further page
+		if len(state) == 0 {
+			idx++
 		}
 	}
 
-	if err = scanner.Err(); err != nil {
-		fmt.Println("xdxd2", err)
+	if idx == len(table.partitions) {
+		return results, next, nil
+	}
 
+	var data []byte
+	if data, err = json.Marshal(lookupCursor{Partition: table.partitions[idx], State: state}); err != nil {
 		return nil, next, err
 	}
 
-	if results, err = cr.populate(ctx, results...); err != nil {
-		fmt.Println("xdxd3", err)
+	return results, base64.URLEncoding.EncodeToString(data), nil
+}
 
-		return nil, next, err
+// selectRecords pages through the assets table itself, pages are read until the limit is filled with assets which
+// pass 'keep' or the table runs out of assets.
+func (cr *CassandraRepo) selectRecords(ctx context.Context, params ports.SelectAssetsRepoParams, limit int, keep func(asset assets_dm.AssetEntity) bool) (results []assets_dm.AssetEntity, next string, err error) {
+
+	cursor := make([]byte, 0)
+	if params.Cursor != "" {
+		if cursor, err = base64.URLEncoding.DecodeString(params.Cursor); err != nil {
+			return nil, next, err
+		}
+	}
+
+	for len(results) < limit {
+		query := SelectRecords(cr.session)
+		if len(params.Ids) != 0 {
+			query = SelectRecordsByIds(cr.session, params.Ids)
+		}
+
+		var assets []assets_dm.AssetEntity
+		if assets, next, err = cr.scan(ctx, query, limit-len(results), cursor); err != nil {
+			return nil, next, err
+		}
+
+		// trashed assets are returned only when they were asked for
+		results = append(results, slices.Filter(assets, keep)...)
+
+		if next == "" {
+			break
+		}
+
+		if cursor, err = base64.URLEncoding.DecodeString(next); err != nil {
+			return nil, next, err
+		}
 	}
 
 	return results, next, nil
 }
 
+// scanIds reads single page of asset ids from lookup table, returned state points at the next page.
+func (cr *CassandraRepo) scanIds(ctx context.Context, query *gocql.Query, limit int, state []byte) (ids []string, next []byte, err error) {
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	if state == nil {
+		state = make([]byte, 0)
+	}
+
+	iter := query.WithContext(ctx).PageSize(limit).PageState(state).Iter()
+	next = append(next, iter.PageState()...)
+
+	var id string
+	for iter.Scan(&id) {
+		ids = append(ids, id)
+	}
+
+	if err = iter.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	return ids, next, nil
+}
+
+// load reads assets with given ids which pass 'keep', order of ids is preserved.
+func (cr *CassandraRepo) load(ctx context.Context, ids []string, keep func(asset assets_dm.AssetEntity) bool) (results []assets_dm.AssetEntity, err error) {
+
+	if len(ids) == 0 {
+		return results, nil
+	}
+
+	var assets []assets_dm.AssetEntity
+	if assets, _, err = cr.scan(ctx, SelectRecordsByIds(cr.session, ids), cassandraMaxLimit, nil); err != nil {
+		return nil, err
+	}
+
+	mapper := make(map[string]assets_dm.AssetEntity, len(assets))
+	for _, asset := range assets {
+		mapper[asset.Id] = asset
+	}
+
+	for _, id := range ids {
+		if asset, ok := mapper[id]; ok && keep(asset) {
+			results = append(results, asset)
+		}
+	}
+
+	return results, nil
+}
+
+func (cr *CassandraRepo) SelectTags(ctx context.Context, params ports.SelectTagsRepoParams) (results []assets_dm.TagCount, err error) {
+
+	cr.logger.Info("assets_db.SelectTags() performed",
+		"params", params,
+		"results", results,
+	)
+
+	var counts map[string]int
+	if len(params.OwnerIds) == 0 {
+		if counts, err = cr.tagCounts(ctx); err != nil {
+			return nil, err
+		}
+	} else {
+		counts = make(map[string]int)
+		for _, ownerId := range params.OwnerIds {
+			if err = cr.countTags(ctx, SelectOwnerTagCounts(cr.session, ownerId), false, func(_ string, tag string, count int) {
+				counts[tag] += count
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for tag, count := range counts {
+		// counters of tags which are no longer used stay in the table at zero
+		if count > 0 {
+			results = append(results, assets_dm.TagCount{Tag: tag, Count: count})
+		}
+	}
+
+	sort.Slice(results, func(a, b int) bool {
+		return results[a].Tag < results[b].Tag
+	})
+
+	return results, nil
+}
+
+// BackfillTags puts live assets stored before tags were indexed into the tag lookup table and brings tag counters in
+// line with it. Counters are moved by the difference from the counted value, so the backfill can be run again.
+func (cr *CassandraRepo) BackfillTags(ctx context.Context) (err error) {
+
+	cr.logger.Info("assets_db.BackfillTags() performed")
+
+	counted := make(map[string]int)
+	if err = cr.walk(ctx, func(asset assets_dm.AssetEntity) error {
+		tags := indexedTags(asset)
+		if len(tags) == 0 {
+			return nil
+		}
+
+		batch := cr.session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
+		for _, tag := range tags {
+			AppendInsertTagQuery(batch, tag, asset.Id)
+			counted[tag]++
+		}
+
+		return cr.session.ExecuteBatch(batch)
+	}); err != nil {
+		return err
+	}
+
+	var counts map[string]int
+	if counts, err = cr.tagCounts(ctx); err != nil {
+		return err
+	}
+
+	for tag := range counts {
+		if _, ok := counted[tag]; !ok {
+			counted[tag] = 0
+		}
+	}
+
+	batch := cr.session.NewBatch(gocql.CounterBatch).WithContext(ctx)
+	for tag, count := range counted {
+		if delta := count - counts[tag]; delta != 0 {
+			AppendUpdateTagCountQuery(batch, tag, delta)
+		}
+	}
+
+	if len(batch.Entries) == 0 {
+		return nil
+	}
+
+	return cr.session.ExecuteBatch(batch)
+}
+
+// BackfillOwnerTags brings tag counters of every owner in line with live assets stored before the counters were kept.
+// Counters are moved by the difference from the counted value, so the backfill can be run again.
+func (cr *CassandraRepo) BackfillOwnerTags(ctx context.Context) (err error) {
+
+	cr.logger.Info("assets_db.BackfillOwnerTags() performed")
+
+	counted := make(map[string]map[string]int)
+	if err = cr.walk(ctx, func(asset assets_dm.AssetEntity) error {
+		owner := ownerPartition(asset.OwnerId)
+		for _, tag := range indexedTags(asset) {
+			if counted[owner] == nil {
+				counted[owner] = make(map[string]int)
+			}
+			counted[owner][tag]++
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	var counts map[string]map[string]int
+	if counts, err = cr.ownerTagCounts(ctx); err != nil {
+		return err
+	}
+
+	for owner, tags := range counts {
+		for tag := range tags {
+			if counted[owner] == nil {
+				counted[owner] = make(map[string]int)
+			}
+			if _, ok := counted[owner][tag]; !ok {
+				counted[owner][tag] = 0
+			}
+		}
+	}
+
+	// every owner gets batch of its own, so that batches stay within single partition
+	for owner, tags := range counted {
+		batch := cr.session.NewBatch(gocql.CounterBatch).WithContext(ctx)
+		for tag, count := range tags {
+			if delta := count - counts[owner][tag]; delta != 0 {
+				AppendUpdateOwnerTagCountQuery(batch, owner, tag, delta)
+			}
+		}
+
+		if len(batch.Entries) == 0 {
+			continue
+		}
+
+		if err = cr.session.ExecuteBatch(batch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// BackfillOrder puts assets stored before trash and order tables were split into partitions into the current tables.
+// Rows are written as they would be on insert, so the backfill can be run again.
+func (cr *CassandraRepo) BackfillOrder(ctx context.Context) (err error) {
+
+	cr.logger.Info("assets_db.BackfillOrder() performed")
+
+	return cr.walk(ctx, func(asset assets_dm.AssetEntity) error {
+		batch := cr.session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
+		if asset.DeleteTime != nil {
+			AppendInsertTrashQuery(batch, asset)
+		} else {
+			AppendInsertOrderQueries(batch, asset)
+		}
+
+		return cr.session.ExecuteBatch(batch)
+	})
+}
+
 func (cr *CassandraRepo) Insert(ctx context.Context, assets ...assets_dm.AssetEntity) (results []assets_dm.AssetEntity, err error) {
 
 	cr.logger.Info("assets_db.Insert() performed",
@@ -111,7 +530,10 @@ func (cr *CassandraRepo) Insert(ctx context.Context, assets ...assets_dm.AssetEn
 		"results", results,
 	)
 
-	if err = cr.execute(ctx, assets, AppendInsertQuery); err != nil {
+	if err = cr.execute(ctx, assets, func(batch *gocql.Batch, counters *gocql.Batch, asset assets_dm.AssetEntity) {
+		AppendInsertQuery(batch, asset)
+		appendLookupQueries(batch, counters, assets_dm.AssetEntity{}, asset)
+	}); err != nil {
 		return nil, err
 	}
 
@@ -133,7 +555,21 @@ func (cr *CassandraRepo) Update(ctx context.Context, models ...assets_dm.AssetEn
 		return results, nil
 	}
 
-	if err = cr.execute(ctx, models, AppendUpdateQuery); err != nil {
+	// lookup rows are keyed by mutable columns, so previous state of the assets has to be found first
+	var previous []assets_dm.AssetEntity
+	if previous, _, err = cr.scan(ctx, SelectRecordsByIds(cr.session, ids(models)), cassandraMaxLimit, nil); err != nil {
+		return nil, err
+	}
+
+	mapper := make(map[string]assets_dm.AssetEntity, len(previous))
+	for _, asset := range previous {
+		mapper[asset.Id] = asset
+	}
+
+	if err = cr.execute(ctx, models, func(batch *gocql.Batch, counters *gocql.Batch, asset assets_dm.AssetEntity) {
+		AppendUpdateQuery(batch, asset)
+		appendLookupQueries(batch, counters, mapper[asset.Id], asset)
+	}); err != nil {
 		return nil, err
 	}
 
@@ -155,27 +591,266 @@ func (cr *CassandraRepo) Delete(ctx context.Context, models ...assets_dm.AssetEn
 		return results, nil
 	}
 
-	if err = cr.execute(ctx, models, AppendDeleteQuery); err != nil {
+	if err = cr.execute(ctx, models, func(batch *gocql.Batch, counters *gocql.Batch, asset assets_dm.AssetEntity) {
+		AppendDeleteQuery(batch, asset)
+		appendLookupQueries(batch, counters, asset, assets_dm.AssetEntity{})
+	}); err != nil {
 		return nil, err
 	}
 
 	return models, nil
 }
 
-func (cr *CassandraRepo) execute(ctx context.Context, assets []assets_dm.AssetEntity, action func(batch *gocql.Batch, asset assets_dm.AssetEntity)) (err error) {
+func (cr *CassandraRepo) execute(ctx context.Context, assets []assets_dm.AssetEntity, action func(batch *gocql.Batch, counters *gocql.Batch, asset assets_dm.AssetEntity)) (err error) {
 
 	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel()
 
 	batch := cr.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	counters := cr.session.NewBatch(gocql.CounterBatch).WithContext(ctx)
 	for idx := range assets {
 		assets[idx].UpdateTime = time.Now()
-		action(batch, assets[idx])
+		action(batch, counters, assets[idx])
 	}
 
 	if err = cr.session.ExecuteBatch(batch); err != nil {
 		return err
 	}
 
+	// counters cannot share batch with other tables, failure is logged since the assets are already stored
+	if len(counters.Entries) != 0 {
+		if err = cr.session.ExecuteBatch(counters); err != nil {
+			cr.logger.Info("failed to update tag counts", "err", err)
+		}
+	}
+
 	return nil
 }
+
+func (cr *CassandraRepo) scan(ctx context.Context, query *gocql.Query, limit int, cursor []byte) (results []assets_dm.AssetEntity, next string, err error) {
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	iter := query.WithContext(ctx).PageSize(limit).PageState(cursor).Iter()
+	defer func() {
+		if err = iter.Close(); err != nil {
+			cr.logger.Info("failed to close iterator", "err", err)
+		}
+	}()
+
+	if len(iter.PageState()) > 0 {
+		next = base64.URLEncoding.EncodeToString(iter.PageState())
+	}
+
+	scanner := iter.Scanner()
+	for scanner.Next() {
+		var obj assets_dm.AssetEntity
+		if err = scanner.Scan(&obj.Id, &obj.ContentId, &obj.CreateTime, &obj.CreatorId, &obj.DeleteTime, &obj.Description, &obj.Name, &obj.OwnerId, &obj.Tags, &obj.Type, &obj.UpdateTime); err != nil {
+			cr.logger.Info("failed to scan asset", "err", err)
+			return nil, next, err
+		} else {
+			results = append(results, obj)
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		cr.logger.Info("failed to scan assets", "err", err)
+		return nil, next, err
+	}
+
+	return results, next, nil
+}
+
+// appendLookupQueries brings lookup tables in line with the change of the asset from old to new state, zero value
+// stands for the asset which doesn't exist. Only live assets are kept in tag and order tables.
+func appendLookupQueries(batch *gocql.Batch, counters *gocql.Batch, old assets_dm.AssetEntity, new assets_dm.AssetEntity) {
+	if old.DeleteTime != nil && (new.DeleteTime == nil || !new.DeleteTime.Equal(*old.DeleteTime)) {
+		AppendDeleteTrashQuery(batch, old)
+	}
+	if new.DeleteTime != nil {
+		AppendInsertTrashQuery(batch, new)
+	}
+
+	tags, oldTags := indexedTags(new), indexedTags(old)
+	for _, tag := range oldTags {
+		if !slices.Contains(tags, tag) {
+			AppendDeleteTagQuery(batch, tag, old.Id)
+			AppendUpdateTagCountQuery(counters, tag, -1)
+		}
+	}
+	for _, tag := range tags {
+		AppendInsertTagQuery(batch, tag, new.Id)
+		if !slices.Contains(oldTags, tag) {
+			AppendUpdateTagCountQuery(counters, tag, 1)
+		}
+	}
+
+	// counters of the owner move along with the tags, transferred asset moves all its tags to the new owner
+	sameOwner := old.Id == "" || new.Id == "" || old.OwnerId == new.OwnerId
+	for _, tag := range oldTags {
+		if !sameOwner || !slices.Contains(tags, tag) {
+			AppendUpdateOwnerTagCountQuery(counters, old.OwnerId, tag, -1)
+		}
+	}
+	for _, tag := range tags {
+		if !sameOwner || !slices.Contains(oldTags, tag) {
+			AppendUpdateOwnerTagCountQuery(counters, new.OwnerId, tag, 1)
+		}
+	}
+
+	var (
+		oldLive = old.Id != "" && old.DeleteTime == nil
+		newLive = new.Id != "" && new.DeleteTime == nil
+	)
+
+	if oldLive && !newLive {
+		AppendDeleteCreateTimeQueries(batch, old)
+	}
+	if oldLive && (!newLive || old.Name != new.Name) {
+		AppendDeleteNameQueries(batch, old)
+	}
+	if newLive {
+		AppendInsertOrderQueries(batch, new)
+	}
+}
+
+// tagCounts reads counters of all tags, including the ones which dropped to zero.
+func (cr *CassandraRepo) tagCounts(ctx context.Context) (results map[string]int, err error) {
+
+	results = make(map[string]int)
+	if err = cr.countTags(ctx, SelectTagCounts(cr.session), false, func(_ string, tag string, count int) {
+		results[tag] = count
+	}); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// ownerTagCounts reads counters of tags of all owners, including the ones which dropped to zero.
+func (cr *CassandraRepo) ownerTagCounts(ctx context.Context) (results map[string]map[string]int, err error) {
+
+	results = make(map[string]map[string]int)
+	if err = cr.countTags(ctx, SelectAllOwnerTagCounts(cr.session), true, func(owner string, tag string, count int) {
+		if results[owner] == nil {
+			results[owner] = make(map[string]int)
+		}
+		results[owner][tag] = count
+	}); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// countTags scans tag counters read by the query, owner is scanned only when the query selects it.
+func (cr *CassandraRepo) countTags(ctx context.Context, query *gocql.Query, withOwner bool, action func(owner string, tag string, count int)) (err error) {
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	iter := query.WithContext(ctx).Iter()
+
+	var (
+		owner string
+		tag   string
+		count int64
+	)
+
+	destinations := []interface{}{&tag, &count}
+	if withOwner {
+		destinations = []interface{}{&owner, &tag, &count}
+	}
+
+	for iter.Scan(destinations...) {
+		action(owner, tag, int(count))
+	}
+
+	if err = iter.Close(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// walk pages through all stored assets, both live and trashed, and calls action for every one of them.
+func (cr *CassandraRepo) walk(ctx context.Context, action func(asset assets_dm.AssetEntity) error) (err error) {
+
+	cursor := make([]byte, 0)
+	for {
+		var (
+			assets []assets_dm.AssetEntity
+			next   string
+		)
+
+		if assets, next, err = cr.scan(ctx, SelectRecords(cr.session), walkPageSize, cursor); err != nil {
+			return err
+		}
+
+		for _, asset := range assets {
+			if err = action(asset); err != nil {
+				return err
+			}
+		}
+
+		if next == "" {
+			return nil
+		}
+
+		if cursor, err = base64.URLEncoding.DecodeString(next); err != nil {
+			return err
+		}
+	}
+}
+
+// matchesParams reports whether the asset belongs to the live assets or the trash, whichever was requested, and
+// whether it is labelled with all requested tags.
+func matchesParams(asset assets_dm.AssetEntity, params ports.SelectAssetsRepoParams) bool {
+	if params.Type != "" && asset.Type != params.Type {
+		return false
+	}
+
+	if !params.CreatedAfter.IsZero() && !asset.CreateTime.After(params.CreatedAfter) {
+		return false
+	}
+
+	if !params.CreatedBefore.IsZero() && !asset.CreateTime.Before(params.CreatedBefore) {
+		return false
+	}
+
+	for _, tag := range params.Tags {
+		if !slices.Contains(asset.Tags, tag) {
+			return false
+		}
+	}
+
+	if !params.Deleted {
+		return asset.DeleteTime == nil
+	}
+
+	return asset.DeleteTime != nil && (params.DeletedBefore.IsZero() || asset.DeleteTime.Before(params.DeletedBefore))
+}
+
+// ordered reports whether the listing has to be read from order tables.
+func ordered(params ports.SelectAssetsRepoParams) bool {
+	return params.Type != "" || !params.CreatedAfter.IsZero() || !params.CreatedBefore.IsZero() || params.Sort != ""
+}
+
+// indexedTags returns tags kept in the lookup table, tags of trashed assets are left out so that they don't show up
+// in tag listings.
+func indexedTags(asset assets_dm.AssetEntity) []string {
+	if asset.DeleteTime != nil {
+		return nil
+	}
+
+	return asset.Tags
+}
+
+func ids(models []assets_dm.AssetEntity) (results []string) {
+	for _, model := range models {
+		results = append(results, model.Id)
+	}
+
+	return results
+}
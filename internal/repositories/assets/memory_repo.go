@@ -4,7 +4,10 @@ import (
 	assets_dm "assets/internal/core/domain/assets"
 	"assets/internal/core/ports"
 	errs "assets/pkg/errors"
+	"assets/pkg/slices"
 	"context"
+	"sort"
+	"strconv"
 )
 
 /// test purposes database
@@ -23,17 +26,37 @@ func (i *InMemoryDb) Select(_ context.Context, params ports.SelectAssetsRepoPara
 
 	if len(params.Ids) != 0 {
 		for _, id := range params.Ids {
-			if value, ok := i.data[id]; ok {
+			if value, ok := i.data[id]; ok && matchesParams(value, params) {
 				results = append(results, value)
 			}
 		}
-	}
 
-	if len(results) > 0 {
 		return results, cursor, err
 	}
 
-	return results, cursor, err
+	if params.Deleted {
+		for _, value := range i.data {
+			if matchesParams(value, params) {
+				results = append(results, value)
+			}
+		}
+
+		sort.Slice(results, func(a, b int) bool {
+			return results[a].DeleteTime.After(*results[b].DeleteTime)
+		})
+	} else if len(params.Tags) != 0 || ordered(params) {
+		for _, value := range i.data {
+			if matchesParams(value, params) {
+				results = append(results, value)
+			}
+		}
+
+		sort.Slice(results, func(a, b int) bool {
+			return less(results[a], results[b], params)
+		})
+	}
+
+	return page(results, params)
 }
 
 func (i *InMemoryDb) Insert(_ context.Context, models ...assets_dm.AssetEntity) (results []assets_dm.AssetEntity, err error) {
@@ -76,3 +99,67 @@ func (i *InMemoryDb) Delete(_ context.Context, models ...assets_dm.AssetEntity)
 
 	return results, nil
 }
+
+func (i *InMemoryDb) SelectTags(_ context.Context, params ports.SelectTagsRepoParams) (results []assets_dm.TagCount, err error) {
+
+	counts := make(map[string]int)
+	for _, value := range i.data {
+		if len(params.OwnerIds) != 0 && !slices.Contains(params.OwnerIds, value.OwnerId) {
+			continue
+		}
+
+		for _, tag := range indexedTags(value) {
+			counts[tag]++
+		}
+	}
+
+	for tag, count := range counts {
+		results = append(results, assets_dm.TagCount{Tag: tag, Count: count})
+	}
+
+	sort.Slice(results, func(a, b int) bool {
+		return results[a].Tag < results[b].Tag
+	})
+
+	return results, nil
+}
+
+// page cuts the page out of all matching assets, cursor is the offset of the page.
+func page(results []assets_dm.AssetEntity, params ports.SelectAssetsRepoParams) (page []assets_dm.AssetEntity, cursor string, err error) {
+
+	offset := 0
+	if params.Cursor != "" {
+		if offset, err = strconv.Atoi(params.Cursor); err != nil {
+			return nil, cursor, err
+		}
+	}
+
+	if offset >= len(results) {
+		return nil, cursor, nil
+	}
+
+	results = results[offset:]
+	if params.Limit != 0 && len(results) > params.Limit {
+		return results[:params.Limit], strconv.Itoa(offset + params.Limit), nil
+	}
+
+	return results, cursor, nil
+}
+
+// less mimics order of lookup tables, ties are broken by ids.
+func less(a assets_dm.AssetEntity, b assets_dm.AssetEntity, params ports.SelectAssetsRepoParams) bool {
+	switch {
+	case !ordered(params):
+		return a.Id < b.Id
+	case params.Sort == assets_dm.SortNameAsc && a.Name != b.Name:
+		return a.Name < b.Name
+	case params.Sort == assets_dm.SortNameDesc && a.Name != b.Name:
+		return a.Name > b.Name
+	case params.Sort == assets_dm.SortCreateTimeAsc && !a.CreateTime.Equal(b.CreateTime):
+		return a.CreateTime.Before(b.CreateTime)
+	case (params.Sort == "" || params.Sort == assets_dm.SortCreateTimeDesc) && !a.CreateTime.Equal(b.CreateTime):
+		return a.CreateTime.After(b.CreateTime)
+	}
+
+	return a.Id < b.Id
+}
@@ -0,0 +1,80 @@
+package attempts_db
+
+import (
+	attempts_dm "assets/internal/core/domain/attempts"
+	"fmt"
+	"github.com/gocql/gocql"
+	"strings"
+	"time"
+)
+
+/*
+ * Select
+ */
+
+var tableName = "login_attempts"
+
+func SelectRecordsByIds(session *gocql.Session, ids []string) (query *gocql.Query) {
+	idList := "'" + strings.Join(ids, "', '") + "'"
+	return session.Query(fmt.Sprintf("SELECT * FROM %s WHERE id IN (%s)", tableName, idList))
+}
+
+/*
+ * Table
+ */
+
+func CreateTableQuery() string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id text PRIMARY KEY, failures int, lock_expire_time timestamp, expire_time timestamp, create_time timestamp, update_time timestamp)", tableName)
+}
+
+func DropTableQuery() string {
+	return fmt.Sprintf("DROP TABLE %s", tableName)
+}
+
+/*
+ * Insert
+ */
+
+func AppendInsertQuery(batch *gocql.Batch, obj attempts_dm.LoginAttemptEntity) {
+	batch.Query(fmt.Sprintf("INSERT INTO %s (id, failures, lock_expire_time, expire_time, create_time, update_time) VALUES (?, ?, ?, ?, ?, ?) USING TTL ?", tableName),
+		obj.Id, obj.Failures, obj.LockExpireTime, obj.ExpireTime, obj.CreateTime, obj.UpdateTime, ttl(obj.ExpireTime))
+}
+
+// InsertIfNotExistsQuery is lightweight transaction, so only one of concurrent requests starts tracking the key.
+func InsertIfNotExistsQuery(session *gocql.Session, obj attempts_dm.LoginAttemptEntity) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("INSERT INTO %s (id, failures, lock_expire_time, expire_time, create_time, update_time) VALUES (?, ?, ?, ?, ?, ?) IF NOT EXISTS USING TTL ?", tableName),
+		obj.Id, obj.Failures, obj.LockExpireTime, obj.ExpireTime, obj.CreateTime, obj.UpdateTime, ttl(obj.ExpireTime))
+}
+
+/*
+ * Update
+ */
+
+func AppendUpdateQuery(batch *gocql.Batch, obj attempts_dm.LoginAttemptEntity) {
+	// every column is rewritten, so the whole record gets the new TTL
+	AppendInsertQuery(batch, obj)
+}
+
+// UpdateIfFailuresQuery is lightweight transaction comparing failures read before, so concurrent failures aren't lost.
+// Every column is set, so the whole record gets the new TTL.
+func UpdateIfFailuresQuery(session *gocql.Session, obj attempts_dm.LoginAttemptEntity, failures int) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("UPDATE %s USING TTL ? SET failures = ?, lock_expire_time = ?, expire_time = ?, create_time = ?, update_time = ? WHERE id = ? IF failures = ?", tableName),
+		ttl(obj.ExpireTime), obj.Failures, obj.LockExpireTime, obj.ExpireTime, obj.CreateTime, obj.UpdateTime, obj.Id, failures)
+}
+
+/*
+ * Delete
+ */
+
+func AppendDeleteQuery(batch *gocql.Batch, obj attempts_dm.LoginAttemptEntity) {
+	batch.Query(fmt.Sprintf("DELETE FROM %s WHERE id = ?", tableName), obj.Id)
+}
+
+// ttl returns amount of seconds left until expiration, records are kept for at least one second.
+func ttl(expireTime time.Time) int {
+	if seconds := int(time.Until(expireTime).Seconds()); seconds > 0 {
+		return seconds
+	}
+
+	return 1
+}
@@ -0,0 +1,168 @@
+package attempts_db
+
+import (
+	attempts_dm "assets/internal/core/domain/attempts"
+	"assets/internal/core/ports"
+	"assets/pkg/logging"
+	"context"
+	"github.com/gocql/gocql"
+	"github.com/pkg/errors"
+	"time"
+)
+
+type CassandraRepo struct {
+	logger  logging.Logger
+	session *gocql.Session
+}
+
+func NewCassandraRepo(logger logging.Logger, session *gocql.Session) (repo *CassandraRepo) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := session.Query(CreateTableQuery()).WithContext(ctx).Exec(); err != nil {
+		panic(errors.Wrap(err, "failed to inspect/create login attempts table"))
+	}
+
+	return &CassandraRepo{logger: logger, session: session}
+}
+
+func (cr *CassandraRepo) Select(ctx context.Context, params ports.SelectLoginAttemptsRepoParams) (results []attempts_dm.LoginAttemptEntity, err error) {
+
+	cr.logger.Info("attempts_db.Select() performed",
+		"params", params,
+	)
+
+	if len(params.Ids) == 0 {
+		return results, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	iter := SelectRecordsByIds(cr.session, params.Ids).WithContext(ctx).Iter()
+	defer func() {
+		if err = iter.Close(); err != nil {
+			cr.logger.Info("failed to close iterator", "err", err)
+		}
+	}()
+
+	var obj attempts_dm.LoginAttemptEntity
+
+	scanner := iter.Scanner()
+	for scanner.Next() {
+		if err = scanner.Scan(&obj.Id, &obj.CreateTime, &obj.ExpireTime, &obj.Failures, &obj.LockExpireTime, &obj.UpdateTime); err != nil {
+			return nil, err
+		} else {
+			results = append(results, obj)
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (cr *CassandraRepo) Insert(ctx context.Context, models ...attempts_dm.LoginAttemptEntity) (results []attempts_dm.LoginAttemptEntity, err error) {
+
+	cr.logger.Info("attempts_db.Insert() performed",
+		"models", models,
+	)
+
+	if len(models) == 0 {
+		return results, nil
+	}
+
+	if err = cr.execute(ctx, models, AppendInsertQuery); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+func (cr *CassandraRepo) Update(ctx context.Context, models ...attempts_dm.LoginAttemptEntity) (results []attempts_dm.LoginAttemptEntity, err error) {
+
+	cr.logger.Info("attempts_db.Update() performed",
+		"models", models,
+	)
+
+	if len(models) == 0 {
+		return results, nil
+	}
+
+	if err = cr.execute(ctx, models, AppendUpdateQuery); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+func (cr *CassandraRepo) InsertIfNotExists(ctx context.Context, model attempts_dm.LoginAttemptEntity) (applied bool, err error) {
+
+	cr.logger.Info("attempts_db.InsertIfNotExists() performed",
+		"model", model,
+	)
+
+	model.UpdateTime = time.Now()
+
+	// values of the stored record are returned when the condition fails, they aren't needed
+	if applied, err = InsertIfNotExistsQuery(cr.session, model).WithContext(ctx).MapScanCAS(make(map[string]interface{})); err != nil {
+		return false, err
+	}
+
+	return applied, nil
+}
+
+func (cr *CassandraRepo) UpdateIfFailures(ctx context.Context, model attempts_dm.LoginAttemptEntity, failures int) (applied bool, err error) {
+
+	cr.logger.Info("attempts_db.UpdateIfFailures() performed",
+		"model", model,
+		"failures", failures,
+	)
+
+	model.UpdateTime = time.Now()
+
+	// values of the condition columns are returned when the condition fails, they aren't needed
+	if applied, err = UpdateIfFailuresQuery(cr.session, model, failures).WithContext(ctx).MapScanCAS(make(map[string]interface{})); err != nil {
+		return false, err
+	}
+
+	return applied, nil
+}
+
+func (cr *CassandraRepo) Delete(ctx context.Context, models ...attempts_dm.LoginAttemptEntity) (results []attempts_dm.LoginAttemptEntity, err error) {
+
+	cr.logger.Info("attempts_db.Delete() performed",
+		"models", models,
+	)
+
+	if len(models) == 0 {
+		return results, nil
+	}
+
+	if err = cr.execute(ctx, models, AppendDeleteQuery); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+func (cr *CassandraRepo) execute(ctx context.Context, models []attempts_dm.LoginAttemptEntity, action func(batch *gocql.Batch, model attempts_dm.LoginAttemptEntity)) (err error) {
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	batch := cr.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	for idx := range models {
+		models[idx].UpdateTime = time.Now()
+		action(batch, models[idx])
+	}
+
+	if err = cr.session.ExecuteBatch(batch); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package attempts_db
+
+import (
+	attempts_dm "assets/internal/core/domain/attempts"
+	"assets/internal/core/ports"
+	errs "assets/pkg/errors"
+	"context"
+	"sync"
+	"time"
+)
+
+/// test purposes database
+
+type InMemoryDb struct {
+	mutex sync.Mutex
+	data  map[string]attempts_dm.LoginAttemptEntity
+}
+
+func NewMemoryRepo() *InMemoryDb {
+	return &InMemoryDb{
+		data: make(map[string]attempts_dm.LoginAttemptEntity),
+	}
+}
+
+func (i *InMemoryDb) Select(_ context.Context, params ports.SelectLoginAttemptsRepoParams) (results []attempts_dm.LoginAttemptEntity, err error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, model := range i.data {
+		if model.ExpireTime.Before(time.Now()) {
+			delete(i.data, model.Id)
+		}
+	}
+
+	for _, id := range params.Ids {
+		if value, ok := i.data[id]; ok {
+			results = append(results, value)
+		}
+	}
+
+	return results, err
+}
+
+func (i *InMemoryDb) Insert(_ context.Context, models ...attempts_dm.LoginAttemptEntity) (results []attempts_dm.LoginAttemptEntity, err error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	for _, model := range models {
+		if _, ok := i.data[model.Id]; ok {
+			return []attempts_dm.LoginAttemptEntity{}, errs.AlreadyExistsError
+		}
+	}
+
+	for _, model := range models {
+		i.data[model.Id] = model
+	}
+
+	return models, err
+}
+
+func (i *InMemoryDb) Update(_ context.Context, models ...attempts_dm.LoginAttemptEntity) (results []attempts_dm.LoginAttemptEntity, err error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, model := range models {
+		if _, ok := i.data[model.Id]; !ok {
+			return []attempts_dm.LoginAttemptEntity{}, errs.CannotBeFoundError
+		} else {
+			i.data[model.Id] = model
+		}
+	}
+
+	return models, err
+}
+
+func (i *InMemoryDb) InsertIfNotExists(_ context.Context, model attempts_dm.LoginAttemptEntity) (bool, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if stored, ok := i.data[model.Id]; ok && !stored.ExpireTime.Before(time.Now()) {
+		return false, nil
+	}
+
+	i.data[model.Id] = model
+
+	return true, nil
+}
+
+func (i *InMemoryDb) UpdateIfFailures(_ context.Context, model attempts_dm.LoginAttemptEntity, failures int) (bool, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	stored, ok := i.data[model.Id]
+	if !ok || stored.ExpireTime.Before(time.Now()) || stored.Failures != failures {
+		return false, nil
+	}
+
+	i.data[model.Id] = model
+
+	return true, nil
+}
+
+func (i *InMemoryDb) Delete(_ context.Context, models ...attempts_dm.LoginAttemptEntity) (results []attempts_dm.LoginAttemptEntity, err error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, model := range models {
+		if _, ok := i.data[model.Id]; !ok {
+			return nil, errs.CannotBeFoundError
+		} else {
+			results = append(results, model)
+			delete(i.data, model.Id)
+		}
+	}
+
+	return results, nil
+}
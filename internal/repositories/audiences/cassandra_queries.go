@@ -43,6 +43,15 @@ func AppendInsertQuery(batch *gocql.Batch, obj assets_dm.AudienceEntity) {
 		obj.Id, obj.Gender, obj.BirthCountry, obj.AgeGroup, obj.SocialMediaHours, obj.PurchasesLastMonth, obj.CreateTime, obj.UpdateTime)
 }
 
+/*
+ * Update
+ */
+
+func AppendUpdateQuery(batch *gocql.Batch, obj assets_dm.AudienceEntity) {
+	batch.Query(fmt.Sprintf("UPDATE %s SET gender = ?, birth_country = ?, age_group = ?, social_media_hours = ?, purchases_last_month = ?, update_time = ? WHERE id = ?", tableName),
+		obj.Gender, obj.BirthCountry, obj.AgeGroup, obj.SocialMediaHours, obj.PurchasesLastMonth, obj.UpdateTime, obj.Id)
+}
+
 /*
  * Delete
  */
@@ -109,6 +109,24 @@ func (cr *CassandraRepo) Insert(ctx context.Context, models ...assets_dm.Audienc
 	return models, nil
 }
 
+func (cr *CassandraRepo) Update(ctx context.Context, models ...assets_dm.AudienceEntity) (results []assets_dm.AudienceEntity, err error) {
+
+	cr.logger.Info("audiences_db.Update() performed",
+		"params", models,
+		"results", results,
+	)
+
+	if len(models) == 0 {
+		return results, nil
+	}
+
+	if err = cr.execute(ctx, models, AppendUpdateQuery); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
 func (cr *CassandraRepo) Delete(ctx context.Context, models ...assets_dm.AudienceEntity) (results []assets_dm.AudienceEntity, err error) {
 
 	cr.logger.Info("audiences_db.Delete() performed",
@@ -0,0 +1,72 @@
+package audit_db
+
+import (
+	audit_dm "assets/internal/core/domain/audit"
+	"fmt"
+	"github.com/gocql/gocql"
+	"strings"
+	"time"
+)
+
+/*
+ * Select
+ */
+
+var tableName = "audit_log"
+
+// SelectRecordsByDay reads single partition, optional filters are applied by the coordinator within that partition.
+func SelectRecordsByDay(session *gocql.Session, day string, actorId string, entityType string, entityId string) (query *gocql.Query) {
+
+	var (
+		conditions = []string{"day = ?"}
+		values     = []any{day}
+	)
+
+	if actorId != "" {
+		conditions = append(conditions, "actor_id = ?")
+		values = append(values, actorId)
+	}
+
+	if entityType != "" {
+		conditions = append(conditions, "entity_type = ?")
+		values = append(values, entityType)
+	}
+
+	if entityId != "" {
+		conditions = append(conditions, "entity_id = ?")
+		values = append(values, entityId)
+	}
+
+	statement := fmt.Sprintf("SELECT * FROM %s WHERE %s", tableName, strings.Join(conditions, " AND "))
+	if len(conditions) > 1 {
+		statement += " ALLOW FILTERING"
+	}
+
+	return session.Query(statement, values...)
+}
+
+/*
+ * Table
+ */
+
+func CreateTableQuery() string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (day text, create_time timestamp, id text, actor_id text, action text, entity_type text, entity_id text, diff text, update_time timestamp, PRIMARY KEY ((day), create_time, id)) WITH CLUSTERING ORDER BY (create_time DESC, id ASC)", tableName)
+}
+
+func DropTableQuery() string {
+	return fmt.Sprintf("DROP TABLE %s", tableName)
+}
+
+/*
+ * Insert
+ */
+
+func AppendInsertQuery(batch *gocql.Batch, obj audit_dm.EntryEntity, diff string) {
+	batch.Query(fmt.Sprintf("INSERT INTO %s (day, create_time, id, actor_id, action, entity_type, entity_id, diff, update_time) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)", tableName),
+		Day(obj.CreateTime), obj.CreateTime, obj.Id, obj.ActorId, obj.Action, obj.EntityType, obj.EntityId, diff, obj.UpdateTime)
+}
+
+// Day returns partition key of entries created at given time.
+func Day(moment time.Time) string {
+	return moment.UTC().Format("2006-01-02")
+}
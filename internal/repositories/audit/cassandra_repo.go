@@ -0,0 +1,128 @@
+package audit_db
+
+import (
+	audit_dm "assets/internal/core/domain/audit"
+	"assets/internal/core/ports"
+	"assets/pkg/logging"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/gocql/gocql"
+	"github.com/pkg/errors"
+	"time"
+)
+
+const cassandraMaxLimit = 10_000
+
+type CassandraRepo struct {
+	logger  logging.Logger
+	session *gocql.Session
+}
+
+func NewCassandraRepo(logger logging.Logger, session *gocql.Session) (repo *CassandraRepo) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := session.Query(CreateTableQuery()).WithContext(ctx).Exec(); err != nil {
+		panic(errors.Wrap(err, "failed to inspect/create audit log table"))
+	}
+
+	return &CassandraRepo{logger: logger, session: session}
+}
+
+func (cr *CassandraRepo) Select(ctx context.Context, params ports.SelectAuditRepoParams) (results []audit_dm.EntryEntity, next string, err error) {
+
+	cr.logger.Info("audit_db.Select() performed",
+		"params", params,
+		"results", results,
+	)
+
+	var (
+		limit  = cassandraMaxLimit
+		cursor = make([]byte, 0)
+	)
+
+	if params.Limit != 0 {
+		limit = params.Limit
+	}
+
+	if params.Cursor != "" {
+		if cursor, err = base64.URLEncoding.DecodeString(params.Cursor); err != nil {
+			return nil, next, err
+		}
+	}
+
+	query := SelectRecordsByDay(cr.session, params.Day, params.ActorId, params.EntityType, params.EntityId)
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	iter := query.WithContext(ctx).PageSize(limit).PageState(cursor).Iter()
+	defer func() {
+		if err = iter.Close(); err != nil {
+			cr.logger.Info("failed to close iterator", "err", err)
+		}
+	}()
+
+	if len(iter.PageState()) > 0 {
+		next = base64.URLEncoding.EncodeToString(iter.PageState())
+	}
+
+	var (
+		day  string
+		diff string
+	)
+
+	scanner := iter.Scanner()
+	for scanner.Next() {
+		var obj audit_dm.EntryEntity
+		if err = scanner.Scan(&day, &obj.CreateTime, &obj.Id, &obj.Action, &obj.ActorId, &diff, &obj.EntityId, &obj.EntityType, &obj.UpdateTime); err != nil {
+			return nil, next, err
+		}
+
+		if err = json.Unmarshal([]byte(diff), &obj.Diff); err != nil {
+			return nil, next, err
+		}
+
+		results = append(results, obj)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, next, err
+	}
+
+	return results, next, nil
+}
+
+func (cr *CassandraRepo) Insert(ctx context.Context, models ...audit_dm.EntryEntity) (results []audit_dm.EntryEntity, err error) {
+
+	cr.logger.Info("audit_db.Insert() performed",
+		"params", models,
+		"results", results,
+	)
+
+	if len(models) == 0 {
+		return results, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	batch := cr.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	for idx := range models {
+		var diff []byte
+		if diff, err = json.Marshal(models[idx].Diff); err != nil {
+			return nil, err
+		}
+
+		models[idx].UpdateTime = time.Now()
+		AppendInsertQuery(batch, models[idx], string(diff))
+	}
+
+	if err = cr.session.ExecuteBatch(batch); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
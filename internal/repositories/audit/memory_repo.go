@@ -0,0 +1,63 @@
+package audit_db
+
+import (
+	audit_dm "assets/internal/core/domain/audit"
+	"assets/internal/core/ports"
+	errs "assets/pkg/errors"
+	"context"
+	"sort"
+)
+
+/// test purposes database
+
+type InMemoryDb struct {
+	data map[string]audit_dm.EntryEntity
+}
+
+func NewMemoryRepo() *InMemoryDb {
+	return &InMemoryDb{
+		data: make(map[string]audit_dm.EntryEntity),
+	}
+}
+
+func (i *InMemoryDb) Select(_ context.Context, params ports.SelectAuditRepoParams) (results []audit_dm.EntryEntity, cursor string, err error) {
+
+	for _, model := range i.data {
+		if Day(model.CreateTime) != params.Day {
+			continue
+		}
+		if params.ActorId != "" && params.ActorId != model.ActorId {
+			continue
+		}
+		if params.EntityType != "" && params.EntityType != model.EntityType {
+			continue
+		}
+		if params.EntityId != "" && params.EntityId != model.EntityId {
+			continue
+		}
+		results = append(results, model)
+	}
+
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].CreateTime.Equal(results[b].CreateTime) {
+			return results[a].Id < results[b].Id
+		}
+		return results[a].CreateTime.After(results[b].CreateTime)
+	})
+
+	return results, cursor, err
+}
+
+func (i *InMemoryDb) Insert(_ context.Context, models ...audit_dm.EntryEntity) (results []audit_dm.EntryEntity, err error) {
+	for _, model := range models {
+		if _, ok := i.data[model.Id]; ok {
+			return []audit_dm.EntryEntity{}, errs.AlreadyExistsError
+		}
+	}
+
+	for _, model := range models {
+		i.data[model.Id] = model
+	}
+
+	return models, err
+}
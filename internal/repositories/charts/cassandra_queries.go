@@ -45,6 +45,16 @@ func AppendInsertQuery(batch *gocql.Batch, obj assets_dm.ChartEntity) {
 		obj.Id, obj.ChartTitle, obj.XAxisTitle, obj.YAxisTitle, string(data), obj.CreateTime, obj.UpdateTime)
 }
 
+/*
+ * Update
+ */
+
+func AppendUpdateQuery(batch *gocql.Batch, obj assets_dm.ChartEntity) {
+	data, _ := json.Marshal(obj.Data)
+	batch.Query(fmt.Sprintf("UPDATE %s SET chart_title = ?, x_axis_title = ?, y_axis_title = ?, \"data\" = ?, update_time = ? WHERE id = ?", tableName),
+		obj.ChartTitle, obj.XAxisTitle, obj.YAxisTitle, string(data), obj.UpdateTime, obj.Id)
+}
+
 /*
  * Delete
  */
@@ -6,6 +6,7 @@ import (
 	"assets/pkg/logging"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"github.com/gocql/gocql"
 	"github.com/pkg/errors"
 	"time"
@@ -73,17 +74,21 @@ func (cr *CassandraRepo) Select(ctx context.Context, params ports.SelectChartsRe
 		next = base64.URLEncoding.EncodeToString(iter.PageState())
 	}
 
-	var obj assets_dm.ChartEntity
-
 	scanner := iter.Scanner()
 	for scanner.Next() {
+		var obj assets_dm.ChartEntity
 		var data string
 		if err = scanner.Scan(&obj.Id, &obj.ChartTitle, &obj.CreateTime, &data, &obj.UpdateTime, &obj.XAxisTitle, &obj.YAxisTitle); err != nil {
 			return nil, next, err
-		} else {
-			obj.Data = data
-			results = append(results, obj)
 		}
+
+		// charts stored before data was typed may hold arbitrary JSON, they are returned without data
+		if err = json.Unmarshal([]byte(data), &obj.Data); err != nil {
+			cr.logger.Info("failed to decode chart data", "id", obj.Id, "err", err)
+			obj.Data = assets_dm.ChartData{}
+		}
+
+		results = append(results, obj)
 	}
 
 	if err = scanner.Err(); err != nil {
@@ -111,6 +116,24 @@ func (cr *CassandraRepo) Insert(ctx context.Context, models ...assets_dm.ChartEn
 	return models, nil
 }
 
+func (cr *CassandraRepo) Update(ctx context.Context, models ...assets_dm.ChartEntity) (results []assets_dm.ChartEntity, err error) {
+
+	cr.logger.Info("charts_db.Update() performed",
+		"params", models,
+		"results", results,
+	)
+
+	if len(models) == 0 {
+		return results, nil
+	}
+
+	if err = cr.execute(ctx, models, AppendUpdateQuery); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
 func (cr *CassandraRepo) Delete(ctx context.Context, models ...assets_dm.ChartEntity) (results []assets_dm.ChartEntity, err error) {
 
 	cr.logger.Info("charts_db.Delete() performed",
@@ -0,0 +1,87 @@
+package collections_db
+
+import (
+	collections_dm "assets/internal/core/domain/collections"
+	"fmt"
+	"github.com/gocql/gocql"
+	"strings"
+)
+
+/*
+ * Select
+ */
+
+var tableName = "collections"
+
+func SelectRecords(session *gocql.Session) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("SELECT * FROM %s", tableName))
+}
+
+func SelectRecordsByIds(session *gocql.Session, ids []string) (query *gocql.Query) {
+	idList := "'" + strings.Join(ids, "', '") + "'"
+	return session.Query(fmt.Sprintf("SELECT * FROM %s WHERE id IN (%s)", tableName, idList))
+}
+
+func SelectRecordsByOwnerIds(session *gocql.Session, ownerIds []string) (query *gocql.Query) {
+	idList := "'" + strings.Join(ownerIds, "', '") + "'"
+	return session.Query(fmt.Sprintf("SELECT * FROM %s WHERE owner_id IN (%s)", tableName, idList))
+}
+
+func SelectRecordsByAssetId(session *gocql.Session, assetId string) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("SELECT * FROM %s WHERE asset_ids CONTAINS ?", tableName), assetId)
+}
+
+/*
+ * Table
+ */
+
+func CreateTableQuery() string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id text PRIMARY KEY, owner_id text, name text, description text, items text, asset_ids set<text>, create_time timestamp, update_time timestamp)", tableName)
+}
+
+func CreateOwnerIdIndexQuery() string {
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_collections_owner_id ON %s (owner_id);", tableName)
+}
+
+func CreateAssetIdsIndexQuery() string {
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_collections_asset_ids ON %s (VALUES(asset_ids));", tableName)
+}
+
+func DropTableQuery() string {
+	return fmt.Sprintf("DROP TABLE %s", tableName)
+}
+
+/*
+ * Insert
+ */
+
+func AppendInsertQuery(batch *gocql.Batch, obj collections_dm.CollectionEntity, items string) {
+	batch.Query(fmt.Sprintf("INSERT INTO %s (id, owner_id, name, description, items, asset_ids, create_time, update_time) VALUES (?, ?, ?, ?, ?, ?, ?, ?)", tableName),
+		obj.Id, obj.OwnerId, obj.Name, obj.Description, items, assetIds(obj), obj.CreateTime, obj.UpdateTime)
+}
+
+/*
+ * Update
+ */
+
+func AppendUpdateQuery(batch *gocql.Batch, obj collections_dm.CollectionEntity, items string) {
+	batch.Query(fmt.Sprintf("UPDATE %s SET owner_id = ?, name = ?, description = ?, items = ?, asset_ids = ?, update_time = ? WHERE id = ?", tableName),
+		obj.OwnerId, obj.Name, obj.Description, items, assetIds(obj), obj.UpdateTime, obj.Id)
+}
+
+/*
+ * Delete
+ */
+
+func AppendDeleteQuery(batch *gocql.Batch, obj collections_dm.CollectionEntity) {
+	batch.Query(fmt.Sprintf("DELETE FROM %s WHERE id = ?", tableName), obj.Id)
+}
+
+// assetIds are kept next to serialized items, so that collections holding the asset can be found by the index.
+func assetIds(obj collections_dm.CollectionEntity) (results []string) {
+	for _, item := range obj.Items {
+		results = append(results, item.AssetId)
+	}
+
+	return results
+}
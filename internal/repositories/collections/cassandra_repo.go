@@ -0,0 +1,212 @@
+package collections_db
+
+import (
+	collections_dm "assets/internal/core/domain/collections"
+	"assets/internal/core/ports"
+	"assets/pkg/logging"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/gocql/gocql"
+	"github.com/pkg/errors"
+	"time"
+)
+
+const cassandraMaxLimit = 10_000
+
+type CassandraRepo struct {
+	logger  logging.Logger
+	session *gocql.Session
+}
+
+func NewCassandraRepo(logger logging.Logger, session *gocql.Session) (repo *CassandraRepo) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := session.Query(CreateTableQuery()).WithContext(ctx).Exec(); err != nil {
+		panic(errors.Wrap(err, "failed to inspect/create collections table"))
+	}
+
+	if err := session.Query(CreateOwnerIdIndexQuery()).WithContext(ctx).Exec(); err != nil {
+		panic(errors.Wrap(err, "failed to inspect/create collections owner_id index"))
+	}
+
+	if err := session.Query(CreateAssetIdsIndexQuery()).WithContext(ctx).Exec(); err != nil {
+		panic(errors.Wrap(err, "failed to inspect/create collections asset_ids index"))
+	}
+
+	return &CassandraRepo{logger: logger, session: session}
+}
+
+func (cr *CassandraRepo) Select(ctx context.Context, params ports.SelectCollectionsRepoParams) (results []collections_dm.CollectionEntity, next string, err error) {
+
+	cr.logger.Info("collections_db.Select() performed",
+		"params", params,
+		"results", results,
+	)
+
+	// index on set values can be queried for single value only, so collections of every asset are read separately
+	if len(params.AssetIds) != 0 {
+		found := make(map[string]bool)
+		for _, assetId := range params.AssetIds {
+			var models []collections_dm.CollectionEntity
+			if models, _, err = cr.scan(ctx, SelectRecordsByAssetId(cr.session, assetId), cassandraMaxLimit, ""); err != nil {
+				return nil, next, err
+			}
+
+			for _, model := range models {
+				if !found[model.Id] {
+					found[model.Id] = true
+					results = append(results, model)
+				}
+			}
+		}
+
+		return results, next, nil
+	}
+
+	limit := cassandraMaxLimit
+	if params.Limit != 0 {
+		limit = params.Limit
+	}
+
+	var query *gocql.Query
+	if len(params.Ids) != 0 {
+		query = SelectRecordsByIds(cr.session, params.Ids)
+	} else if len(params.OwnerIds) != 0 {
+		query = SelectRecordsByOwnerIds(cr.session, params.OwnerIds)
+	} else {
+		query = SelectRecords(cr.session)
+	}
+
+	return cr.scan(ctx, query, limit, params.Cursor)
+}
+
+func (cr *CassandraRepo) Insert(ctx context.Context, models ...collections_dm.CollectionEntity) (results []collections_dm.CollectionEntity, err error) {
+
+	cr.logger.Info("collections_db.Insert() performed",
+		"params", models,
+		"results", results,
+	)
+
+	if len(models) == 0 {
+		return results, nil
+	}
+
+	if err = cr.execute(ctx, models, AppendInsertQuery); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+func (cr *CassandraRepo) Update(ctx context.Context, models ...collections_dm.CollectionEntity) (results []collections_dm.CollectionEntity, err error) {
+
+	cr.logger.Info("collections_db.Update() performed",
+		"params", models,
+		"results", results,
+	)
+
+	if len(models) == 0 {
+		return results, nil
+	}
+
+	if err = cr.execute(ctx, models, AppendUpdateQuery); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+func (cr *CassandraRepo) Delete(ctx context.Context, models ...collections_dm.CollectionEntity) (results []collections_dm.CollectionEntity, err error) {
+
+	cr.logger.Info("collections_db.Delete() performed",
+		"params", models,
+		"results", results,
+	)
+
+	if len(models) == 0 {
+		return results, nil
+	}
+
+	if err = cr.execute(ctx, models, func(batch *gocql.Batch, collection collections_dm.CollectionEntity, _ string) {
+		AppendDeleteQuery(batch, collection)
+	}); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+func (cr *CassandraRepo) scan(ctx context.Context, query *gocql.Query, limit int, cursor string) (results []collections_dm.CollectionEntity, next string, err error) {
+
+	state := make([]byte, 0)
+	if cursor != "" {
+		if state, err = base64.URLEncoding.DecodeString(cursor); err != nil {
+			return nil, next, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	iter := query.WithContext(ctx).PageSize(limit).PageState(state).Iter()
+	defer func() {
+		if err = iter.Close(); err != nil {
+			cr.logger.Info("failed to close iterator", "err", err)
+		}
+	}()
+
+	if len(iter.PageState()) > 0 {
+		next = base64.URLEncoding.EncodeToString(iter.PageState())
+	}
+
+	var (
+		items    string
+		assetIds []string
+	)
+
+	scanner := iter.Scanner()
+	for scanner.Next() {
+		var obj collections_dm.CollectionEntity
+		if err = scanner.Scan(&obj.Id, &assetIds, &obj.CreateTime, &obj.Description, &items, &obj.Name, &obj.OwnerId, &obj.UpdateTime); err != nil {
+			return nil, next, err
+		}
+
+		if err = json.Unmarshal([]byte(items), &obj.Items); err != nil {
+			return nil, next, err
+		}
+
+		results = append(results, obj)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, next, err
+	}
+
+	return results, next, nil
+}
+
+func (cr *CassandraRepo) execute(ctx context.Context, models []collections_dm.CollectionEntity, action func(batch *gocql.Batch, collection collections_dm.CollectionEntity, items string)) (err error) {
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	batch := cr.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	for idx := range models {
+		var items []byte
+		if items, err = json.Marshal(models[idx].Items); err != nil {
+			return err
+		}
+
+		models[idx].UpdateTime = time.Now()
+		action(batch, models[idx], string(items))
+	}
+
+	if err = cr.session.ExecuteBatch(batch); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package collections_db
+
+import (
+	collections_dm "assets/internal/core/domain/collections"
+	"assets/internal/core/ports"
+	errs "assets/pkg/errors"
+	"assets/pkg/slices"
+	"context"
+	"sort"
+)
+
+/// test purposes database
+
+type InMemoryDb struct {
+	data map[string]collections_dm.CollectionEntity
+}
+
+func NewMemoryRepo() *InMemoryDb {
+	return &InMemoryDb{
+		data: make(map[string]collections_dm.CollectionEntity),
+	}
+}
+
+func (i *InMemoryDb) Select(_ context.Context, params ports.SelectCollectionsRepoParams) (results []collections_dm.CollectionEntity, cursor string, err error) {
+
+	if len(params.Ids) != 0 {
+		for _, id := range params.Ids {
+			if value, ok := i.data[id]; ok {
+				results = append(results, value)
+			}
+		}
+
+		return results, cursor, err
+	}
+
+	for _, value := range i.data {
+		if len(params.OwnerIds) != 0 && slices.Contains(params.OwnerIds, value.OwnerId) {
+			results = append(results, value)
+		} else if len(params.AssetIds) != 0 && slices.HasCommon(params.AssetIds, assetIds(value)) {
+			results = append(results, value)
+		}
+	}
+
+	sort.Slice(results, func(a, b int) bool {
+		return results[a].Id < results[b].Id
+	})
+
+	return results, cursor, err
+}
+
+func (i *InMemoryDb) Insert(_ context.Context, models ...collections_dm.CollectionEntity) (results []collections_dm.CollectionEntity, err error) {
+	for _, model := range models {
+		if _, ok := i.data[model.Id]; ok {
+			return []collections_dm.CollectionEntity{}, errs.AlreadyExistsError
+		}
+	}
+
+	for _, model := range models {
+		i.data[model.Id] = model
+	}
+
+	return models, err
+}
+
+func (i *InMemoryDb) Update(_ context.Context, models ...collections_dm.CollectionEntity) (results []collections_dm.CollectionEntity, err error) {
+
+	for _, model := range models {
+		if _, ok := i.data[model.Id]; !ok {
+			return []collections_dm.CollectionEntity{}, errs.CannotBeFoundError
+		} else {
+			i.data[model.Id] = model
+		}
+	}
+
+	return models, err
+}
+
+func (i *InMemoryDb) Delete(_ context.Context, models ...collections_dm.CollectionEntity) (results []collections_dm.CollectionEntity, err error) {
+
+	for _, model := range models {
+		if _, ok := i.data[model.Id]; !ok {
+			return nil, errs.CannotBeFoundError
+		} else {
+			results = append(results, model)
+			delete(i.data, model.Id)
+		}
+	}
+
+	return results, nil
+}
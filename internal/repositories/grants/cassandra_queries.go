@@ -0,0 +1,73 @@
+package grants_db
+
+import (
+	grants_dm "assets/internal/core/domain/grants"
+	"fmt"
+	"github.com/gocql/gocql"
+	"strings"
+)
+
+/*
+ * Select
+ */
+
+var (
+	tableName        = "asset_grants"
+	granteeTableName = "asset_grants_by_grantee"
+)
+
+func SelectRecords(session *gocql.Session) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("SELECT * FROM %s", tableName))
+}
+
+func SelectRecordsByAssetIds(session *gocql.Session, assetIds []string) (query *gocql.Query) {
+	idList := "'" + strings.Join(assetIds, "', '") + "'"
+	return session.Query(fmt.Sprintf("SELECT * FROM %s WHERE asset_id IN (%s)", tableName, idList))
+}
+
+// SelectRecordsByGrantee reads the lookup table, which keeps grants of the grantee within single partition.
+func SelectRecordsByGrantee(session *gocql.Session, granteeType grants_dm.GranteeType, granteeId string) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("SELECT asset_id, grantee_type, grantee_id, create_time, id, permission, update_time FROM %s WHERE grantee_type = ? AND grantee_id = ?", granteeTableName),
+		granteeType, granteeId)
+}
+
+/*
+ * Table
+ */
+
+func CreateTableQuery() string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (asset_id text, grantee_type text, grantee_id text, id text, permission text, create_time timestamp, update_time timestamp, PRIMARY KEY ((asset_id), grantee_type, grantee_id))", tableName)
+}
+
+func CreateGranteeTableQuery() string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (grantee_type text, grantee_id text, asset_id text, id text, permission text, create_time timestamp, update_time timestamp, PRIMARY KEY ((grantee_type, grantee_id), asset_id))", granteeTableName)
+}
+
+func DropTableQuery() string {
+	return fmt.Sprintf("DROP TABLE %s", tableName)
+}
+
+/*
+ * Insert
+ */
+
+// AppendInsertQuery replaces previous grant of the grantee, since grantee can hold single grant per asset.
+func AppendInsertQuery(batch *gocql.Batch, obj grants_dm.GrantEntity) {
+	batch.Query(fmt.Sprintf("INSERT INTO %s (asset_id, grantee_type, grantee_id, id, permission, create_time, update_time) VALUES (?, ?, ?, ?, ?, ?, ?)", tableName),
+		obj.AssetId, obj.GranteeType, obj.GranteeId, obj.Id, obj.Permission, obj.CreateTime, obj.UpdateTime)
+	AppendInsertGranteeQuery(batch, obj)
+}
+
+func AppendInsertGranteeQuery(batch *gocql.Batch, obj grants_dm.GrantEntity) {
+	batch.Query(fmt.Sprintf("INSERT INTO %s (grantee_type, grantee_id, asset_id, id, permission, create_time, update_time) VALUES (?, ?, ?, ?, ?, ?, ?)", granteeTableName),
+		obj.GranteeType, obj.GranteeId, obj.AssetId, obj.Id, obj.Permission, obj.CreateTime, obj.UpdateTime)
+}
+
+/*
+ * Delete
+ */
+
+func AppendDeleteQuery(batch *gocql.Batch, obj grants_dm.GrantEntity) {
+	batch.Query(fmt.Sprintf("DELETE FROM %s WHERE asset_id = ? AND grantee_type = ? AND grantee_id = ?", tableName), obj.AssetId, obj.GranteeType, obj.GranteeId)
+	batch.Query(fmt.Sprintf("DELETE FROM %s WHERE grantee_type = ? AND grantee_id = ? AND asset_id = ?", granteeTableName), obj.GranteeType, obj.GranteeId, obj.AssetId)
+}
@@ -0,0 +1,215 @@
+package grants_db
+
+import (
+	grants_dm "assets/internal/core/domain/grants"
+	"assets/internal/core/ports"
+	"assets/pkg/logging"
+	"context"
+	"encoding/base64"
+	"github.com/gocql/gocql"
+	"github.com/pkg/errors"
+	"time"
+)
+
+const (
+	cassandraMaxLimit = 10_000
+	backfillPageSize  = 100
+)
+
+type CassandraRepo struct {
+	logger  logging.Logger
+	session *gocql.Session
+}
+
+func NewCassandraRepo(logger logging.Logger, session *gocql.Session) (repo *CassandraRepo) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := session.Query(CreateTableQuery()).WithContext(ctx).Exec(); err != nil {
+		panic(errors.Wrap(err, "failed to inspect/create asset grants table"))
+	}
+
+	if err := session.Query(CreateGranteeTableQuery()).WithContext(ctx).Exec(); err != nil {
+		panic(errors.Wrap(err, "failed to inspect/create asset grants by grantee table"))
+	}
+
+	return &CassandraRepo{logger: logger, session: session}
+}
+
+func (cr *CassandraRepo) Select(ctx context.Context, params ports.SelectGrantsRepoParams) (results []grants_dm.GrantEntity, next string, err error) {
+
+	cr.logger.Info("grants_db.Select() performed",
+		"params", params,
+		"results", results,
+	)
+
+	if len(params.AssetIds) == 0 {
+		return cr.selectByGrantees(ctx, params)
+	}
+
+	var (
+		limit  = cassandraMaxLimit
+		cursor = make([]byte, 0)
+	)
+
+	if params.Limit != 0 {
+		limit = params.Limit
+	}
+
+	if params.Cursor != "" {
+		if cursor, err = base64.URLEncoding.DecodeString(params.Cursor); err != nil {
+			return nil, next, err
+		}
+	}
+
+	return cr.scan(ctx, SelectRecordsByAssetIds(cr.session, params.AssetIds), limit, cursor)
+}
+
+// selectByGrantees reads partitions of every grantee from the lookup table.
+func (cr *CassandraRepo) selectByGrantees(ctx context.Context, params ports.SelectGrantsRepoParams) (results []grants_dm.GrantEntity, next string, err error) {
+
+	var queries []*gocql.Query
+	for _, userId := range params.GranteeUsers {
+		queries = append(queries, SelectRecordsByGrantee(cr.session, grants_dm.GranteeTypeUser, userId))
+	}
+	for _, group := range params.GranteeGroups {
+		queries = append(queries, SelectRecordsByGrantee(cr.session, grants_dm.GranteeTypeGroup, group))
+	}
+
+	for _, query := range queries {
+		var grants []grants_dm.GrantEntity
+		if grants, _, err = cr.scan(ctx, query, cassandraMaxLimit, nil); err != nil {
+			return nil, next, err
+		}
+
+		results = append(results, grants...)
+	}
+
+	return results, next, nil
+}
+
+func (cr *CassandraRepo) Insert(ctx context.Context, models ...grants_dm.GrantEntity) (results []grants_dm.GrantEntity, err error) {
+
+	cr.logger.Info("grants_db.Insert() performed",
+		"params", models,
+		"results", results,
+	)
+
+	if len(models) == 0 {
+		return results, nil
+	}
+
+	if err = cr.execute(ctx, models, AppendInsertQuery); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+func (cr *CassandraRepo) Delete(ctx context.Context, models ...grants_dm.GrantEntity) (results []grants_dm.GrantEntity, err error) {
+
+	cr.logger.Info("grants_db.Delete() performed",
+		"params", models,
+		"results", results,
+	)
+
+	if len(models) == 0 {
+		return results, nil
+	}
+
+	if err = cr.execute(ctx, models, AppendDeleteQuery); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+// BackfillGrantees puts grants stored before the grantee lookup table into it. Rows are written as they would be on
+// insert, so the backfill can be run again.
+func (cr *CassandraRepo) BackfillGrantees(ctx context.Context) (err error) {
+
+	cr.logger.Info("grants_db.BackfillGrantees() performed")
+
+	cursor := make([]byte, 0)
+	for {
+		var (
+			grants []grants_dm.GrantEntity
+			next   string
+		)
+
+		if grants, next, err = cr.scan(ctx, SelectRecords(cr.session), backfillPageSize, cursor); err != nil {
+			return err
+		}
+
+		if len(grants) != 0 {
+			batch := cr.session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
+			for _, grant := range grants {
+				AppendInsertGranteeQuery(batch, grant)
+			}
+
+			if err = cr.session.ExecuteBatch(batch); err != nil {
+				return err
+			}
+		}
+
+		if next == "" {
+			return nil
+		}
+
+		if cursor, err = base64.URLEncoding.DecodeString(next); err != nil {
+			return err
+		}
+	}
+}
+
+func (cr *CassandraRepo) scan(ctx context.Context, query *gocql.Query, limit int, cursor []byte) (results []grants_dm.GrantEntity, next string, err error) {
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	iter := query.WithContext(ctx).PageSize(limit).PageState(cursor).Iter()
+	defer func() {
+		if err = iter.Close(); err != nil {
+			cr.logger.Info("failed to close iterator", "err", err)
+		}
+	}()
+
+	if len(iter.PageState()) > 0 {
+		next = base64.URLEncoding.EncodeToString(iter.PageState())
+	}
+
+	scanner := iter.Scanner()
+	for scanner.Next() {
+		var obj grants_dm.GrantEntity
+		if err = scanner.Scan(&obj.AssetId, &obj.GranteeType, &obj.GranteeId, &obj.CreateTime, &obj.Id, &obj.Permission, &obj.UpdateTime); err != nil {
+			return nil, next, err
+		}
+
+		results = append(results, obj)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, next, err
+	}
+
+	return results, next, nil
+}
+
+func (cr *CassandraRepo) execute(ctx context.Context, models []grants_dm.GrantEntity, action func(batch *gocql.Batch, grant grants_dm.GrantEntity)) (err error) {
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	batch := cr.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	for idx := range models {
+		models[idx].UpdateTime = time.Now()
+		action(batch, models[idx])
+	}
+
+	if err = cr.session.ExecuteBatch(batch); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package grants_db
+
+import (
+	grants_dm "assets/internal/core/domain/grants"
+	"assets/internal/core/ports"
+	errs "assets/pkg/errors"
+	"assets/pkg/slices"
+	"context"
+	"sort"
+)
+
+/// test purposes database
+
+type InMemoryDb struct {
+	data map[string]grants_dm.GrantEntity
+}
+
+func NewMemoryRepo() *InMemoryDb {
+	return &InMemoryDb{
+		data: make(map[string]grants_dm.GrantEntity),
+	}
+}
+
+func (i *InMemoryDb) Select(_ context.Context, params ports.SelectGrantsRepoParams) (results []grants_dm.GrantEntity, cursor string, err error) {
+
+	for _, model := range i.data {
+		if slices.Contains(params.AssetIds, model.AssetId) || granted(model, params) {
+			results = append(results, model)
+		}
+	}
+
+	sort.Slice(results, func(a, b int) bool {
+		return key(results[a]) < key(results[b])
+	})
+
+	return results, cursor, err
+}
+
+func (i *InMemoryDb) Insert(_ context.Context, models ...grants_dm.GrantEntity) (results []grants_dm.GrantEntity, err error) {
+
+	for _, model := range models {
+		i.data[key(model)] = model
+	}
+
+	return models, err
+}
+
+func (i *InMemoryDb) Delete(_ context.Context, models ...grants_dm.GrantEntity) (results []grants_dm.GrantEntity, err error) {
+
+	for _, model := range models {
+		if _, ok := i.data[key(model)]; !ok {
+			return nil, errs.CannotBeFoundError
+		} else {
+			results = append(results, model)
+			delete(i.data, key(model))
+		}
+	}
+
+	return results, nil
+}
+
+// granted reports whether the grant is given to one of requested users or groups.
+func granted(model grants_dm.GrantEntity, params ports.SelectGrantsRepoParams) bool {
+	switch model.GranteeType {
+	case grants_dm.GranteeTypeUser:
+		return slices.Contains(params.GranteeUsers, model.GranteeId)
+	case grants_dm.GranteeTypeGroup:
+		return slices.Contains(params.GranteeGroups, model.GranteeId)
+	}
+
+	return false
+}
+
+// key mimics primary key of the table, grantee holds single grant per asset.
+func key(model grants_dm.GrantEntity) string {
+	return model.AssetId + "/" + model.GranteeType + "/" + model.GranteeId
+}
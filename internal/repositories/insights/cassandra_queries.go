@@ -43,6 +43,15 @@ func AppendInsertQuery(batch *gocql.Batch, obj assets_dm.InsightEntity) {
 		obj.Id, obj.Text, obj.CreateTime, obj.UpdateTime)
 }
 
+/*
+ * Update
+ */
+
+func AppendUpdateQuery(batch *gocql.Batch, obj assets_dm.InsightEntity) {
+	batch.Query(fmt.Sprintf("UPDATE %s SET text = ?, update_time = ? WHERE id = ?", tableName),
+		obj.Text, obj.UpdateTime, obj.Id)
+}
+
 /*
  * Delete
  */
@@ -105,6 +105,24 @@ func (cr *CassandraRepo) Insert(ctx context.Context, models ...assets_dm.Insight
 	return models, nil
 }
 
+func (cr *CassandraRepo) Update(ctx context.Context, models ...assets_dm.InsightEntity) (results []assets_dm.InsightEntity, err error) {
+
+	cr.logger.Info("insights_db.Update() performed",
+		"params", models,
+		"results", results,
+	)
+
+	if len(models) == 0 {
+		return results, nil
+	}
+
+	if err = cr.execute(ctx, models, AppendUpdateQuery); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
 func (cr *CassandraRepo) Delete(ctx context.Context, models ...assets_dm.InsightEntity) (results []assets_dm.InsightEntity, err error) {
 
 	cr.logger.Info("insights_db.Delete() performed",
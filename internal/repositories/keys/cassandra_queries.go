@@ -0,0 +1,69 @@
+package keys_db
+
+import (
+	keys_dm "assets/internal/core/domain/keys"
+	"fmt"
+	"github.com/gocql/gocql"
+	"strings"
+)
+
+/*
+ * Select
+ */
+
+var tableName = "api_keys"
+
+func SelectRecords(session *gocql.Session) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("SELECT * FROM %s", tableName))
+}
+
+func SelectRecordsByIds(session *gocql.Session, ids []string) (query *gocql.Query) {
+	idList := "'" + strings.Join(ids, "', '") + "'"
+	return session.Query(fmt.Sprintf("SELECT * FROM %s WHERE id IN (%s)", tableName, idList))
+}
+
+func SelectRecordsByUserId(session *gocql.Session, userId string) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("SELECT * FROM %s WHERE user_id = ?", tableName), userId)
+}
+
+/*
+ * Table
+ */
+
+func CreateTableQuery() string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id text PRIMARY KEY, user_id text, name text, scopes set<text>, hash text, last_used_time timestamp, create_time timestamp, update_time timestamp)", tableName)
+}
+
+func CreateUserIdIndexQuery() string {
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON %s (user_id);", tableName)
+}
+
+func DropTableQuery() string {
+	return fmt.Sprintf("DROP TABLE %s", tableName)
+}
+
+/*
+ * Insert
+ */
+
+func AppendInsertQuery(batch *gocql.Batch, obj keys_dm.ApiKeyEntity) {
+	batch.Query(fmt.Sprintf("INSERT INTO %s (id, user_id, name, scopes, hash, last_used_time, create_time, update_time) VALUES (?, ?, ?, ?, ?, ?, ?, ?)", tableName),
+		obj.Id, obj.UserId, obj.Name, obj.Scopes, obj.Hash, obj.LastUsedTime, obj.CreateTime, obj.UpdateTime)
+}
+
+/*
+ * Update
+ */
+
+func AppendUpdateQuery(batch *gocql.Batch, obj keys_dm.ApiKeyEntity) {
+	batch.Query(fmt.Sprintf("UPDATE %s SET name = ?, scopes = ?, last_used_time = ?, update_time = ? WHERE id = ?", tableName),
+		obj.Name, obj.Scopes, obj.LastUsedTime, obj.UpdateTime, obj.Id)
+}
+
+/*
+ * Delete
+ */
+
+func AppendDeleteQuery(batch *gocql.Batch, obj keys_dm.ApiKeyEntity) {
+	batch.Query(fmt.Sprintf("DELETE FROM %s WHERE id = ?", tableName), obj.Id)
+}
@@ -0,0 +1,84 @@
+package keys_db
+
+import (
+	keys_dm "assets/internal/core/domain/keys"
+	"assets/internal/core/ports"
+	errs "assets/pkg/errors"
+	"context"
+)
+
+/// test purposes database
+
+type InMemoryDb struct {
+	data map[string]keys_dm.ApiKeyEntity
+}
+
+func NewMemoryRepo() *InMemoryDb {
+	return &InMemoryDb{
+		data: make(map[string]keys_dm.ApiKeyEntity),
+	}
+}
+
+func (i *InMemoryDb) Select(_ context.Context, params ports.SelectApiKeysRepoParams) (results []keys_dm.ApiKeyEntity, cursor string, err error) {
+
+	if len(params.Ids) != 0 {
+		for _, id := range params.Ids {
+			if value, ok := i.data[id]; ok {
+				results = append(results, value)
+			}
+		}
+
+		return results, cursor, err
+	}
+
+	for _, model := range i.data {
+		for _, userId := range params.UserIds {
+			if userId == model.UserId {
+				results = append(results, model)
+			}
+		}
+	}
+
+	return results, cursor, err
+}
+
+func (i *InMemoryDb) Insert(_ context.Context, models ...keys_dm.ApiKeyEntity) (results []keys_dm.ApiKeyEntity, err error) {
+	for _, model := range models {
+		if _, ok := i.data[model.Id]; ok {
+			return []keys_dm.ApiKeyEntity{}, errs.AlreadyExistsError
+		}
+	}
+
+	for _, model := range models {
+		i.data[model.Id] = model
+	}
+
+	return models, err
+}
+
+func (i *InMemoryDb) Update(_ context.Context, models ...keys_dm.ApiKeyEntity) (results []keys_dm.ApiKeyEntity, err error) {
+
+	for _, model := range models {
+		if _, ok := i.data[model.Id]; !ok {
+			return []keys_dm.ApiKeyEntity{}, errs.CannotBeFoundError
+		} else {
+			i.data[model.Id] = model
+		}
+	}
+
+	return models, err
+}
+
+func (i *InMemoryDb) Delete(_ context.Context, models ...keys_dm.ApiKeyEntity) (results []keys_dm.ApiKeyEntity, err error) {
+
+	for _, model := range models {
+		if _, ok := i.data[model.Id]; !ok {
+			return nil, errs.CannotBeFoundError
+		} else {
+			results = append(results, model)
+			delete(i.data, model.Id)
+		}
+	}
+
+	return results, nil
+}
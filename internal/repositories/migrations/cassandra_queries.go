@@ -0,0 +1,33 @@
+package migrations_db
+
+import (
+	"fmt"
+	"github.com/gocql/gocql"
+	"time"
+)
+
+/*
+ * Select
+ */
+
+var tableName = "migrations"
+
+func SelectRecordByName(session *gocql.Session, name string) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("SELECT name FROM %s WHERE name = ?", tableName), name)
+}
+
+/*
+ * Table
+ */
+
+func CreateTableQuery() string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (name text PRIMARY KEY, apply_time timestamp)", tableName)
+}
+
+/*
+ * Insert
+ */
+
+func InsertQuery(session *gocql.Session, name string, applyTime time.Time) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("INSERT INTO %s (name, apply_time) VALUES (?, ?)", tableName), name, applyTime)
+}
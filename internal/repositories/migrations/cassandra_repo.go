@@ -0,0 +1,52 @@
+package migrations_db
+
+import (
+	"assets/pkg/logging"
+	"context"
+	"github.com/gocql/gocql"
+	"github.com/pkg/errors"
+	"time"
+)
+
+type CassandraRepo struct {
+	logger  logging.Logger
+	session *gocql.Session
+}
+
+// NewCassandraRepo creates registry of applied migrations, it lets changes of existing data run once per keyspace.
+func NewCassandraRepo(logger logging.Logger, session *gocql.Session) (repo *CassandraRepo) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := session.Query(CreateTableQuery()).WithContext(ctx).Exec(); err != nil {
+		panic(errors.Wrap(err, "failed to inspect/create migrations table"))
+	}
+
+	return &CassandraRepo{logger: logger, session: session}
+}
+
+// Apply runs migration unless it was already applied. Migration is recorded only once it succeeds, so a failed one is
+// retried on the next start and has to be safe to run more than once.
+func (cr *CassandraRepo) Apply(ctx context.Context, name string, migration func(ctx context.Context) error) (err error) {
+
+	cr.logger.Info("migrations_db.Apply() performed",
+		"name", name,
+	)
+
+	queryCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	var applied string
+	if err = SelectRecordByName(cr.session, name).WithContext(queryCtx).Scan(&applied); err == nil {
+		return nil
+	} else if !errors.Is(err, gocql.ErrNotFound) {
+		return err
+	}
+
+	if err = migration(ctx); err != nil {
+		return errors.Wrap(err, "failed to apply migration "+name)
+	}
+
+	return InsertQuery(cr.session, name, time.Now()).WithContext(ctx).Exec()
+}
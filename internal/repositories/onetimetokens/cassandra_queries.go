@@ -0,0 +1,75 @@
+package onetimetokens_db
+
+import (
+	tokens_dm "assets/internal/core/domain/tokens"
+	"fmt"
+	"github.com/gocql/gocql"
+	"strings"
+	"time"
+)
+
+/*
+ * Select
+ */
+
+var tableName = "one_time_tokens"
+
+func SelectRecords(session *gocql.Session) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("SELECT * FROM %s", tableName))
+}
+
+func SelectRecordsByIds(session *gocql.Session, ids []string) (query *gocql.Query) {
+	idList := "'" + strings.Join(ids, "', '") + "'"
+	return session.Query(fmt.Sprintf("SELECT * FROM %s WHERE id IN (%s)", tableName, idList))
+}
+
+func SelectRecordsByUserId(session *gocql.Session, userId string) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("SELECT * FROM %s WHERE user_id = ?", tableName), userId)
+}
+
+/*
+ * Table
+ */
+
+func CreateTableQuery() string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id text PRIMARY KEY, user_id text, purpose text, hash text, payload map<text, text>, expire_time timestamp, create_time timestamp, update_time timestamp)", tableName)
+}
+
+func CreateUserIdIndexQuery() string {
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_one_time_tokens_user_id ON %s (user_id);", tableName)
+}
+
+func DropTableQuery() string {
+	return fmt.Sprintf("DROP TABLE %s", tableName)
+}
+
+/*
+ * Insert
+ */
+
+func AppendInsertQuery(batch *gocql.Batch, obj tokens_dm.OneTimeTokenEntity) {
+	batch.Query(fmt.Sprintf("INSERT INTO %s (id, user_id, purpose, hash, payload, expire_time, create_time, update_time) VALUES (?, ?, ?, ?, ?, ?, ?, ?) USING TTL ?", tableName),
+		obj.Id, obj.UserId, obj.Purpose, obj.Hash, obj.Payload, obj.ExpireTime, obj.CreateTime, obj.UpdateTime, ttl(obj.ExpireTime))
+}
+
+/*
+ * Delete
+ */
+
+func AppendDeleteQuery(batch *gocql.Batch, obj tokens_dm.OneTimeTokenEntity) {
+	batch.Query(fmt.Sprintf("DELETE FROM %s WHERE id = ?", tableName), obj.Id)
+}
+
+// ConsumeQuery is lightweight transaction, so only one of concurrent requests using the same token succeeds.
+func ConsumeQuery(session *gocql.Session, obj tokens_dm.OneTimeTokenEntity) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("DELETE FROM %s WHERE id = ? IF EXISTS", tableName), obj.Id)
+}
+
+// ttl returns amount of seconds left until expiration, records are kept for at least one second.
+func ttl(expireTime time.Time) int {
+	if seconds := int(time.Until(expireTime).Seconds()); seconds > 0 {
+		return seconds
+	}
+
+	return 1
+}
@@ -0,0 +1,189 @@
+package onetimetokens_db
+
+import (
+	tokens_dm "assets/internal/core/domain/tokens"
+	"assets/internal/core/ports"
+	"assets/pkg/logging"
+	"context"
+	"encoding/base64"
+	"github.com/gocql/gocql"
+	"github.com/pkg/errors"
+	"time"
+)
+
+const cassandraMaxLimit = 10_000
+
+type CassandraRepo struct {
+	logger  logging.Logger
+	session *gocql.Session
+}
+
+func NewCassandraRepo(logger logging.Logger, session *gocql.Session) (repo *CassandraRepo) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := session.Query(CreateTableQuery()).WithContext(ctx).Exec(); err != nil {
+		panic(errors.Wrap(err, "failed to inspect/create one time tokens table"))
+	}
+
+	if err := session.Query(CreateUserIdIndexQuery()).WithContext(ctx).Exec(); err != nil {
+		panic(errors.Wrap(err, "failed to inspect/create one time tokens user_id index"))
+	}
+
+	return &CassandraRepo{logger: logger, session: session}
+}
+
+func (cr *CassandraRepo) Select(ctx context.Context, params ports.SelectOneTimeTokensRepoParams) (results []tokens_dm.OneTimeTokenEntity, next string, err error) {
+
+	cr.logger.Info("onetimetokens_db.Select() performed",
+		"params", params,
+		"results", results,
+	)
+
+	// secondary index doesn't support IN restriction, so every user is fetched separately
+	if len(params.Ids) == 0 && len(params.UserIds) != 0 {
+		for _, userId := range params.UserIds {
+			var partial []tokens_dm.OneTimeTokenEntity
+			if partial, _, err = cr.scan(ctx, SelectRecordsByUserId(cr.session, userId), cassandraMaxLimit, nil); err != nil {
+				return nil, next, err
+			}
+			results = append(results, partial...)
+		}
+
+		return results, next, nil
+	}
+
+	var (
+		limit  = cassandraMaxLimit
+		cursor = make([]byte, 0)
+	)
+
+	if params.Limit != 0 {
+		limit = params.Limit
+	}
+
+	if params.Cursor != "" {
+		if cursor, err = base64.URLEncoding.DecodeString(params.Cursor); err != nil {
+			return nil, next, err
+		}
+	}
+
+	var query *gocql.Query
+	if len(params.Ids) != 0 {
+		query = SelectRecordsByIds(cr.session, params.Ids)
+	} else {
+		query = SelectRecords(cr.session)
+	}
+
+	return cr.scan(ctx, query, limit, cursor)
+}
+
+func (cr *CassandraRepo) Insert(ctx context.Context, models ...tokens_dm.OneTimeTokenEntity) (results []tokens_dm.OneTimeTokenEntity, err error) {
+
+	cr.logger.Info("onetimetokens_db.Insert() performed",
+		"ids", ids(models),
+	)
+
+	if len(models) == 0 {
+		return results, nil
+	}
+
+	if err = cr.execute(ctx, models, AppendInsertQuery); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+func (cr *CassandraRepo) Consume(ctx context.Context, model tokens_dm.OneTimeTokenEntity) (applied bool, err error) {
+
+	cr.logger.Info("onetimetokens_db.Consume() performed",
+		"id", model.Id,
+	)
+
+	// values of the removed row are returned when the condition holds, they aren't needed
+	if applied, err = ConsumeQuery(cr.session, model).WithContext(ctx).MapScanCAS(make(map[string]interface{})); err != nil {
+		return false, err
+	}
+
+	return applied, nil
+}
+
+func (cr *CassandraRepo) Delete(ctx context.Context, models ...tokens_dm.OneTimeTokenEntity) (results []tokens_dm.OneTimeTokenEntity, err error) {
+
+	cr.logger.Info("onetimetokens_db.Delete() performed",
+		"ids", ids(models),
+	)
+
+	if len(models) == 0 {
+		return results, nil
+	}
+
+	if err = cr.execute(ctx, models, AppendDeleteQuery); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+func (cr *CassandraRepo) scan(ctx context.Context, query *gocql.Query, limit int, cursor []byte) (results []tokens_dm.OneTimeTokenEntity, next string, err error) {
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	iter := query.WithContext(ctx).PageSize(limit).PageState(cursor).Iter()
+	defer func() {
+		if err = iter.Close(); err != nil {
+			cr.logger.Info("failed to close iterator", "err", err)
+		}
+	}()
+
+	if len(iter.PageState()) > 0 {
+		next = base64.URLEncoding.EncodeToString(iter.PageState())
+	}
+
+	var obj tokens_dm.OneTimeTokenEntity
+
+	scanner := iter.Scanner()
+	for scanner.Next() {
+		if err = scanner.Scan(&obj.Id, &obj.CreateTime, &obj.ExpireTime, &obj.Hash, &obj.Payload, &obj.Purpose, &obj.UpdateTime, &obj.UserId); err != nil {
+			return nil, next, err
+		} else {
+			results = append(results, obj)
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, next, err
+	}
+
+	return results, next, nil
+}
+
+func (cr *CassandraRepo) execute(ctx context.Context, models []tokens_dm.OneTimeTokenEntity, action func(batch *gocql.Batch, model tokens_dm.OneTimeTokenEntity)) (err error) {
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	batch := cr.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	for idx := range models {
+		models[idx].UpdateTime = time.Now()
+		action(batch, models[idx])
+	}
+
+	if err = cr.session.ExecuteBatch(batch); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ids is used for logging purposes, so token hashes never end up in logs.
+func ids(models []tokens_dm.OneTimeTokenEntity) (results []string) {
+	for _, model := range models {
+		results = append(results, model.Id)
+	}
+
+	return results
+}
@@ -0,0 +1,99 @@
+package onetimetokens_db
+
+import (
+	tokens_dm "assets/internal/core/domain/tokens"
+	"assets/internal/core/ports"
+	errs "assets/pkg/errors"
+	"context"
+	"sync"
+	"time"
+)
+
+/// test purposes database
+
+type InMemoryDb struct {
+	mutex sync.Mutex
+	data  map[string]tokens_dm.OneTimeTokenEntity
+}
+
+func NewMemoryRepo() *InMemoryDb {
+	return &InMemoryDb{
+		data: make(map[string]tokens_dm.OneTimeTokenEntity),
+	}
+}
+
+func (i *InMemoryDb) Select(_ context.Context, params ports.SelectOneTimeTokensRepoParams) (results []tokens_dm.OneTimeTokenEntity, cursor string, err error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, model := range i.data {
+		if model.ExpireTime.Before(time.Now()) {
+			delete(i.data, model.Id)
+		}
+	}
+
+	if len(params.Ids) != 0 {
+		for _, id := range params.Ids {
+			if value, ok := i.data[id]; ok {
+				results = append(results, value)
+			}
+		}
+
+		return results, cursor, err
+	}
+
+	for _, model := range i.data {
+		for _, userId := range params.UserIds {
+			if userId == model.UserId {
+				results = append(results, model)
+			}
+		}
+	}
+
+	return results, cursor, err
+}
+
+func (i *InMemoryDb) Insert(_ context.Context, models ...tokens_dm.OneTimeTokenEntity) (results []tokens_dm.OneTimeTokenEntity, err error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	for _, model := range models {
+		if _, ok := i.data[model.Id]; ok {
+			return []tokens_dm.OneTimeTokenEntity{}, errs.AlreadyExistsError
+		}
+	}
+
+	for _, model := range models {
+		i.data[model.Id] = model
+	}
+
+	return models, err
+}
+
+func (i *InMemoryDb) Consume(_ context.Context, model tokens_dm.OneTimeTokenEntity) (bool, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if _, ok := i.data[model.Id]; !ok {
+		return false, nil
+	}
+
+	delete(i.data, model.Id)
+
+	return true, nil
+}
+
+func (i *InMemoryDb) Delete(_ context.Context, models ...tokens_dm.OneTimeTokenEntity) (results []tokens_dm.OneTimeTokenEntity, err error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, model := range models {
+		if _, ok := i.data[model.Id]; !ok {
+			return nil, errs.CannotBeFoundError
+		} else {
+			results = append(results, model)
+			delete(i.data, model.Id)
+		}
+	}
+
+	return results, nil
+}
@@ -3,6 +3,7 @@ package sessions_db
 import (
 	"fmt"
 	"github.com/gocql/gocql"
+	"time"
 )
 
 /*
@@ -12,7 +13,16 @@ import (
 var tableName = "sessions"
 
 func SelectRecordsById(session *gocql.Session, id string) (query *gocql.Query) {
-	return session.Query(fmt.Sprintf("SELECT * FROM %s WHERE id = '%s'", tableName, id))
+	return session.Query(fmt.Sprintf("SELECT id, \"values\", create_time FROM %s WHERE id = ?", tableName), id)
+}
+
+func SelectIdsByUserId(session *gocql.Session, userId string) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("SELECT id FROM %s WHERE user_id = ?", tableName), userId)
+}
+
+// SelectColumnQuery fails unless the sessions table has given column.
+func SelectColumnQuery(name string) string {
+	return fmt.Sprintf("SELECT %s FROM %s LIMIT 1", name, tableName)
 }
 
 /*
@@ -20,7 +30,16 @@ func SelectRecordsById(session *gocql.Session, id string) (query *gocql.Query) {
  */
 
 func CreateTableQuery() string {
-	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id text PRIMARY KEY, \"values\" text, create_time timestamp, update_time timestamp)", tableName)
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id text PRIMARY KEY, user_id text, \"values\" text, create_time timestamp, update_time timestamp)", tableName)
+}
+
+// AddUserIdColumnQuery adds user_id column to sessions table created before sessions were tied to their users.
+func AddUserIdColumnQuery() string {
+	return fmt.Sprintf("ALTER TABLE %s ADD user_id text", tableName)
+}
+
+func CreateUserIdIndexQuery() string {
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON %s (user_id);", tableName)
 }
 
 func DropTableQuery() string {
@@ -31,7 +50,15 @@ func DropTableQuery() string {
  * Insert
  */
 
-func InsertQuery(cassandra *gocql.Session, id string, values string) (query *gocql.Query) {
-	return cassandra.Query(fmt.Sprintf("INSERT INTO %s (id, \"values\") VALUES (?, ?)", tableName),
-		id, values)
+func InsertQuery(cassandra *gocql.Session, id string, userId string, values string, createTime time.Time, ttl time.Duration) (query *gocql.Query) {
+	return cassandra.Query(fmt.Sprintf("INSERT INTO %s (id, user_id, \"values\", create_time, update_time) VALUES (?, ?, ?, ?, ?) USING TTL ?", tableName),
+		id, userId, values, createTime, time.Now(), int(ttl.Seconds()))
+}
+
+/*
+ * Delete
+ */
+
+func AppendDeleteQuery(batch *gocql.Batch, id string) {
+	batch.Query(fmt.Sprintf("DELETE FROM %s WHERE id = ?", tableName), id)
 }
@@ -1,6 +1,7 @@
 package sessions_db
 
 import (
+	"assets/internal/core/ports"
 	"assets/pkg/logging"
 	"context"
 	"encoding/base32"
@@ -14,17 +15,23 @@ import (
 	"time"
 )
 
+// createTimeKey is used to carry creation time of loaded session between New and Save, it's never persisted in values.
+const createTimeKey = "_create_time"
+
 type CassandraRepo struct {
-	logger  logging.Logger
-	session *gocql.Session
+	logger   logging.Logger
+	session  *gocql.Session
+	lifetime time.Duration
 }
 
 type Session struct {
-	Id     string
-	Values map[interface{}]interface{}
+	Id         string
+	Values     string
+	CreateTime time.Time
 }
 
-func NewCassandraRepo(logger logging.Logger, session *gocql.Session) (repo *CassandraRepo) {
+// NewCassandraRepo creates sessions store, sessions expire after 'lifetime' since their last save.
+func NewCassandraRepo(logger logging.Logger, session *gocql.Session, lifetime time.Duration) (repo *CassandraRepo) {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
@@ -33,7 +40,18 @@ func NewCassandraRepo(logger logging.Logger, session *gocql.Session) (repo *Cass
 		panic(errors.Wrap(err, "failed to inspect/create sessions table"))
 	}
 
-	return &CassandraRepo{logger: logger, session: session}
+	// tables created by previous versions lack user_id column, it has to exist before it's indexed
+	if err := session.Query(SelectColumnQuery("user_id")).WithContext(ctx).Exec(); err != nil {
+		if err = session.Query(AddUserIdColumnQuery()).WithContext(ctx).Exec(); err != nil {
+			panic(errors.Wrap(err, "failed to add user_id column to sessions table"))
+		}
+	}
+
+	if err := session.Query(CreateUserIdIndexQuery()).WithContext(ctx).Exec(); err != nil {
+		panic(errors.Wrap(err, "failed to inspect/create sessions user_id index"))
+	}
+
+	return &CassandraRepo{logger: logger, session: session, lifetime: lifetime}
 }
 
 func (s *CassandraRepo) Get(r *http.Request, name string) (*sessions.Session, error) {
@@ -42,21 +60,56 @@ func (s *CassandraRepo) Get(r *http.Request, name string) (*sessions.Session, er
 
 func (s *CassandraRepo) New(r *http.Request, name string) (*sessions.Session, error) {
 	session := sessions.NewSession(s, name)
+	session.Options = &sessions.Options{Path: "/", HttpOnly: true, MaxAge: int(s.lifetime.Seconds())}
+	session.ID = generateId()
 	session.IsNew = true
 
+	cookie, err := r.Cookie(name)
+	if err != nil || cookie.Value == "" {
+		return session, nil
+	}
+
 	c := &Session{}
-	if err := SelectRecordsById(s.session, name).Scan(&c.Id, &c.Values); err == nil {
-		session.ID = c.Id
-		session.Values = c.Values
-		session.IsNew = false
+	if err = SelectRecordsById(s.session, cookie.Value).WithContext(r.Context()).Scan(&c.Id, &c.Values, &c.CreateTime); err != nil {
+		return session, nil
+	}
+
+	values := make(map[string]interface{})
+	if err = json.Unmarshal([]byte(c.Values), &values); err != nil {
+		return session, nil
 	}
 
+	for k, v := range values {
+		session.Values[k] = v
+	}
+
+	session.Values[createTimeKey] = c.CreateTime
+	session.ID = c.Id
+	session.IsNew = false
+
 	return session, nil
 }
 
 func (s *CassandraRepo) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) (err error) {
+
+	if session.Options != nil && session.Options.MaxAge < 0 {
+		if !session.IsNew && session.ID != "" {
+			if err = s.Revoke(r.Context(), session.ID); err != nil {
+				return err
+			}
+		}
+
+		http.SetCookie(w, sessions.NewCookie(session.Name(), "", session.Options))
+		return nil
+	}
+
 	if session.ID == "" {
-		session.ID = strings.TrimRight(base32.StdEncoding.EncodeToString(securecookie.GenerateRandomKey(32)), "=")
+		session.ID = generateId()
+	}
+
+	createTime := time.Now()
+	if value, ok := session.Values[createTimeKey].(time.Time); ok && !value.IsZero() {
+		createTime = value
 	}
 
 	temp := make(map[string]interface{})
@@ -65,7 +118,9 @@ func (s *CassandraRepo) Save(r *http.Request, w http.ResponseWriter, session *se
 		if !ok {
 			return errors.New("Non-string key found in map")
 		}
-		temp[key] = v
+		if key != createTimeKey {
+			temp[key] = v
+		}
 	}
 
 	var data []byte
@@ -73,11 +128,83 @@ func (s *CassandraRepo) Save(r *http.Request, w http.ResponseWriter, session *se
 		return err
 	}
 
-	if err := InsertQuery(s.session, session.ID, string(data)).Exec(); err != nil {
+	userId, _ := temp[ports.SessionUserIdKey].(string)
+
+	// every save rewrites entire row, so expiration time of the session is moved forward
+	if err = InsertQuery(s.session, session.ID, userId, string(data), createTime, s.lifetime).WithContext(r.Context()).Exec(); err != nil {
 		return err
 	}
 
+	session.Values[createTimeKey] = createTime
+	session.IsNew = false
+
 	http.SetCookie(w, sessions.NewCookie(session.Name(), session.ID, session.Options))
 
 	return nil
 }
+
+// Exists reports whether session with provided id is still active.
+func (s *CassandraRepo) Exists(ctx context.Context, id string) (exists bool, err error) {
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	var sessionId string
+	if err = SelectRecordsById(s.session, id).WithContext(ctx).Scan(&sessionId, new(string), new(time.Time)); errors.Is(err, gocql.ErrNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Revoke removes sessions with provided ids.
+func (s *CassandraRepo) Revoke(ctx context.Context, ids ...string) (err error) {
+
+	s.logger.Info("sessions_db.Revoke() performed",
+		"ids", ids,
+	)
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	batch := s.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	for _, id := range ids {
+		AppendDeleteQuery(batch, id)
+	}
+
+	return s.session.ExecuteBatch(batch)
+}
+
+// RevokeUsers removes all sessions which belong to provided users.
+func (s *CassandraRepo) RevokeUsers(ctx context.Context, userIds ...string) (err error) {
+
+	s.logger.Info("sessions_db.RevokeUsers() performed",
+		"userIds", userIds,
+	)
+
+	var ids []string
+	for _, userId := range userIds {
+		iter := SelectIdsByUserId(s.session, userId).WithContext(ctx).Iter()
+
+		var id string
+		for iter.Scan(&id) {
+			ids = append(ids, id)
+		}
+
+		if err = iter.Close(); err != nil {
+			return err
+		}
+	}
+
+	return s.Revoke(ctx, ids...)
+}
+
+func generateId() string {
+	return strings.TrimRight(base32.StdEncoding.EncodeToString(securecookie.GenerateRandomKey(32)), "=")
+}
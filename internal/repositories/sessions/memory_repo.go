@@ -0,0 +1,112 @@
+package sessions_db
+
+import (
+	"assets/internal/core/ports"
+	"context"
+	"github.com/gorilla/sessions"
+	"net/http"
+	"sync"
+)
+
+/// test purposes database
+
+type InMemoryDb struct {
+	mutex sync.Mutex
+	data  map[string]map[interface{}]interface{}
+}
+
+func NewMemoryRepo() *InMemoryDb {
+	return &InMemoryDb{
+		data: make(map[string]map[interface{}]interface{}),
+	}
+}
+
+func (i *InMemoryDb) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(i, name)
+}
+
+func (i *InMemoryDb) New(r *http.Request, name string) (*sessions.Session, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	session := sessions.NewSession(i, name)
+	session.Options = &sessions.Options{Path: "/", HttpOnly: true}
+	session.ID = generateId()
+	session.IsNew = true
+
+	cookie, err := r.Cookie(name)
+	if err != nil || cookie.Value == "" {
+		return session, nil
+	}
+
+	values, ok := i.data[cookie.Value]
+	if !ok {
+		return session, nil
+	}
+
+	for k, v := range values {
+		session.Values[k] = v
+	}
+
+	session.ID = cookie.Value
+	session.IsNew = false
+
+	return session, nil
+}
+
+func (i *InMemoryDb) Save(_ *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if session.Options != nil && session.Options.MaxAge < 0 {
+		delete(i.data, session.ID)
+		http.SetCookie(w, sessions.NewCookie(session.Name(), "", session.Options))
+		return nil
+	}
+
+	values := make(map[interface{}]interface{})
+	for k, v := range session.Values {
+		values[k] = v
+	}
+
+	i.data[session.ID] = values
+	session.IsNew = false
+
+	http.SetCookie(w, sessions.NewCookie(session.Name(), session.ID, session.Options))
+
+	return nil
+}
+
+func (i *InMemoryDb) Exists(_ context.Context, id string) (bool, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	_, ok := i.data[id]
+	return ok, nil
+}
+
+func (i *InMemoryDb) Revoke(_ context.Context, ids ...string) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, id := range ids {
+		delete(i.data, id)
+	}
+
+	return nil
+}
+
+func (i *InMemoryDb) RevokeUsers(_ context.Context, userIds ...string) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for id, values := range i.data {
+		for _, userId := range userIds {
+			if values[ports.SessionUserIdKey] == userId {
+				delete(i.data, id)
+			}
+		}
+	}
+
+	return nil
+}
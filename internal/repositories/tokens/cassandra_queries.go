@@ -0,0 +1,103 @@
+package tokens_db
+
+import (
+	tokens_dm "assets/internal/core/domain/tokens"
+	"fmt"
+	"github.com/gocql/gocql"
+	"strings"
+	"time"
+)
+
+/*
+ * Select
+ */
+
+var tableName = "refresh_tokens"
+
+func SelectRecords(session *gocql.Session) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("SELECT * FROM %s", tableName))
+}
+
+func SelectRecordsByIds(session *gocql.Session, ids []string) (query *gocql.Query) {
+	idList := "'" + strings.Join(ids, "', '") + "'"
+	return session.Query(fmt.Sprintf("SELECT * FROM %s WHERE id IN (%s)", tableName, idList))
+}
+
+func SelectRecordsByFamilyId(session *gocql.Session, familyId string) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("SELECT * FROM %s WHERE family_id = ?", tableName), familyId)
+}
+
+func SelectRecordsByUserId(session *gocql.Session, userId string) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("SELECT * FROM %s WHERE user_id = ?", tableName), userId)
+}
+
+/*
+ * Table
+ */
+
+func CreateTableQuery() string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id text PRIMARY KEY, user_id text, family_id text, session_id text, hash text, used boolean, expire_time timestamp, create_time timestamp, update_time timestamp)", tableName)
+}
+
+func CreateFamilyIdIndexQuery() string {
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON %s (family_id);", tableName)
+}
+
+func CreateUserIdIndexQuery() string {
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON %s (user_id);", tableName)
+}
+
+// SelectColumnQuery fails unless the refresh tokens table has given column.
+func SelectColumnQuery(name string) string {
+	return fmt.Sprintf("SELECT %s FROM %s LIMIT 1", name, tableName)
+}
+
+// AddSessionIdColumnQuery adds session_id column to refresh tokens table created before tokens were tied to sessions.
+func AddSessionIdColumnQuery() string {
+	return fmt.Sprintf("ALTER TABLE %s ADD session_id text", tableName)
+}
+
+func DropTableQuery() string {
+	return fmt.Sprintf("DROP TABLE %s", tableName)
+}
+
+/*
+ * Insert
+ */
+
+func AppendInsertQuery(batch *gocql.Batch, obj tokens_dm.RefreshTokenEntity) {
+	batch.Query(fmt.Sprintf("INSERT INTO %s (id, user_id, family_id, session_id, hash, used, expire_time, create_time, update_time) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) USING TTL ?", tableName),
+		obj.Id, obj.UserId, obj.FamilyId, obj.SessionId, obj.Hash, obj.Used, obj.ExpireTime, obj.CreateTime, obj.UpdateTime, ttl(obj.ExpireTime))
+}
+
+/*
+ * Update
+ */
+
+func AppendUpdateQuery(batch *gocql.Batch, obj tokens_dm.RefreshTokenEntity) {
+	batch.Query(fmt.Sprintf("UPDATE %s USING TTL ? SET used = ?, update_time = ? WHERE id = ?", tableName),
+		ttl(obj.ExpireTime), obj.Used, obj.UpdateTime, obj.Id)
+}
+
+// MarkUsedQuery is lightweight transaction, so only one of concurrent requests using the same token succeeds.
+func MarkUsedQuery(session *gocql.Session, obj tokens_dm.RefreshTokenEntity) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("UPDATE %s USING TTL ? SET used = true, update_time = ? WHERE id = ? IF used = false", tableName),
+		ttl(obj.ExpireTime), obj.UpdateTime, obj.Id)
+}
+
+/*
+ * Delete
+ */
+
+func AppendDeleteQuery(batch *gocql.Batch, obj tokens_dm.RefreshTokenEntity) {
+	batch.Query(fmt.Sprintf("DELETE FROM %s WHERE id = ?", tableName), obj.Id)
+}
+
+// ttl returns amount of seconds left until expiration, records are kept for at least one second.
+func ttl(expireTime time.Time) int {
+	if seconds := int(time.Until(expireTime).Seconds()); seconds > 0 {
+		return seconds
+	}
+
+	return 1
+}
@@ -0,0 +1,127 @@
+package tokens_db
+
+import (
+	tokens_dm "assets/internal/core/domain/tokens"
+	"assets/internal/core/ports"
+	errs "assets/pkg/errors"
+	"context"
+	"sync"
+	"time"
+)
+
+/// test purposes database
+
+type InMemoryDb struct {
+	mutex sync.Mutex
+	data  map[string]tokens_dm.RefreshTokenEntity
+}
+
+func NewMemoryRepo() *InMemoryDb {
+	return &InMemoryDb{
+		data: make(map[string]tokens_dm.RefreshTokenEntity),
+	}
+}
+
+func (i *InMemoryDb) Select(_ context.Context, params ports.SelectRefreshTokensRepoParams) (results []tokens_dm.RefreshTokenEntity, cursor string, err error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, model := range i.data {
+		if model.ExpireTime.Before(time.Now()) {
+			delete(i.data, model.Id)
+		}
+	}
+
+	if len(params.Ids) != 0 {
+		for _, id := range params.Ids {
+			if value, ok := i.data[id]; ok {
+				results = append(results, value)
+			}
+		}
+
+		return results, cursor, err
+	}
+
+	for _, model := range i.data {
+		for _, familyId := range params.FamilyIds {
+			if familyId == model.FamilyId {
+				results = append(results, model)
+			}
+		}
+		for _, userId := range params.UserIds {
+			if userId == model.UserId {
+				results = append(results, model)
+			}
+		}
+	}
+
+	return results, cursor, err
+}
+
+func (i *InMemoryDb) Insert(_ context.Context, models ...tokens_dm.RefreshTokenEntity) (results []tokens_dm.RefreshTokenEntity, err error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, model := range models {
+		if _, ok := i.data[model.Id]; ok {
+			return []tokens_dm.RefreshTokenEntity{}, errs.AlreadyExistsError
+		}
+	}
+
+	for _, model := range models {
+		i.data[model.Id] = model
+	}
+
+	return models, err
+}
+
+func (i *InMemoryDb) Update(_ context.Context, models ...tokens_dm.RefreshTokenEntity) (results []tokens_dm.RefreshTokenEntity, err error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, model := range models {
+		if _, ok := i.data[model.Id]; !ok {
+			return []tokens_dm.RefreshTokenEntity{}, errs.CannotBeFoundError
+		} else {
+			i.data[model.Id] = model
+		}
+	}
+
+	return models, err
+}
+
+func (i *InMemoryDb) MarkUsed(_ context.Context, model tokens_dm.RefreshTokenEntity) (bool, error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	stored, ok := i.data[model.Id]
+	if !ok {
+		return false, errs.CannotBeFoundError
+	}
+
+	if stored.Used {
+		return false, nil
+	}
+
+	stored.Used = true
+	stored.UpdateTime = model.UpdateTime
+	i.data[model.Id] = stored
+
+	return true, nil
+}
+
+func (i *InMemoryDb) Delete(_ context.Context, models ...tokens_dm.RefreshTokenEntity) (results []tokens_dm.RefreshTokenEntity, err error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, model := range models {
+		if _, ok := i.data[model.Id]; !ok {
+			return nil, errs.CannotBeFoundError
+		} else {
+			results = append(results, model)
+			delete(i.data, model.Id)
+		}
+	}
+
+	return results, nil
+}
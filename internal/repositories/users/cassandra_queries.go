@@ -5,13 +5,33 @@ import (
 	"fmt"
 	"github.com/gocql/gocql"
 	"strings"
+	"unicode/utf8"
 )
 
 /*
  * Select
  */
 
-var tableName = "users"
+var (
+	tableName       = "users"
+	emailsTableName = "users_by_email"
+)
+
+// column is the column added to the users table after its first release, tables created before have to be altered.
+type column struct {
+	name string
+	kind string
+}
+
+var addedColumns = []column{
+	{name: "roles", kind: "set<text>"},
+	{name: "verified", kind: "boolean"},
+	{name: "disabled", kind: "boolean"},
+	{name: "totp_enabled", kind: "boolean"},
+	{name: "totp_secret", kind: "text"},
+	{name: "totp_last_step", kind: "bigint"},
+	{name: "recovery_codes", kind: "set<text>"},
+}
 
 func SelectRecords(session *gocql.Session) (query *gocql.Query) {
 	return session.Query(fmt.Sprintf("SELECT * FROM %s", tableName))
@@ -27,18 +47,45 @@ func SelectRecordsByEmails(session *gocql.Session, emails []string) (query *gocq
 	return session.Query(fmt.Sprintf("SELECT * FROM %s WHERE email IN (%s)", tableName, emailsList))
 }
 
+// SelectColumnQuery fails unless the users table has given column.
+func SelectColumnQuery(name string) string {
+	return fmt.Sprintf("SELECT %s FROM %s LIMIT 1", name, tableName)
+}
+
+// SelectBackfillRecords reads columns needed to backfill users stored by previous versions, verified is null only for
+// users stored before it was added.
+func SelectBackfillRecords(session *gocql.Session) (query *gocql.Query) {
+	return session.Query(fmt.Sprintf("SELECT id, email, verified FROM %s", tableName))
+}
+
+// SelectIdsByEmailPrefix reads the lookup table, emails are clustered within partition of their first letter, so
+// prefix becomes a range of the clustering key.
+func SelectIdsByEmailPrefix(session *gocql.Session, prefix string) (query *gocql.Query) {
+	prefix = strings.ToLower(prefix)
+	return session.Query(fmt.Sprintf("SELECT id FROM %s WHERE bucket = ? AND email >= ? AND email < ?", emailsTableName),
+		emailBucket(prefix), prefix, prefix+string(utf8.MaxRune))
+}
+
 /*
  * Table
  */
 
 func CreateTableQuery() string {
-	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id text PRIMARY KEY, email text, password text, create_time timestamp, update_time timestamp)", tableName)
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id text PRIMARY KEY, email text, password text, roles set<text>, verified boolean, disabled boolean, totp_enabled boolean, totp_secret text, totp_last_step bigint, recovery_codes set<text>, create_time timestamp, update_time timestamp)", tableName)
 }
 
 func CreateEmailIndexQuery() string {
 	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_email ON %s (email);", tableName)
 }
 
+func CreateEmailsTableQuery() string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (bucket text, email text, id text, PRIMARY KEY ((bucket), email, id))", emailsTableName)
+}
+
+func AddColumnQuery(added column) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD %s %s", tableName, added.name, added.kind)
+}
+
 func DropTableQuery() string {
 	return fmt.Sprintf("DROP TABLE %s", tableName)
 }
@@ -48,6 +95,47 @@ func DropTableQuery() string {
  */
 
 func AppendInsertQuery(batch *gocql.Batch, obj users_dm.UserEntity) {
-	batch.Query(fmt.Sprintf("INSERT INTO %s (id, email, password, create_time, update_time) VALUES (?, ?, ?, ?, ?)", tableName),
-		obj.Id, obj.Email, obj.Password, obj.CreateTime, obj.UpdateTime)
+	batch.Query(fmt.Sprintf("INSERT INTO %s (id, email, password, roles, verified, disabled, totp_enabled, totp_secret, totp_last_step, recovery_codes, create_time, update_time) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", tableName),
+		obj.Id, obj.Email, obj.Password, obj.Roles, obj.Verified, obj.Disabled, obj.TotpEnabled, obj.TotpSecret, obj.TotpLastStep, obj.RecoveryCodes, obj.CreateTime, obj.UpdateTime)
+}
+
+func AppendInsertEmailQuery(batch *gocql.Batch, obj users_dm.UserEntity) {
+	email := strings.ToLower(obj.Email)
+	batch.Query(fmt.Sprintf("INSERT INTO %s (bucket, email, id) VALUES (?, ?, ?)", emailsTableName),
+		emailBucket(email), email, obj.Id)
+}
+
+/*
+ * Update
+ */
+
+func AppendUpdateQuery(batch *gocql.Batch, obj users_dm.UserEntity) {
+	batch.Query(fmt.Sprintf("UPDATE %s SET email = ?, password = ?, roles = ?, verified = ?, disabled = ?, totp_enabled = ?, totp_secret = ?, totp_last_step = ?, recovery_codes = ?, update_time = ? WHERE id = ?", tableName),
+		obj.Email, obj.Password, obj.Roles, obj.Verified, obj.Disabled, obj.TotpEnabled, obj.TotpSecret, obj.TotpLastStep, obj.RecoveryCodes, obj.UpdateTime, obj.Id)
+}
+
+// AppendUpdateLegacyQuery gives user stored before roles and verification the defaults of users registered since.
+func AppendUpdateLegacyQuery(batch *gocql.Batch, id string, roles []users_dm.Role) {
+	batch.Query(fmt.Sprintf("UPDATE %s SET roles = ?, verified = false, disabled = false, totp_enabled = false WHERE id = ?", tableName),
+		roles, id)
+}
+
+/*
+ * Delete
+ */
+
+func AppendDeleteQuery(batch *gocql.Batch, obj users_dm.UserEntity) {
+	batch.Query(fmt.Sprintf("DELETE FROM %s WHERE id = ?", tableName), obj.Id)
+}
+
+func AppendDeleteEmailQuery(batch *gocql.Batch, obj users_dm.UserEntity) {
+	email := strings.ToLower(obj.Email)
+	batch.Query(fmt.Sprintf("DELETE FROM %s WHERE bucket = ? AND email = ? AND id = ?", emailsTableName),
+		emailBucket(email), email, obj.Id)
+}
+
+// emailBucket returns partition of the email lookup table, which is the first letter of lowercase email.
+func emailBucket(email string) string {
+	first, _ := utf8.DecodeRuneInString(email)
+	return string(first)
 }
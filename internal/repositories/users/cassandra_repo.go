@@ -8,10 +8,14 @@ import (
 	"encoding/base64"
 	"github.com/gocql/gocql"
 	"github.com/pkg/errors"
+	"strings"
 	"time"
 )
 
-const cassandraMaxLimit = 10_000
+const (
+	cassandraMaxLimit = 10_000
+	backfillPageSize  = 100
+)
 
 type CassandraRepo struct {
 	logger  logging.Logger
@@ -27,10 +31,25 @@ func NewCassandraRepo(logger logging.Logger, session *gocql.Session) (repo *Cass
 		panic(errors.Wrap(err, "failed to inspect/create users table"))
 	}
 
+	// tables created by previous versions lack columns added since, they have to exist before any user is written
+	for _, added := range addedColumns {
+		if err := session.Query(SelectColumnQuery(added.name)).WithContext(ctx).Exec(); err == nil {
+			continue
+		}
+
+		if err := session.Query(AddColumnQuery(added)).WithContext(ctx).Exec(); err != nil {
+			panic(errors.Wrap(err, "failed to add "+added.name+" column to users table"))
+		}
+	}
+
 	if err := session.Query(CreateEmailIndexQuery()).WithContext(ctx).Exec(); err != nil {
 		panic(errors.Wrap(err, "failed to inspect/create email index on messages table"))
 	}
 
+	if err := session.Query(CreateEmailsTableQuery()).WithContext(ctx).Exec(); err != nil {
+		panic(errors.Wrap(err, "failed to inspect/create users by email table"))
+	}
+
 	return &CassandraRepo{logger: logger, session: session}
 }
 
@@ -56,6 +75,10 @@ func (cr *CassandraRepo) Select(ctx context.Context, params ports.SelectUsersRep
 		}
 	}
 
+	if len(params.Ids) == 0 && len(params.Emails) == 0 && params.EmailPrefix != "" {
+		return cr.selectByEmailPrefix(ctx, params.EmailPrefix, limit, cursor)
+	}
+
 	var query *gocql.Query
 	if len(params.Ids) != 0 {
 		query = SelectRecordsByIds(cr.session, params.Ids)
@@ -65,38 +88,98 @@ func (cr *CassandraRepo) Select(ctx context.Context, params ports.SelectUsersRep
 		query = SelectRecords(cr.session)
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	return cr.scan(ctx, query, limit, cursor)
+}
+
+// selectByEmailPrefix pages through the email lookup table and loads users found on the page, so the cursor belongs
+// to the lookup table and users come ordered by email.
+func (cr *CassandraRepo) selectByEmailPrefix(ctx context.Context, prefix string, limit int, cursor []byte) (results []users_dm.UserEntity, next string, err error) {
+
+	queryCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel()
 
-	iter := query.WithContext(ctx).PageSize(limit).PageState(cursor).Iter()
-	defer func() {
-		if err = iter.Close(); err != nil {
-			cr.logger.Info("failed to close iterator", "err", err)
-		}
-	}()
+	iter := SelectIdsByEmailPrefix(cr.session, prefix).WithContext(queryCtx).PageSize(limit).PageState(cursor).Iter()
 
 	if len(iter.PageState()) > 0 {
 		next = base64.URLEncoding.EncodeToString(iter.PageState())
 	}
 
-	var obj users_dm.UserEntity
+	var (
+		id  string
+		ids []string
+	)
 
-	scanner := iter.Scanner()
-	for scanner.Next() {
-		if err = scanner.Scan(&obj.Id, &obj.CreateTime, &obj.Email, &obj.Password, &obj.UpdateTime); err != nil {
-			return nil, next, err
-		} else {
-			results = append(results, obj)
-		}
+	for iter.Scan(&id) {
+		ids = append(ids, id)
 	}
 
-	if err = scanner.Err(); err != nil {
+	if err = iter.Close(); err != nil {
 		return nil, next, err
 	}
 
+	if len(ids) == 0 {
+		return results, next, nil
+	}
+
+	var users []users_dm.UserEntity
+	if users, _, err = cr.scan(ctx, SelectRecordsByIds(cr.session, ids), cassandraMaxLimit, nil); err != nil {
+		return nil, next, err
+	}
+
+	mapper := make(map[string]users_dm.UserEntity, len(users))
+	for _, user := range users {
+		mapper[user.Id] = user
+	}
+
+	for _, id := range ids {
+		if user, ok := mapper[id]; ok {
+			results = append(results, user)
+		}
+	}
+
 	return results, next, nil
 }
 
+// Backfill puts users stored before the email lookup table into it. Users stored before roles and verification get the
+// viewer role, as newly registered users do, and stay unverified. Backfill can be run again.
+func (cr *CassandraRepo) Backfill(ctx context.Context) (err error) {
+
+	cr.logger.Info("users_db.Backfill() performed")
+
+	cursor := make([]byte, 0)
+	for {
+		iter := SelectBackfillRecords(cr.session).WithContext(ctx).PageSize(backfillPageSize).PageState(cursor).Iter()
+		cursor = append([]byte(nil), iter.PageState()...)
+
+		var (
+			user     users_dm.UserEntity
+			verified *bool
+		)
+
+		batch := cr.session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
+		for iter.Scan(&user.Id, &user.Email, &verified) {
+			AppendInsertEmailQuery(batch, user)
+			if verified == nil {
+				AppendUpdateLegacyQuery(batch, user.Id, []users_dm.Role{users_dm.RoleViewer})
+			}
+		}
+
+		if err = iter.Close(); err != nil {
+			return err
+		}
+
+		if len(batch.Entries) != 0 {
+			if err = cr.session.ExecuteBatch(batch); err != nil {
+				return err
+			}
+		}
+
+		if len(cursor) == 0 {
+			return nil
+		}
+	}
+}
+
 func (cr *CassandraRepo) Insert(ctx context.Context, assets ...users_dm.UserEntity) (results []users_dm.UserEntity, err error) {
 
 	cr.logger.Info("users_db.Insert() performed",
@@ -104,13 +187,72 @@ func (cr *CassandraRepo) Insert(ctx context.Context, assets ...users_dm.UserEnti
 		"results", results,
 	)
 
-	if err = cr.execute(ctx, assets, AppendInsertQuery); err != nil {
+	if err = cr.execute(ctx, assets, func(batch *gocql.Batch, user users_dm.UserEntity) {
+		AppendInsertQuery(batch, user)
+		AppendInsertEmailQuery(batch, user)
+	}); err != nil {
 		return nil, err
 	}
 
 	return assets, nil
 }
 
+func (cr *CassandraRepo) Update(ctx context.Context, models ...users_dm.UserEntity) (results []users_dm.UserEntity, err error) {
+
+	cr.logger.Info("users_db.Update() performed",
+		"params", models,
+		"results", results,
+	)
+
+	if len(models) == 0 {
+		return results, nil
+	}
+
+	// lookup rows are keyed by email, so rows of changed emails have to be replaced
+	var previous []users_dm.UserEntity
+	if previous, _, err = cr.scan(ctx, SelectRecordsByIds(cr.session, ids(models)), cassandraMaxLimit, nil); err != nil {
+		return nil, err
+	}
+
+	emails := make(map[string]users_dm.UserEntity, len(previous))
+	for _, user := range previous {
+		emails[user.Id] = user
+	}
+
+	if err = cr.execute(ctx, models, func(batch *gocql.Batch, user users_dm.UserEntity) {
+		AppendUpdateQuery(batch, user)
+		if old, ok := emails[user.Id]; ok && !strings.EqualFold(old.Email, user.Email) {
+			AppendDeleteEmailQuery(batch, old)
+		}
+		AppendInsertEmailQuery(batch, user)
+	}); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+func (cr *CassandraRepo) Delete(ctx context.Context, models ...users_dm.UserEntity) (results []users_dm.UserEntity, err error) {
+
+	cr.logger.Info("users_db.Delete() performed",
+		"params", models,
+		"results", results,
+	)
+
+	if len(models) == 0 {
+		return results, nil
+	}
+
+	if err = cr.execute(ctx, models, func(batch *gocql.Batch, user users_dm.UserEntity) {
+		AppendDeleteQuery(batch, user)
+		AppendDeleteEmailQuery(batch, user)
+	}); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
 func (cr *CassandraRepo) execute(ctx context.Context, users []users_dm.UserEntity, action func(batch *gocql.Batch, user users_dm.UserEntity)) (err error) {
 
 	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
@@ -128,3 +270,45 @@ func (cr *CassandraRepo) execute(ctx context.Context, users []users_dm.UserEntit
 
 	return nil
 }
+
+func (cr *CassandraRepo) scan(ctx context.Context, query *gocql.Query, limit int, cursor []byte) (results []users_dm.UserEntity, next string, err error) {
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	iter := query.WithContext(ctx).PageSize(limit).PageState(cursor).Iter()
+	defer func() {
+		if err = iter.Close(); err != nil {
+			cr.logger.Info("failed to close iterator", "err", err)
+		}
+	}()
+
+	if len(iter.PageState()) > 0 {
+		next = base64.URLEncoding.EncodeToString(iter.PageState())
+	}
+
+	var obj users_dm.UserEntity
+
+	scanner := iter.Scanner()
+	for scanner.Next() {
+		if err = scanner.Scan(&obj.Id, &obj.CreateTime, &obj.Disabled, &obj.Email, &obj.Password, &obj.RecoveryCodes, &obj.Roles, &obj.TotpEnabled, &obj.TotpLastStep, &obj.TotpSecret, &obj.UpdateTime, &obj.Verified); err != nil {
+			return nil, next, err
+		} else {
+			results = append(results, obj)
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, next, err
+	}
+
+	return results, next, nil
+}
+
+func ids(models []users_dm.UserEntity) (results []string) {
+	for _, model := range models {
+		results = append(results, model.Id)
+	}
+
+	return results
+}
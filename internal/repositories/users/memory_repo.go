@@ -5,6 +5,9 @@ import (
 	"assets/internal/core/ports"
 	errs "assets/pkg/errors"
 	"context"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 /// test purposes database
@@ -47,9 +50,47 @@ func (i *InMemoryDb) Select(_ context.Context, params ports.SelectUsersRepoParam
 		return results, cursor, err
 	}
 
+	if len(params.Ids) == 0 && len(params.Emails) == 0 && params.EmailPrefix != "" {
+		return i.selectByEmailPrefix(params)
+	}
+
 	return results, cursor, err
 }
 
+// selectByEmailPrefix returns users ordered by email, cursor is the offset of the next page.
+func (i *InMemoryDb) selectByEmailPrefix(params ports.SelectUsersRepoParams) (results []users_dm.UserEntity, cursor string, err error) {
+
+	prefix := strings.ToLower(params.EmailPrefix)
+	for _, model := range i.data {
+		if strings.HasPrefix(strings.ToLower(model.Email), prefix) {
+			results = append(results, model)
+		}
+	}
+
+	sort.Slice(results, func(a, b int) bool {
+		return strings.ToLower(results[a].Email) < strings.ToLower(results[b].Email)
+	})
+
+	offset := 0
+	if params.Cursor != "" {
+		if offset, err = strconv.Atoi(params.Cursor); err != nil {
+			return nil, cursor, err
+		}
+	}
+
+	if offset >= len(results) {
+		return nil, cursor, nil
+	}
+
+	results = results[offset:]
+	if params.Limit != 0 && len(results) > params.Limit {
+		results = results[:params.Limit]
+		cursor = strconv.Itoa(offset + params.Limit)
+	}
+
+	return results, cursor, nil
+}
+
 func (i *InMemoryDb) Insert(_ context.Context, models ...users_dm.UserEntity) (results []users_dm.UserEntity, err error) {
 	for _, model := range models {
 		if _, ok := i.data[model.Id]; ok {
@@ -63,3 +104,30 @@ func (i *InMemoryDb) Insert(_ context.Context, models ...users_dm.UserEntity) (r
 
 	return models, err
 }
+
+func (i *InMemoryDb) Update(_ context.Context, models ...users_dm.UserEntity) (results []users_dm.UserEntity, err error) {
+
+	for _, model := range models {
+		if _, ok := i.data[model.Id]; !ok {
+			return []users_dm.UserEntity{}, errs.CannotBeFoundError
+		} else {
+			i.data[model.Id] = model
+		}
+	}
+
+	return models, err
+}
+
+func (i *InMemoryDb) Delete(_ context.Context, models ...users_dm.UserEntity) (results []users_dm.UserEntity, err error) {
+
+	for _, model := range models {
+		if _, ok := i.data[model.Id]; !ok {
+			return nil, errs.CannotBeFoundError
+		} else {
+			results = append(results, model)
+			delete(i.data, model.Id)
+		}
+	}
+
+	return results, nil
+}
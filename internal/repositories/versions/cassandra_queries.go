@@ -0,0 +1,64 @@
+package versions_db
+
+import (
+	versions_dm "assets/internal/core/domain/versions"
+	"fmt"
+	"github.com/gocql/gocql"
+	"strconv"
+	"strings"
+)
+
+/*
+ * Select
+ */
+
+var tableName = "asset_versions"
+
+func SelectRecordsByAssetIds(session *gocql.Session, assetIds []string, numbers []int, latest bool) (query *gocql.Query) {
+
+	idList := "'" + strings.Join(assetIds, "', '") + "'"
+	statement := fmt.Sprintf("SELECT * FROM %s WHERE asset_id IN (%s)", tableName, idList)
+
+	if len(numbers) != 0 {
+		numberList := make([]string, 0, len(numbers))
+		for _, number := range numbers {
+			numberList = append(numberList, strconv.Itoa(number))
+		}
+		statement += fmt.Sprintf(" AND number IN (%s)", strings.Join(numberList, ", "))
+	}
+
+	if latest {
+		statement += " PER PARTITION LIMIT 1"
+	}
+
+	return session.Query(statement)
+}
+
+/*
+ * Table
+ */
+
+func CreateTableQuery() string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (asset_id text, number int, id text, author_id text, snapshot text, create_time timestamp, update_time timestamp, PRIMARY KEY ((asset_id), number)) WITH CLUSTERING ORDER BY (number DESC)", tableName)
+}
+
+func DropTableQuery() string {
+	return fmt.Sprintf("DROP TABLE %s", tableName)
+}
+
+/*
+ * Insert
+ */
+
+func AppendInsertQuery(batch *gocql.Batch, obj versions_dm.VersionEntity, snapshot string) {
+	batch.Query(fmt.Sprintf("INSERT INTO %s (asset_id, number, id, author_id, snapshot, create_time, update_time) VALUES (?, ?, ?, ?, ?, ?, ?)", tableName),
+		obj.AssetId, obj.Number, obj.Id, obj.AuthorId, snapshot, obj.CreateTime, obj.UpdateTime)
+}
+
+/*
+ * Delete
+ */
+
+func AppendDeleteQuery(batch *gocql.Batch, obj versions_dm.VersionEntity) {
+	batch.Query(fmt.Sprintf("DELETE FROM %s WHERE asset_id = ? AND number = ?", tableName), obj.AssetId, obj.Number)
+}
@@ -0,0 +1,162 @@
+package versions_db
+
+import (
+	versions_dm "assets/internal/core/domain/versions"
+	"assets/internal/core/ports"
+	"assets/pkg/logging"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/gocql/gocql"
+	"github.com/pkg/errors"
+	"time"
+)
+
+const cassandraMaxLimit = 10_000
+
+type CassandraRepo struct {
+	logger  logging.Logger
+	session *gocql.Session
+}
+
+func NewCassandraRepo(logger logging.Logger, session *gocql.Session) (repo *CassandraRepo) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := session.Query(CreateTableQuery()).WithContext(ctx).Exec(); err != nil {
+		panic(errors.Wrap(err, "failed to inspect/create asset versions table"))
+	}
+
+	return &CassandraRepo{logger: logger, session: session}
+}
+
+func (cr *CassandraRepo) Select(ctx context.Context, params ports.SelectVersionsRepoParams) (results []versions_dm.VersionEntity, next string, err error) {
+
+	cr.logger.Info("versions_db.Select() performed",
+		"params", params,
+		"results", results,
+	)
+
+	if len(params.AssetIds) == 0 {
+		return results, next, nil
+	}
+
+	var (
+		limit  = cassandraMaxLimit
+		cursor = make([]byte, 0)
+	)
+
+	if params.Limit != 0 {
+		limit = params.Limit
+	}
+
+	if params.Cursor != "" {
+		if cursor, err = base64.URLEncoding.DecodeString(params.Cursor); err != nil {
+			return nil, next, err
+		}
+	}
+
+	query := SelectRecordsByAssetIds(cr.session, params.AssetIds, params.Numbers, params.Latest)
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	iter := query.WithContext(ctx).PageSize(limit).PageState(cursor).Iter()
+	defer func() {
+		if err = iter.Close(); err != nil {
+			cr.logger.Info("failed to close iterator", "err", err)
+		}
+	}()
+
+	if len(iter.PageState()) > 0 {
+		next = base64.URLEncoding.EncodeToString(iter.PageState())
+	}
+
+	var snapshot string
+
+	scanner := iter.Scanner()
+	for scanner.Next() {
+		var obj versions_dm.VersionEntity
+		if err = scanner.Scan(&obj.AssetId, &obj.Number, &obj.AuthorId, &obj.CreateTime, &obj.Id, &snapshot, &obj.UpdateTime); err != nil {
+			return nil, next, err
+		}
+
+		if err = json.Unmarshal([]byte(snapshot), &obj.Snapshot); err != nil {
+			return nil, next, err
+		}
+
+		results = append(results, obj)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, next, err
+	}
+
+	return results, next, nil
+}
+
+func (cr *CassandraRepo) Insert(ctx context.Context, models ...versions_dm.VersionEntity) (results []versions_dm.VersionEntity, err error) {
+
+	cr.logger.Info("versions_db.Insert() performed",
+		"params", models,
+		"results", results,
+	)
+
+	if len(models) == 0 {
+		return results, nil
+	}
+
+	snapshots := make(map[string]string, len(models))
+	for _, model := range models {
+		var snapshot []byte
+		if snapshot, err = json.Marshal(model.Snapshot); err != nil {
+			return nil, err
+		}
+		snapshots[model.Id] = string(snapshot)
+	}
+
+	if err = cr.execute(ctx, models, func(batch *gocql.Batch, version versions_dm.VersionEntity) {
+		AppendInsertQuery(batch, version, snapshots[version.Id])
+	}); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+func (cr *CassandraRepo) Delete(ctx context.Context, models ...versions_dm.VersionEntity) (results []versions_dm.VersionEntity, err error) {
+
+	cr.logger.Info("versions_db.Delete() performed",
+		"params", models,
+		"results", results,
+	)
+
+	if len(models) == 0 {
+		return results, nil
+	}
+
+	if err = cr.execute(ctx, models, AppendDeleteQuery); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+func (cr *CassandraRepo) execute(ctx context.Context, models []versions_dm.VersionEntity, action func(batch *gocql.Batch, version versions_dm.VersionEntity)) (err error) {
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	batch := cr.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	for idx := range models {
+		models[idx].UpdateTime = time.Now()
+		action(batch, models[idx])
+	}
+
+	if err = cr.session.ExecuteBatch(batch); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package versions_db
+
+import (
+	versions_dm "assets/internal/core/domain/versions"
+	"assets/internal/core/ports"
+	errs "assets/pkg/errors"
+	"assets/pkg/slices"
+	"context"
+	"sort"
+)
+
+/// test purposes database
+
+type InMemoryDb struct {
+	data map[string]versions_dm.VersionEntity
+}
+
+func NewMemoryRepo() *InMemoryDb {
+	return &InMemoryDb{
+		data: make(map[string]versions_dm.VersionEntity),
+	}
+}
+
+func (i *InMemoryDb) Select(_ context.Context, params ports.SelectVersionsRepoParams) (results []versions_dm.VersionEntity, cursor string, err error) {
+
+	for _, model := range i.data {
+		if !slices.Contains(params.AssetIds, model.AssetId) {
+			continue
+		}
+		if len(params.Numbers) != 0 && !slices.Contains(params.Numbers, model.Number) {
+			continue
+		}
+		results = append(results, model)
+	}
+
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].AssetId != results[b].AssetId {
+			return results[a].AssetId < results[b].AssetId
+		}
+		return results[a].Number > results[b].Number
+	})
+
+	if params.Latest {
+		latest := make([]versions_dm.VersionEntity, 0, len(results))
+		for idx, model := range results {
+			if idx == 0 || results[idx-1].AssetId != model.AssetId {
+				latest = append(latest, model)
+			}
+		}
+		results = latest
+	}
+
+	return results, cursor, err
+}
+
+func (i *InMemoryDb) Insert(_ context.Context, models ...versions_dm.VersionEntity) (results []versions_dm.VersionEntity, err error) {
+	for _, model := range models {
+		for _, stored := range i.data {
+			if stored.AssetId == model.AssetId && stored.Number == model.Number {
+				return []versions_dm.VersionEntity{}, errs.AlreadyExistsError
+			}
+		}
+	}
+
+	for _, model := range models {
+		i.data[model.Id] = model
+	}
+
+	return models, err
+}
+
+func (i *InMemoryDb) Delete(_ context.Context, models ...versions_dm.VersionEntity) (results []versions_dm.VersionEntity, err error) {
+
+	for _, model := range models {
+		if _, ok := i.data[model.Id]; !ok {
+			return nil, errs.CannotBeFoundError
+		} else {
+			results = append(results, model)
+			delete(i.data, model.Id)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,203 @@
+package search
+
+import (
+	assets_dm "assets/internal/core/domain/assets"
+	"assets/internal/core/ports"
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Weights of indexed fields, matches in the name count the most.
+const (
+	nameWeight        = 3.0
+	chartTitleWeight  = 2.0
+	descriptionWeight = 1.0
+	insightWeight     = 1.0
+)
+
+// prefixPenalty scales score of terms which only start with the query token.
+const prefixPenalty = 0.5
+
+// InvertedIndex keeps whole index in memory, it has to be rebuilt whenever the service starts. Every query token has
+// to match a term of the asset, either exactly or as its prefix, and assets are ranked by sum of weighted tf-idf of
+// matched terms.
+type InvertedIndex struct {
+	mutex     sync.RWMutex
+	postings  map[string]map[string]float64
+	documents map[string][]string
+	terms     []string
+	dirty     bool
+}
+
+func NewInvertedIndex() *InvertedIndex {
+	return &InvertedIndex{
+		postings:  make(map[string]map[string]float64),
+		documents: make(map[string][]string),
+	}
+}
+
+func (x *InvertedIndex) Index(_ context.Context, assets ...assets_dm.AssetEntity) error {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	for _, asset := range assets {
+		x.remove(asset.Id)
+
+		frequencies := document(asset)
+		for term, frequency := range frequencies {
+			if _, ok := x.postings[term]; !ok {
+				x.postings[term] = make(map[string]float64)
+				x.dirty = true
+			}
+			x.postings[term][asset.Id] = frequency
+			x.documents[asset.Id] = append(x.documents[asset.Id], term)
+		}
+	}
+
+	return nil
+}
+
+func (x *InvertedIndex) Remove(_ context.Context, ids ...string) error {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	for _, id := range ids {
+		x.remove(id)
+	}
+
+	return nil
+}
+
+func (x *InvertedIndex) Search(_ context.Context, params ports.SearchParams) (results []ports.SearchHit, err error) {
+	tokens := tokenize(params.Query)
+	if len(tokens) == 0 {
+		return results, nil
+	}
+
+	x.refreshTerms()
+
+	x.mutex.RLock()
+	defer x.mutex.RUnlock()
+
+	var scores map[string]float64
+	for _, token := range tokens {
+		matched := x.score(token)
+
+		// assets have to match every token of the query
+		if scores == nil {
+			scores = matched
+		} else {
+			for id, score := range scores {
+				if value, ok := matched[id]; ok {
+					scores[id] = score + value
+				} else {
+					delete(scores, id)
+				}
+			}
+		}
+	}
+
+	for id, score := range scores {
+		results = append(results, ports.SearchHit{Id: id, Score: score})
+	}
+
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+		return results[a].Id < results[b].Id
+	})
+
+	if params.Limit > 0 && len(results) > params.Limit {
+		results = results[:params.Limit]
+	}
+
+	return results, nil
+}
+
+// score sums weighted tf-idf of terms matching the token, terms matched by prefix only are penalized.
+func (x *InvertedIndex) score(token string) (results map[string]float64) {
+	results = make(map[string]float64)
+
+	for idx := sort.SearchStrings(x.terms, token); idx < len(x.terms) && strings.HasPrefix(x.terms[idx], token); idx++ {
+		term := x.terms[idx]
+		postings := x.postings[term]
+
+		idf := math.Log(1 + float64(len(x.documents))/float64(len(postings)))
+		if term != token {
+			idf *= prefixPenalty
+		}
+
+		for id, frequency := range postings {
+			results[id] += frequency * idf
+		}
+	}
+
+	return results
+}
+
+func (x *InvertedIndex) remove(id string) {
+	for _, term := range x.documents[id] {
+		delete(x.postings[term], id)
+		if len(x.postings[term]) == 0 {
+			delete(x.postings, term)
+			x.dirty = true
+		}
+	}
+
+	delete(x.documents, id)
+}
+
+// refreshTerms rebuilds sorted list of terms used for prefix matching once vocabulary has changed.
+func (x *InvertedIndex) refreshTerms() {
+	x.mutex.Lock()
+	defer x.mutex.Unlock()
+
+	if !x.dirty {
+		return
+	}
+
+	x.terms = make([]string, 0, len(x.postings))
+	for term := range x.postings {
+		x.terms = append(x.terms, term)
+	}
+	sort.Strings(x.terms)
+	x.dirty = false
+}
+
+// document counts weighted frequencies of terms found in searchable fields of the asset.
+func document(asset assets_dm.AssetEntity) (results map[string]float64) {
+	results = make(map[string]float64)
+
+	type field struct {
+		text   string
+		weight float64
+	}
+
+	fields := []field{{asset.Name, nameWeight}, {asset.Description, descriptionWeight}}
+	if asset.AssetData.Chart != nil {
+		fields = append(fields, field{asset.AssetData.Chart.ChartTitle, chartTitleWeight})
+	}
+	if asset.AssetData.Insight != nil {
+		fields = append(fields, field{asset.AssetData.Insight.Text, insightWeight})
+	}
+
+	for _, field := range fields {
+		for _, token := range tokenize(field.text) {
+			results[token] += field.weight
+		}
+	}
+
+	return results
+}
+
+// tokenize splits text into lower-cased words made of letters and digits.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
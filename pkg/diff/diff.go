@@ -0,0 +1,99 @@
+package diff
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Change describes value of a single field before and after the modification, missing side is nil.
+type Change struct {
+	Path   string `json:"path"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// Compute compares JSON representations of both values and returns changed fields ordered by path. Nested fields are
+// addressed with dots, i.e. 'asset_data.chart.title' or 'tags.0'. Nil value stands for the object which doesn't exist,
+// so every field of the other one is reported.
+func Compute(before any, after any) (results []Change, err error) {
+
+	var left, right map[string]any
+	if left, err = flatten(before); err != nil {
+		return nil, err
+	}
+
+	if right, err = flatten(after); err != nil {
+		return nil, err
+	}
+
+	for path, value := range left {
+		if other, ok := right[path]; !ok || !reflect.DeepEqual(value, other) {
+			results = append(results, Change{Path: path, Before: value, After: other})
+		}
+	}
+
+	for path, value := range right {
+		if _, ok := left[path]; !ok {
+			results = append(results, Change{Path: path, After: value})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Path < results[j].Path
+	})
+
+	return results, nil
+}
+
+func flatten(value any) (results map[string]any, err error) {
+
+	results = make(map[string]any)
+	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Pointer && reflect.ValueOf(value).IsNil()) {
+		return results, nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded any
+	if err = json.Unmarshal(encoded, &decoded); err != nil {
+		return nil, err
+	}
+
+	walk("", decoded, results)
+
+	return results, nil
+}
+
+func walk(prefix string, value any, results map[string]any) {
+
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	switch typed := value.(type) {
+	case map[string]any:
+		if len(typed) == 0 && prefix != "" {
+			results[prefix] = typed
+		}
+		for key, item := range typed {
+			walk(join(key), item, results)
+		}
+	case []any:
+		if len(typed) == 0 && prefix != "" {
+			results[prefix] = typed
+		}
+		for idx, item := range typed {
+			walk(join(strconv.Itoa(idx)), item, results)
+		}
+	default:
+		results[prefix] = typed
+	}
+}
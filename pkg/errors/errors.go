@@ -1,11 +1,26 @@
 package errs
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
-	ValidationError     = errors.New("validation error")
-	AuthenticationError = errors.New("failed to authenticate user")
-	ProcessingError     = errors.New("processing error")
-	AlreadyExistsError  = errors.New("entity already exits")
-	CannotBeFoundError  = errors.New("entity cannot be found")
+	ValidationError      = errors.New("validation error")
+	AuthenticationError  = errors.New("failed to authenticate user")
+	PermissionError      = errors.New("permission denied")
+	ProcessingError      = errors.New("processing error")
+	AlreadyExistsError   = errors.New("entity already exits")
+	CannotBeFoundError   = errors.New("entity cannot be found")
+	TooManyRequestsError = errors.New("too many requests")
 )
+
+// RetryAfterError tells the caller how long to wait before trying again.
+type RetryAfterError struct {
+	RetryAfter time.Duration
+}
+
+func (e RetryAfterError) Error() string {
+	return fmt.Sprintf("retry after %s", e.RetryAfter.Round(time.Second))
+}
@@ -0,0 +1,35 @@
+package identity
+
+import (
+	"assets/pkg/slices"
+	"context"
+)
+
+// Identity describes authenticated caller of the request. Callers authenticated with api key have no session, their
+// access is limited to scopes of the key.
+type Identity struct {
+	UserId    string
+	Email     string
+	Roles     []string
+	SessionId string
+	ApiKeyId  string
+	Scopes    []string
+}
+
+// Allows reports whether caller may access resources guarded by the scope, sessions are not limited by scopes.
+func (i Identity) Allows(scope string) bool {
+	return i.ApiKeyId == "" || slices.Contains(i.Scopes, scope)
+}
+
+type contextKey struct{}
+
+// WithIdentity returns copy of the context which carries provided identity.
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, identity)
+}
+
+// FromContext extracts identity from the context, second value reports whether identity was present.
+func FromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(contextKey{}).(Identity)
+	return identity, ok
+}
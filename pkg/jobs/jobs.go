@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"assets/pkg/logging"
+	"context"
+	"time"
+)
+
+// Every runs job on given interval until the context is cancelled, failures are logged and don't stop the schedule.
+func Every(ctx context.Context, logger logging.Logger, name string, interval time.Duration, job func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := job(ctx); err != nil {
+					logger.Info("job failed", "job", name, "err", err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,48 @@
+package secrets
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// Generate returns random, url safe secret.
+func Generate() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// Hash returns hex encoded sha256 of the secret, secrets are random so plain hash is sufficient.
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Matches compares secret with stored hash in constant time.
+func Matches(hash string, secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(Hash(secret))) == 1
+}
+
+// Join builds token handed out to the client in form of '<id>.<secret>'.
+func Join(id string, secret string) string {
+	return id + "." + secret
+}
+
+// Split extracts record id and secret from the token built with Join.
+func Split(token string) (id string, secret string, err error) {
+
+	id, secret, found := strings.Cut(token, ".")
+	if !found || id == "" || secret == "" {
+		return "", "", errors.New("malformed token")
+	}
+
+	return id, secret, nil
+}
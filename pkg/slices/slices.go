@@ -55,3 +55,25 @@ func MatchOrder[E any, S any](source []E, second []S, comparator Comparator[E, S
 
 	return result, nil
 }
+
+// Contains reports whether 'element' is present in the 'source' list.
+func Contains[E comparable](source []E, element E) bool {
+	for _, item := range source {
+		if item == element {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Filter returns new list with elements from 'source' for which 'predicate' returns true.
+func Filter[E any](source []E, predicate Function[E, bool]) (output []E) {
+	for _, item := range source {
+		if predicate(item) {
+			output = append(output, item)
+		}
+	}
+
+	return output
+}
@@ -0,0 +1,109 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RFC 6238 defaults, which are the only parameters supported by most authenticator apps.
+const (
+	Period = 30
+	Digits = 6
+	Skew   = 1
+
+	modulo = 1_000_000 // 10^Digits
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewSecret returns random base32 encoded secret.
+func NewSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return encoding.EncodeToString(secret), nil
+}
+
+// Code returns one time password valid at given time.
+func Code(secret string, at time.Time) (string, error) {
+	return code(secret, uint64(at.Unix()/Period))
+}
+
+// Validate checks code against given time, codes of 'Skew' neighbouring periods are accepted to tolerate clock drift.
+func Validate(secret string, passcode string, at time.Time) bool {
+	_, ok := Verify(secret, passcode, at, 0)
+	return ok
+}
+
+// Verify checks code like Validate does, but rejects codes of periods up to 'last', so accepted code cannot be replayed.
+// It returns period of the accepted code, which should be passed as 'last' to the next check.
+func Verify(secret string, passcode string, at time.Time, last int64) (step int64, ok bool) {
+
+	if len(passcode) != Digits {
+		return 0, false
+	}
+
+	current := at.Unix() / Period
+	for offset := int64(-Skew); offset <= Skew; offset++ {
+		step = current + offset
+		if step <= last {
+			continue
+		}
+
+		expected, err := code(secret, uint64(step))
+		if err != nil {
+			return 0, false
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(passcode)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// Uri returns key uri which can be rendered as QR code and scanned by authenticator apps.
+func Uri(issuer string, account string, secret string) string {
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(Digits))
+	query.Set("period", fmt.Sprint(Period))
+
+	label := url.PathEscape(issuer + ":" + account)
+
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+func code(secret string, counter uint64) (string, error) {
+
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", Digits, value%modulo), nil
+}
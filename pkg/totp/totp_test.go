@@ -0,0 +1,79 @@
+package totp
+
+import (
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type TotpSuite struct {
+	suite.Suite
+}
+
+// secret of RFC 6238 test vectors for SHA1, "12345678901234567890" encoded in base32
+var secret = encoding.EncodeToString([]byte("12345678901234567890"))
+
+// vectors of RFC 6238 appendix B for SHA1, codes are cut to the last 'Digits' digits of the 8 digit ones
+var vectors = []struct {
+	at   int64
+	code string
+}{
+	{at: 59, code: "287082"},
+	{at: 1111111109, code: "081804"},
+	{at: 1111111111, code: "050471"},
+	{at: 1234567890, code: "005924"},
+	{at: 2000000000, code: "279037"},
+	{at: 20000000000, code: "353130"},
+}
+
+func TestTotpSuite(t *testing.T) {
+	suite.Run(t, new(TotpSuite))
+}
+
+/*
+* Tests
+ */
+
+func (suite *TotpSuite) TestCodeShouldMatchRfcVectors() {
+
+	for _, vector := range vectors {
+		code, err := Code(secret, time.Unix(vector.at, 0))
+		suite.Nil(err, "should return empty error")
+		suite.Equal(vector.code, code, "should match code of RFC 6238 at %d", vector.at)
+	}
+}
+
+func (suite *TotpSuite) TestValidateShouldAcceptNeighbouringPeriods() {
+
+	for _, vector := range vectors {
+		at := time.Unix(vector.at, 0)
+
+		suite.True(Validate(secret, vector.code, at), "should accept code of current period")
+		suite.True(Validate(secret, vector.code, at.Add(Period*time.Second)), "should accept code of previous period")
+		suite.True(Validate(secret, vector.code, at.Add(-Period*time.Second)), "should accept code of next period")
+		suite.False(Validate(secret, vector.code, at.Add(2*Period*time.Second)), "should reject code outside of skew")
+	}
+
+	suite.False(Validate(secret, "28708", time.Unix(59, 0)), "should reject code of wrong length")
+	suite.False(Validate("not base32!", "287082", time.Unix(59, 0)), "should reject invalid secret")
+}
+
+func (suite *TotpSuite) TestVerifyShouldRejectCodesUpToLastStep() {
+
+	at := time.Unix(1111111109, 0)
+
+	step, ok := Verify(secret, "081804", at, 0)
+	suite.True(ok, "should accept valid code")
+	suite.Equal(int64(1111111109/Period), step, "should return period of the code")
+
+	_, ok = Verify(secret, "081804", at, step)
+	suite.False(ok, "should reject code of last accepted period")
+
+	_, ok = Verify(secret, "081804", at, step+1)
+	suite.False(ok, "should reject code of earlier period")
+
+	next, _ := Code(secret, at.Add(Period*time.Second))
+	nextStep, ok := Verify(secret, next, at, step)
+	suite.True(ok, "should accept code of later period")
+	suite.Equal(step+1, nextStep, "should return period of the code")
+}
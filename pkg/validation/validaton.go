@@ -9,6 +9,12 @@ type Validator interface {
 	Validate(obj any) error
 }
 
+// Checker is implemented by types with rules spanning several fields, which cannot be expressed by tags. Check is run
+// for every Checker found within the validated object once its tags are satisfied.
+type Checker interface {
+	Check() error
+}
+
 type DefaultValidator struct {
 	validate *validator.Validate
 }
@@ -37,6 +43,45 @@ func (v *DefaultValidator) Validate(obj any) error {
 		if err := v.validate.Struct(object); err != nil {
 			return err
 		}
+
+		if err := check(reflect.ValueOf(object)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var checkerType = reflect.TypeOf((*Checker)(nil)).Elem()
+
+// check walks exported fields, pointers and slices of the value and runs every Checker it finds.
+func check(value reflect.Value) error {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !value.IsNil() {
+			return check(value.Elem())
+		}
+	case reflect.Struct:
+		if value.Type().Implements(checkerType) && value.CanInterface() {
+			if err := value.Interface().(Checker).Check(); err != nil {
+				return err
+			}
+		}
+
+		for i := 0; i < value.NumField(); i++ {
+			if !value.Type().Field(i).IsExported() {
+				continue
+			}
+			if err := check(value.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := check(value.Index(i)); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil